@@ -196,10 +196,15 @@ func (egcd EthGasConsumeDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simula
 			return ctx, errorsmod.Wrapf(err, "failed to deduct transaction costs from user balance")
 		}
 
+		// the fee event is in units of the evm denom, like the fee of the cosmos tx
+		feeUnits := sdk.Coins{}
+		if fee := fees.AmountOf(evmDenom); fee.IsPositive() {
+			feeUnits = sdk.Coins{{Denom: evmDenom, Amount: sdkmath.NewIntFromBigInt(evmParams.FeeUnits(fee.BigInt()))}}
+		}
 		events = append(events,
 			sdk.NewEvent(
 				sdk.EventTypeTx,
-				sdk.NewAttribute(sdk.AttributeKeyFee, fees.String()),
+				sdk.NewAttribute(sdk.AttributeKeyFee, feeUnits.String()),
 			),
 		)
 
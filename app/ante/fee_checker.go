@@ -80,7 +80,8 @@ func NewDynamicFeeChecker(k DynamicFeeEVMKeeper) authante.TxFeeChecker {
 		feeCoins := feeTx.GetFee()
 		fee := feeCoins.AmountOfNoDenomValidation(denom)
 
-		feeCap := fee.Quo(sdkmath.NewIntFromUint64(gas))
+		// the fee is in units of the evm denom, the prices are compared in wei
+		feeCap := fee.Mul(sdkmath.NewIntFromBigInt(params.EVMConversionFactor())).Quo(sdkmath.NewIntFromUint64(gas))
 		baseFeeInt := sdkmath.NewIntFromBigInt(baseFee)
 
 		if feeCap.LT(baseFeeInt) {
@@ -94,7 +95,7 @@ func NewDynamicFeeChecker(k DynamicFeeEVMKeeper) authante.TxFeeChecker {
 		effectiveFee := sdk.Coins{
 			{
 				Denom:  denom,
-				Amount: effectivePrice.Mul(sdkmath.NewIntFromUint64(gas)),
+				Amount: sdkmath.NewIntFromBigInt(params.FeeUnits(effectivePrice.Mul(sdkmath.NewIntFromUint64(gas)).BigInt())),
 			},
 		}
 
@@ -22,8 +22,9 @@ import (
 var _ DynamicFeeEVMKeeper = MockEVMKeeper{}
 
 type MockEVMKeeper struct {
-	BaseFee        *big.Int
-	EnableLondonHF bool
+	BaseFee          *big.Int
+	EnableLondonHF   bool
+	ConversionFactor uint64
 }
 
 func (m MockEVMKeeper) GetBaseFee(ctx sdk.Context, ethCfg *params.ChainConfig) *big.Int {
@@ -34,7 +35,11 @@ func (m MockEVMKeeper) GetBaseFee(ctx sdk.Context, ethCfg *params.ChainConfig) *
 }
 
 func (m MockEVMKeeper) GetParams(ctx sdk.Context) evmtypes.Params {
-	return evmtypes.DefaultParams()
+	params := evmtypes.DefaultParams()
+	if m.ConversionFactor != 0 {
+		params.ConversionFactor = m.ConversionFactor
+	}
+	return params
 }
 
 func (m MockEVMKeeper) ChainID() *big.Int {
@@ -203,6 +208,38 @@ func TestSDKTxFeeChecker(t *testing.T) {
 			5,
 			true,
 		},
+		{
+			"fail, dynamic fee in units of the conversion factor",
+			deliverTxCtx,
+			MockEVMKeeper{
+				EnableLondonHF: true, BaseFee: big.NewInt(1000000000000), ConversionFactor: 1000000000000,
+			},
+			func() sdk.Tx {
+				txBuilder := encodingConfig.TxConfig.NewTxBuilder()
+				txBuilder.SetGasLimit(2)
+				txBuilder.SetFeeAmount(sdk.NewCoins(sdk.NewCoin("aphoton", sdkmath.NewInt(1))))
+				return txBuilder.GetTx()
+			},
+			"",
+			0,
+			false,
+		},
+		{
+			"success, dynamic fee in units of the conversion factor",
+			deliverTxCtx,
+			MockEVMKeeper{
+				EnableLondonHF: true, BaseFee: big.NewInt(1000000000000), ConversionFactor: 1000000000000,
+			},
+			func() sdk.Tx {
+				txBuilder := encodingConfig.TxConfig.NewTxBuilder()
+				txBuilder.SetGasLimit(2)
+				txBuilder.SetFeeAmount(sdk.NewCoins(sdk.NewCoin("aphoton", sdkmath.NewInt(3))))
+				return txBuilder.GetTx()
+			},
+			"3aphoton",
+			500000,
+			true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -91,10 +91,11 @@ func (mpd MinGasPriceDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate
 	}
 	evmParams := mpd.evmKeeper.GetParams(ctx)
 	evmDenom := evmParams.GetEvmDenom()
+	// the min gas price is in wei, the fees of the cosmos txs are in units of the evm denom
 	minGasPrices := sdk.DecCoins{
 		{
 			Denom:  evmDenom,
-			Amount: minGasPrice,
+			Amount: evmParams.GasPriceToUnits(minGasPrice),
 		},
 	}
 
@@ -204,7 +205,8 @@ func (mfd EthMempoolFeeDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulat
 	}
 
 	evmDenom := evmParams.GetEvmDenom()
-	minGasPrice := ctx.MinGasPrices().AmountOf(evmDenom)
+	// the min-gas-prices are in units of the evm denom, the fees of the eth txs are in wei
+	minGasPrice := evmParams.GasPriceToWei(ctx.MinGasPrices().AmountOf(evmDenom))
 
 	for _, msg := range tx.GetMsgs() {
 		ethMsg, ok := msg.(*evmtypes.MsgEthereumTx)
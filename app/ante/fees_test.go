@@ -346,6 +346,36 @@ func (s AnteTestSuite) TestEthMinGasPriceDecorator() {
 	}
 }
 
-func (suite AnteTestSuite) TestEthMempoolFeeDecorator() {
-	// TODO: add test
+func (s AnteTestSuite) TestEthMempoolFeeDecorator() {
+	// the min-gas-prices are in units of the evm denom, the gas prices of the eth txs are in wei
+	s.enableLondonHF = false
+	s.evmParamsOption = func(params *evmtypes.Params) {
+		params.ConversionFactor = 1000000000000
+	}
+	s.SetupTest()
+	minGasPrices := sdk.NewDecCoins(sdk.NewDecCoinFromDec(evmtypes.DefaultEVMDenom, sdkmath.LegacyNewDecWithPrec(1, 2)))
+	ctx := s.ctx.WithIsCheckTx(true).WithMinGasPrices(minGasPrices)
+	from, privKey := tests.NewAddrKey()
+	to := tests.GenerateAddress()
+
+	testCases := []struct {
+		name     string
+		gasPrice *big.Int
+		expPass  bool
+	}{
+		{"gas price below the min-gas-prices", big.NewInt(9999999999), false},
+		{"gas price of the min-gas-prices", big.NewInt(10000000000), true},
+	}
+	for _, tc := range testCases {
+		s.Run(tc.name, func() {
+			msg := s.BuildTestEthTx(from, to, nil, make([]byte, 0), tc.gasPrice, nil, nil, nil)
+			dec := ante.NewEthMempoolFeeDecorator(s.app.EvmKeeper)
+			_, err := dec.AnteHandle(ctx, s.CreateTestTx(msg, privKey, 1, false), false, NextFn)
+			if tc.expPass {
+				s.Require().NoError(err)
+			} else {
+				s.Require().ErrorContains(err, "insufficient fee")
+			}
+		})
+	}
 }
@@ -0,0 +1,285 @@
+// Copyright 2021 Evmos Foundation
+// This file is part of Evmos' Ethermint library.
+//
+// The Ethermint library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Ethermint library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Ethermint library. If not, see https://github.com/evmos/ethermint/blob/main/LICENSE
+package indexer
+
+import (
+	"bytes"
+
+	errorsmod "cosmossdk.io/errors"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/bitutil"
+	"github.com/ethereum/go-ethereum/core/bloombits"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// BloomBitsSectionSize is the number of blocks of a bloom bits section, the bloom bits
+// of a section are generated once the blooms of all its blocks are indexed.
+const BloomBitsSectionSize = params.BloomBitsBlocks
+
+// bloomIndexes are the bloom bits set by an address or a topic.
+type bloomIndexes [3]uint
+
+// bloomGroups are the bloom indexes of the filter criteria, a block matches if it
+// matches any of the alternatives of every group.
+type bloomGroups [][]bloomIndexes
+
+// newBloomGroups builds the bloom groups of the addresses and topics, the wildcards
+// are skipped.
+func newBloomGroups(addresses []common.Address, topics [][]common.Hash) bloomGroups {
+	var groups bloomGroups
+	if len(addresses) > 0 {
+		group := make([]bloomIndexes, len(addresses))
+		for i, address := range addresses {
+			group[i] = calcBloomIndexes(address.Bytes())
+		}
+		groups = append(groups, group)
+	}
+	for _, alternatives := range topics {
+		if len(alternatives) == 0 {
+			continue
+		}
+		group := make([]bloomIndexes, len(alternatives))
+		for i, topic := range alternatives {
+			group[i] = calcBloomIndexes(topic.Bytes())
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// calcBloomIndexes returns the bloom bits set by the data, the bit indexes are the
+// ones of the bloombits generator.
+func calcBloomIndexes(data []byte) bloomIndexes {
+	hash := crypto.Keccak256(data)
+
+	var idxs bloomIndexes
+	for i := range idxs {
+		idxs[i] = (uint(hash[2*i])<<8)&2047 + uint(hash[2*i+1])
+	}
+	return idxs
+}
+
+// matchBloom checks if a block bloom matches the groups.
+func (groups bloomGroups) matchBloom(bloom []byte) bool {
+	if len(bloom) == 0 {
+		// the block has no logs
+		return false
+	}
+	isSet := func(idx uint) bool {
+		return bloom[ethtypes.BloomByteLength-1-idx/8]&(1<<(idx%8)) != 0
+	}
+	for _, group := range groups {
+		matched := false
+		for _, idxs := range group {
+			if isSet(idxs[0]) && isSet(idxs[1]) && isSet(idxs[2]) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// bloomCandidates returns the blocks of [from, to] whose blooms match the groups. The
+// bloom bits are used for the complete sections, and the block blooms for the others.
+func (kv *KVIndexer) bloomCandidates(from, to int64, groups bloomGroups) ([]int64, error) {
+	var heights []int64
+	for section := uint64(from) / BloomBitsSectionSize; section <= uint64(to)/BloomBitsSectionSize; section++ { //#nosec G115
+		start := max(from, int64(section*BloomBitsSectionSize))   //#nosec G115
+		end := min(to, int64((section+1)*BloomBitsSectionSize)-1) //#nosec G115
+		complete, err := kv.db.Has(BloomSectionKey(section))
+		if err != nil {
+			return nil, errorsmod.Wrap(err, "bloomCandidates")
+		}
+
+		if !complete {
+			it, err := kv.db.Iterator(BlockBloomKey(start), BlockBloomKey(end+1))
+			if err != nil {
+				return nil, errorsmod.Wrap(err, "bloomCandidates")
+			}
+			for ; it.Valid(); it.Next() {
+				if groups.matchBloom(it.Value()) {
+					heights = append(heights, int64(sdk.BigEndianToUint64(it.Key()[1:]))) //#nosec G115
+				}
+			}
+			err = it.Error()
+			it.Close()
+			if err != nil {
+				return nil, errorsmod.Wrap(err, "bloomCandidates")
+			}
+			continue
+		}
+
+		matches, err := kv.matchSection(section, groups)
+		if err != nil {
+			return nil, err
+		}
+		for height := start; height <= end; height++ {
+			i := uint64(height) - section*BloomBitsSectionSize //#nosec G115
+			if matches[i/8]&(1<<(7-i%8)) != 0 {
+				heights = append(heights, height)
+			}
+		}
+	}
+	return heights, nil
+}
+
+// matchSection returns the bit vector of the blocks of a complete section matching the groups.
+func (kv *KVIndexer) matchSection(section uint64, groups bloomGroups) ([]byte, error) {
+	vectors := make(map[uint][]byte)
+	loadVector := func(bit uint) ([]byte, error) {
+		if vector, ok := vectors[bit]; ok {
+			return vector, nil
+		}
+		bz, err := kv.db.Get(BloomBitsKey(bit, section))
+		if err != nil {
+			return nil, errorsmod.Wrapf(err, "load bloom bits %d of section %d", bit, section)
+		}
+		vector := make([]byte, BloomBitsSectionSize/8)
+		if len(bz) > 0 {
+			vector, err = bitutil.DecompressBytes(bz, int(BloomBitsSectionSize/8))
+			if err != nil {
+				return nil, errorsmod.Wrapf(err, "decompress bloom bits %d of section %d", bit, section)
+			}
+		}
+		vectors[bit] = vector
+		return vector, nil
+	}
+
+	var matches []byte
+	for _, group := range groups {
+		groupMatches := make([]byte, BloomBitsSectionSize/8)
+		for _, idxs := range group {
+			alternative := bytes.Repeat([]byte{0xff}, int(BloomBitsSectionSize/8))
+			for _, idx := range idxs {
+				vector, err := loadVector(idx)
+				if err != nil {
+					return nil, err
+				}
+				bitutil.ANDBytes(alternative, alternative, vector)
+			}
+			bitutil.ORBytes(groupMatches, groupMatches, alternative)
+		}
+		if matches == nil {
+			matches = groupMatches
+		} else {
+			bitutil.ANDBytes(matches, matches, groupMatches)
+		}
+	}
+	return matches, nil
+}
+
+// generateBloomBits generates and stores the bloom bits of a section if the blooms of
+// all its blocks are indexed and the section is not already generated.
+func (kv *KVIndexer) generateBloomBits(section uint64) error {
+	done, err := kv.db.Has(BloomSectionKey(section))
+	if err != nil || done {
+		return err
+	}
+
+	generator, err := bloombits.NewGenerator(uint(BloomBitsSectionSize))
+	if err != nil {
+		return err
+	}
+	start := int64(section * BloomBitsSectionSize)     //#nosec G115
+	end := int64((section + 1) * BloomBitsSectionSize) //#nosec G115
+	next := start
+	if next == 0 {
+		// there's no genesis block
+		if err := generator.AddBloom(0, ethtypes.Bloom{}); err != nil {
+			return err
+		}
+		next++
+	}
+
+	it, err := kv.db.Iterator(BlockBloomKey(start), BlockBloomKey(end))
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	for ; it.Valid(); it.Next() {
+		height := int64(sdk.BigEndianToUint64(it.Key()[1:])) //#nosec G115
+		if height != next {
+			// the section is not complete
+			return nil
+		}
+		if err := generator.AddBloom(uint(height-start), ethtypes.BytesToBloom(it.Value())); err != nil { //#nosec G115
+			return err
+		}
+		next++
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if next != end {
+		return nil
+	}
+
+	batch := kv.db.NewBatch()
+	defer batch.Close()
+	empty := make([]byte, BloomBitsSectionSize/8)
+	for bit := uint(0); bit < ethtypes.BloomBitLength; bit++ {
+		bits, err := generator.Bitset(bit)
+		if err != nil {
+			return err
+		}
+		// save space for the bits never set in the section
+		if bytes.Equal(bits, empty) {
+			continue
+		}
+		if err := batch.Set(BloomBitsKey(bit, section), bitutil.CompressBytes(bits)); err != nil {
+			return err
+		}
+	}
+	if err := batch.Set(BloomSectionKey(section), []byte{}); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// BloomStatus returns the section size and the number of sections of the bloom bits index.
+func (kv *KVIndexer) BloomStatus() (uint64, uint64) {
+	it, err := kv.db.Iterator([]byte{KeyPrefixBloomSection}, []byte{KeyPrefixBloomSection + 1})
+	if err != nil {
+		kv.logger.Error("failed to iterate bloom sections", "err", err)
+		return BloomBitsSectionSize, 0
+	}
+	defer it.Close()
+
+	var sections uint64
+	for ; it.Valid(); it.Next() {
+		sections++
+	}
+	return BloomBitsSectionSize, sections
+}
+
+// BloomBitsKey returns the key for db entry: `(bit index, section) -> compressed bit vector`
+func BloomBitsKey(bit uint, section uint64) []byte {
+	bz := append([]byte{KeyPrefixBloomBits}, sdk.Uint64ToBigEndian(uint64(bit))[6:]...)
+	return append(bz, sdk.Uint64ToBigEndian(section)...)
+}
+
+// BloomSectionKey returns the key for db entry: `section -> nil`, it marks the sections
+// with generated bloom bits.
+func BloomSectionKey(section uint64) []byte {
+	return append([]byte{KeyPrefixBloomSection}, sdk.Uint64ToBigEndian(section)...)
+}
@@ -0,0 +1,108 @@
+package indexer_test
+
+import (
+	"math/big"
+	"testing"
+
+	"cosmossdk.io/log"
+	abci "github.com/cometbft/cometbft/abci/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtx "github.com/cosmos/cosmos-sdk/x/auth/tx"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/evmos/ethermint/crypto/ethsecp256k1"
+	evmenc "github.com/evmos/ethermint/encoding"
+	"github.com/evmos/ethermint/indexer"
+	rpctypes "github.com/evmos/ethermint/rpc/types"
+	"github.com/evmos/ethermint/tests"
+	"github.com/evmos/ethermint/x/evm"
+	evmtypes "github.com/evmos/ethermint/x/evm/types"
+)
+
+func TestParseEthTxs(t *testing.T) {
+	priv, err := ethsecp256k1.GenerateKey()
+	require.NoError(t, err)
+	signer := tests.NewSigner(priv)
+	ethSigner := ethtypes.LatestSignerForChainID(nil)
+	txConfig := evmenc.MakeTestEncodingConfig(evm.AppModuleBasic{}).TxConfig
+
+	to := common.BigToAddress(big.NewInt(1))
+	newMsg := func(nonce uint64) *evmtypes.MsgEthereumTx {
+		msg := evmtypes.NewTx(nil, nonce, &to, big.NewInt(1000), 30000, nil, nil, nil, nil, nil)
+		msg.From = common.BytesToAddress(priv.PubKey().Address().Bytes()).Hex()
+		require.NoError(t, msg.Sign(ethSigner, signer))
+		return msg
+	}
+	encode := func(ext bool, msgs ...*evmtypes.MsgEthereumTx) tmtypes.Tx {
+		builder := txConfig.NewTxBuilder()
+		if ext {
+			option, err := codectypes.NewAnyWithValue(&evmtypes.ExtensionOptionsEthereumTx{})
+			require.NoError(t, err)
+			builder.(authtx.ExtensionOptionsTxBuilder).SetExtensionOptions(option)
+		}
+		sdkMsgs := make([]sdk.Msg, len(msgs))
+		for i, msg := range msgs {
+			msg.From = ""
+			sdkMsgs[i] = msg
+		}
+		require.NoError(t, builder.SetMsgs(sdkMsgs...))
+		bz, err := txConfig.TxEncoder()(builder.GetTx())
+		require.NoError(t, err)
+		return bz
+	}
+	ethTxEvent := func(msg *evmtypes.MsgEthereumTx, index, gasUsed string) abci.Event {
+		return abci.Event{Type: evmtypes.EventTypeEthereumTx, Attributes: []abci.EventAttribute{
+			{Key: "ethereumTxHash", Value: msg.Hash},
+			{Key: "txIndex", Value: index},
+			{Key: "txGasUsed", Value: gasUsed},
+		}}
+	}
+
+	msg1, msg2, msg3, msg4 := newMsg(0), newMsg(1), newMsg(2), newMsg(3)
+	block := &tmtypes.Block{Header: tmtypes.Header{Height: 1}, Data: tmtypes.Data{Txs: []tmtypes.Tx{
+		[]byte("invalid tx"),
+		encode(false, msg1),
+		encode(true, msg1, msg2),
+		encode(true, msg3),
+		encode(true, msg3, msg4),
+	}}}
+	txResults := []*abci.ExecTxResult{
+		{Code: 0},
+		// without the extension option, the eth tx is invalid
+		{Code: 0, Events: []abci.Event{ethTxEvent(msg1, "0", "21000")}},
+		// exceeds the block gas limit, the gas limit of the msgs is charged
+		{Code: 11, Log: rpctypes.ExceedBlockGasLimitError + " 60000"},
+		// the txs with invalid events are skipped
+		{Code: 0, Events: []abci.Event{ethTxEvent(msg3, "0x1", "21000")}},
+		{Code: 0, Events: []abci.Event{ethTxEvent(msg3, "2", "21000"), ethTxEvent(msg4, "3", "22000")}},
+	}
+
+	txs := indexer.ParseEthTxs(txConfig.TxDecoder(), log.NewNopLogger(), block, txResults)
+	require.Len(t, txs, 4)
+	expected := []struct {
+		msg               *evmtypes.MsgEthereumTx
+		txIndex, msgIndex uint32
+		gasUsed           uint64
+		cumulativeGasUsed uint64
+		failed            bool
+	}{
+		{msg1, 2, 0, 30000, 30000, true},
+		{msg2, 2, 1, 30000, 60000, true},
+		{msg3, 4, 0, 21000, 21000, false},
+		{msg4, 4, 1, 22000, 43000, false},
+	}
+	for i, exp := range expected {
+		require.Equal(t, exp.msg.AsTransaction().Hash(), txs[i].Hash)
+		require.Equal(t, int64(1), txs[i].Result.Height)
+		require.Equal(t, exp.txIndex, txs[i].Result.TxIndex)
+		require.Equal(t, exp.msgIndex, txs[i].Result.MsgIndex)
+		require.Equal(t, int32(i), txs[i].Result.EthTxIndex) //#nosec G115
+		require.Equal(t, exp.gasUsed, txs[i].Result.GasUsed)
+		require.Equal(t, exp.cumulativeGasUsed, txs[i].Result.CumulativeGasUsed)
+		require.Equal(t, exp.failed, txs[i].Result.Failed)
+	}
+}
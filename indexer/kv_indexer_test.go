@@ -1,6 +1,7 @@
 package indexer_test
 
 import (
+	"encoding/json"
 	"math/big"
 	"testing"
 
@@ -16,6 +17,7 @@ import (
 	evmenc "github.com/evmos/ethermint/encoding"
 	"github.com/evmos/ethermint/indexer"
 	"github.com/evmos/ethermint/tests"
+	ethermint "github.com/evmos/ethermint/types"
 	"github.com/evmos/ethermint/x/evm"
 	"github.com/evmos/ethermint/x/evm/types"
 	"github.com/stretchr/testify/require"
@@ -195,6 +197,238 @@ func TestKVIndexer(t *testing.T) {
 	}
 }
 
+// logsBlockResult builds the result of a block with a tx emitting the logs.
+func logsBlockResult(t *testing.T, logs ...*ethtypes.Log) *abci.ResponseFinalizeBlock {
+	attrs := make([]abci.EventAttribute, len(logs))
+	for i, log := range logs {
+		bz, err := json.Marshal(types.NewLogFromEth(log))
+		require.NoError(t, err)
+		attrs[i] = abci.EventAttribute{Key: types.AttributeKeyTxLog, Value: string(bz)}
+	}
+	return &abci.ResponseFinalizeBlock{
+		TxResults: []*abci.ExecTxResult{{Events: []abci.Event{{Type: types.EventTypeTxLog, Attributes: attrs}}}},
+	}
+}
+
+func TestKVIndexerLogs(t *testing.T) {
+	encodingConfig := MakeEncodingConfig()
+	clientCtx := client.Context{}.WithTxConfig(encodingConfig.TxConfig).WithCodec(encodingConfig.Codec)
+	testIndexerLogs(t, indexer.NewKVIndexer(dbm.NewMemDB(), log.NewNopLogger(), clientCtx))
+}
+
+// logsIndexer is an eth tx indexer which also index the logs.
+type logsIndexer interface {
+	ethermint.EVMTxIndexer
+	ethermint.EVMLogIndexer
+}
+
+// testIndexerLogs runs the logs range queries test cases against an empty indexer.
+func testIndexerLogs(t *testing.T, idxer logsIndexer) {
+	addr1, addr2 := common.BigToAddress(big.NewInt(1)), common.BigToAddress(big.NewInt(2))
+	topic1, topic2 := common.BigToHash(big.NewInt(1)), common.BigToHash(big.NewInt(2))
+	newLog := func(height uint64, index uint, address common.Address, topics ...common.Hash) *ethtypes.Log {
+		if topics == nil {
+			topics = []common.Hash{}
+		}
+		return &ethtypes.Log{Address: address, Topics: topics, Data: []byte{}, BlockNumber: height, Index: index}
+	}
+	blockLogs := map[int64][]*ethtypes.Log{
+		1: {newLog(1, 0, addr1, topic1), newLog(1, 1, addr2, topic2)},
+		3: {newLog(3, 0, addr2, topic1, topic2)},
+		4: {newLog(4, 0, addr1)},
+	}
+
+	first, last, err := idxer.LogsIndexedRange()
+	require.NoError(t, err)
+	require.Equal(t, int64(-1), first)
+	require.Equal(t, int64(-1), last)
+
+	for height := int64(1); height <= 5; height++ {
+		block := &tmtypes.Block{Header: tmtypes.Header{Height: height}}
+		require.NoError(t, idxer.IndexBlock(block, logsBlockResult(t, blockLogs[height]...)))
+	}
+
+	first, last, err = idxer.LogsIndexedRange()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), first)
+	require.Equal(t, int64(5), last)
+
+	testCases := []struct {
+		name      string
+		from, to  int64
+		addresses []common.Address
+		topics    [][]common.Hash
+		limit     int
+		expLogs   []*ethtypes.Log
+		expPass   bool
+	}{
+		{
+			"all logs",
+			1, 5, nil, nil, 10,
+			[]*ethtypes.Log{blockLogs[1][0], blockLogs[1][1], blockLogs[3][0], blockLogs[4][0]},
+			true,
+		},
+		{
+			"by address",
+			1, 5, []common.Address{addr1}, nil, 10,
+			[]*ethtypes.Log{blockLogs[1][0], blockLogs[4][0]},
+			true,
+		},
+		{
+			"by addresses and range",
+			2, 5, []common.Address{addr1, addr2}, nil, 10,
+			[]*ethtypes.Log{blockLogs[3][0], blockLogs[4][0]},
+			true,
+		},
+		{
+			"by topic",
+			1, 5, nil, [][]common.Hash{{topic1}}, 10,
+			[]*ethtypes.Log{blockLogs[1][0], blockLogs[3][0]},
+			true,
+		},
+		{
+			"by topic position",
+			1, 5, nil, [][]common.Hash{{}, {topic2}}, 10,
+			[]*ethtypes.Log{blockLogs[3][0]},
+			true,
+		},
+		{
+			"by number of topics",
+			1, 5, nil, [][]common.Hash{{}}, 10,
+			[]*ethtypes.Log{blockLogs[1][0], blockLogs[1][1], blockLogs[3][0]},
+			true,
+		},
+		{
+			"by address and topics",
+			1, 5, []common.Address{addr2}, [][]common.Hash{{topic1, topic2}}, 10,
+			[]*ethtypes.Log{blockLogs[1][1], blockLogs[3][0]},
+			true,
+		},
+		{
+			"no match",
+			1, 5, []common.Address{addr1}, [][]common.Hash{{topic2}}, 10,
+			[]*ethtypes.Log{},
+			true,
+		},
+		{
+			"limit exceeded",
+			1, 5, nil, nil, 3,
+			nil,
+			false,
+		},
+		{
+			"limit exceeded with criteria",
+			1, 5, []common.Address{addr1}, nil, 1,
+			nil,
+			false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logs, err := idxer.GetLogs(tc.from, tc.to, tc.addresses, tc.topics, tc.limit)
+			if tc.expPass {
+				require.NoError(t, err)
+				require.Equal(t, tc.expLogs, logs)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestKVIndexerLogsRange(t *testing.T) {
+	encodingConfig := MakeEncodingConfig()
+	clientCtx := client.Context{}.WithTxConfig(encodingConfig.TxConfig).WithCodec(encodingConfig.Codec)
+	testIndexerLogsRange(t, indexer.NewKVIndexer(dbm.NewMemDB(), log.NewNopLogger(), clientCtx))
+}
+
+// testIndexerLogsRange checks that the range of the indexed logs stops at the blocks
+// which failed to be indexed, against an empty indexer.
+func testIndexerLogsRange(t *testing.T, idxer logsIndexer) {
+	indexBlock := func(height int64, blockResult *abci.ResponseFinalizeBlock) error {
+		return idxer.IndexBlock(&tmtypes.Block{Header: tmtypes.Header{Height: height}}, blockResult)
+	}
+	requireRange := func(expFirst, expLast int64) {
+		first, last, err := idxer.LogsIndexedRange()
+		require.NoError(t, err)
+		require.Equal(t, expFirst, first)
+		require.Equal(t, expLast, last)
+	}
+	invalidLogs := &abci.ResponseFinalizeBlock{TxResults: []*abci.ExecTxResult{{Events: []abci.Event{{
+		Type:       types.EventTypeTxLog,
+		Attributes: []abci.EventAttribute{{Key: types.AttributeKeyTxLog, Value: "{"}},
+	}}}}}
+
+	require.NoError(t, indexBlock(10, logsBlockResult(t)))
+	require.NoError(t, indexBlock(11, logsBlockResult(t)))
+	requireRange(10, 11)
+
+	// the range doesn't move past a block which failed to be indexed
+	require.Error(t, indexBlock(12, invalidLogs))
+	require.NoError(t, indexBlock(13, logsBlockResult(t)))
+	requireRange(10, 11)
+
+	// the blocks are indexed backward too
+	require.NoError(t, indexBlock(9, logsBlockResult(t)))
+	requireRange(9, 11)
+
+	// the range is extended over the blocks indexed past the gap once it's indexed
+	require.NoError(t, indexBlock(12, logsBlockResult(t)))
+	requireRange(9, 13)
+	require.NoError(t, indexBlock(11, logsBlockResult(t)))
+	requireRange(9, 13)
+}
+
+func TestKVIndexerBloomBits(t *testing.T) {
+	addr1, addr2 := common.BigToAddress(big.NewInt(1)), common.BigToAddress(big.NewInt(2))
+	topic := common.BigToHash(big.NewInt(1))
+	heights := []int64{1, 100, 4000, 4095, 4096, 4100}
+	logs := make(map[int64]*ethtypes.Log)
+	for i, height := range heights {
+		address := addr1
+		if i%2 == 1 {
+			address = addr2
+		}
+		logs[height] = &ethtypes.Log{Address: address, Topics: []common.Hash{topic}, Data: []byte{}, BlockNumber: uint64(height)}
+	}
+
+	encodingConfig := MakeEncodingConfig()
+	clientCtx := client.Context{}.WithTxConfig(encodingConfig.TxConfig).WithCodec(encodingConfig.Codec)
+	idxer := indexer.NewKVIndexer(dbm.NewMemDB(), log.NewNopLogger(), clientCtx)
+
+	// index backward, the section is complete once its first block is indexed
+	last := int64(indexer.BloomBitsSectionSize + 10)
+	for height := last; height >= 1; height-- {
+		var blockLogs []*ethtypes.Log
+		if log, ok := logs[height]; ok {
+			blockLogs = append(blockLogs, log)
+		}
+		block := &tmtypes.Block{Header: tmtypes.Header{Height: height}}
+		require.NoError(t, idxer.IndexBlock(block, logsBlockResult(t, blockLogs...)))
+
+		_, sections := idxer.BloomStatus()
+		if height == 1 {
+			require.Equal(t, uint64(1), sections)
+		} else {
+			require.Zero(t, sections)
+		}
+	}
+
+	// the range covers the complete section and the next one
+	res, err := idxer.GetLogs(1, last, []common.Address{addr1}, nil, 10)
+	require.NoError(t, err)
+	require.Equal(t, []*ethtypes.Log{logs[1], logs[4000], logs[4096]}, res)
+
+	res, err = idxer.GetLogs(50, 4096, nil, [][]common.Hash{{topic}}, 10)
+	require.NoError(t, err)
+	require.Equal(t, []*ethtypes.Log{logs[100], logs[4000], logs[4095], logs[4096]}, res)
+
+	res, err = idxer.GetLogs(1, last, []common.Address{addr2}, [][]common.Hash{{common.BigToHash(big.NewInt(2))}}, 10)
+	require.NoError(t, err)
+	require.Empty(t, res)
+}
+
 // MakeEncodingConfig creates the EncodingConfig
 func MakeEncodingConfig() params.EncodingConfig {
 	return evmenc.MakeTestEncodingConfig(evm.AppModuleBasic{})
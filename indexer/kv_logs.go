@@ -0,0 +1,396 @@
+// Copyright 2021 Evmos Foundation
+// This file is part of Evmos' Ethermint library.
+//
+// The Ethermint library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Ethermint library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Ethermint library. If not, see https://github.com/evmos/ethermint/blob/main/LICENSE
+package indexer
+
+import (
+	"encoding/json"
+	"sort"
+
+	errorsmod "cosmossdk.io/errors"
+	abci "github.com/cometbft/cometbft/abci/types"
+	dbm "github.com/cosmos/cosmos-db"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+
+	ethermint "github.com/evmos/ethermint/types"
+	evmtypes "github.com/evmos/ethermint/x/evm/types"
+)
+
+// maxIndexedTopics is the number of log topic positions in the topic index.
+const maxIndexedTopics = 4
+
+// indexLogs stores the logs of a block, keyed by position in the block, by address and
+// by topic, and the bloom of the block. The block extends the range of the blocks
+// indexed without gap if it's next to it.
+func (kv *KVIndexer) indexLogs(batch dbm.Batch, height int64, blockResult *abci.ResponseFinalizeBlock) error {
+	var (
+		bloom    ethtypes.Bloom
+		position uint64
+	)
+	for i, result := range blockResult.TxResults {
+		logs, err := parseLogsFromEvents(result.Events)
+		if err != nil {
+			return errorsmod.Wrapf(err, "parse logs of tx %d", i)
+		}
+
+		for _, log := range logs {
+			bz, err := json.Marshal(log)
+			if err != nil {
+				return errorsmod.Wrap(err, "marshal log")
+			}
+			if err := batch.Set(LogKey(height, position), bz); err != nil {
+				return errorsmod.Wrap(err, "set log key")
+			}
+			if err := batch.Set(LogAddressKey(log.Address, height, position), []byte{}); err != nil {
+				return errorsmod.Wrap(err, "set log address key")
+			}
+			for i, topic := range log.Topics {
+				if i == maxIndexedTopics {
+					break
+				}
+				if err := batch.Set(LogTopicKey(i, topic, height, position), []byte{}); err != nil {
+					return errorsmod.Wrap(err, "set log topic key")
+				}
+			}
+
+			bloom.Add(log.Address.Bytes())
+			for _, topic := range log.Topics {
+				bloom.Add(topic.Bytes())
+			}
+			position++
+		}
+	}
+
+	// save space for the blocks without logs
+	bz := []byte{}
+	if bloom != (ethtypes.Bloom{}) {
+		bz = bloom.Bytes()
+	}
+	if err := batch.Set(BlockBloomKey(height), bz); err != nil {
+		return errorsmod.Wrap(err, "set block bloom key")
+	}
+
+	first, last, err := kv.LogsIndexedRange()
+	if err != nil {
+		return err
+	}
+	newFirst, newLast, err := extendIndexedRange(first, last, height, func(height int64) (bool, error) {
+		return kv.db.Has(BlockBloomKey(height))
+	})
+	if err != nil {
+		return errorsmod.Wrap(err, "extend logs range")
+	}
+	if newFirst != first || newLast != last {
+		bz := append(sdk.Uint64ToBigEndian(uint64(newFirst)), sdk.Uint64ToBigEndian(uint64(newLast))...) //#nosec G115
+		if err := batch.Set([]byte{KeyPrefixLogsRange}, bz); err != nil {
+			return errorsmod.Wrap(err, "set logs range key")
+		}
+	}
+	return nil
+}
+
+// LogsIndexedRange returns the first and last blocks of the range indexed without gap,
+// -1 if none. The range stops at the blocks which failed to be indexed, the logs of the
+// blocks indexed past a gap are not served until the gap is indexed.
+func (kv *KVIndexer) LogsIndexedRange() (int64, int64, error) {
+	bz, err := kv.db.Get([]byte{KeyPrefixLogsRange})
+	if err != nil {
+		return 0, 0, errorsmod.Wrap(err, "LogsIndexedRange")
+	}
+	if len(bz) != 16 {
+		return -1, -1, nil
+	}
+	return int64(sdk.BigEndianToUint64(bz[:8])), int64(sdk.BigEndianToUint64(bz[8:])), nil //#nosec G115
+}
+
+// GetBlockBloom returns the bloom of the logs of a block, false if the logs of the block
+// are not indexed.
+func (kv *KVIndexer) GetBlockBloom(height int64) (ethtypes.Bloom, bool, error) {
+	found, err := kv.db.Has(BlockBloomKey(height))
+	if err != nil || !found {
+		return ethtypes.Bloom{}, false, errorsmod.Wrapf(err, "GetBlockBloom %d", height)
+	}
+	bz, err := kv.db.Get(BlockBloomKey(height))
+	if err != nil {
+		return ethtypes.Bloom{}, false, errorsmod.Wrapf(err, "GetBlockBloom %d", height)
+	}
+	return ethtypes.BytesToBloom(bz), true, nil
+}
+
+// extendIndexedRange returns the range of the blocks indexed without gap once the block
+// is indexed. The blocks are indexed in both directions so the range is extended at both
+// ends, and over the blocks indexed past a gap once the gap is indexed.
+func extendIndexedRange(first, last, height int64, indexed func(int64) (bool, error)) (int64, int64, error) {
+	step := int64(1)
+	switch {
+	case first == -1:
+		return height, height, nil
+	case height == last+1:
+		last = height
+	case height == first-1:
+		first, step = height, -1
+	default:
+		return first, last, nil
+	}
+	for {
+		next := last + 1
+		if step < 0 {
+			next = first - 1
+		}
+		if next < 1 {
+			return first, last, nil
+		}
+		ok, err := indexed(next)
+		if err != nil || !ok {
+			return first, last, err
+		}
+		if step < 0 {
+			first = next
+		} else {
+			last = next
+		}
+	}
+}
+
+// GetLogs returns the logs of the blocks [from, to] matching the addresses and topics.
+// The candidate blocks are found with the bloom bits of the complete sections and the
+// blooms of the other blocks, then the logs are looked up by address or topic in each
+// candidate block.
+func (kv *KVIndexer) GetLogs(
+	from, to int64,
+	addresses []common.Address,
+	topics [][]common.Hash,
+	limit int,
+) ([]*ethtypes.Log, error) {
+	logs := []*ethtypes.Log{}
+	if from > to {
+		return logs, nil
+	}
+
+	groups := newBloomGroups(addresses, topics)
+	if len(groups) == 0 {
+		// no address nor topic criteria, only the number of topics is checked
+		err := kv.iterateLogs(LogKey(from, 0), LogKey(to+1, 0), func(log *ethtypes.Log) error {
+			if !logMatches(log, addresses, topics) {
+				return nil
+			}
+			if len(logs) == limit {
+				return ethermint.LogsLimitError{Limit: limit}
+			}
+			logs = append(logs, log)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return logs, nil
+	}
+
+	heights, err := kv.bloomCandidates(from, to, groups)
+	if err != nil {
+		return nil, err
+	}
+	for _, height := range heights {
+		blockLogs, err := kv.blockLogs(height, addresses, topics)
+		if err != nil {
+			return nil, err
+		}
+		if len(logs)+len(blockLogs) > limit {
+			return nil, ethermint.LogsLimitError{Limit: limit}
+		}
+		logs = append(logs, blockLogs...)
+	}
+	return logs, nil
+}
+
+// blockLogs returns the logs of a block matching the addresses and topics, the logs are
+// looked up with the address index if there are addresses, or with the topic index of
+// the first topic position with topics.
+func (kv *KVIndexer) blockLogs(height int64, addresses []common.Address, topics [][]common.Hash) ([]*ethtypes.Log, error) {
+	var prefixes [][]byte
+	switch {
+	case len(addresses) > 0:
+		for _, address := range addresses {
+			prefixes = append(prefixes, logAddressPrefix(address, height))
+		}
+	default:
+		for i, alternatives := range topics {
+			if len(alternatives) == 0 || i == maxIndexedTopics {
+				continue
+			}
+			for _, topic := range alternatives {
+				prefixes = append(prefixes, logTopicPrefix(i, topic, height))
+			}
+			break
+		}
+	}
+
+	var logs []*ethtypes.Log
+	if len(prefixes) == 0 {
+		err := kv.iterateLogs(LogKey(height, 0), LogKey(height+1, 0), func(log *ethtypes.Log) error {
+			if logMatches(log, addresses, topics) {
+				logs = append(logs, log)
+			}
+			return nil
+		})
+		return logs, err
+	}
+
+	var positions []uint64
+	seen := make(map[uint64]bool)
+	for _, prefix := range prefixes {
+		it, err := dbm.IteratePrefix(kv.db, prefix)
+		if err != nil {
+			return nil, errorsmod.Wrap(err, "blockLogs")
+		}
+		for ; it.Valid(); it.Next() {
+			position := sdk.BigEndianToUint64(it.Key()[len(it.Key())-8:])
+			if !seen[position] {
+				seen[position] = true
+				positions = append(positions, position)
+			}
+		}
+		err = it.Error()
+		it.Close()
+		if err != nil {
+			return nil, errorsmod.Wrap(err, "blockLogs")
+		}
+	}
+	sort.Slice(positions, func(i, j int) bool { return positions[i] < positions[j] })
+
+	for _, position := range positions {
+		bz, err := kv.db.Get(LogKey(height, position))
+		if err != nil {
+			return nil, errorsmod.Wrapf(err, "blockLogs %d", height)
+		}
+		var log ethtypes.Log
+		if err := json.Unmarshal(bz, &log); err != nil {
+			return nil, errorsmod.Wrapf(err, "blockLogs %d", height)
+		}
+		if logMatches(&log, addresses, topics) {
+			logs = append(logs, &log)
+		}
+	}
+	return logs, nil
+}
+
+// iterateLogs calls the callback with the logs in the key range, in order.
+func (kv *KVIndexer) iterateLogs(start, end []byte, cb func(*ethtypes.Log) error) error {
+	it, err := kv.db.Iterator(start, end)
+	if err != nil {
+		return errorsmod.Wrap(err, "iterateLogs")
+	}
+	defer it.Close()
+	for ; it.Valid(); it.Next() {
+		var log ethtypes.Log
+		if err := json.Unmarshal(it.Value(), &log); err != nil {
+			return errorsmod.Wrap(err, "iterateLogs")
+		}
+		if err := cb(&log); err != nil {
+			return err
+		}
+	}
+	return it.Error()
+}
+
+// logMatches checks if a log matches the addresses and topics, with the same rules as
+// the eth_getLogs filter criteria.
+func logMatches(log *ethtypes.Log, addresses []common.Address, topics [][]common.Hash) bool {
+	if len(addresses) > 0 {
+		found := false
+		for _, address := range addresses {
+			if log.Address == address {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(topics) > len(log.Topics) {
+		return false
+	}
+	for i, alternatives := range topics {
+		if len(alternatives) == 0 {
+			continue
+		}
+		found := false
+		for _, topic := range alternatives {
+			if log.Topics[i] == topic {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// parseLogsFromEvents parses the eth logs of all the messages of a tx from its events.
+func parseLogsFromEvents(events []abci.Event) ([]*ethtypes.Log, error) {
+	var logs []*evmtypes.Log
+	for _, event := range events {
+		if event.Type != evmtypes.EventTypeTxLog {
+			continue
+		}
+		for _, attr := range event.Attributes {
+			if attr.Key != evmtypes.AttributeKeyTxLog {
+				continue
+			}
+			var log evmtypes.Log
+			if err := json.Unmarshal([]byte(attr.Value), &log); err != nil {
+				return nil, err
+			}
+			logs = append(logs, &log)
+		}
+	}
+	return evmtypes.LogsToEthereum(logs), nil
+}
+
+// LogKey returns the key for db entry: `(block number, log position) -> log`
+func LogKey(blockNumber int64, position uint64) []byte {
+	bz := sdk.Uint64ToBigEndian(uint64(blockNumber)) //#nosec G115
+	return append(append([]byte{KeyPrefixLog}, bz...), sdk.Uint64ToBigEndian(position)...)
+}
+
+// LogAddressKey returns the key for db entry: `(address, block number, log position) -> nil`
+func LogAddressKey(address common.Address, blockNumber int64, position uint64) []byte {
+	return append(logAddressPrefix(address, blockNumber), sdk.Uint64ToBigEndian(position)...)
+}
+
+// LogTopicKey returns the key for db entry: `(topic position, topic, block number, log position) -> nil`
+func LogTopicKey(index int, topic common.Hash, blockNumber int64, position uint64) []byte {
+	return append(logTopicPrefix(index, topic, blockNumber), sdk.Uint64ToBigEndian(position)...)
+}
+
+// BlockBloomKey returns the key for db entry: `block number -> bloom`
+func BlockBloomKey(blockNumber int64) []byte {
+	return append([]byte{KeyPrefixBlockBloom}, sdk.Uint64ToBigEndian(uint64(blockNumber))...) //#nosec G115
+}
+
+func logAddressPrefix(address common.Address, blockNumber int64) []byte {
+	bz := append([]byte{KeyPrefixLogAddress}, address.Bytes()...)
+	return append(bz, sdk.Uint64ToBigEndian(uint64(blockNumber))...) //#nosec G115
+}
+
+func logTopicPrefix(index int, topic common.Hash, blockNumber int64) []byte {
+	bz := append([]byte{KeyPrefixLogTopic, byte(index)}, topic.Bytes()...)
+	return append(bz, sdk.Uint64ToBigEndian(uint64(blockNumber))...) //#nosec G115
+}
@@ -0,0 +1,520 @@
+// Copyright 2021 Evmos Foundation
+// This file is part of Evmos' Ethermint library.
+//
+// The Ethermint library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Ethermint library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Ethermint library. If not, see https://github.com/evmos/ethermint/blob/main/LICENSE
+package indexer
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	errorsmod "cosmossdk.io/errors"
+	"cosmossdk.io/log"
+	abci "github.com/cometbft/cometbft/abci/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	// sql drivers of the indexer
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+
+	ethermint "github.com/evmos/ethermint/types"
+)
+
+const (
+	SQLDriverSQLite   = "sqlite3"
+	SQLDriverPostgres = "postgres"
+)
+
+var (
+	_ ethermint.EVMTxIndexer  = &SQLIndexer{}
+	_ ethermint.EVMLogIndexer = &SQLIndexer{}
+)
+
+// sqlSchema creates the tables of the sql indexer, the statements are supported by
+// both SQLite and Postgres. The hashes and addresses are stored as lower case hex
+// strings, and the big integers as decimal strings, so they can be queried directly.
+var sqlSchema = []string{
+	`CREATE TABLE IF NOT EXISTS eth_blocks (
+		height BIGINT PRIMARY KEY,
+		hash TEXT NOT NULL,
+		time BIGINT NOT NULL,
+		tx_count INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS eth_txs (
+		hash TEXT PRIMARY KEY,
+		height BIGINT NOT NULL,
+		tx_index INTEGER NOT NULL,
+		msg_index INTEGER NOT NULL,
+		eth_tx_index INTEGER NOT NULL,
+		type INTEGER NOT NULL,
+		sender TEXT NOT NULL,
+		recipient TEXT,
+		nonce BIGINT NOT NULL,
+		value TEXT NOT NULL,
+		gas_limit BIGINT NOT NULL,
+		gas_price TEXT NOT NULL,
+		input TEXT NOT NULL
+	)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS eth_txs_height_index ON eth_txs (height, eth_tx_index)`,
+	`CREATE INDEX IF NOT EXISTS eth_txs_sender ON eth_txs (sender)`,
+	`CREATE INDEX IF NOT EXISTS eth_txs_recipient ON eth_txs (recipient)`,
+	`CREATE TABLE IF NOT EXISTS eth_receipts (
+		tx_hash TEXT PRIMARY KEY,
+		height BIGINT NOT NULL,
+		status INTEGER NOT NULL,
+		gas_used BIGINT NOT NULL,
+		cumulative_gas_used BIGINT NOT NULL,
+		contract_address TEXT
+	)`,
+	`CREATE INDEX IF NOT EXISTS eth_receipts_height ON eth_receipts (height)`,
+	`CREATE TABLE IF NOT EXISTS eth_logs (
+		height BIGINT NOT NULL,
+		position BIGINT NOT NULL,
+		log_index BIGINT NOT NULL,
+		tx_hash TEXT NOT NULL,
+		tx_index BIGINT NOT NULL,
+		block_hash TEXT NOT NULL,
+		address TEXT NOT NULL,
+		num_topics INTEGER NOT NULL,
+		topic0 TEXT,
+		topic1 TEXT,
+		topic2 TEXT,
+		topic3 TEXT,
+		data TEXT NOT NULL,
+		PRIMARY KEY (height, position)
+	)`,
+	`CREATE INDEX IF NOT EXISTS eth_logs_tx_hash ON eth_logs (tx_hash)`,
+	`CREATE INDEX IF NOT EXISTS eth_logs_address ON eth_logs (address, height)`,
+	`CREATE INDEX IF NOT EXISTS eth_logs_topic0 ON eth_logs (topic0, height)`,
+	`CREATE INDEX IF NOT EXISTS eth_logs_topic1 ON eth_logs (topic1, height)`,
+	`CREATE INDEX IF NOT EXISTS eth_logs_topic2 ON eth_logs (topic2, height)`,
+	`CREATE INDEX IF NOT EXISTS eth_logs_topic3 ON eth_logs (topic3, height)`,
+	`CREATE TABLE IF NOT EXISTS eth_logs_range (
+		id INTEGER PRIMARY KEY,
+		first_height BIGINT NOT NULL,
+		last_height BIGINT NOT NULL
+	)`,
+}
+
+// SQLIndexer implements a eth tx indexer on a SQL database, it stores the txs, the
+// receipts and the logs in relational tables.
+type SQLIndexer struct {
+	db        *sql.DB
+	driver    string
+	logger    log.Logger
+	clientCtx client.Context
+}
+
+// NewSQLIndexer opens the database and creates the SQLIndexer, the tables are created
+// if they don't exist.
+func NewSQLIndexer(driver, dsn string, logger log.Logger, clientCtx client.Context) (*SQLIndexer, error) {
+	if driver != SQLDriverSQLite && driver != SQLDriverPostgres {
+		return nil, fmt.Errorf("unsupported sql indexer driver: %s", driver)
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, errorsmod.Wrap(err, "open sql indexer db")
+	}
+	if driver == SQLDriverSQLite {
+		// SQLite doesn't support concurrent writes, and every connection to an in-memory
+		// database opens a new database.
+		db.SetMaxOpenConns(1)
+	}
+	for _, stmt := range sqlSchema {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil, errorsmod.Wrap(err, "create sql indexer tables")
+		}
+	}
+	return &SQLIndexer{db, driver, logger, clientCtx}, nil
+}
+
+// Close closes the database.
+func (idx *SQLIndexer) Close() error {
+	return idx.db.Close()
+}
+
+// IndexBlock index all the eth txs of a block, with their receipts and logs, in a
+// database transaction. The previous rows of the block are replaced, so a block
+// can be indexed again.
+func (idx *SQLIndexer) IndexBlock(block *tmtypes.Block, blockResult *abci.ResponseFinalizeBlock) error {
+	height := block.Header.Height
+	txs := ParseEthTxs(idx.clientCtx.TxConfig.TxDecoder(), idx.logger, block, blockResult.TxResults)
+
+	dbTx, err := idx.db.Begin()
+	if err != nil {
+		return errorsmod.Wrapf(err, "IndexBlock %d", height)
+	}
+	defer dbTx.Rollback() //nolint:errcheck
+
+	exec := func(query string, args ...interface{}) error {
+		_, err := dbTx.Exec(idx.rebind(query), args...)
+		return err
+	}
+
+	for _, table := range []string{"eth_blocks", "eth_txs", "eth_receipts", "eth_logs"} {
+		if err := exec("DELETE FROM "+table+" WHERE height = ?", height); err != nil {
+			return errorsmod.Wrapf(err, "IndexBlock %d, delete %s", height, table)
+		}
+	}
+	if err := exec(
+		"INSERT INTO eth_blocks (height, hash, time, tx_count) VALUES (?, ?, ?, ?)",
+		height, hexutil.Encode(block.Hash()), block.Time.Unix(), len(txs),
+	); err != nil {
+		return errorsmod.Wrapf(err, "IndexBlock %d, insert block", height)
+	}
+
+	for _, tx := range txs {
+		if err := idx.insertTx(exec, tx); err != nil {
+			return errorsmod.Wrapf(err, "IndexBlock %d, insert tx %s", height, tx.Hash.Hex())
+		}
+	}
+
+	var position int64
+	for i, result := range blockResult.TxResults {
+		logs, err := parseLogsFromEvents(result.Events)
+		if err != nil {
+			return errorsmod.Wrapf(err, "IndexBlock %d, parse logs of tx %d", height, i)
+		}
+		for _, log := range logs {
+			topics := make([]interface{}, maxIndexedTopics)
+			for i := range topics {
+				if i < len(log.Topics) {
+					topics[i] = hexutil.Encode(log.Topics[i].Bytes())
+				}
+			}
+			if err := exec(
+				`INSERT INTO eth_logs (height, position, log_index, tx_hash, tx_index, block_hash, address,
+				num_topics, topic0, topic1, topic2, topic3, data) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				height, position, int64(log.Index), hexutil.Encode(log.TxHash.Bytes()), int64(log.TxIndex), //#nosec G115
+				hexutil.Encode(log.BlockHash.Bytes()), hexutil.Encode(log.Address.Bytes()), len(log.Topics),
+				topics[0], topics[1], topics[2], topics[3], hexutil.Encode(log.Data),
+			); err != nil {
+				return errorsmod.Wrapf(err, "IndexBlock %d, insert log", height)
+			}
+			position++
+		}
+	}
+
+	if err := idx.extendLogsRange(dbTx, height); err != nil {
+		return errorsmod.Wrapf(err, "IndexBlock %d, extend logs range", height)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return errorsmod.Wrapf(err, "IndexBlock %d, commit", height)
+	}
+	return nil
+}
+
+// insertTx inserts an eth tx and its receipt, the tx is removed first in case it was
+// indexed in another block.
+func (idx *SQLIndexer) insertTx(exec func(string, ...interface{}) error, tx EthTx) error {
+	hash := hexutil.Encode(tx.Hash.Bytes())
+	if err := exec("DELETE FROM eth_txs WHERE hash = ?", hash); err != nil {
+		return err
+	}
+	if err := exec("DELETE FROM eth_receipts WHERE tx_hash = ?", hash); err != nil {
+		return err
+	}
+
+	ethTx := tx.Msg.AsTransaction()
+	// the sender is not set in the msgs of the committed txs
+	sender, err := ethtypes.Sender(ethtypes.LatestSignerForChainID(ethTx.ChainId()), ethTx)
+	if err != nil {
+		return errorsmod.Wrap(err, "recover sender")
+	}
+	var recipient, contractAddress interface{}
+	if to := ethTx.To(); to != nil {
+		recipient = hexutil.Encode(to.Bytes())
+	} else {
+		contractAddress = hexutil.Encode(crypto.CreateAddress(sender, ethTx.Nonce()).Bytes())
+	}
+	if err := exec(
+		`INSERT INTO eth_txs (hash, height, tx_index, msg_index, eth_tx_index, type, sender, recipient,
+		nonce, value, gas_limit, gas_price, input) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		hash, tx.Result.Height, int64(tx.Result.TxIndex), int64(tx.Result.MsgIndex), tx.Result.EthTxIndex,
+		int(ethTx.Type()), hexutil.Encode(sender.Bytes()), recipient, int64(ethTx.Nonce()), //#nosec G115
+		ethTx.Value().String(), int64(ethTx.Gas()), ethTx.GasPrice().String(), hexutil.Encode(ethTx.Data()), //#nosec G115
+	); err != nil {
+		return err
+	}
+
+	status := ethtypes.ReceiptStatusSuccessful
+	if tx.Result.Failed {
+		status = ethtypes.ReceiptStatusFailed
+	}
+	return exec(
+		`INSERT INTO eth_receipts (tx_hash, height, status, gas_used, cumulative_gas_used, contract_address)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		hash, tx.Result.Height, int64(status), int64(tx.Result.GasUsed), //#nosec G115
+		int64(tx.Result.CumulativeGasUsed), contractAddress, //#nosec G115
+	)
+}
+
+// LastIndexedBlock returns the latest indexed block number, returns -1 if db is empty
+func (idx *SQLIndexer) LastIndexedBlock() (int64, error) {
+	_, last, err := idx.blocksRange()
+	return last, err
+}
+
+// FirstIndexedBlock returns the first indexed block number, returns -1 if db is empty
+func (idx *SQLIndexer) FirstIndexedBlock() (int64, error) {
+	first, _, err := idx.blocksRange()
+	return first, err
+}
+
+// blocksRange returns the first and last indexed blocks, -1 if none.
+func (idx *SQLIndexer) blocksRange() (int64, int64, error) {
+	var first, last sql.NullInt64
+	if err := idx.db.QueryRow("SELECT MIN(height), MAX(height) FROM eth_blocks").Scan(&first, &last); err != nil {
+		return 0, 0, errorsmod.Wrap(err, "blocksRange")
+	}
+	if !first.Valid {
+		return -1, -1, nil
+	}
+	return first.Int64, last.Int64, nil
+}
+
+// LogsIndexedRange returns the first and last blocks of the range indexed without gap,
+// -1 if none. The range stops at the blocks which failed to be indexed, the logs of the
+// blocks indexed past a gap are not served until the gap is indexed.
+func (idx *SQLIndexer) LogsIndexedRange() (int64, int64, error) {
+	first, last, err := loadLogsRange(idx.db.QueryRow)
+	if err != nil {
+		return 0, 0, errorsmod.Wrap(err, "LogsIndexedRange")
+	}
+	return first, last, nil
+}
+
+// extendLogsRange extends the range of the blocks indexed without gap with the block.
+func (idx *SQLIndexer) extendLogsRange(dbTx *sql.Tx, height int64) error {
+	first, last, err := loadLogsRange(dbTx.QueryRow)
+	if err != nil {
+		return err
+	}
+	newFirst, newLast, err := extendIndexedRange(first, last, height, func(height int64) (bool, error) {
+		var count int
+		err := dbTx.QueryRow(idx.rebind("SELECT COUNT(*) FROM eth_blocks WHERE height = ?"), height).Scan(&count)
+		return count > 0, err
+	})
+	if err != nil {
+		return err
+	}
+	if newFirst == first && newLast == last {
+		return nil
+	}
+	if _, err := dbTx.Exec("DELETE FROM eth_logs_range"); err != nil {
+		return err
+	}
+	_, err = dbTx.Exec(
+		idx.rebind("INSERT INTO eth_logs_range (id, first_height, last_height) VALUES (1, ?, ?)"),
+		newFirst, newLast,
+	)
+	return err
+}
+
+func loadLogsRange(queryRow func(string, ...interface{}) *sql.Row) (int64, int64, error) {
+	var first, last int64
+	err := queryRow("SELECT first_height, last_height FROM eth_logs_range").Scan(&first, &last)
+	if err == sql.ErrNoRows {
+		return -1, -1, nil
+	}
+	return first, last, err
+}
+
+// GetByTxHash finds eth tx by eth tx hash
+func (idx *SQLIndexer) GetByTxHash(hash common.Hash) (*ethermint.TxResult, error) {
+	res, err := idx.getTxResult("t.hash = ?", hexutil.Encode(hash.Bytes()))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("tx not found, hash: %s", hash.Hex())
+	}
+	if err != nil {
+		return nil, errorsmod.Wrapf(err, "GetByTxHash %s", hash.Hex())
+	}
+	return res, nil
+}
+
+// GetByBlockAndIndex finds eth tx by block number and eth tx index
+func (idx *SQLIndexer) GetByBlockAndIndex(blockNumber int64, txIndex int32) (*ethermint.TxResult, error) {
+	res, err := idx.getTxResult("t.height = ? AND t.eth_tx_index = ?", blockNumber, txIndex)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("tx not found, block: %d, eth-index: %d", blockNumber, txIndex)
+	}
+	if err != nil {
+		return nil, errorsmod.Wrapf(err, "GetByBlockAndIndex %d %d", blockNumber, txIndex)
+	}
+	return res, nil
+}
+
+func (idx *SQLIndexer) getTxResult(cond string, args ...interface{}) (*ethermint.TxResult, error) {
+	query := `SELECT t.height, t.tx_index, t.msg_index, t.eth_tx_index, r.status, r.gas_used, r.cumulative_gas_used
+		FROM eth_txs t JOIN eth_receipts r ON r.tx_hash = t.hash WHERE ` + cond
+	var (
+		res                        ethermint.TxResult
+		txIndex, msgIndex, status  int64
+		gasUsed, cumulativeGasUsed int64
+	)
+	if err := idx.db.QueryRow(idx.rebind(query), args...).Scan(
+		&res.Height, &txIndex, &msgIndex, &res.EthTxIndex, &status, &gasUsed, &cumulativeGasUsed,
+	); err != nil {
+		return nil, err
+	}
+	res.TxIndex = uint32(txIndex)                              //#nosec G115
+	res.MsgIndex = uint32(msgIndex)                            //#nosec G115
+	res.Failed = status == int64(ethtypes.ReceiptStatusFailed) //#nosec G115
+	res.GasUsed = uint64(gasUsed)                              //#nosec G115
+	res.CumulativeGasUsed = uint64(cumulativeGasUsed)          //#nosec G115
+	return &res, nil
+}
+
+// GetLogs returns the logs of the blocks [from, to] matching the addresses and topics,
+// the criteria are applied in the query with the address and topic indexes.
+func (idx *SQLIndexer) GetLogs(
+	from, to int64,
+	addresses []common.Address,
+	topics [][]common.Hash,
+	limit int,
+) ([]*ethtypes.Log, error) {
+	logs := []*ethtypes.Log{}
+	if from > to {
+		return logs, nil
+	}
+	if len(topics) > maxIndexedTopics {
+		// logs have at most 4 topics
+		return logs, nil
+	}
+
+	conds := []string{"height >= ?", "height <= ?"}
+	args := []interface{}{from, to}
+	inCond := func(column string, values [][]byte) {
+		placeholders := make([]string, len(values))
+		for i, value := range values {
+			placeholders[i] = "?"
+			args = append(args, hexutil.Encode(value))
+		}
+		conds = append(conds, fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ", ")))
+	}
+	if len(addresses) > 0 {
+		values := make([][]byte, len(addresses))
+		for i, address := range addresses {
+			values[i] = address.Bytes()
+		}
+		inCond("address", values)
+	}
+	if len(topics) > 0 {
+		conds = append(conds, "num_topics >= ?")
+		args = append(args, len(topics))
+	}
+	for i, alternatives := range topics {
+		if len(alternatives) == 0 {
+			continue
+		}
+		values := make([][]byte, len(alternatives))
+		for j, topic := range alternatives {
+			values[j] = topic.Bytes()
+		}
+		inCond("topic"+strconv.Itoa(i), values)
+	}
+
+	query := `SELECT height, log_index, tx_hash, tx_index, block_hash, address, num_topics,
+		topic0, topic1, topic2, topic3, data FROM eth_logs WHERE ` + strings.Join(conds, " AND ") +
+		" ORDER BY height, position LIMIT " + strconv.Itoa(limit+1)
+	rows, err := idx.db.Query(idx.rebind(query), args...)
+	if err != nil {
+		return nil, errorsmod.Wrap(err, "GetLogs")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if len(logs) == limit {
+			return nil, ethermint.LogsLimitError{Limit: limit}
+		}
+		log, err := scanLog(rows)
+		if err != nil {
+			return nil, errorsmod.Wrap(err, "GetLogs")
+		}
+		logs = append(logs, log)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errorsmod.Wrap(err, "GetLogs")
+	}
+	return logs, nil
+}
+
+// BloomStatus returns the section size and the number of sections of the bloom bits
+// index, the logs are queried with the table indexes so there are no bloom bits.
+func (idx *SQLIndexer) BloomStatus() (uint64, uint64) {
+	return BloomBitsSectionSize, 0
+}
+
+// rebind replaces the `?` placeholders of a query with the ones of the driver.
+func (idx *SQLIndexer) rebind(query string) string {
+	if idx.driver != SQLDriverPostgres {
+		return query
+	}
+	var (
+		sb strings.Builder
+		n  int
+	)
+	for _, c := range query {
+		if c != '?' {
+			sb.WriteRune(c)
+			continue
+		}
+		n++
+		sb.WriteString("$" + strconv.Itoa(n))
+	}
+	return sb.String()
+}
+
+func scanLog(rows *sql.Rows) (*ethtypes.Log, error) {
+	var (
+		height, index, txIndex int64
+		numTopics              int
+		txHash, blockHash      string
+		address, data          string
+		topics                 [maxIndexedTopics]sql.NullString
+	)
+	if err := rows.Scan(
+		&height, &index, &txHash, &txIndex, &blockHash, &address, &numTopics,
+		&topics[0], &topics[1], &topics[2], &topics[3], &data,
+	); err != nil {
+		return nil, err
+	}
+	bz, err := hexutil.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	log := &ethtypes.Log{
+		Address:     common.HexToAddress(address),
+		Topics:      make([]common.Hash, numTopics),
+		Data:        bz,
+		BlockNumber: uint64(height), //#nosec G115
+		TxHash:      common.HexToHash(txHash),
+		TxIndex:     uint(txIndex), //#nosec G115
+		BlockHash:   common.HexToHash(blockHash),
+		Index:       uint(index), //#nosec G115
+	}
+	for i := range log.Topics {
+		log.Topics[i] = common.HexToHash(topics[i].String)
+	}
+	return log, nil
+}
@@ -0,0 +1,248 @@
+package indexer_test
+
+import (
+	"database/sql"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cosmossdk.io/log"
+	abci "github.com/cometbft/cometbft/abci/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/evmos/ethermint/crypto/ethsecp256k1"
+	"github.com/evmos/ethermint/indexer"
+	"github.com/evmos/ethermint/tests"
+	"github.com/evmos/ethermint/x/evm/types"
+	"github.com/stretchr/testify/require"
+)
+
+// postgresDSNEnv is the env variable of the local postgres instance to run the sql indexer
+// tests against, they only run on SQLite if it's not set.
+const postgresDSNEnv = "ETHERMINT_TEST_POSTGRES_DSN"
+
+// newSQLIndexers returns empty sql indexers for each available database.
+func newSQLIndexers(t *testing.T, clientCtx client.Context) map[string]*indexer.SQLIndexer {
+	idxers := make(map[string]*indexer.SQLIndexer)
+
+	dsn := filepath.Join(t.TempDir(), "evmindexer.db")
+	idxer, err := indexer.NewSQLIndexer(indexer.SQLDriverSQLite, dsn, log.NewNopLogger(), clientCtx)
+	require.NoError(t, err)
+	idxers[indexer.SQLDriverSQLite] = idxer
+
+	if dsn := os.Getenv(postgresDSNEnv); dsn != "" {
+		db, err := sql.Open(indexer.SQLDriverPostgres, dsn)
+		require.NoError(t, err)
+		_, err = db.Exec("DROP TABLE IF EXISTS eth_blocks, eth_txs, eth_receipts, eth_logs")
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+
+		idxer, err := indexer.NewSQLIndexer(indexer.SQLDriverPostgres, dsn, log.NewNopLogger(), clientCtx)
+		require.NoError(t, err)
+		idxers[indexer.SQLDriverPostgres] = idxer
+	}
+
+	for _, idxer := range idxers {
+		idxer := idxer
+		t.Cleanup(func() { idxer.Close() })
+	}
+	return idxers
+}
+
+func TestSQLIndexer(t *testing.T) {
+	priv, err := ethsecp256k1.GenerateKey()
+	require.NoError(t, err)
+	from := common.BytesToAddress(priv.PubKey().Address().Bytes())
+	signer := tests.NewSigner(priv)
+	ethSigner := ethtypes.LatestSignerForChainID(nil)
+
+	encodingConfig := MakeEncodingConfig()
+	clientCtx := client.Context{}.WithTxConfig(encodingConfig.TxConfig).WithCodec(encodingConfig.Codec)
+
+	to := common.BigToAddress(big.NewInt(1))
+	transfer := types.NewTx(nil, 0, &to, big.NewInt(1000), 21000, big.NewInt(1), nil, nil, nil, nil)
+	create := types.NewTx(nil, 1, nil, big.NewInt(0), 100000, big.NewInt(1), nil, nil, []byte{0x60, 0x00}, nil)
+
+	var (
+		txs       []tmtypes.Tx
+		txResults []*abci.ExecTxResult
+	)
+	for i, tx := range []*types.MsgEthereumTx{transfer, create} {
+		tx.From = from.Hex()
+		require.NoError(t, tx.Sign(ethSigner, signer))
+
+		tmTx, err := tx.BuildTx(clientCtx.TxConfig.NewTxBuilder(), "aphoton")
+		require.NoError(t, err)
+		txBz, err := clientCtx.TxConfig.TxEncoder()(tmTx)
+		require.NoError(t, err)
+		txs = append(txs, txBz)
+
+		attrs := []abci.EventAttribute{
+			{Key: "ethereumTxHash", Value: tx.AsTransaction().Hash().Hex()},
+			{Key: "txIndex", Value: big.NewInt(int64(i)).String()},
+			{Key: "txGasUsed", Value: "21000"},
+		}
+		if i == 1 {
+			attrs = append(attrs, abci.EventAttribute{Key: "ethereumTxFailed", Value: "reverted"})
+		}
+		txResults = append(txResults, &abci.ExecTxResult{
+			GasUsed: 21000,
+			Events:  []abci.Event{{Type: types.EventTypeEthereumTx, Attributes: attrs}},
+		})
+	}
+	block := &tmtypes.Block{Header: tmtypes.Header{Height: 1}, Data: tmtypes.Data{Txs: txs}}
+	blockResult := &abci.ResponseFinalizeBlock{TxResults: txResults}
+
+	for name, idxer := range newSQLIndexers(t, clientCtx) {
+		t.Run(name, func(t *testing.T) {
+			last, err := idxer.LastIndexedBlock()
+			require.NoError(t, err)
+			require.Equal(t, int64(-1), last)
+
+			require.NoError(t, idxer.IndexBlock(block, blockResult))
+			// indexing a block again replaces its rows
+			require.NoError(t, idxer.IndexBlock(block, blockResult))
+
+			first, err := idxer.FirstIndexedBlock()
+			require.NoError(t, err)
+			require.Equal(t, int64(1), first)
+			last, err = idxer.LastIndexedBlock()
+			require.NoError(t, err)
+			require.Equal(t, int64(1), last)
+
+			res, err := idxer.GetByTxHash(transfer.AsTransaction().Hash())
+			require.NoError(t, err)
+			require.Equal(t, int64(1), res.Height)
+			require.Equal(t, int32(0), res.EthTxIndex)
+			require.Equal(t, uint64(21000), res.GasUsed)
+			require.False(t, res.Failed)
+
+			res2, err := idxer.GetByBlockAndIndex(1, 1)
+			require.NoError(t, err)
+			require.Equal(t, uint32(1), res2.TxIndex)
+			require.Equal(t, uint64(21000), res2.CumulativeGasUsed)
+			require.True(t, res2.Failed)
+
+			_, err = idxer.GetByBlockAndIndex(1, 2)
+			require.Error(t, err)
+			_, err = idxer.GetByTxHash(common.Hash{})
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestSQLIndexerTables(t *testing.T) {
+	priv, err := ethsecp256k1.GenerateKey()
+	require.NoError(t, err)
+	from := common.BytesToAddress(priv.PubKey().Address().Bytes())
+
+	encodingConfig := MakeEncodingConfig()
+	clientCtx := client.Context{}.WithTxConfig(encodingConfig.TxConfig).WithCodec(encodingConfig.Codec)
+
+	create := types.NewTx(nil, 3, nil, big.NewInt(5), 100000, big.NewInt(1), nil, nil, []byte{0x60, 0x00}, nil)
+	create.From = from.Hex()
+	require.NoError(t, create.Sign(ethtypes.LatestSignerForChainID(nil), tests.NewSigner(priv)))
+	tmTx, err := create.BuildTx(clientCtx.TxConfig.NewTxBuilder(), "aphoton")
+	require.NoError(t, err)
+	txBz, err := clientCtx.TxConfig.TxEncoder()(tmTx)
+	require.NoError(t, err)
+
+	dsn := filepath.Join(t.TempDir(), "evmindexer.db")
+	idxer, err := indexer.NewSQLIndexer(indexer.SQLDriverSQLite, dsn, log.NewNopLogger(), clientCtx)
+	require.NoError(t, err)
+	block := &tmtypes.Block{Header: tmtypes.Header{Height: 2}, Data: tmtypes.Data{Txs: []tmtypes.Tx{txBz}}}
+	require.NoError(t, idxer.IndexBlock(block, &abci.ResponseFinalizeBlock{
+		TxResults: []*abci.ExecTxResult{{GasUsed: 53000, Events: []abci.Event{{Type: types.EventTypeEthereumTx, Attributes: []abci.EventAttribute{
+			{Key: "ethereumTxHash", Value: create.AsTransaction().Hash().Hex()},
+			{Key: "txIndex", Value: "0"},
+			{Key: "txGasUsed", Value: "53000"},
+		}}}}},
+	}))
+	require.NoError(t, idxer.Close())
+
+	// the tables can be queried directly
+	db, err := sql.Open(indexer.SQLDriverSQLite, dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	var (
+		sender, value, input string
+		recipient            sql.NullString
+		nonce                int64
+	)
+	err = db.QueryRow("SELECT sender, recipient, nonce, value, input FROM eth_txs WHERE height = 2").
+		Scan(&sender, &recipient, &nonce, &value, &input)
+	require.NoError(t, err)
+	require.Equal(t, hexutil.Encode(from.Bytes()), sender)
+	require.False(t, recipient.Valid)
+	require.Equal(t, int64(3), nonce)
+	require.Equal(t, "5", value)
+	require.Equal(t, "0x6000", input)
+
+	var (
+		status, gasUsed int64
+		contract        string
+	)
+	err = db.QueryRow("SELECT status, gas_used, contract_address FROM eth_receipts WHERE tx_hash = ?",
+		hexutil.Encode(create.AsTransaction().Hash().Bytes())).Scan(&status, &gasUsed, &contract)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), status)
+	require.Equal(t, int64(53000), gasUsed)
+	require.Equal(t, hexutil.Encode(crypto.CreateAddress(from, 3).Bytes()), contract)
+}
+
+func TestSQLIndexerInvalidSender(t *testing.T) {
+	encodingConfig := MakeEncodingConfig()
+	clientCtx := client.Context{}.WithTxConfig(encodingConfig.TxConfig).WithCodec(encodingConfig.Codec)
+
+	// the sender of an unsigned tx can't be recovered
+	to := common.BigToAddress(big.NewInt(1))
+	tx := types.NewTx(big.NewInt(9000), 0, &to, big.NewInt(1000), 21000, big.NewInt(1), nil, nil, nil, nil)
+	tmTx, err := tx.BuildTx(clientCtx.TxConfig.NewTxBuilder(), "aphoton")
+	require.NoError(t, err)
+	txBz, err := clientCtx.TxConfig.TxEncoder()(tmTx)
+	require.NoError(t, err)
+	block := &tmtypes.Block{Header: tmtypes.Header{Height: 1}, Data: tmtypes.Data{Txs: []tmtypes.Tx{txBz}}}
+	blockResult := &abci.ResponseFinalizeBlock{
+		TxResults: []*abci.ExecTxResult{{GasUsed: 21000, Events: []abci.Event{{Type: types.EventTypeEthereumTx, Attributes: []abci.EventAttribute{
+			{Key: "ethereumTxHash", Value: tx.AsTransaction().Hash().Hex()},
+			{Key: "txIndex", Value: "0"},
+			{Key: "txGasUsed", Value: "21000"},
+		}}}}},
+	}
+
+	for name, idxer := range newSQLIndexers(t, clientCtx) {
+		t.Run(name, func(t *testing.T) {
+			require.ErrorContains(t, idxer.IndexBlock(block, blockResult), "recover sender")
+			// nothing is indexed
+			last, err := idxer.LastIndexedBlock()
+			require.NoError(t, err)
+			require.Equal(t, int64(-1), last)
+		})
+	}
+}
+
+func TestSQLIndexerLogs(t *testing.T) {
+	encodingConfig := MakeEncodingConfig()
+	clientCtx := client.Context{}.WithTxConfig(encodingConfig.TxConfig).WithCodec(encodingConfig.Codec)
+	for name, idxer := range newSQLIndexers(t, clientCtx) {
+		t.Run(name, func(t *testing.T) {
+			testIndexerLogs(t, idxer)
+		})
+	}
+}
+
+func TestSQLIndexerLogsRange(t *testing.T) {
+	encodingConfig := MakeEncodingConfig()
+	clientCtx := client.Context{}.WithTxConfig(encodingConfig.TxConfig).WithCodec(encodingConfig.Codec)
+	for name, idxer := range newSQLIndexers(t, clientCtx) {
+		t.Run(name, func(t *testing.T) {
+			testIndexerLogsRange(t, idxer)
+		})
+	}
+}
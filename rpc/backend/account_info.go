@@ -25,8 +25,10 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	rpcproof "github.com/evmos/ethermint/rpc/proof"
 	rpctypes "github.com/evmos/ethermint/rpc/types"
 	evmtypes "github.com/evmos/ethermint/x/evm/types"
 	"github.com/pkg/errors"
@@ -121,14 +123,42 @@ func (b *Backend) GetProof(address common.Address, storageKeys []string, blockNr
 		return nil, errors.New("invalid balance")
 	}
 
+	// query balance proofs, the balance of the evm denom is split between the bank and the
+	// fractional balance of the evm store
+	params, err := b.queryClient.Params(ctx, &evmtypes.QueryParamsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	_, balanceProof, err := b.queryClient.GetProof(clientCtx, banktypes.StoreKey, rpcproof.BalanceKey(address, params.Params.EvmDenom))
+	if err != nil {
+		return nil, err
+	}
+	var fractionalBalanceProof []string
+	if params.Params.EVMConversionFactor().Cmp(big.NewInt(1)) > 0 {
+		_, proof, err := b.queryClient.GetProof(clientCtx, evmtypes.StoreKey, evmtypes.FractionalBalanceKey(address))
+		if err != nil {
+			return nil, err
+		}
+		fractionalBalanceProof = GetHexProofs(proof)
+	}
+
+	// query the storage hash and its proof
+	storageHash, storageHashProof, err := b.queryClient.GetProof(clientCtx, evmtypes.StoreKey, evmtypes.StorageHashKey(address))
+	if err != nil {
+		return nil, err
+	}
+
 	return &rpctypes.AccountResult{
-		Address:      address,
-		AccountProof: GetHexProofs(proof),
-		Balance:      (*hexutil.Big)(balance.BigInt()),
-		CodeHash:     common.HexToHash(res.CodeHash),
-		Nonce:        hexutil.Uint64(res.Nonce),
-		StorageHash:  common.Hash{}, // NOTE: Ethermint doesn't have a storage hash. TODO: implement?
-		StorageProof: storageProofs,
+		Address:                address,
+		AccountProof:           GetHexProofs(proof),
+		Balance:                (*hexutil.Big)(balance.BigInt()),
+		BalanceProof:           GetHexProofs(balanceProof),
+		FractionalBalanceProof: fractionalBalanceProof,
+		CodeHash:               common.HexToHash(res.CodeHash),
+		Nonce:                  hexutil.Uint64(res.Nonce),
+		StorageHash:            common.BytesToHash(storageHash),
+		StorageHashProof:       GetHexProofs(storageHashProof),
+		StorageProof:           storageProofs,
 	}, nil
 }
 
@@ -11,6 +11,7 @@ import (
 	"google.golang.org/grpc/metadata"
 
 	"github.com/evmos/ethermint/rpc/backend/mocks"
+	rpcproof "github.com/evmos/ethermint/rpc/proof"
 	rpctypes "github.com/evmos/ethermint/rpc/types"
 	"github.com/evmos/ethermint/tests"
 	evmtypes "github.com/evmos/ethermint/x/evm/types"
@@ -127,6 +128,7 @@ func (suite *BackendTestSuite) TestGetProof() {
 				RegisterBlock(client, bn.Int64(), nil)
 				queryClient := suite.backend.queryClient.QueryClient.(*mocks.EVMQueryClient)
 				RegisterAccount(queryClient, addr, bn.Int64())
+				RegisterParamsWithoutHeader(queryClient, bn.Int64())
 
 				// Use the IAVL height if a valid tendermint height is passed in.
 				iavlHeight := bn.Int64()
@@ -144,15 +146,31 @@ func (suite *BackendTestSuite) TestGetProof() {
 					append(authtypes.AddressStoreKeyPrefix.Bytes(), address1.Bytes()...),
 					tmrpcclient.ABCIQueryOptions{Height: iavlHeight, Prove: true},
 				)
+				RegisterABCIQueryWithOptions(
+					client,
+					bn.Int64(),
+					"store/bank/key",
+					rpcproof.BalanceKey(address1, evmtypes.DefaultEVMDenom),
+					tmrpcclient.ABCIQueryOptions{Height: iavlHeight, Prove: true},
+				)
+				RegisterABCIQueryWithOptions(
+					client,
+					bn.Int64(),
+					"store/evm/key",
+					evmtypes.StorageHashKey(address1),
+					tmrpcclient.ABCIQueryOptions{Height: iavlHeight, Prove: true},
+				)
 			},
 			true,
 			&rpctypes.AccountResult{
-				Address:      address1,
-				AccountProof: []string{""},
-				Balance:      (*hexutil.Big)(big.NewInt(0)),
-				CodeHash:     common.HexToHash(""),
-				Nonce:        0x0,
-				StorageHash:  common.Hash{},
+				Address:          address1,
+				AccountProof:     []string{""},
+				Balance:          (*hexutil.Big)(big.NewInt(0)),
+				BalanceProof:     []string{""},
+				CodeHash:         common.HexToHash(""),
+				Nonce:            0x0,
+				StorageHash:      common.BytesToHash([]byte{2}),
+				StorageHashProof: []string{""},
 				StorageProof: []rpctypes.StorageResult{
 					{
 						Key:   "0x0",
@@ -63,6 +63,38 @@ func RegisterTraceBlockError(queryClient *mocks.EVMQueryClient) {
 		Return(nil, errortypes.ErrInvalidRequest)
 }
 
+func RegisterTraceTransactionWithConfig(queryClient *mocks.EVMQueryClient, msgEthTx *evmtypes.MsgEthereumTx, config *evmtypes.TraceConfig, result interface{}) {
+	data, _ := json.Marshal(result)
+	queryClient.On("TraceTx", rpc.ContextWithHeight(1),
+		&evmtypes.QueryTraceTxRequest{Msg: msgEthTx, BlockNumber: 1, TraceConfig: config, ChainId: 9000}).
+		Return(&evmtypes.QueryTraceTxResponse{Data: data}, nil)
+}
+
+// TraceCall
+func RegisterTraceCall(queryClient *mocks.EVMQueryClient, request *evmtypes.QueryTraceCallRequest, result interface{}) {
+	data, _ := json.Marshal(result)
+	queryClient.On("TraceCall", mock.Anything, request).
+		Return(&evmtypes.QueryTraceCallResponse{Data: data}, nil)
+}
+
+func RegisterTraceCallError(queryClient *mocks.EVMQueryClient, request *evmtypes.QueryTraceCallRequest) {
+	queryClient.On("TraceCall", mock.Anything, request).
+		Return(nil, errortypes.ErrInvalidRequest)
+}
+
+func RegisterTraceBlockWithResults(queryClient *mocks.EVMQueryClient, txs []*evmtypes.MsgEthereumTx, config *evmtypes.TraceConfig, results []*evmtypes.TxTraceResult) {
+	data, _ := json.Marshal(results)
+	queryClient.On("TraceBlock", rpc.ContextWithHeight(1),
+		&evmtypes.QueryTraceBlockRequest{Txs: txs, BlockNumber: 1, TraceConfig: config, ChainId: 9000}).
+		Return(&evmtypes.QueryTraceBlockResponse{Data: data}, nil)
+}
+
+func RegisterIntermediateRoots(queryClient *mocks.EVMQueryClient, txs []*evmtypes.MsgEthereumTx, roots [][]byte) {
+	queryClient.On("IntermediateRoots", rpc.ContextWithHeight(1),
+		&evmtypes.QueryIntermediateRootsRequest{Txs: txs, BlockNumber: 1, ChainId: 9000}).
+		Return(&evmtypes.QueryIntermediateRootsResponse{Roots: roots}, nil)
+}
+
 // Params
 func RegisterParams(queryClient *mocks.EVMQueryClient, header *metadata.MD, height int64) {
 	queryClient.On("Params", rpc.ContextWithHeight(height), &evmtypes.QueryParamsRequest{}, grpc.Header(header)).
@@ -144,6 +176,16 @@ func RegisterEthCall(queryClient *mocks.EVMQueryClient, request *evmtypes.EthCal
 		Return(&evmtypes.MsgEthereumTxResponse{}, nil)
 }
 
+func RegisterCreateAccessList(queryClient *mocks.EVMQueryClient, request *evmtypes.EthCallRequest, res *evmtypes.CreateAccessListResponse) {
+	queryClient.On("CreateAccessList", rpc.ContextWithHeight(1), request).
+		Return(res, nil)
+}
+
+func RegisterEthSimulate(queryClient *mocks.EVMQueryClient, request *evmtypes.EthSimulateRequest, result []byte) {
+	queryClient.On("EthSimulate", rpc.ContextWithHeight(1), request).
+		Return(&evmtypes.EthSimulateResponse{Result: result}, nil)
+}
+
 func RegisterEthCallError(queryClient *mocks.EVMQueryClient, request *evmtypes.EthCallRequest) {
 	ctx, _ := context.WithCancel(rpc.ContextWithHeight(1))
 	queryClient.On("EthCall", ctx, request).
@@ -254,6 +296,17 @@ func RegisterAccount(queryClient *mocks.EVMQueryClient, addr common.Address, hei
 		)
 }
 
+func RegisterAccountWithNonce(queryClient *mocks.EVMQueryClient, addr common.Address, nonce uint64) {
+	queryClient.On("Account", rpc.ContextWithHeight(1), &evmtypes.QueryAccountRequest{Address: addr.String()}).
+		Return(&evmtypes.QueryAccountResponse{
+			Balance:  "0",
+			CodeHash: "",
+			Nonce:    nonce,
+		},
+			nil,
+		)
+}
+
 // Balance
 func RegisterBalance(queryClient *mocks.EVMQueryClient, addr common.Address, height int64) {
 	queryClient.On("Balance", rpc.ContextWithHeight(height), &evmtypes.QueryBalanceRequest{Address: addr.String()}).
@@ -19,6 +19,8 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/pkg/errors"
+
+	ethermint "github.com/evmos/ethermint/types"
 )
 
 // GetLogs returns all the logs from all the ethereum transactions in a block.
@@ -44,8 +46,40 @@ func (b *Backend) GetLogsByHeight(height *int64) ([][]*ethtypes.Log, error) {
 	return GetLogsFromBlockResults(blockRes)
 }
 
+// GetIndexedLogs returns the logs of the blocks [from, to] matching the addresses and
+// topics that are covered by the indexer, along with the last height it served. The
+// logs of the blocks above that height must be fetched from the block results, the
+// returned height is from-1 if the indexer doesn't index the logs or doesn't cover from.
+func (b *Backend) GetIndexedLogs(
+	from, to int64,
+	addresses []common.Address,
+	topics [][]common.Hash,
+	limit int,
+) ([]*ethtypes.Log, int64, error) {
+	logIndexer, ok := b.indexer.(ethermint.EVMLogIndexer)
+	if !ok {
+		return nil, from - 1, nil
+	}
+	first, last, err := logIndexer.LogsIndexedRange()
+	if err != nil {
+		return nil, from - 1, err
+	}
+	if first < 0 || from < first || from > last {
+		return nil, from - 1, nil
+	}
+	to = min(to, last)
+	logs, err := logIndexer.GetLogs(from, to, addresses, topics, limit)
+	if err != nil {
+		return nil, from - 1, err
+	}
+	return logs, to, nil
+}
+
 // BloomStatus returns the BloomBitsBlocks and the number of processed sections maintained
 // by the chain indexer.
 func (b *Backend) BloomStatus() (uint64, uint64) {
+	if logIndexer, ok := b.indexer.(ethermint.EVMLogIndexer); ok {
+		return logIndexer.BloomStatus()
+	}
 	return 4096, 0
 }
@@ -2,7 +2,9 @@ package backend
 
 import (
 	"encoding/json"
+	"math/big"
 
+	abci "github.com/cometbft/cometbft/abci/types"
 	tmtypes "github.com/cometbft/cometbft/types"
 	"github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
@@ -115,3 +117,44 @@ func (suite *BackendTestSuite) TestBloomStatus() {
 		})
 	}
 }
+
+func (suite *BackendTestSuite) TestGetIndexedLogs() {
+	address := common.BigToAddress(big.NewInt(1))
+	log := &ethtypes.Log{Address: address, Topics: []common.Hash{}, Data: []byte{}, BlockNumber: 2}
+	logBz, err := json.Marshal(evmtypes.NewLogFromEth(log))
+	suite.Require().NoError(err)
+
+	testCases := []struct {
+		name     string
+		from, to int64
+		expLogs  []*ethtypes.Log
+		expLast  int64
+	}{
+		{"pass - range indexed", 1, 2, []*ethtypes.Log{log}, 2},
+		{"pass - range partially indexed", 1, 3, []*ethtypes.Log{log}, 2},
+		{"pass - range not indexed", 5, 6, nil, 4},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.SetupTest()
+
+			for height := int64(1); height <= 2; height++ {
+				blockRes := &abci.ResponseFinalizeBlock{TxResults: []*abci.ExecTxResult{}}
+				if height == 2 {
+					blockRes.TxResults = append(blockRes.TxResults, &abci.ExecTxResult{Events: []abci.Event{{
+						Type:       evmtypes.EventTypeTxLog,
+						Attributes: []abci.EventAttribute{{Key: evmtypes.AttributeKeyTxLog, Value: string(logBz)}},
+					}}})
+				}
+				err := suite.backend.indexer.IndexBlock(&tmtypes.Block{Header: tmtypes.Header{Height: height}}, blockRes)
+				suite.Require().NoError(err)
+			}
+
+			logs, last, err := suite.backend.GetIndexedLogs(tc.from, tc.to, []common.Address{address}, nil, 10)
+			suite.Require().NoError(err)
+			suite.Require().Equal(tc.expLast, last)
+			suite.Require().Equal(tc.expLogs, logs)
+		})
+	}
+}
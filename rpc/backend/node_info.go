@@ -66,26 +66,20 @@ func (b *Backend) Accounts() ([]common.Address, error) {
 // yet received the latest block headers from its pears. In case it is synchronizing:
 // - startingBlock: block number this node started to synchronize from
 // - currentBlock:  block number this node is currently importing
-// - highestBlock:  block number of the highest block header this node has received from peers
-// - pulledStates:  number of state entries processed until now
-// - knownStates:   number of known state entries that still need to be pulled
+// - highestBlock:  block number of the highest block committed by the peers of this node
+// - pulledStates:  number of state sync snapshot chunks applied until now
+// - knownStates:   number of chunks of the state sync snapshot being restored
 func (b *Backend) Syncing() (interface{}, error) {
-	status, err := b.clientCtx.Client.Status(b.ctx)
+	progress, err := rpctypes.GetSyncProgress(b.ctx, b.clientCtx, b.syncTracker)
 	if err != nil {
 		return false, err
 	}
 
-	if !status.SyncInfo.CatchingUp {
+	if progress == nil {
 		return false, nil
 	}
 
-	return map[string]interface{}{
-		"startingBlock": hexutil.Uint64(status.SyncInfo.EarliestBlockHeight),
-		"currentBlock":  hexutil.Uint64(status.SyncInfo.LatestBlockHeight),
-		// "highestBlock":  nil, // NA
-		// "pulledStates":  nil, // NA
-		// "knownStates":   nil, // NA
-	}, nil
+	return progress, nil
 }
 
 // SetEtherbase sets the etherbase of the miner
@@ -12,6 +12,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/evmos/ethermint/crypto/ethsecp256k1"
 	"github.com/evmos/ethermint/rpc/backend/mocks"
+	rpctypes "github.com/evmos/ethermint/rpc/types"
 	ethermint "github.com/evmos/ethermint/types"
 	"github.com/spf13/viper"
 	"google.golang.org/grpc/metadata"
@@ -191,12 +192,53 @@ func (suite *BackendTestSuite) TestSyncing() {
 			func() {
 				client := suite.backend.clientCtx.Client.(*mocks.Client)
 				RegisterStatus(client)
+				RegisterDumpConsensusState(client, 12, 15)
 				status, _ := client.Status(suite.backend.ctx)
 				status.SyncInfo.CatchingUp = true
+				status.SyncInfo.EarliestBlockHeight = 1
+				status.SyncInfo.LatestBlockHeight = 5
 			},
-			map[string]interface{}{
-				"startingBlock": hexutil.Uint64(0),
-				"currentBlock":  hexutil.Uint64(0),
+			&rpctypes.SyncProgress{
+				StartingBlock: hexutil.Uint64(1),
+				CurrentBlock:  hexutil.Uint64(5),
+				HighestBlock:  hexutil.Uint64(14),
+			},
+			true,
+		},
+		{
+			"pass - Node is catching up, peer states not available",
+			func() {
+				client := suite.backend.clientCtx.Client.(*mocks.Client)
+				RegisterStatus(client)
+				RegisterDumpConsensusStateError(client)
+				status, _ := client.Status(suite.backend.ctx)
+				status.SyncInfo.CatchingUp = true
+				status.SyncInfo.LatestBlockHeight = 5
+			},
+			&rpctypes.SyncProgress{
+				CurrentBlock: hexutil.Uint64(5),
+				HighestBlock: hexutil.Uint64(5),
+			},
+			true,
+		},
+		{
+			"pass - Node is restoring a state sync snapshot",
+			func() {
+				client := suite.backend.clientCtx.Client.(*mocks.Client)
+				RegisterStatus(client)
+				RegisterDumpConsensusState(client, 101)
+				status, _ := client.Status(suite.backend.ctx)
+				status.SyncInfo.CatchingUp = true
+
+				// the tracker is reset with the backend
+				suite.backend.syncTracker.SnapshotAccepted(4)
+				suite.backend.syncTracker.ChunkApplied()
+				suite.backend.syncTracker.ChunkApplied()
+			},
+			&rpctypes.SyncProgress{
+				HighestBlock: hexutil.Uint64(100),
+				PulledStates: hexutil.Uint64(2),
+				KnownStates:  hexutil.Uint64(4),
 			},
 			true,
 		},
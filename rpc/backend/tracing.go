@@ -16,18 +16,25 @@
 package backend
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	tmrpctypes "github.com/cometbft/cometbft/rpc/core/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	rpctypes "github.com/evmos/ethermint/rpc/types"
+	"github.com/evmos/ethermint/x/evm/tracers"
 	evmtypes "github.com/evmos/ethermint/x/evm/types"
 	"github.com/pkg/errors"
 )
 
+// maxTraceFilterBlockRange is the maximum [from, to] blocks distance of the `trace_filter`
+// calls, all the txs of the blocks are replayed to build their traces.
+const maxTraceFilterBlockRange = 100
+
 // TraceTransaction returns the structured logs created during the execution of EVM
 // and returns them as a JSON object.
 func (b *Backend) TraceTransaction(hash common.Hash, config *evmtypes.TraceConfig) (interface{}, error) {
@@ -130,6 +137,77 @@ func (b *Backend) TraceTransaction(hash common.Hash, config *evmtypes.TraceConfi
 	return decodedResult, nil
 }
 
+// TraceCall traces the execution of an arbitrary call on the state of the given block,
+// with the state and block overrides of the config applied, and returns the result of
+// the configured tracer as a JSON object.
+func (b *Backend) TraceCall(
+	args evmtypes.TransactionArgs,
+	blockNrOrHash rpctypes.BlockNumberOrHash,
+	config *rpctypes.TraceCallConfig,
+) (interface{}, error) {
+	blockNr, err := b.BlockNumberFromTendermint(blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	blk, err := b.TendermintBlockByNumber(blockNr)
+	if err != nil {
+		b.logger.Debug("block not found", "number", blockNr)
+		return nil, err
+	}
+	if blk == nil || blk.Block == nil {
+		return nil, errors.New("header not found")
+	}
+
+	argsBz, err := json.Marshal(&args)
+	if err != nil {
+		return nil, err
+	}
+
+	traceCallRequest := evmtypes.QueryTraceCallRequest{
+		Args:            argsBz,
+		GasCap:          b.RPCGasCap(),
+		BlockNumber:     blk.Block.Height,
+		BlockTime:       blk.Block.Time,
+		BlockHash:       common.Bytes2Hex(blk.BlockID.Hash),
+		ProposerAddress: sdk.ConsAddress(blk.Block.ProposerAddress),
+		ChainId:         b.chainID.Int64(),
+	}
+
+	if config != nil {
+		traceCallRequest.TraceConfig = &config.TraceConfig
+		traceCallRequest.Overrides, err = marshalStateOverrides(config.StateOverrides)
+		if err != nil {
+			return nil, err
+		}
+		if config.BlockOverrides != nil {
+			traceCallRequest.BlockOverrides, err = json.Marshal(config.BlockOverrides)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	ctx := rpctypes.ContextWithHeight(blk.Block.Height)
+	if timeout := b.RPCEVMTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	traceResult, err := b.queryClient.TraceCall(ctx, &traceCallRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	// Response format is unknown due to custom tracer config param
+	var decodedResult interface{}
+	if err := json.Unmarshal(traceResult.Data, &decodedResult); err != nil {
+		return nil, err
+	}
+
+	return decodedResult, nil
+}
+
 // TraceBlock configures a new tracer according to the provided configuration, and
 // executes all the transactions contained within. The return value will be one item
 // per transaction, dependent on the requested tracer.
@@ -149,29 +227,7 @@ func (b *Backend) TraceBlock(height rpctypes.BlockNumber,
 		b.logger.Debug("block result not found", "height", block.Block.Height, "error", err.Error())
 		return nil, nil
 	}
-	txDecoder := b.clientCtx.TxConfig.TxDecoder()
-
-	var txsMessages []*evmtypes.MsgEthereumTx
-	for i, tx := range txs {
-		if !rpctypes.TxSuccessOrExceedsBlockGasLimit(blockRes.TxsResults[i]) {
-			b.logger.Debug("invalid tx result code", "cosmos-hash", hexutil.Encode(tx.Hash()))
-			continue
-		}
-		decodedTx, err := txDecoder(tx)
-		if err != nil {
-			b.logger.Error("failed to decode transaction", "hash", txs[i].Hash(), "error", err.Error())
-			continue
-		}
-
-		for _, msg := range decodedTx.GetMsgs() {
-			ethMessage, ok := msg.(*evmtypes.MsgEthereumTx)
-			if !ok {
-				// Just considers Ethereum transactions
-				continue
-			}
-			txsMessages = append(txsMessages, ethMessage)
-		}
-	}
+	txsMessages := b.blockEthMsgsToReplay(block, blockRes)
 
 	// minus one to get the context at the beginning of the block
 	contextHeight := height - 1
@@ -203,3 +259,311 @@ func (b *Backend) TraceBlock(height rpctypes.BlockNumber,
 
 	return decodedResults, nil
 }
+
+// TraceCalls returns the OpenEthereum style call traces of a transaction.
+func (b *Backend) TraceCalls(hash common.Hash) ([]tracers.FlatCallTrace, error) {
+	res, err := b.TraceTransaction(hash, &evmtypes.TraceConfig{Tracer: tracers.FlatCallTracer})
+	if err != nil {
+		return nil, err
+	}
+
+	var traces []tracers.FlatCallTrace
+	if err := decodeTraceResult(res, &traces); err != nil {
+		return nil, err
+	}
+	return traces, nil
+}
+
+// TraceBlockCalls returns the OpenEthereum style call traces of the transactions
+// of a block, in the order of the block.
+func (b *Backend) TraceBlockCalls(block *tmrpctypes.ResultBlock) ([]tracers.FlatCallTrace, error) {
+	results, err := b.TraceBlock(
+		rpctypes.BlockNumber(block.Block.Height),
+		&evmtypes.TraceConfig{Tracer: tracers.FlatCallTracer},
+		block,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	traces := []tracers.FlatCallTrace{}
+	for _, result := range results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("failed to trace transaction in block %d: %s", block.Block.Height, result.Error)
+		}
+
+		var txTraces []tracers.FlatCallTrace
+		if err := decodeTraceResult(result.Result, &txTraces); err != nil {
+			return nil, err
+		}
+		traces = append(traces, txTraces...)
+	}
+	return traces, nil
+}
+
+// TraceFilter returns the OpenEthereum style call traces of the block range
+// matching the sender and recipient addresses of the filter.
+func (b *Backend) TraceFilter(args rpctypes.TraceFilterArgs) ([]tracers.FlatCallTrace, error) {
+	latest, err := b.BlockNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	resolve := func(blockNum *rpctypes.BlockNumber) int64 {
+		if blockNum == nil || *blockNum == rpctypes.EthLatestBlockNumber || *blockNum == rpctypes.EthPendingBlockNumber {
+			return int64(latest) // #nosec G115
+		}
+		return blockNum.Int64()
+	}
+	from, to := max(resolve(args.FromBlock), 1), resolve(args.ToBlock)
+	if from > to {
+		return nil, fmt.Errorf("invalid block range: from block %d is after to block %d", from, to)
+	}
+	if blockRangeCap := min(int64(b.RPCBlockRangeCap()), maxTraceFilterBlockRange); to-from > blockRangeCap {
+		return nil, fmt.Errorf("maximum [from, to] blocks distance: %d", blockRangeCap)
+	}
+	if to > int64(latest) { // #nosec G115
+		return nil, fmt.Errorf("to block %d is after the latest block %d", to, latest)
+	}
+
+	fromAddresses := make(map[common.Address]struct{}, len(args.FromAddress))
+	for _, addr := range args.FromAddress {
+		fromAddresses[addr] = struct{}{}
+	}
+	toAddresses := make(map[common.Address]struct{}, len(args.ToAddress))
+	for _, addr := range args.ToAddress {
+		toAddresses[addr] = struct{}{}
+	}
+
+	var after uint64
+	if args.After != nil {
+		after = *args.After
+	}
+
+	traces := []tracers.FlatCallTrace{}
+	for height := from; height <= to; height++ {
+		block, err := b.TendermintBlockByNumber(rpctypes.BlockNumber(height))
+		if err != nil {
+			return nil, err
+		}
+		if block == nil || block.Block == nil {
+			return nil, fmt.Errorf("block %d not found", height)
+		}
+
+		blockTraces, err := b.TraceBlockCalls(block)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, trace := range blockTraces {
+			if !traceMatchesAddresses(trace, fromAddresses, toAddresses) {
+				continue
+			}
+			if after > 0 {
+				after--
+				continue
+			}
+			traces = append(traces, trace)
+			if args.Count != nil && uint64(len(traces)) == *args.Count {
+				return traces, nil
+			}
+		}
+	}
+	return traces, nil
+}
+
+// TraceReplayTransaction replays a transaction, and returns the OpenEthereum style
+// traces of the requested types, "trace" and "stateDiff" are supported.
+func (b *Backend) TraceReplayTransaction(hash common.Hash, traceTypes []string) (*rpctypes.TraceResults, error) {
+	var withTrace, withStateDiff bool
+	for _, traceType := range traceTypes {
+		switch traceType {
+		case "trace":
+			withTrace = true
+		case "stateDiff":
+			withStateDiff = true
+		default:
+			return nil, fmt.Errorf("unsupported trace type: %s", traceType)
+		}
+	}
+
+	results := &rpctypes.TraceResults{Output: hexutil.Bytes{}}
+
+	// the call traces are needed for the output of the transaction, the state diff is
+	// traced on the same replay
+	var traces []tracers.FlatCallTrace
+	if withStateDiff {
+		res, err := b.TraceTransaction(hash, &evmtypes.TraceConfig{
+			Tracer:           tracers.MuxTracer,
+			TracerJsonConfig: fmt.Sprintf(`{"%s":{},"%s":{}}`, tracers.FlatCallTracer, tracers.StateDiffTracer),
+		})
+		if err != nil {
+			return nil, err
+		}
+		var replay map[string]json.RawMessage
+		if err := decodeTraceResult(res, &replay); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(replay[tracers.FlatCallTracer], &traces); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(replay[tracers.StateDiffTracer], &results.StateDiff); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if traces, err = b.TraceCalls(hash); err != nil {
+			return nil, err
+		}
+	}
+	if len(traces) == 0 {
+		return nil, errors.New("transaction has no call trace")
+	}
+
+	if res := traces[0].Result; res != nil {
+		switch {
+		case res.Output != nil:
+			results.Output = *res.Output
+		case res.Code != nil:
+			results.Output = *res.Code
+		}
+	}
+
+	if withTrace {
+		// replayed traces are not bound to a block
+		for i := range traces {
+			traces[i].BlockHash = nil
+			traces[i].BlockNumber = nil
+			traces[i].TransactionHash = nil
+			traces[i].TransactionPosition = nil
+		}
+		results.Trace = traces
+	}
+	return results, nil
+}
+
+// traceMatchesAddresses returns true if the sender and the recipient of the traced call
+// are in the given sets, an empty set matches any address.
+func traceMatchesAddresses(trace tracers.FlatCallTrace, fromAddresses, toAddresses map[common.Address]struct{}) bool {
+	matches := func(addresses map[common.Address]struct{}, candidates ...*common.Address) bool {
+		if len(addresses) == 0 {
+			return true
+		}
+		for _, addr := range candidates {
+			if addr == nil {
+				continue
+			}
+			if _, ok := addresses[*addr]; ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	var created *common.Address
+	if trace.Result != nil {
+		created = trace.Result.Address
+	}
+	return matches(fromAddresses, trace.Action.From, trace.Action.Address) &&
+		matches(toAddresses, trace.Action.To, trace.Action.RefundAddress, created)
+}
+
+// decodeTraceResult decodes the result of a tracer, which is returned as
+// generic json by the trace queries.
+func decodeTraceResult(result interface{}, v interface{}) error {
+	bz, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bz, v)
+}
+
+// IntermediateRoots executes all the transactions contained within the block, and
+// returns the state commitment after each transaction. No tracer is run, so only the
+// timeout of the trace config is honored.
+func (b *Backend) IntermediateRoots(block *tmrpctypes.ResultBlock, config *evmtypes.TraceConfig) ([]common.Hash, error) {
+	var timeout time.Duration
+	if config != nil {
+		if config.Tracer != "" {
+			return nil, fmt.Errorf("tracer %s is not supported by the intermediate roots", config.Tracer)
+		}
+		if config.Timeout != "" {
+			var err error
+			if timeout, err = time.ParseDuration(config.Timeout); err != nil {
+				return nil, fmt.Errorf("invalid timeout value: %w", err)
+			}
+		}
+	}
+
+	if len(block.Block.Txs) == 0 {
+		return []common.Hash{}, nil
+	}
+	blockRes, err := b.TendermintBlockResultByNumber(&block.Block.Height)
+	if err != nil {
+		return nil, fmt.Errorf("block result not found for height %d: %w", block.Block.Height, err)
+	}
+
+	// minus one to get the context at the beginning of the block
+	contextHeight := block.Block.Height - 1
+	if contextHeight < 1 {
+		// 0 is a special value for `ContextWithHeight`.
+		contextHeight = 1
+	}
+
+	req := &evmtypes.QueryIntermediateRootsRequest{
+		Txs:             b.blockEthMsgsToReplay(block, blockRes),
+		BlockNumber:     block.Block.Height,
+		BlockTime:       block.Block.Time,
+		BlockHash:       common.Bytes2Hex(block.BlockID.Hash),
+		ProposerAddress: sdk.ConsAddress(block.Block.ProposerAddress),
+		ChainId:         b.chainID.Int64(),
+	}
+
+	ctx := rpctypes.ContextWithHeight(contextHeight)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	res, err := b.queryClient.IntermediateRoots(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	roots := make([]common.Hash, len(res.Roots))
+	for i, root := range res.Roots {
+		roots[i] = common.BytesToHash(root)
+	}
+	return roots, nil
+}
+
+// blockEthMsgsToReplay returns the ethereum messages of the block transactions
+// which were executed, in the order of the block.
+func (b *Backend) blockEthMsgsToReplay(block *tmrpctypes.ResultBlock, blockRes *tmrpctypes.ResultBlockResults) []*evmtypes.MsgEthereumTx {
+	txs := block.Block.Txs
+	txDecoder := b.clientCtx.TxConfig.TxDecoder()
+
+	var txsMessages []*evmtypes.MsgEthereumTx
+	for i, tx := range txs {
+		if !rpctypes.TxSuccessOrExceedsBlockGasLimit(blockRes.TxsResults[i]) {
+			b.logger.Debug("invalid tx result code", "cosmos-hash", hexutil.Encode(tx.Hash()))
+			continue
+		}
+		decodedTx, err := txDecoder(tx)
+		if err != nil {
+			b.logger.Error("failed to decode transaction", "hash", txs[i].Hash(), "error", err.Error())
+			continue
+		}
+
+		for _, msg := range decodedTx.GetMsgs() {
+			ethMessage, ok := msg.(*evmtypes.MsgEthereumTx)
+			if !ok {
+				// Just considers Ethereum transactions
+				continue
+			}
+			txsMessages = append(txsMessages, ethMessage)
+		}
+	}
+	return txsMessages
+}
@@ -1,7 +1,9 @@
 package backend
 
 import (
+	"encoding/json"
 	"fmt"
+	"math/big"
 
 	"cosmossdk.io/log"
 	abci "github.com/cometbft/cometbft/abci/types"
@@ -11,11 +13,16 @@ import (
 	dbm "github.com/cosmos/cosmos-db"
 	"github.com/cosmos/cosmos-sdk/crypto"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/evmos/ethermint/crypto/ethsecp256k1"
 	"github.com/evmos/ethermint/indexer"
 	"github.com/evmos/ethermint/rpc/backend/mocks"
+	rpctypes "github.com/evmos/ethermint/rpc/types"
+	"github.com/evmos/ethermint/tests"
+	"github.com/evmos/ethermint/x/evm/tracers"
 	evmtypes "github.com/evmos/ethermint/x/evm/types"
+	"google.golang.org/grpc/metadata"
 )
 
 func (suite *BackendTestSuite) TestTraceTransaction() {
@@ -258,3 +265,462 @@ func (suite *BackendTestSuite) TestTraceBlock() {
 		})
 	}
 }
+
+func (suite *BackendTestSuite) TestIntermediateRoots() {
+	msgEthTx, bz := suite.buildEthereumTx()
+	emptyBlock := tmtypes.MakeBlock(1, []tmtypes.Tx{}, nil, nil)
+	emptyBlock.ChainID = ChainID
+	filledBlock := tmtypes.MakeBlock(1, []tmtypes.Tx{bz}, nil, nil)
+	filledBlock.ChainID = ChainID
+	resBlockEmpty := tmrpctypes.ResultBlock{Block: emptyBlock, BlockID: emptyBlock.LastBlockID}
+	resBlockFilled := tmrpctypes.ResultBlock{Block: filledBlock, BlockID: filledBlock.LastBlockID}
+	root := common.HexToHash("0x1")
+
+	testCases := []struct {
+		name         string
+		registerMock func()
+		resBlock     *tmrpctypes.ResultBlock
+		config       *evmtypes.TraceConfig
+		expRoots     []common.Hash
+		expPass      bool
+	}{
+		{
+			"pass - no transaction returning empty array",
+			func() {},
+			&resBlockEmpty,
+			nil,
+			[]common.Hash{},
+			true,
+		},
+		{
+			"fail - tracer is not supported",
+			func() {},
+			&resBlockFilled,
+			&evmtypes.TraceConfig{Tracer: "callTracer"},
+			nil,
+			false,
+		},
+		{
+			"fail - invalid timeout",
+			func() {},
+			&resBlockFilled,
+			&evmtypes.TraceConfig{Timeout: "1 second"},
+			nil,
+			false,
+		},
+		{
+			"fail - block results not found",
+			func() {
+				client := suite.backend.clientCtx.Client.(*mocks.Client)
+				RegisterBlockResultsError(client, 1)
+			},
+			&resBlockFilled,
+			nil,
+			nil,
+			false,
+		},
+		{
+			"pass - one root per transaction",
+			func() {
+				queryClient := suite.backend.queryClient.QueryClient.(*mocks.EVMQueryClient)
+				RegisterIntermediateRoots(queryClient, []*evmtypes.MsgEthereumTx{msgEthTx}, [][]byte{root.Bytes()})
+				client := suite.backend.clientCtx.Client.(*mocks.Client)
+				RegisterBlockResults(client, 1)
+			},
+			&resBlockFilled,
+			nil,
+			[]common.Hash{root},
+			true,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(fmt.Sprintf("case %s", tc.name), func() {
+			suite.SetupTest() // reset test and queries
+			tc.registerMock()
+
+			roots, err := suite.backend.IntermediateRoots(tc.resBlock, tc.config)
+			if tc.expPass {
+				suite.Require().NoError(err)
+				suite.Require().Equal(tc.expRoots, roots)
+			} else {
+				suite.Require().Error(err)
+			}
+		})
+	}
+}
+
+// flatCallTraces returns the call traces of a transaction calling a contract,
+// which calls another contract and creates a third one.
+func flatCallTraces(sender, contract, callee, created common.Address) []tracers.FlatCallTrace {
+	return []tracers.FlatCallTrace{
+		{
+			Type:         "call",
+			Action:       tracers.FlatCallAction{CallType: "call", From: &sender, To: &contract},
+			Result:       &tracers.FlatCallResult{Output: &hexutil.Bytes{0x1}},
+			Subtraces:    2,
+			TraceAddress: []int{},
+		},
+		{
+			Type:         "call",
+			Action:       tracers.FlatCallAction{CallType: "call", From: &contract, To: &callee},
+			Result:       &tracers.FlatCallResult{Output: &hexutil.Bytes{}},
+			TraceAddress: []int{0},
+		},
+		{
+			Type:         "create",
+			Action:       tracers.FlatCallAction{From: &contract},
+			Result:       &tracers.FlatCallResult{Address: &created, Code: &hexutil.Bytes{}},
+			TraceAddress: []int{1},
+		},
+	}
+}
+
+func (suite *BackendTestSuite) TestTraceBlockCalls() {
+	msgEthTx, bz := suite.buildEthereumTx()
+	block := tmtypes.MakeBlock(1, []tmtypes.Tx{bz}, nil, nil)
+	block.ChainID = ChainID
+	resBlock := &tmrpctypes.ResultBlock{Block: block, BlockID: block.LastBlockID}
+	traces := flatCallTraces(tests.GenerateAddress(), tests.GenerateAddress(), tests.GenerateAddress(), tests.GenerateAddress())
+	config := &evmtypes.TraceConfig{Tracer: tracers.FlatCallTracer}
+
+	testCases := []struct {
+		name      string
+		results   []*evmtypes.TxTraceResult
+		expTraces []tracers.FlatCallTrace
+		expPass   bool
+	}{
+		{
+			"pass - traces of the block transactions",
+			[]*evmtypes.TxTraceResult{{Result: traces}},
+			traces,
+			true,
+		},
+		{
+			"fail - transaction trace failed",
+			[]*evmtypes.TxTraceResult{{Error: "execution timeout"}},
+			nil,
+			false,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(fmt.Sprintf("case %s", tc.name), func() {
+			suite.SetupTest() // reset test and queries
+			queryClient := suite.backend.queryClient.QueryClient.(*mocks.EVMQueryClient)
+			RegisterTraceBlockWithResults(queryClient, []*evmtypes.MsgEthereumTx{msgEthTx}, config, tc.results)
+			client := suite.backend.clientCtx.Client.(*mocks.Client)
+			RegisterBlockResults(client, 1)
+
+			res, err := suite.backend.TraceBlockCalls(resBlock)
+			if tc.expPass {
+				suite.Require().NoError(err)
+				suite.Require().Equal(tc.expTraces, res)
+			} else {
+				suite.Require().Error(err)
+			}
+		})
+	}
+}
+
+func (suite *BackendTestSuite) TestTraceFilter() {
+	msgEthTx, bz := suite.buildEthereumTx()
+	sender, contract, callee, created := tests.GenerateAddress(), tests.GenerateAddress(), tests.GenerateAddress(), tests.GenerateAddress()
+	traces := flatCallTraces(sender, contract, callee, created)
+	one, blockNum := uint64(1), rpctypes.BlockNumber(1)
+	aboveCap := rpctypes.BlockNumber(2 + maxTraceFilterBlockRange)
+	afterLatest := rpctypes.BlockNumber(2)
+
+	registerBlockTraces := func() {
+		client := suite.backend.clientCtx.Client.(*mocks.Client)
+		_, err := RegisterBlock(client, 1, bz)
+		suite.Require().NoError(err)
+		_, err = RegisterBlockResults(client, 1)
+		suite.Require().NoError(err)
+		queryClient := suite.backend.queryClient.QueryClient.(*mocks.EVMQueryClient)
+		RegisterTraceBlockWithResults(
+			queryClient,
+			[]*evmtypes.MsgEthereumTx{msgEthTx},
+			&evmtypes.TraceConfig{Tracer: tracers.FlatCallTracer},
+			[]*evmtypes.TxTraceResult{{Result: traces}},
+		)
+	}
+
+	testCases := []struct {
+		name         string
+		registerMock func()
+		args         rpctypes.TraceFilterArgs
+		expTraces    []tracers.FlatCallTrace
+		expPass      bool
+	}{
+		{
+			"pass - all traces of the latest block",
+			registerBlockTraces,
+			rpctypes.TraceFilterArgs{},
+			traces,
+			true,
+		},
+		{
+			"pass - filter by sender",
+			registerBlockTraces,
+			rpctypes.TraceFilterArgs{FromBlock: &blockNum, ToBlock: &blockNum, FromAddress: []common.Address{contract}},
+			traces[1:],
+			true,
+		},
+		{
+			"pass - filter by created contract",
+			registerBlockTraces,
+			rpctypes.TraceFilterArgs{ToAddress: []common.Address{created}},
+			traces[2:],
+			true,
+		},
+		{
+			"pass - filter by sender and recipient",
+			registerBlockTraces,
+			rpctypes.TraceFilterArgs{FromAddress: []common.Address{contract, sender}, ToAddress: []common.Address{contract}},
+			traces[:1],
+			true,
+		},
+		{
+			"pass - paginated",
+			registerBlockTraces,
+			rpctypes.TraceFilterArgs{After: &one, Count: &one},
+			traces[1:2],
+			true,
+		},
+		{
+			"fail - block range above the cap",
+			func() {},
+			rpctypes.TraceFilterArgs{FromBlock: &blockNum, ToBlock: &aboveCap},
+			nil,
+			false,
+		},
+		{
+			"fail - to block after the latest block",
+			func() {},
+			rpctypes.TraceFilterArgs{FromBlock: &blockNum, ToBlock: &afterLatest},
+			nil,
+			false,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(fmt.Sprintf("case %s", tc.name), func() {
+			suite.SetupTest() // reset test and queries
+			var header metadata.MD
+			queryClient := suite.backend.queryClient.QueryClient.(*mocks.EVMQueryClient)
+			RegisterParams(queryClient, &header, 1)
+			tc.registerMock()
+
+			res, err := suite.backend.TraceFilter(tc.args)
+			if tc.expPass {
+				suite.Require().NoError(err)
+				suite.Require().Equal(tc.expTraces, res)
+			} else {
+				suite.Require().Error(err)
+			}
+		})
+	}
+}
+
+func (suite *BackendTestSuite) TestTraceReplayTransaction() {
+	msgEthereumTx, _ := suite.buildEthereumTx()
+	txBz := suite.signAndEncodeEthTx(msgEthereumTx)
+	txHash := msgEthereumTx.AsTransaction().Hash()
+
+	traces := flatCallTraces(tests.GenerateAddress(), tests.GenerateAddress(), tests.GenerateAddress(), tests.GenerateAddress())
+	blockHash, txPosition := common.HexToHash("0x1"), uint64(0)
+	for i := range traces {
+		traces[i].BlockHash = &blockHash
+		traces[i].TransactionHash = &txHash
+		traces[i].TransactionPosition = &txPosition
+	}
+	stateDiff := map[string]interface{}{"0x0000000000000000000000000000000000000001": map[string]interface{}{"balance": "="}}
+
+	registerTraces := func(withStateDiff bool) func() {
+		return func() {
+			queryClient := suite.backend.queryClient.QueryClient.(*mocks.EVMQueryClient)
+			client := suite.backend.clientCtx.Client.(*mocks.Client)
+			_, err := RegisterBlock(client, 1, txBz)
+			suite.Require().NoError(err)
+			if withStateDiff {
+				// both tracers run on a single replay
+				RegisterTraceTransactionWithConfig(queryClient, msgEthereumTx, &evmtypes.TraceConfig{
+					Tracer:           tracers.MuxTracer,
+					TracerJsonConfig: `{"flatCallTracer":{},"stateDiffTracer":{}}`,
+				}, map[string]interface{}{tracers.FlatCallTracer: traces, tracers.StateDiffTracer: stateDiff})
+			} else {
+				RegisterTraceTransactionWithConfig(queryClient, msgEthereumTx, &evmtypes.TraceConfig{Tracer: tracers.FlatCallTracer}, traces)
+			}
+		}
+	}
+
+	testCases := []struct {
+		name         string
+		registerMock func()
+		traceTypes   []string
+		expResults   *rpctypes.TraceResults
+		expPass      bool
+	}{
+		{
+			"pass - output only",
+			registerTraces(false),
+			[]string{},
+			&rpctypes.TraceResults{Output: hexutil.Bytes{0x1}},
+			true,
+		},
+		{
+			"pass - trace and state diff",
+			registerTraces(true),
+			[]string{"trace", "stateDiff"},
+			&rpctypes.TraceResults{
+				Output:    hexutil.Bytes{0x1},
+				Trace:     flatCallTraces(*traces[0].Action.From, *traces[0].Action.To, *traces[1].Action.To, *traces[2].Result.Address),
+				StateDiff: stateDiff,
+			},
+			true,
+		},
+		{
+			"fail - unsupported trace type",
+			func() {},
+			[]string{"vmTrace"},
+			nil,
+			false,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(fmt.Sprintf("case %s", tc.name), func() {
+			suite.SetupTest() // reset test and queries
+			tc.registerMock()
+
+			suite.backend.indexer = indexer.NewKVIndexer(dbm.NewMemDB(), log.NewNopLogger(), suite.backend.clientCtx)
+			err := suite.backend.indexer.IndexBlock(
+				&types.Block{Header: types.Header{Height: 1}, Data: types.Data{Txs: []types.Tx{txBz}}},
+				&abci.ResponseFinalizeBlock{TxResults: []*abci.ExecTxResult{{
+					Events: []abci.Event{{Type: evmtypes.EventTypeEthereumTx, Attributes: []abci.EventAttribute{
+						{Key: "ethereumTxHash", Value: txHash.Hex()},
+						{Key: "txIndex", Value: "0"},
+						{Key: "txGasUsed", Value: "21000"},
+					}}},
+				}}},
+			)
+			suite.Require().NoError(err)
+
+			res, err := suite.backend.TraceReplayTransaction(txHash, tc.traceTypes)
+			if tc.expPass {
+				suite.Require().NoError(err)
+				suite.Require().Equal(tc.expResults, res)
+			} else {
+				suite.Require().Error(err)
+			}
+		})
+	}
+}
+
+func (suite *BackendTestSuite) TestTraceCall() {
+	toAddr := tests.GenerateAddress()
+	callArgs := evmtypes.TransactionArgs{To: &toAddr}
+	argsBz, err := json.Marshal(callArgs)
+	suite.Require().NoError(err)
+
+	code := hexutil.Bytes{0x1}
+	stateOverrides := rpctypes.StateOverride{toAddr: rpctypes.OverrideAccount{Code: &code}}
+	stateOverridesBz, err := json.Marshal(stateOverrides)
+	suite.Require().NoError(err)
+	blockOverrides := evmtypes.BlockOverrides{Number: (*hexutil.Big)(big.NewInt(10))}
+	blockOverridesBz, err := json.Marshal(blockOverrides)
+	suite.Require().NoError(err)
+
+	request := func() *evmtypes.QueryTraceCallRequest {
+		return &evmtypes.QueryTraceCallRequest{
+			Args:        argsBz,
+			GasCap:      suite.backend.RPCGasCap(),
+			BlockNumber: 1,
+			ChainId:     suite.backend.chainID.Int64(),
+		}
+	}
+	result := map[string]interface{}{"type": "CALL"}
+
+	testCases := []struct {
+		name         string
+		registerMock func()
+		config       *rpctypes.TraceCallConfig
+		expResult    interface{}
+		expPass      bool
+	}{
+		{
+			"fail - block not found",
+			func() {
+				client := suite.backend.clientCtx.Client.(*mocks.Client)
+				RegisterBlockError(client, 1)
+			},
+			nil,
+			nil,
+			false,
+		},
+		{
+			"fail - trace call error",
+			func() {
+				client := suite.backend.clientCtx.Client.(*mocks.Client)
+				queryClient := suite.backend.queryClient.QueryClient.(*mocks.EVMQueryClient)
+				_, err := RegisterBlock(client, 1, nil)
+				suite.Require().NoError(err)
+				RegisterTraceCallError(queryClient, request())
+			},
+			nil,
+			nil,
+			false,
+		},
+		{
+			"pass - without config",
+			func() {
+				client := suite.backend.clientCtx.Client.(*mocks.Client)
+				queryClient := suite.backend.queryClient.QueryClient.(*mocks.EVMQueryClient)
+				_, err := RegisterBlock(client, 1, nil)
+				suite.Require().NoError(err)
+				RegisterTraceCall(queryClient, request(), result)
+			},
+			nil,
+			result,
+			true,
+		},
+		{
+			"pass - with tracer and overrides",
+			func() {
+				client := suite.backend.clientCtx.Client.(*mocks.Client)
+				queryClient := suite.backend.queryClient.QueryClient.(*mocks.EVMQueryClient)
+				_, err := RegisterBlock(client, 1, nil)
+				suite.Require().NoError(err)
+				req := request()
+				req.TraceConfig = &evmtypes.TraceConfig{Tracer: "callTracer"}
+				req.Overrides = stateOverridesBz
+				req.BlockOverrides = blockOverridesBz
+				RegisterTraceCall(queryClient, req, result)
+			},
+			&rpctypes.TraceCallConfig{
+				TraceConfig:    evmtypes.TraceConfig{Tracer: "callTracer"},
+				StateOverrides: &stateOverrides,
+				BlockOverrides: &blockOverrides,
+			},
+			result,
+			true,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(fmt.Sprintf("case %s", tc.name), func() {
+			suite.SetupTest() // reset test and queries
+			tc.registerMock()
+
+			blockNr := rpctypes.BlockNumber(1)
+			res, err := suite.backend.TraceCall(callArgs, rpctypes.BlockNumberOrHash{BlockNumber: &blockNr}, tc.config)
+			if tc.expPass {
+				suite.Require().NoError(err)
+				suite.Require().Equal(tc.expResult, res)
+			} else {
+				suite.Require().Error(err)
+			}
+		})
+	}
+}
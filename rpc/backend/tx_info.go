@@ -27,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/evmos/ethermint/indexer"
 	rpctypes "github.com/evmos/ethermint/rpc/types"
 	ethermint "github.com/evmos/ethermint/types"
 	evmtypes "github.com/evmos/ethermint/x/evm/types"
@@ -166,21 +167,130 @@ func (b *Backend) GetTransactionReceipt(hash common.Hash) (map[string]interface{
 	}
 	ethMsg := tx.GetMsgs()[res.MsgIndex].(*evmtypes.MsgEthereumTx)
 
-	txData, err := evmtypes.UnpackTxData(ethMsg.Data)
-	if err != nil {
-		b.logger.Error("failed to unpack tx data", "error", err.Error())
-		return nil, err
-	}
-
-	cumulativeGasUsed := uint64(0)
 	blockRes, err := b.TendermintBlockResultByNumber(&res.Height)
 	if err != nil {
 		b.logger.Debug("failed to retrieve block results", "height", res.Height, "error", err.Error())
 		return nil, nil
 	}
+	cumulativeGasUsed := uint64(0)
 	for _, txResult := range blockRes.TxsResults[0:res.TxIndex] {
 		cumulativeGasUsed += uint64(txResult.GasUsed)
 	}
+
+	chainID, err := b.ChainID()
+	if err != nil {
+		return nil, err
+	}
+
+	if res.EthTxIndex == -1 {
+		// Fallback to find tx index by iterating all valid eth transactions
+		msgs := b.EthMsgsFromTendermintBlock(resBlock, blockRes)
+		for i := range msgs {
+			if msgs[i].Hash == hexTx {
+				res.EthTxIndex = int32(i) //#nosec G115
+				break
+			}
+		}
+	}
+	// return error if still unable to find the eth tx index
+	if res.EthTxIndex == -1 {
+		return nil, errors.New("can't find index of ethereum tx")
+	}
+
+	var baseFee *big.Int
+	if ethMsg.AsTransaction().Type() == ethtypes.DynamicFeeTxType {
+		baseFee, err = b.BaseFee(blockRes)
+		if err != nil {
+			// tolerate the error for pruned node.
+			b.logger.Error("fetch basefee failed, node is pruned?", "height", res.Height, "error", err)
+		}
+	}
+
+	return b.formatTxReceipt(ethMsg, res, resBlock, blockRes, cumulativeGasUsed, chainID.ToInt(), baseFee)
+}
+
+// GetBlockReceipts returns the receipts of all the ethereum transactions of a block.
+// The block and its results are fetched once, the receipts are built from them.
+func (b *Backend) GetBlockReceipts(blockNrOrHash rpctypes.BlockNumberOrHash) ([]map[string]interface{}, error) {
+	blockNum, err := b.BlockNumberFromTendermint(blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	resBlock, err := b.TendermintBlockByNumber(blockNum)
+	if err != nil {
+		b.logger.Debug("block not found", "height", blockNum, "error", err.Error())
+		return nil, nil
+	}
+	if resBlock == nil || resBlock.Block == nil {
+		return nil, nil
+	}
+	height := resBlock.Block.Height
+	blockRes, err := b.TendermintBlockResultByNumber(&height)
+	if err != nil {
+		b.logger.Debug("failed to retrieve block results", "height", height, "error", err.Error())
+		return nil, nil
+	}
+
+	return b.ReceiptsFromBlockResults(resBlock, blockRes)
+}
+
+// ReceiptsFromBlockResults builds the receipts of all the ethereum transactions of a
+// block from the block results. The results of the transactions are parsed from the
+// events like the indexer does, so the block doesn't need to be indexed yet.
+func (b *Backend) ReceiptsFromBlockResults(
+	resBlock *tmrpctypes.ResultBlock,
+	blockRes *tmrpctypes.ResultBlockResults,
+) ([]map[string]interface{}, error) {
+	chainID, err := b.ChainID()
+	if err != nil {
+		return nil, err
+	}
+	baseFee, err := b.BaseFee(blockRes)
+	if err != nil {
+		// tolerate the error for pruned node.
+		b.logger.Error("fetch basefee failed, node is pruned?", "height", blockRes.Height, "error", err)
+	}
+
+	// gas used by the cosmos txs preceding each tx of the block
+	precedingGasUsed := make([]uint64, len(blockRes.TxsResults)+1)
+	for i, txResult := range blockRes.TxsResults {
+		precedingGasUsed[i+1] = precedingGasUsed[i] + uint64(txResult.GasUsed) //#nosec G115
+	}
+
+	txs := indexer.ParseEthTxs(b.clientCtx.TxConfig.TxDecoder(), b.logger, resBlock.Block, blockRes.TxsResults)
+	receipts := make([]map[string]interface{}, 0, len(txs))
+	for i := range txs {
+		res := &txs[i].Result
+		receipt, err := b.formatTxReceipt(txs[i].Msg, res, resBlock, blockRes, precedingGasUsed[res.TxIndex], chainID.ToInt(), baseFee)
+		if err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, receipt)
+	}
+
+	return receipts, nil
+}
+
+// formatTxReceipt builds the receipt of an ethereum tx from its result and the results
+// of its block. The cumulative gas used is the gas used by the cosmos txs preceding the
+// tx in the block, and the base fee is the one of the block, if any.
+func (b *Backend) formatTxReceipt(
+	ethMsg *evmtypes.MsgEthereumTx,
+	res *ethermint.TxResult,
+	resBlock *tmrpctypes.ResultBlock,
+	blockRes *tmrpctypes.ResultBlockResults,
+	cumulativeGasUsed uint64,
+	chainID *big.Int,
+	baseFee *big.Int,
+) (map[string]interface{}, error) {
+	hash := ethMsg.AsTransaction().Hash()
+
+	txData, err := evmtypes.UnpackTxData(ethMsg.Data)
+	if err != nil {
+		b.logger.Error("failed to unpack tx data", "error", err.Error())
+		return nil, err
+	}
+
 	cumulativeGasUsed += res.CumulativeGasUsed
 
 	var status hexutil.Uint
@@ -189,10 +299,6 @@ func (b *Backend) GetTransactionReceipt(hash common.Hash) (map[string]interface{
 	} else {
 		status = hexutil.Uint(ethtypes.ReceiptStatusSuccessful)
 	}
-	chainID, err := b.ChainID()
-	if err != nil {
-		return nil, err
-	}
 
 	// NOTE
 	// This patch applies only to the period when the chain-id was set to 9000 between the v8 and v8.1.1 versions of Canto.
@@ -202,7 +308,7 @@ func (b *Backend) GetTransactionReceipt(hash common.Hash) (map[string]interface{
 	if res.Height >= 10848200 && res.Height < 10849447 {
 		from, err = ethMsg.GetSender(big.NewInt(9000)) // 9000 is the default chain-id that was applied during that period.
 	} else {
-		from, err = ethMsg.GetSender(chainID.ToInt())
+		from, err = ethMsg.GetSender(chainID)
 	}
 	if err != nil {
 		return nil, err
@@ -211,22 +317,7 @@ func (b *Backend) GetTransactionReceipt(hash common.Hash) (map[string]interface{
 	// parse tx logs from events
 	logs, err := TxLogsFromEvents(blockRes.TxsResults[res.TxIndex].Events, int(res.MsgIndex))
 	if err != nil {
-		b.logger.Debug("failed to parse logs", "hash", hexTx, "error", err.Error())
-	}
-
-	if res.EthTxIndex == -1 {
-		// Fallback to find tx index by iterating all valid eth transactions
-		msgs := b.EthMsgsFromTendermintBlock(resBlock, blockRes)
-		for i := range msgs {
-			if msgs[i].Hash == hexTx {
-				res.EthTxIndex = int32(i) //#nosec G115
-				break
-			}
-		}
-	}
-	// return error if still unable to find the eth tx index
-	if res.EthTxIndex == -1 {
-		return nil, errors.New("can't find index of ethereum tx")
+		b.logger.Debug("failed to parse logs", "hash", hash.Hex(), "error", err.Error())
 	}
 
 	receipt := map[string]interface{}{
@@ -263,14 +354,8 @@ func (b *Backend) GetTransactionReceipt(hash common.Hash) (map[string]interface{
 		receipt["contractAddress"] = crypto.CreateAddress(from, txData.GetNonce())
 	}
 
-	if dynamicTx, ok := txData.(*evmtypes.DynamicFeeTx); ok {
-		baseFee, err := b.BaseFee(blockRes)
-		if err != nil {
-			// tolerate the error for pruned node.
-			b.logger.Error("fetch basefee failed, node is pruned?", "height", res.Height, "error", err)
-		} else {
-			receipt["effectiveGasPrice"] = hexutil.Big(*dynamicTx.EffectiveGasPrice(baseFee))
-		}
+	if dynamicTx, ok := txData.(*evmtypes.DynamicFeeTx); ok && baseFee != nil {
+		receipt["effectiveGasPrice"] = hexutil.Big(*dynamicTx.EffectiveGasPrice(baseFee))
 	}
 
 	return receipt, nil
@@ -20,6 +20,7 @@ import (
 	"github.com/evmos/ethermint/tests"
 	ethermint "github.com/evmos/ethermint/types"
 	evmtypes "github.com/evmos/ethermint/x/evm/types"
+	"github.com/stretchr/testify/mock"
 	"google.golang.org/grpc/metadata"
 )
 
@@ -608,6 +609,202 @@ func (suite *BackendTestSuite) TestGetTransactionReceipt() {
 	}
 }
 
+func (suite *BackendTestSuite) TestGetBlockReceipts() {
+	msgEthereumTx, _ := suite.buildEthereumTx()
+	txHash := msgEthereumTx.AsTransaction().Hash()
+
+	txBz := suite.signAndEncodeEthTx(msgEthereumTx)
+	block := &types.Block{Header: types.Header{Height: 1}, Data: types.Data{Txs: []types.Tx{txBz}}}
+	blockResult := &abci.ResponseFinalizeBlock{
+		TxResults: []*abci.ExecTxResult{
+			{
+				Code: 0,
+				Events: []abci.Event{
+					{Type: evmtypes.EventTypeEthereumTx, Attributes: []abci.EventAttribute{
+						{Key: "ethereumTxHash", Value: txHash.Hex()},
+						{Key: "txIndex", Value: "0"},
+						{Key: "amount", Value: "1000"},
+						{Key: "txGasUsed", Value: "21000"},
+						{Key: "txHash", Value: ""},
+						{Key: "recipient", Value: "0x775b87ef5D82ca211811C1a02CE0fE0CA3a455d7"},
+					}},
+				},
+			},
+		},
+	}
+	blockNum := rpctypes.BlockNumber(1)
+	// the receipts are built from the block results, not from the indexer
+	registerReceiptsMocks := func() {
+		var header metadata.MD
+		queryClient := suite.backend.queryClient.QueryClient.(*mocks.EVMQueryClient)
+		client := suite.backend.clientCtx.Client.(*mocks.Client)
+		RegisterParams(queryClient, &header, 1)
+		RegisterParamsWithoutHeader(queryClient, 1)
+		RegisterBaseFee(queryClient, sdkmath.NewInt(1))
+		RegisterBlock(client, 1, txBz)
+		client.On("BlockResults", rpctypes.ContextWithHeight(1), mock.AnythingOfType("*int64")).
+			Return(&tmrpctypes.ResultBlockResults{Height: 1, TxsResults: blockResult.TxResults}, nil)
+	}
+
+	testCases := []struct {
+		name         string
+		registerMock func()
+		indexed      bool
+		expReceipts  int
+		expPass      bool
+	}{
+		{
+			"pass - block not found",
+			func() {
+				client := suite.backend.clientCtx.Client.(*mocks.Client)
+				RegisterBlockError(client, 1)
+			},
+			true,
+			0,
+			true,
+		},
+		{
+			"pass - block results not found",
+			func() {
+				client := suite.backend.clientCtx.Client.(*mocks.Client)
+				RegisterBlock(client, 1, txBz)
+				RegisterBlockResultsError(client, 1)
+			},
+			true,
+			0,
+			true,
+		},
+		{
+			"pass - receipts of the block not indexed yet",
+			registerReceiptsMocks,
+			false,
+			1,
+			true,
+		},
+		{
+			"pass - receipts of the block",
+			registerReceiptsMocks,
+			true,
+			1,
+			true,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.SetupTest() // reset
+			tc.registerMock()
+
+			suite.backend.indexer = indexer.NewKVIndexer(dbm.NewMemDB(), log.NewNopLogger(), suite.backend.clientCtx)
+			if tc.indexed {
+				err := suite.backend.indexer.IndexBlock(block, blockResult)
+				suite.Require().NoError(err)
+			}
+
+			receipts, err := suite.backend.GetBlockReceipts(rpctypes.BlockNumberOrHash{BlockNumber: &blockNum})
+			if !tc.expPass {
+				suite.Require().Error(err)
+				return
+			}
+			suite.Require().NoError(err)
+			suite.Require().Len(receipts, tc.expReceipts)
+
+			if !tc.indexed {
+				return
+			}
+			// the receipts match the ones returned by hash
+			for _, receipt := range receipts {
+				txReceipt, err := suite.backend.GetTransactionReceipt(receipt["transactionHash"].(common.Hash))
+				suite.Require().NoError(err)
+				suite.Require().Equal(txReceipt, receipt)
+				suite.Require().Equal(hexutil.Uint64(0), receipt["transactionIndex"])
+			}
+		})
+	}
+}
+
+func (suite *BackendTestSuite) TestReceiptsFromBlockResults() {
+	msgEthereumTx, _ := suite.buildEthereumTx()
+	txBz := suite.signAndEncodeEthTx(msgEthereumTx)
+	txHash := msgEthereumTx.AsTransaction().Hash()
+	resBlock := &tmrpctypes.ResultBlock{Block: &types.Block{Header: types.Header{Height: 1}, Data: types.Data{Txs: []types.Tx{[]byte("cosmos tx"), txBz}}}}
+	ethTxResult := &abci.ExecTxResult{
+		Code:    0,
+		GasUsed: 21000,
+		Events: []abci.Event{
+			{Type: evmtypes.EventTypeEthereumTx, Attributes: []abci.EventAttribute{
+				{Key: "ethereumTxHash", Value: txHash.Hex()},
+				{Key: "txIndex", Value: "0"},
+				{Key: "amount", Value: "1000"},
+				{Key: "txGasUsed", Value: "21000"},
+				{Key: "txHash", Value: ""},
+				{Key: "recipient", Value: "0x775b87ef5D82ca211811C1a02CE0fE0CA3a455d7"},
+			}},
+		},
+	}
+
+	testCases := []struct {
+		name        string
+		txResults   []*abci.ExecTxResult
+		expReceipts int
+		expPass     bool
+	}{
+		{
+			"pass - failed cosmos tx is skipped",
+			[]*abci.ExecTxResult{{Code: 1, GasUsed: 1000}, {Code: 1, GasUsed: 1000}},
+			0,
+			true,
+		},
+		{
+			"pass - receipts of the block",
+			[]*abci.ExecTxResult{{Code: 1, GasUsed: 1000}, ethTxResult},
+			1,
+			true,
+		},
+		{
+			"pass - tx with invalid events is skipped",
+			[]*abci.ExecTxResult{
+				{Code: 1, GasUsed: 1000},
+				{Events: []abci.Event{{Type: evmtypes.EventTypeEthereumTx, Attributes: []abci.EventAttribute{
+					{Key: "ethereumTxHash", Value: txHash.Hex()},
+					{Key: "txIndex", Value: "0x1"},
+				}}}},
+			},
+			0,
+			true,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.SetupTest() // reset
+			var header metadata.MD
+			queryClient := suite.backend.queryClient.QueryClient.(*mocks.EVMQueryClient)
+			RegisterParams(queryClient, &header, 1)
+			RegisterParamsWithoutHeader(queryClient, 1)
+			RegisterBaseFee(queryClient, sdkmath.NewInt(1))
+
+			blockRes := &tmrpctypes.ResultBlockResults{Height: 1, TxsResults: tc.txResults}
+			receipts, err := suite.backend.ReceiptsFromBlockResults(resBlock, blockRes)
+			if !tc.expPass {
+				suite.Require().Error(err)
+				return
+			}
+			suite.Require().NoError(err)
+			suite.Require().Len(receipts, tc.expReceipts)
+
+			for _, receipt := range receipts {
+				suite.Require().Equal(txHash, receipt["transactionHash"])
+				suite.Require().Equal(hexutil.Uint(ethtypes.ReceiptStatusSuccessful), receipt["status"])
+				suite.Require().Equal(hexutil.Uint64(21000), receipt["gasUsed"])
+				// the gas used by the preceding cosmos tx is included
+				suite.Require().Equal(hexutil.Uint64(22000), receipt["cumulativeGasUsed"])
+				suite.Require().Equal(hexutil.Uint64(0), receipt["transactionIndex"])
+			}
+		})
+	}
+}
+
 func (suite *BackendTestSuite) TestCheckChainIdWithTransactionReceipt() {
 
 	patchedHeight := int64(10848200)
@@ -649,7 +846,7 @@ func (suite *BackendTestSuite) TestCheckChainIdWithTransactionReceipt() {
 				suite.backend.clientCtx.WithChainID("canto_7700-1").WithHeight(patchedHeight)
 
 				idxer := indexer.NewKVIndexer(dbm.NewMemDB(), ctx.Logger, suite.backend.clientCtx)
-				suite.backend = NewBackend(ctx, ctx.Logger, suite.backend.clientCtx, true, idxer)
+				suite.backend = NewBackend(ctx, ctx.Logger, suite.backend.clientCtx, true, idxer, suite.backend.syncTracker)
 				suite.backend.queryClient.QueryClient = mocks.NewEVMQueryClient(suite.T())
 				suite.backend.clientCtx.Client = mocks.NewClient(suite.T())
 				suite.backend.queryClient.FeeMarket = mocks.NewFeeMarketQueryClient(suite.T())
@@ -696,7 +893,7 @@ func (suite *BackendTestSuite) TestCheckChainIdWithTransactionReceipt() {
 				suite.backend.clientCtx = suite.backend.clientCtx.WithChainID("canto_7700-1").WithHeight(notPatchedHeight)
 
 				idxer := indexer.NewKVIndexer(dbm.NewMemDB(), ctx.Logger, suite.backend.clientCtx)
-				suite.backend = NewBackend(ctx, ctx.Logger, suite.backend.clientCtx, true, idxer)
+				suite.backend = NewBackend(ctx, ctx.Logger, suite.backend.clientCtx, true, idxer, suite.backend.syncTracker)
 				suite.backend.queryClient.QueryClient = mocks.NewEVMQueryClient(suite.T())
 				suite.backend.clientCtx.Client = mocks.NewClient(suite.T())
 				suite.backend.queryClient.FeeMarket = mocks.NewFeeMarketQueryClient(suite.T())
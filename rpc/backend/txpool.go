@@ -0,0 +1,172 @@
+// Copyright 2021 Evmos Foundation
+// This file is part of Evmos' Ethermint library.
+//
+// The Ethermint library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Ethermint library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Ethermint library. If not, see https://github.com/evmos/ethermint/blob/main/LICENSE
+package backend
+
+import (
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"golang.org/x/sync/errgroup"
+
+	evmtypes "github.com/evmos/ethermint/x/evm/types"
+)
+
+// txPoolQueryWorkers bounds the concurrent account queries of the txpool content.
+const txPoolQueryWorkers = 8
+
+// TxPoolContent returns the ethereum transactions of the mempool grouped by sender
+// and sorted by nonce. The transactions which are executable on top of the current
+// account nonce are returned as pending, the ones behind a nonce gap as queued.
+func (b *Backend) TxPoolContent() (
+	pending map[common.Address][]*ethtypes.Transaction,
+	queued map[common.Address][]*ethtypes.Transaction,
+	err error,
+) {
+	txsBySender, err := b.pendingEthTxsBySender()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	senders := make([]common.Address, 0, len(txsBySender))
+	for sender := range txsBySender {
+		senders = append(senders, sender)
+	}
+	nonces, err := b.accountNonces(senders)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pending = make(map[common.Address][]*ethtypes.Transaction)
+	queued = make(map[common.Address][]*ethtypes.Transaction)
+	for sender, txs := range txsBySender {
+		senderPending, senderQueued := splitTxPoolTxs(txs, nonces[sender])
+		if len(senderPending) > 0 {
+			pending[sender] = senderPending
+		}
+		if len(senderQueued) > 0 {
+			queued[sender] = senderQueued
+		}
+	}
+	return pending, queued, nil
+}
+
+// TxPoolContentFrom returns the pending and queued ethereum transactions of the
+// mempool sent by the given address.
+func (b *Backend) TxPoolContentFrom(address common.Address) (
+	pending []*ethtypes.Transaction,
+	queued []*ethtypes.Transaction,
+	err error,
+) {
+	txsBySender, err := b.pendingEthTxsBySender()
+	if err != nil {
+		return nil, nil, err
+	}
+	txs, ok := txsBySender[address]
+	if !ok {
+		return nil, nil, nil
+	}
+	nonces, err := b.accountNonces([]common.Address{address})
+	if err != nil {
+		return nil, nil, err
+	}
+	pending, queued = splitTxPoolTxs(txs, nonces[address])
+	return pending, queued, nil
+}
+
+// pendingEthTxsBySender decodes the mempool transactions and groups the
+// `MsgEthereumTx` messages by their sender.
+func (b *Backend) pendingEthTxsBySender() (map[common.Address][]*ethtypes.Transaction, error) {
+	txs, err := b.PendingTransactions()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[common.Address][]*ethtypes.Transaction)
+	for _, tx := range txs {
+		for _, msg := range (*tx).GetMsgs() {
+			ethMsg, ok := msg.(*evmtypes.MsgEthereumTx)
+			if !ok {
+				// not valid ethereum tx
+				break
+			}
+
+			sender, err := ethMsg.GetSender(b.chainID)
+			if err != nil {
+				b.logger.Debug("failed to recover sender of pending tx", "hash", ethMsg.Hash, "error", err.Error())
+				continue
+			}
+			result[sender] = append(result[sender], ethMsg.AsTransaction())
+		}
+	}
+	return result, nil
+}
+
+// accountNonces queries the nonces of the given accounts, at most txPoolQueryWorkers
+// queries are run concurrently.
+func (b *Backend) accountNonces(addresses []common.Address) (map[common.Address]uint64, error) {
+	nonces := make([]uint64, len(addresses))
+	var g errgroup.Group
+	g.SetLimit(txPoolQueryWorkers)
+	for i, address := range addresses {
+		i, address := i, address
+		g.Go(func() error {
+			res, err := b.queryClient.Account(b.ctx, &evmtypes.QueryAccountRequest{Address: address.Hex()})
+			if err != nil {
+				return err
+			}
+			nonces[i] = res.Nonce
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	result := make(map[common.Address]uint64, len(addresses))
+	for i, address := range addresses {
+		result[address] = nonces[i]
+	}
+	return result, nil
+}
+
+// splitTxPoolTxs sorts the transactions of a sender by nonce and splits them into
+// the pending ones, which form a gapless sequence starting at the account nonce,
+// and the queued ones. Transactions with a nonce lower than the account nonce are
+// already included in a block and are dropped.
+func splitTxPoolTxs(txs []*ethtypes.Transaction, nonce uint64) (
+	pending []*ethtypes.Transaction,
+	queued []*ethtypes.Transaction,
+) {
+	sort.SliceStable(txs, func(i, j int) bool {
+		return txs[i].Nonce() < txs[j].Nonce()
+	})
+
+	next := nonce
+	for _, tx := range txs {
+		switch {
+		case tx.Nonce() < next:
+			// already executed, or a duplicated nonce
+			continue
+		case tx.Nonce() == next:
+			pending = append(pending, tx)
+			next++
+		default:
+			queued = append(queued, tx)
+		}
+	}
+	return pending, queued
+}
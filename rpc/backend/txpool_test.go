@@ -0,0 +1,138 @@
+package backend
+
+import (
+	"math/big"
+
+	"github.com/cometbft/cometbft/types"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	errortypes "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/evmos/ethermint/rpc/backend/mocks"
+	rpc "github.com/evmos/ethermint/rpc/types"
+	"github.com/evmos/ethermint/tests"
+	evmtypes "github.com/evmos/ethermint/x/evm/types"
+)
+
+func (suite *BackendTestSuite) TestTxPoolContent() {
+	from, priv := tests.NewAddrKey()
+	other, otherPriv := tests.NewAddrKey()
+
+	buildTxFrom := func(sender common.Address, signer keyring.Signer, nonce uint64) []byte {
+		msg := evmtypes.NewTx(suite.backend.chainID, nonce, &common.Address{}, big.NewInt(0), 100000, big.NewInt(1), nil, nil, nil, nil)
+		msg.From = sender.String()
+		suite.Require().NoError(msg.Sign(ethtypes.LatestSigner(suite.backend.ChainConfig()), signer))
+
+		tx, err := msg.BuildTx(suite.backend.clientCtx.TxConfig.NewTxBuilder(), "aphoton")
+		suite.Require().NoError(err)
+		bz, err := suite.backend.clientCtx.TxConfig.TxEncoder()(tx)
+		suite.Require().NoError(err)
+		return bz
+	}
+	buildTx := func(nonce uint64) []byte {
+		return buildTxFrom(from, tests.NewSigner(priv), nonce)
+	}
+
+	testCases := []struct {
+		name         string
+		registerMock func()
+		expPending   []uint64
+		expQueued    []uint64
+		expPass      bool
+	}{
+		{
+			"fail - pending transactions returns error",
+			func() {
+				client := suite.backend.clientCtx.Client.(*mocks.Client)
+				RegisterUnconfirmedTxsError(client, nil)
+			},
+			nil,
+			nil,
+			false,
+		},
+		{
+			"pass - empty mempool",
+			func() {
+				client := suite.backend.clientCtx.Client.(*mocks.Client)
+				RegisterUnconfirmedTxs(client, nil, nil)
+			},
+			nil,
+			nil,
+			true,
+		},
+		{
+			"pass - split pending and queued by account nonce",
+			func() {
+				queryClient := suite.backend.queryClient.QueryClient.(*mocks.EVMQueryClient)
+				RegisterParamsWithoutHeader(queryClient, 1)
+				txs := types.Txs{buildTx(3), buildTx(0), buildTx(2), buildTx(5)}
+				client := suite.backend.clientCtx.Client.(*mocks.Client)
+				RegisterUnconfirmedTxs(client, nil, txs)
+				RegisterAccountWithNonce(queryClient, from, 2)
+			},
+			[]uint64{2, 3},
+			[]uint64{5},
+			true,
+		},
+		{
+			"pass - split the txs of each sender by its own nonce",
+			func() {
+				queryClient := suite.backend.queryClient.QueryClient.(*mocks.EVMQueryClient)
+				RegisterParamsWithoutHeader(queryClient, 1)
+				otherSigner := tests.NewSigner(otherPriv)
+				txs := types.Txs{buildTx(1), buildTxFrom(other, otherSigner, 0), buildTx(0), buildTxFrom(other, otherSigner, 1)}
+				client := suite.backend.clientCtx.Client.(*mocks.Client)
+				RegisterUnconfirmedTxs(client, nil, txs)
+				RegisterAccountWithNonce(queryClient, from, 0)
+				RegisterAccountWithNonce(queryClient, other, 1)
+			},
+			[]uint64{0, 1},
+			nil,
+			true,
+		},
+		{
+			"fail - account query returns error",
+			func() {
+				queryClient := suite.backend.queryClient.QueryClient.(*mocks.EVMQueryClient)
+				RegisterParamsWithoutHeader(queryClient, 1)
+				client := suite.backend.clientCtx.Client.(*mocks.Client)
+				RegisterUnconfirmedTxs(client, nil, types.Txs{buildTx(0)})
+				queryClient.On("Account", rpc.ContextWithHeight(1), &evmtypes.QueryAccountRequest{Address: from.String()}).
+					Return(nil, errortypes.ErrInvalidRequest)
+			},
+			nil,
+			nil,
+			false,
+		},
+	}
+
+	nonces := func(txs []*ethtypes.Transaction) []uint64 {
+		var result []uint64
+		for _, tx := range txs {
+			result = append(result, tx.Nonce())
+		}
+		return result
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.SetupTest() // reset
+			tc.registerMock()
+
+			pending, queued, err := suite.backend.TxPoolContent()
+			if tc.expPass {
+				suite.Require().NoError(err)
+				suite.Require().Equal(tc.expPending, nonces(pending[from]))
+				suite.Require().Equal(tc.expQueued, nonces(queued[from]))
+
+				pendingFrom, queuedFrom, err := suite.backend.TxPoolContentFrom(from)
+				suite.Require().NoError(err)
+				suite.Require().Equal(tc.expPending, nonces(pendingFrom))
+				suite.Require().Equal(tc.expQueued, nonces(queuedFrom))
+			} else {
+				suite.Require().Error(err)
+			}
+		})
+	}
+}
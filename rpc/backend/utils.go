@@ -309,3 +309,12 @@ func GetHexProofs(proof *crypto.ProofOps) []string {
 	}
 	return proofs
 }
+
+// marshalStateOverrides encodes the state overrides to be passed to the gRPC query,
+// it returns nil if there's no override.
+func marshalStateOverrides(overrides *types.StateOverride) ([]byte, error) {
+	if overrides == nil {
+		return nil, nil
+	}
+	return json.Marshal(overrides)
+}
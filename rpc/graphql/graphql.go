@@ -0,0 +1,943 @@
+// Copyright 2021 Evmos Foundation
+// This file is part of Evmos' Ethermint library.
+//
+// The Ethermint library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Ethermint library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Ethermint library. If not, see https://github.com/evmos/ethermint/blob/main/LICENSE
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+
+	"cosmossdk.io/log"
+	tmrpctypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+
+	"github.com/evmos/ethermint/rpc/backend"
+	rpcfilters "github.com/evmos/ethermint/rpc/namespaces/ethereum/eth/filters"
+	rpctypes "github.com/evmos/ethermint/rpc/types"
+	evmtypes "github.com/evmos/ethermint/x/evm/types"
+)
+
+// Long is a 64 bit integer of the schema, the inputs are decimal or 0x-prefixed
+// hexadecimal strings or numbers.
+type Long int64
+
+// ImplementsGraphQLType returns true if Long implements the provided GraphQL type.
+func (b Long) ImplementsGraphQLType(name string) bool { return name == "Long" }
+
+// UnmarshalGraphQL unmarshals the provided GraphQL query data.
+func (b *Long) UnmarshalGraphQL(input interface{}) error {
+	switch input := input.(type) {
+	case string:
+		if strings.HasPrefix(input, "0x") {
+			value, err := hexutil.DecodeUint64(input)
+			*b = Long(value) //#nosec G115
+			return err
+		}
+		value, err := strconv.ParseInt(input, 10, 64)
+		*b = Long(value)
+		return err
+	case int32:
+		*b = Long(input)
+	case int64:
+		*b = Long(input)
+	case float64:
+		*b = Long(input)
+	default:
+		return fmt.Errorf("unexpected type %T for Long", input)
+	}
+	return nil
+}
+
+// blockNumberOrLatest returns the block number of an optional block argument.
+func blockNumberOrLatest(block *Long) rpctypes.BlockNumber {
+	if block == nil {
+		return rpctypes.EthLatestBlockNumber
+	}
+	return rpctypes.BlockNumber(*block)
+}
+
+// Resolver is the root resolver of the schema, the queries are served by the EVM backend.
+type Resolver struct {
+	backend backend.EVMBackend
+	logger  log.Logger
+}
+
+// Account is an account at a block.
+type Account struct {
+	r        *Resolver
+	address  common.Address
+	blockNum rpctypes.BlockNumber
+}
+
+func (a *Account) blockNrOrHash() rpctypes.BlockNumberOrHash {
+	blockNum := a.blockNum
+	return rpctypes.BlockNumberOrHash{BlockNumber: &blockNum}
+}
+
+func (a *Account) Address(_ context.Context) common.Address {
+	return a.address
+}
+
+func (a *Account) Balance(_ context.Context) (hexutil.Big, error) {
+	balance, err := a.r.backend.GetBalance(a.address, a.blockNrOrHash())
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return *balance, nil
+}
+
+func (a *Account) TransactionCount(_ context.Context) (hexutil.Uint64, error) {
+	nonce, err := a.r.backend.GetTransactionCount(a.address, a.blockNum)
+	if err != nil {
+		return 0, err
+	}
+	return *nonce, nil
+}
+
+func (a *Account) Code(_ context.Context) (hexutil.Bytes, error) {
+	return a.r.backend.GetCode(a.address, a.blockNrOrHash())
+}
+
+func (a *Account) Storage(_ context.Context, args struct{ Slot common.Hash }) (common.Hash, error) {
+	value, err := a.r.backend.GetStorageAt(a.address, args.Slot.Hex(), a.blockNrOrHash())
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(value), nil
+}
+
+// Log is a log emitted by a transaction.
+type Log struct {
+	r   *Resolver
+	tx  *Transaction
+	log *ethtypes.Log
+}
+
+func (l *Log) Index(_ context.Context) int32 {
+	return int32(l.log.Index) //#nosec G115
+}
+
+func (l *Log) Account(_ context.Context, args struct{ Block *Long }) *Account {
+	return &Account{r: l.r, address: l.log.Address, blockNum: blockNumberOrLatest(args.Block)}
+}
+
+func (l *Log) Topics(_ context.Context) []common.Hash {
+	return l.log.Topics
+}
+
+func (l *Log) Data(_ context.Context) hexutil.Bytes {
+	return l.log.Data
+}
+
+func (l *Log) Transaction(_ context.Context) *Transaction {
+	return l.tx
+}
+
+// AccessTuple is an entry of the access list of a transaction.
+type AccessTuple struct {
+	address     common.Address
+	storageKeys []common.Hash
+}
+
+func (at *AccessTuple) Address(_ context.Context) common.Address {
+	return at.address
+}
+
+func (at *AccessTuple) StorageKeys(_ context.Context) []common.Hash {
+	return at.storageKeys
+}
+
+// Transaction is a transaction, it's loaded by hash if only the hash is known, e.g.
+// for the logs. The receipt of the transactions of a block is the one of the block
+// receipts.
+type Transaction struct {
+	r    *Resolver
+	hash common.Hash
+
+	mu      sync.Mutex
+	tx      *rpctypes.RPCTransaction
+	block   *Block
+	receipt map[string]interface{}
+}
+
+// resolve loads the transaction, nil if not found.
+func (t *Transaction) resolve() (*rpctypes.RPCTransaction, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.tx != nil {
+		return t.tx, nil
+	}
+	tx, err := t.r.backend.GetTransactionByHash(t.hash)
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, errors.Errorf("transaction %s not found", t.hash.Hex())
+	}
+	t.tx = tx
+	return tx, nil
+}
+
+// getBlock returns the block of the transaction, nil if not mined.
+func (t *Transaction) getBlock() (*Block, error) {
+	tx, err := t.resolve()
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.block != nil || tx.BlockNumber == nil {
+		return t.block, nil
+	}
+	block, err := t.r.blockByNumber(rpctypes.BlockNumber(tx.BlockNumber.ToInt().Int64()))
+	if err != nil {
+		return nil, err
+	}
+	t.block = block
+	return block, nil
+}
+
+// getReceipt returns the receipt of the transaction, nil if not mined.
+func (t *Transaction) getReceipt() (map[string]interface{}, error) {
+	block, err := t.getBlock()
+	if err != nil || block == nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.receipt != nil {
+		return t.receipt, nil
+	}
+	receipts, err := block.getReceipts()
+	if err != nil {
+		return nil, err
+	}
+	t.receipt = receipts[t.hash]
+	return t.receipt, nil
+}
+
+func (t *Transaction) Hash(_ context.Context) common.Hash {
+	return t.hash
+}
+
+func (t *Transaction) Nonce(_ context.Context) (hexutil.Uint64, error) {
+	tx, err := t.resolve()
+	if err != nil {
+		return 0, err
+	}
+	return tx.Nonce, nil
+}
+
+func (t *Transaction) Index(_ context.Context) (*int32, error) {
+	tx, err := t.resolve()
+	if err != nil || tx.TransactionIndex == nil {
+		return nil, err
+	}
+	index := int32(*tx.TransactionIndex) //#nosec G115
+	return &index, nil
+}
+
+func (t *Transaction) From(_ context.Context, args struct{ Block *Long }) (*Account, error) {
+	tx, err := t.resolve()
+	if err != nil {
+		return nil, err
+	}
+	return &Account{r: t.r, address: tx.From, blockNum: blockNumberOrLatest(args.Block)}, nil
+}
+
+func (t *Transaction) To(_ context.Context, args struct{ Block *Long }) (*Account, error) {
+	tx, err := t.resolve()
+	if err != nil || tx.To == nil {
+		return nil, err
+	}
+	return &Account{r: t.r, address: *tx.To, blockNum: blockNumberOrLatest(args.Block)}, nil
+}
+
+func (t *Transaction) Value(_ context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve()
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return *tx.Value, nil
+}
+
+func (t *Transaction) GasPrice(_ context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve()
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return *tx.GasPrice, nil
+}
+
+func (t *Transaction) MaxFeePerGas(_ context.Context) (*hexutil.Big, error) {
+	tx, err := t.resolve()
+	if err != nil {
+		return nil, err
+	}
+	return tx.GasFeeCap, nil
+}
+
+func (t *Transaction) MaxPriorityFeePerGas(_ context.Context) (*hexutil.Big, error) {
+	tx, err := t.resolve()
+	if err != nil {
+		return nil, err
+	}
+	return tx.GasTipCap, nil
+}
+
+func (t *Transaction) EffectiveTip(_ context.Context) (*hexutil.Big, error) {
+	tx, err := t.resolve()
+	if err != nil {
+		return nil, err
+	}
+	block, err := t.getBlock()
+	if err != nil || block == nil {
+		return nil, err
+	}
+	baseFee, ok := block.fields["baseFeePerGas"].(*hexutil.Big)
+	if !ok {
+		// no base fee, the miner gets the gas price
+		return tx.GasPrice, nil
+	}
+	// the gas price of the mined dynamic fee txs is the effective gas price
+	tip := new(big.Int).Sub(tx.GasPrice.ToInt(), baseFee.ToInt())
+	return (*hexutil.Big)(tip), nil
+}
+
+func (t *Transaction) Gas(_ context.Context) (hexutil.Uint64, error) {
+	tx, err := t.resolve()
+	if err != nil {
+		return 0, err
+	}
+	return tx.Gas, nil
+}
+
+func (t *Transaction) InputData(_ context.Context) (hexutil.Bytes, error) {
+	tx, err := t.resolve()
+	if err != nil {
+		return nil, err
+	}
+	return tx.Input, nil
+}
+
+func (t *Transaction) Block(_ context.Context) (*Block, error) {
+	return t.getBlock()
+}
+
+func (t *Transaction) Status(_ context.Context) (*hexutil.Uint64, error) {
+	receipt, err := t.getReceipt()
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	status := hexutil.Uint64(receipt["status"].(hexutil.Uint))
+	return &status, nil
+}
+
+func (t *Transaction) GasUsed(_ context.Context) (*hexutil.Uint64, error) {
+	receipt, err := t.getReceipt()
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	gasUsed := receipt["gasUsed"].(hexutil.Uint64)
+	return &gasUsed, nil
+}
+
+func (t *Transaction) CumulativeGasUsed(_ context.Context) (*hexutil.Uint64, error) {
+	receipt, err := t.getReceipt()
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	gasUsed := receipt["cumulativeGasUsed"].(hexutil.Uint64)
+	return &gasUsed, nil
+}
+
+func (t *Transaction) EffectiveGasPrice(_ context.Context) (*hexutil.Big, error) {
+	receipt, err := t.getReceipt()
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	if price, ok := receipt["effectiveGasPrice"].(hexutil.Big); ok {
+		return &price, nil
+	}
+	tx, err := t.resolve()
+	if err != nil {
+		return nil, err
+	}
+	return tx.GasPrice, nil
+}
+
+func (t *Transaction) CreatedContract(_ context.Context, args struct{ Block *Long }) (*Account, error) {
+	receipt, err := t.getReceipt()
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	address, ok := receipt["contractAddress"].(common.Address)
+	if !ok {
+		return nil, nil
+	}
+	return &Account{r: t.r, address: address, blockNum: blockNumberOrLatest(args.Block)}, nil
+}
+
+func (t *Transaction) Logs(_ context.Context) (*[]*Log, error) {
+	receipt, err := t.getReceipt()
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	// the receipt logs are empty lists of logs without logs
+	ethLogs, _ := receipt["logs"].([]*ethtypes.Log)
+	logs := make([]*Log, len(ethLogs))
+	for i, ethLog := range ethLogs {
+		logs[i] = &Log{r: t.r, tx: t, log: ethLog}
+	}
+	return &logs, nil
+}
+
+func (t *Transaction) R(_ context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve()
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return *tx.R, nil
+}
+
+func (t *Transaction) S(_ context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve()
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return *tx.S, nil
+}
+
+func (t *Transaction) V(_ context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve()
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return *tx.V, nil
+}
+
+func (t *Transaction) Type(_ context.Context) (*int32, error) {
+	tx, err := t.resolve()
+	if err != nil {
+		return nil, err
+	}
+	txType := int32(tx.Type) //#nosec G115
+	return &txType, nil
+}
+
+func (t *Transaction) AccessList(_ context.Context) (*[]*AccessTuple, error) {
+	tx, err := t.resolve()
+	if err != nil || tx.Accesses == nil {
+		return nil, err
+	}
+	accessList := make([]*AccessTuple, len(*tx.Accesses))
+	for i, tuple := range *tx.Accesses {
+		accessList[i] = &AccessTuple{address: tuple.Address, storageKeys: tuple.StorageKeys}
+	}
+	return &accessList, nil
+}
+
+// Block is a block with its results. The block and its results are loaded when a field
+// other than its number is resolved, the receipts on demand, so the blocks of a range
+// cost only the fields queried.
+type Block struct {
+	r      *Resolver
+	number int64
+
+	mu       sync.Mutex
+	resBlock *tmrpctypes.ResultBlock
+	blockRes *tmrpctypes.ResultBlockResults
+	fields   map[string]interface{}
+	txs      []*Transaction
+	receipts map[common.Hash]map[string]interface{}
+}
+
+// newBlock returns the block of a tendermint block, nil if not found.
+func (r *Resolver) newBlock(resBlock *tmrpctypes.ResultBlock) *Block {
+	if resBlock == nil || resBlock.Block == nil {
+		return nil
+	}
+	return &Block{r: r, number: resBlock.Block.Height, resBlock: resBlock}
+}
+
+// blockByNumber returns the block of a number, nil if not found.
+func (r *Resolver) blockByNumber(blockNum rpctypes.BlockNumber) (*Block, error) {
+	resBlock, err := r.backend.TendermintBlockByNumber(blockNum)
+	if err != nil {
+		return nil, err
+	}
+	return r.newBlock(resBlock), nil
+}
+
+// resolve loads the block and its results.
+func (b *Block) resolve() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.fields != nil {
+		return nil
+	}
+	if b.resBlock == nil {
+		resBlock, err := b.r.backend.TendermintBlockByNumber(rpctypes.BlockNumber(b.number))
+		if err != nil {
+			return err
+		}
+		if resBlock == nil || resBlock.Block == nil {
+			return errors.Errorf("block %d not found", b.number)
+		}
+		b.resBlock = resBlock
+	}
+	blockRes, err := b.r.backend.TendermintBlockResultByNumber(&b.number)
+	if err != nil {
+		return errors.Wrapf(err, "block result not found for height %d", b.number)
+	}
+	fields, err := b.r.backend.RPCBlockFromTendermintBlock(b.resBlock, blockRes, true)
+	if err != nil {
+		return err
+	}
+
+	rpcTxs, _ := fields["transactions"].([]interface{})
+	for _, rpcTx := range rpcTxs {
+		tx, ok := rpcTx.(*rpctypes.RPCTransaction)
+		if !ok {
+			continue
+		}
+		b.txs = append(b.txs, &Transaction{r: b.r, hash: tx.Hash, tx: tx, block: b})
+	}
+	b.blockRes = blockRes
+	b.fields = fields
+	return nil
+}
+
+// getReceipts returns the receipts of the block transactions by hash.
+func (b *Block) getReceipts() (map[common.Hash]map[string]interface{}, error) {
+	if err := b.resolve(); err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.receipts != nil {
+		return b.receipts, nil
+	}
+	receipts, err := b.r.backend.ReceiptsFromBlockResults(b.resBlock, b.blockRes)
+	if err != nil {
+		return nil, err
+	}
+	b.receipts = make(map[common.Hash]map[string]interface{}, len(receipts))
+	for _, receipt := range receipts {
+		b.receipts[receipt["transactionHash"].(common.Hash)] = receipt
+	}
+	return b.receipts, nil
+}
+
+// field returns a field of the RPC block.
+func (b *Block) field(name string) (interface{}, error) {
+	if err := b.resolve(); err != nil {
+		return nil, err
+	}
+	return b.fields[name], nil
+}
+
+func (b *Block) Number(_ context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(b.number) //#nosec G115
+}
+
+func (b *Block) Hash(_ context.Context) (common.Hash, error) {
+	if err := b.resolve(); err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(b.resBlock.Block.Hash()), nil
+}
+
+func (b *Block) Parent(_ context.Context) (*Block, error) {
+	if b.number <= 1 {
+		return nil, nil
+	}
+	return b.r.blockByNumber(rpctypes.BlockNumber(b.number - 1))
+}
+
+func (b *Block) Nonce(_ context.Context) hexutil.Bytes {
+	nonce := ethtypes.BlockNonce{}
+	return nonce[:]
+}
+
+func (b *Block) TransactionsRoot(_ context.Context) (common.Hash, error) {
+	root, err := b.field("transactionsRoot")
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return root.(common.Hash), nil
+}
+
+func (b *Block) TransactionCount(_ context.Context) (*int32, error) {
+	if err := b.resolve(); err != nil {
+		return nil, err
+	}
+	count := int32(len(b.txs)) //#nosec G115
+	return &count, nil
+}
+
+func (b *Block) StateRoot(_ context.Context) (common.Hash, error) {
+	if err := b.resolve(); err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(b.resBlock.Block.AppHash), nil
+}
+
+func (b *Block) ReceiptsRoot(_ context.Context) (common.Hash, error) {
+	root, err := b.field("receiptsRoot")
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return root.(common.Hash), nil
+}
+
+func (b *Block) Miner(_ context.Context, args struct{ Block *Long }) (*Account, error) {
+	miner, err := b.field("miner")
+	if err != nil {
+		return nil, err
+	}
+	return &Account{r: b.r, address: miner.(common.Address), blockNum: blockNumberOrLatest(args.Block)}, nil
+}
+
+func (b *Block) ExtraData(_ context.Context) hexutil.Bytes {
+	return hexutil.Bytes{}
+}
+
+func (b *Block) GasLimit(_ context.Context) (hexutil.Uint64, error) {
+	gasLimit, err := b.field("gasLimit")
+	if err != nil {
+		return 0, err
+	}
+	return gasLimit.(hexutil.Uint64), nil
+}
+
+func (b *Block) GasUsed(_ context.Context) (hexutil.Uint64, error) {
+	gasUsed, err := b.field("gasUsed")
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(gasUsed.(*hexutil.Big).ToInt().Uint64()), nil
+}
+
+func (b *Block) BaseFeePerGas(_ context.Context) (*hexutil.Big, error) {
+	field, err := b.field("baseFeePerGas")
+	if err != nil {
+		return nil, err
+	}
+	baseFee, _ := field.(*hexutil.Big)
+	return baseFee, nil
+}
+
+func (b *Block) Timestamp(_ context.Context) (hexutil.Uint64, error) {
+	timestamp, err := b.field("timestamp")
+	if err != nil {
+		return 0, err
+	}
+	return timestamp.(hexutil.Uint64), nil
+}
+
+func (b *Block) LogsBloom(_ context.Context) (hexutil.Bytes, error) {
+	bloom, err := b.field("logsBloom")
+	if err != nil {
+		return nil, err
+	}
+	return bloom.(ethtypes.Bloom).Bytes(), nil
+}
+
+func (b *Block) MixHash(_ context.Context) common.Hash {
+	return common.Hash{}
+}
+
+func (b *Block) Difficulty(_ context.Context) hexutil.Big {
+	return hexutil.Big{}
+}
+
+func (b *Block) TotalDifficulty(_ context.Context) hexutil.Big {
+	return hexutil.Big{}
+}
+
+func (b *Block) OmmerCount(_ context.Context) *int32 {
+	count := int32(0)
+	return &count
+}
+
+func (b *Block) Ommers(_ context.Context) *[]*Block {
+	return &[]*Block{}
+}
+
+func (b *Block) OmmerAt(_ context.Context, _ struct{ Index int32 }) *Block {
+	return nil
+}
+
+func (b *Block) OmmerHash(_ context.Context) common.Hash {
+	return ethtypes.EmptyUncleHash
+}
+
+func (b *Block) Transactions(_ context.Context) (*[]*Transaction, error) {
+	if err := b.resolve(); err != nil {
+		return nil, err
+	}
+	txs := b.txs
+	if txs == nil {
+		txs = []*Transaction{}
+	}
+	return &txs, nil
+}
+
+func (b *Block) TransactionAt(_ context.Context, args struct{ Index int32 }) (*Transaction, error) {
+	if err := b.resolve(); err != nil {
+		return nil, err
+	}
+	if args.Index < 0 || int(args.Index) >= len(b.txs) {
+		return nil, nil
+	}
+	return b.txs[args.Index], nil
+}
+
+// BlockFilterCriteria is the logs filter of a block.
+type BlockFilterCriteria struct {
+	Addresses *[]common.Address
+	Topics    *[][]common.Hash
+}
+
+func (b *Block) Logs(_ context.Context, args struct{ Filter BlockFilterCriteria }) ([]*Log, error) {
+	receipts, err := b.getReceipts()
+	if err != nil {
+		return nil, err
+	}
+
+	var addresses []common.Address
+	if args.Filter.Addresses != nil {
+		addresses = *args.Filter.Addresses
+	}
+	var topics [][]common.Hash
+	if args.Filter.Topics != nil {
+		topics = *args.Filter.Topics
+	}
+
+	logs := []*Log{}
+	for _, tx := range b.txs {
+		ethLogs, _ := receipts[tx.hash]["logs"].([]*ethtypes.Log)
+		for _, ethLog := range rpcfilters.FilterLogs(ethLogs, nil, nil, addresses, topics) {
+			logs = append(logs, &Log{r: b.r, tx: tx, log: ethLog})
+		}
+	}
+	return logs, nil
+}
+
+func (b *Block) Account(_ context.Context, args struct{ Address common.Address }) *Account {
+	return &Account{r: b.r, address: args.Address, blockNum: rpctypes.BlockNumber(b.number)}
+}
+
+// CallData is the message of a call.
+type CallData struct {
+	From                 *common.Address
+	To                   *common.Address
+	Gas                  *Long
+	GasPrice             *hexutil.Big
+	MaxFeePerGas         *hexutil.Big
+	MaxPriorityFeePerGas *hexutil.Big
+	Value                *hexutil.Big
+	Data                 *hexutil.Bytes
+}
+
+// txArgs returns the transaction args of the call.
+func (data CallData) txArgs() evmtypes.TransactionArgs {
+	args := evmtypes.TransactionArgs{
+		From:                 data.From,
+		To:                   data.To,
+		GasPrice:             data.GasPrice,
+		MaxFeePerGas:         data.MaxFeePerGas,
+		MaxPriorityFeePerGas: data.MaxPriorityFeePerGas,
+		Value:                data.Value,
+		Data:                 data.Data,
+	}
+	if data.Gas != nil {
+		gas := hexutil.Uint64(*data.Gas) //#nosec G115
+		args.Gas = &gas
+	}
+	return args
+}
+
+// CallResult is the result of a call.
+type CallResult struct {
+	data    hexutil.Bytes
+	gasUsed hexutil.Uint64
+	status  hexutil.Uint64
+}
+
+func (c *CallResult) Data(_ context.Context) hexutil.Bytes {
+	return c.data
+}
+
+func (c *CallResult) GasUsed(_ context.Context) hexutil.Uint64 {
+	return c.gasUsed
+}
+
+func (c *CallResult) Status(_ context.Context) hexutil.Uint64 {
+	return c.status
+}
+
+func (b *Block) Call(_ context.Context, args struct{ Data CallData }) (*CallResult, error) {
+	res, err := b.r.backend.DoCall(args.Data.txArgs(), rpctypes.BlockNumber(b.number), nil)
+	if err != nil {
+		return nil, err
+	}
+	status := hexutil.Uint64(ethtypes.ReceiptStatusSuccessful)
+	if res.Failed() {
+		status = hexutil.Uint64(ethtypes.ReceiptStatusFailed)
+	}
+	return &CallResult{data: res.Ret, gasUsed: hexutil.Uint64(res.GasUsed), status: status}, nil
+}
+
+func (b *Block) EstimateGas(_ context.Context, args struct{ Data CallData }) (hexutil.Uint64, error) {
+	blockNum := rpctypes.BlockNumber(b.number)
+	return b.r.backend.EstimateGas(args.Data.txArgs(), &blockNum, nil)
+}
+
+func (r *Resolver) Block(_ context.Context, args struct {
+	Number *Long
+	Hash   *common.Hash
+}) (*Block, error) {
+	if args.Hash != nil {
+		resBlock, err := r.backend.TendermintBlockByHash(*args.Hash)
+		if err != nil {
+			return nil, err
+		}
+		return r.newBlock(resBlock), nil
+	}
+	return r.blockByNumber(blockNumberOrLatest(args.Number))
+}
+
+func (r *Resolver) Blocks(_ context.Context, args struct {
+	From *Long
+	To   *Long
+}) ([]*Block, error) {
+	head, err := r.backend.BlockNumber()
+	if err != nil {
+		return nil, err
+	}
+	from := int64(1)
+	if args.From != nil {
+		from = max(int64(*args.From), 1)
+	}
+	to := int64(head) //#nosec G115
+	if args.To != nil {
+		to = min(int64(*args.To), to)
+	}
+	if rangeCap := r.blockRangeCap(); to-from > rangeCap {
+		return nil, fmt.Errorf("maximum [from, to] blocks distance: %d", rangeCap)
+	}
+
+	// the blocks are loaded when their fields are resolved
+	blocks := []*Block{}
+	for height := from; height <= to; height++ {
+		blocks = append(blocks, &Block{r: r, number: height})
+	}
+	return blocks, nil
+}
+
+// blockRangeCap returns the max distance of the block ranges of the queries, the
+// GraphQL queries are charged as a single request whatever the number of blocks.
+func (r *Resolver) blockRangeCap() int64 {
+	return min(int64(r.backend.RPCBlockRangeCap()), maxBlockRange)
+}
+
+func (r *Resolver) Transaction(_ context.Context, args struct{ Hash common.Hash }) (*Transaction, error) {
+	tx, err := r.backend.GetTransactionByHash(args.Hash)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	return &Transaction{r: r, hash: tx.Hash, tx: tx}, nil
+}
+
+// FilterCriteria is the logs filter of a block range.
+type FilterCriteria struct {
+	FromBlock *Long
+	ToBlock   *Long
+	Addresses *[]common.Address
+	Topics    *[][]common.Hash
+}
+
+func (r *Resolver) Logs(ctx context.Context, args struct{ Filter FilterCriteria }) ([]*Log, error) {
+	var addresses []common.Address
+	if args.Filter.Addresses != nil {
+		addresses = *args.Filter.Addresses
+	}
+	var topics [][]common.Hash
+	if args.Filter.Topics != nil {
+		topics = *args.Filter.Topics
+	}
+	begin := int64(blockNumberOrLatest(args.Filter.FromBlock))
+	end := int64(blockNumberOrLatest(args.Filter.ToBlock))
+
+	filter := rpcfilters.NewRangeFilter(r.logger, r.backend, begin, end, addresses, topics)
+	ethLogs, err := filter.Logs(ctx, int(r.backend.RPCLogsCap()), r.blockRangeCap())
+	if err != nil {
+		return nil, err
+	}
+
+	// the logs of a transaction share its resolver
+	txs := make(map[common.Hash]*Transaction)
+	logs := make([]*Log, len(ethLogs))
+	for i, ethLog := range ethLogs {
+		tx, ok := txs[ethLog.TxHash]
+		if !ok {
+			tx = &Transaction{r: r, hash: ethLog.TxHash}
+			txs[ethLog.TxHash] = tx
+		}
+		logs[i] = &Log{r: r, tx: tx, log: ethLog}
+	}
+	return logs, nil
+}
+
+func (r *Resolver) GasPrice(_ context.Context) (hexutil.Big, error) {
+	price, err := r.backend.GasPrice()
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return *price, nil
+}
+
+func (r *Resolver) MaxPriorityFeePerGas(_ context.Context) (hexutil.Big, error) {
+	head := r.backend.CurrentHeader()
+	if head == nil {
+		return hexutil.Big{}, errors.New("latest header not found")
+	}
+	tipCap, err := r.backend.SuggestGasTipCap(head.BaseFee)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*tipCap), nil
+}
+
+func (r *Resolver) ChainID(_ context.Context) (hexutil.Big, error) {
+	chainID, err := r.backend.ChainID()
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return *chainID, nil
+}
+
+func (r *Resolver) SendRawTransaction(_ context.Context, args struct{ Data hexutil.Bytes }) (common.Hash, error) {
+	return r.backend.SendRawTransaction(args.Data)
+}
@@ -0,0 +1,226 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"cosmossdk.io/log"
+	tmrpctypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/evmos/ethermint/rpc/backend"
+	rpctypes "github.com/evmos/ethermint/rpc/types"
+)
+
+// mockBackend serves a chain of 10 blocks, the block 5 has a transaction with two logs.
+type mockBackend struct {
+	backend.EVMBackend
+}
+
+var (
+	txHash     = common.HexToHash("0x01")
+	logAddress = common.HexToAddress("0x02")
+)
+
+func (mockBackend) BlockNumber() (hexutil.Uint64, error) { return 10, nil }
+
+func (mockBackend) RPCBlockRangeCap() int32 { return 5 }
+
+func (mockBackend) ChainID() (*hexutil.Big, error) { return (*hexutil.Big)(big.NewInt(9000)), nil }
+
+func (mockBackend) GasPrice() (*hexutil.Big, error) { return (*hexutil.Big)(big.NewInt(100)), nil }
+
+func (mockBackend) TendermintBlockByNumber(blockNum rpctypes.BlockNumber) (*tmrpctypes.ResultBlock, error) {
+	if blockNum.Int64() > 10 {
+		return nil, nil
+	}
+	return &tmrpctypes.ResultBlock{Block: &tmtypes.Block{Header: tmtypes.Header{Height: blockNum.Int64()}}}, nil
+}
+
+func (mockBackend) TendermintBlockResultByNumber(height *int64) (*tmrpctypes.ResultBlockResults, error) {
+	return &tmrpctypes.ResultBlockResults{Height: *height}, nil
+}
+
+func (mockBackend) RPCBlockFromTendermintBlock(resBlock *tmrpctypes.ResultBlock, _ *tmrpctypes.ResultBlockResults, _ bool) (map[string]interface{}, error) {
+	txs := []interface{}{}
+	if resBlock.Block.Height == 5 {
+		txs = append(txs, &rpctypes.RPCTransaction{Hash: txHash, BlockNumber: (*hexutil.Big)(big.NewInt(5))})
+	}
+	return map[string]interface{}{"transactions": txs}, nil
+}
+
+func (mockBackend) ReceiptsFromBlockResults(resBlock *tmrpctypes.ResultBlock, _ *tmrpctypes.ResultBlockResults) ([]map[string]interface{}, error) {
+	if resBlock.Block.Height != 5 {
+		return nil, nil
+	}
+	logs := []*ethtypes.Log{
+		{Address: logAddress, Data: []byte{1}, TxHash: txHash},
+		{Address: common.HexToAddress("0x03"), Data: []byte{2}, TxHash: txHash},
+	}
+	return []map[string]interface{}{{"transactionHash": txHash, "logs": logs}}, nil
+}
+
+func (mockBackend) GetTransactionByHash(common.Hash) (*rpctypes.RPCTransaction, error) {
+	return nil, nil
+}
+
+// query posts the body to the handler, and returns the status and the response.
+func query(t *testing.T, body string) (int, map[string]interface{}) {
+	handler, err := NewHandler(mockBackend{}, log.NewNopLogger())
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body)))
+	var res map[string]interface{}
+	if rec.Code != http.StatusRequestEntityTooLarge && rec.Header().Get("Content-Type") == "application/json" {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	}
+	return rec.Code, res
+}
+
+// queryBody returns the body of a query request.
+func queryBody(t *testing.T, q string) string {
+	bz, err := json.Marshal(map[string]string{"query": q})
+	require.NoError(t, err)
+	return string(bz)
+}
+
+func TestResolver(t *testing.T) {
+	testCases := []struct {
+		name      string
+		query     string
+		expStatus int
+		expData   string
+		expError  string
+	}{
+		{
+			"chain id and gas price", "{ chainID gasPrice }", http.StatusOK,
+			`{"chainID":"0x2328","gasPrice":"0x64"}`, "",
+		},
+		{
+			"block transactions", "{ block(number: 5) { number transactions { hash } } }", http.StatusOK,
+			`{"block":{"number":"0x5","transactions":[{"hash":"` + txHash.Hex() + `"}]}}`, "",
+		},
+		{
+			"block logs filtered by address",
+			`{ block(number: "0x5") { logs(filter: {addresses: ["` + logAddress.Hex() + `"]}) { data transaction { hash } } } }`,
+			http.StatusOK,
+			`{"block":{"logs":[{"data":"0x01","transaction":{"hash":"` + txHash.Hex() + `"}}]}}`, "",
+		},
+		{"block not found", "{ block(number: 11) { number } }", http.StatusOK, `{"block":null}`, ""},
+		{
+			"blocks range", "{ blocks(from: 8) { number } }", http.StatusOK,
+			`{"blocks":[{"number":"0x8"},{"number":"0x9"},{"number":"0xa"}]}`, "",
+		},
+		{"blocks range too large", "{ blocks(from: 1, to: 10) { number } }", http.StatusBadRequest, "", "maximum [from, to] blocks distance: 5"},
+		{"transaction not found", `{ transaction(hash: "` + txHash.Hex() + `") { hash } }`, http.StatusOK, `{"transaction":null}`, ""},
+		{
+			"query too deep",
+			"{ block { parent { parent { parent { parent { parent { parent { parent { parent { parent { parent { number } } } } } } } } } } } }",
+			http.StatusBadRequest, "", "exceeds max depth 10",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status, res := query(t, queryBody(t, tc.query))
+			require.Equal(t, tc.expStatus, status)
+			if tc.expError != "" {
+				require.Contains(t, res["errors"].([]interface{})[0].(map[string]interface{})["message"], tc.expError)
+				return
+			}
+			require.Nil(t, res["errors"])
+			data, err := json.Marshal(res["data"])
+			require.NoError(t, err)
+			require.JSONEq(t, tc.expData, string(data))
+		})
+	}
+}
+
+// rangeBackend counts the loaded block results, with a large block range cap.
+type rangeBackend struct {
+	mockBackend
+	loaded *int32
+}
+
+func (rangeBackend) BlockNumber() (hexutil.Uint64, error) { return 1000, nil }
+
+func (rangeBackend) RPCBlockRangeCap() int32 { return 10000 }
+
+func (b rangeBackend) TendermintBlockResultByNumber(height *int64) (*tmrpctypes.ResultBlockResults, error) {
+	atomic.AddInt32(b.loaded, 1)
+	return b.mockBackend.TendermintBlockResultByNumber(height)
+}
+
+func TestBlocksRange(t *testing.T) {
+	var loaded int32
+	handler, err := NewHandler(rangeBackend{loaded: &loaded}, log.NewNopLogger())
+	require.NoError(t, err)
+	exec := func(q string) map[string]interface{} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(queryBody(t, q))))
+		var res map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		return res
+	}
+
+	// the range is capped below the cap of the backend
+	res := exec("{ blocks(from: 1, to: 200) { number } }")
+	require.Contains(t, res["errors"].([]interface{})[0].(map[string]interface{})["message"], "maximum [from, to] blocks distance: 100")
+
+	// the numbers of the blocks don't load them
+	res = exec("{ blocks(from: 1, to: 100) { number } }")
+	require.Nil(t, res["errors"])
+	require.Len(t, res["data"].(map[string]interface{})["blocks"], 100)
+	require.Zero(t, atomic.LoadInt32(&loaded))
+
+	res = exec("{ blocks(from: 1, to: 10) { transactionCount } }")
+	require.Nil(t, res["errors"])
+	require.Equal(t, int32(10), atomic.LoadInt32(&loaded))
+}
+
+func TestHandlerInvalidRequests(t *testing.T) {
+	status, _ := query(t, "not json")
+	require.Equal(t, http.StatusBadRequest, status)
+
+	large := `{"query":"` + string(bytes.Repeat([]byte{' '}, maxRequestContentLength)) + `{ chainID }"}`
+	status, _ = query(t, large)
+	require.Equal(t, http.StatusRequestEntityTooLarge, status)
+}
+
+func TestLongUnmarshalGraphQL(t *testing.T) {
+	testCases := []struct {
+		input  interface{}
+		exp    Long
+		expErr bool
+	}{
+		{"10", 10, false},
+		{"0xa", 10, false},
+		{int32(10), 10, false},
+		{int64(10), 10, false},
+		{float64(10), 10, false},
+		{"0xzz", 0, true},
+		{"ten", 0, true},
+		{true, 0, true},
+	}
+	for _, tc := range testCases {
+		var l Long
+		err := l.UnmarshalGraphQL(tc.input)
+		if tc.expErr {
+			require.Error(t, err, tc.input)
+			continue
+		}
+		require.NoError(t, err, tc.input)
+		require.Equal(t, tc.exp, l)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 Evmos Foundation
+// This file is part of Evmos' Ethermint library.
+//
+// The Ethermint library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Ethermint library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Ethermint library. If not, see https://github.com/evmos/ethermint/blob/main/LICENSE
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"cosmossdk.io/log"
+	"github.com/graph-gophers/graphql-go"
+
+	"github.com/evmos/ethermint/rpc/backend"
+)
+
+const (
+	// maxRequestContentLength is the max size of the requests, the same as the JSON-RPC server
+	maxRequestContentLength = 5 * 1024 * 1024
+	// maxQueryDepth is the max depth of the queries, deep enough for the nested
+	// transactions, logs and accounts of a block
+	maxQueryDepth = 10
+	// maxParallelism is the max number of resolvers run concurrently by a query
+	maxParallelism = 10
+	// maxBlockRange is the max distance of the block ranges of the blocks and logs
+	// queries, below the range cap of the JSON-RPC methods
+	maxBlockRange = 100
+)
+
+// handler serves the GraphQL queries of the POST requests.
+type handler struct {
+	schema *graphql.Schema
+}
+
+func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+	body := http.MaxBytesReader(w, r.Body, maxRequestContentLength)
+	if err := json.NewDecoder(body).Decode(&params); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := h.schema.Exec(r.Context(), params.Query, params.OperationName, params.Variables)
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if len(response.Errors) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	_, _ = w.Write(responseJSON)
+}
+
+// NewHandler returns the http handler of the EIP-1767 GraphQL queries served by the
+// EVM backend.
+func NewHandler(evmBackend backend.EVMBackend, logger log.Logger) (http.Handler, error) {
+	resolver := &Resolver{backend: evmBackend, logger: logger.With("module", "graphql")}
+	schema, err := graphql.ParseSchema(
+		schema, resolver, graphql.MaxDepth(maxQueryDepth), graphql.MaxParallelism(maxParallelism),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return handler{schema: schema}, nil
+}
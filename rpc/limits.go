@@ -0,0 +1,362 @@
+// Copyright 2021 Evmos Foundation
+// This file is part of Evmos' Ethermint library.
+//
+// The Ethermint library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Ethermint library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Ethermint library. If not, see https://github.com/evmos/ethermint/blob/main/LICENSE
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/evmos/ethermint/server/config"
+)
+
+const (
+	// errCodeInvalidRequest is returned for the batches exceeding the max length
+	errCodeInvalidRequest = -32600
+	// errCodeResponseTooLarge is returned for the responses exceeding the max size
+	errCodeResponseTooLarge = -32003
+	// errCodeLimitExceeded is returned for the requests exceeding the rate limit
+	errCodeLimitExceeded = -32005
+
+	// maxRequestContentLength is the max size of the requests, the same as the go-ethereum rpc server
+	maxRequestContentLength = 5 * 1024 * 1024
+
+	// limiterPruneInterval is the interval between two removals of the idle clients
+	limiterPruneInterval = time.Minute
+
+	// forwardedHeader marks the requests forwarded by the websocket server, the limits are
+	// already applied to them with the address of the websocket client.
+	forwardedHeader = "X-Ethermint-Forwarded"
+
+	// forwardedForHeader is the header of the client IPs set by the reverse proxies
+	forwardedForHeader = "X-Forwarded-For"
+)
+
+// forwardToken authenticates the requests forwarded by the websocket server of this process.
+var forwardToken = newForwardToken()
+
+func newForwardToken() string {
+	bz := make([]byte, 16)
+	if _, err := rand.Read(bz); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(bz)
+}
+
+// methodCost is the cost of the methods matching a prefix.
+type methodCost struct {
+	prefix string
+	cost   int
+}
+
+// RequestLimiter enforces the limits of the JSON-RPC servers: a token bucket per client IP
+// charged with the cost of the called methods, the max length of the batches and the max
+// size of the responses. A single limiter is shared by the http and websocket servers.
+type RequestLimiter struct {
+	rate             rate.Limit
+	burst            int
+	maxBatch         int
+	maxResponseBytes int
+	costs            map[string]int
+	prefixCosts      []methodCost
+	trustedProxies   []netip.Prefix
+
+	mu        sync.Mutex
+	clients   map[string]*rate.Limiter
+	lastPrune time.Time
+}
+
+// NewRequestLimiter creates the RequestLimiter of the JSON-RPC config. The method costs are
+// case insensitive, and a trailing `*` matches the methods with the prefix.
+func NewRequestLimiter(cfg config.JSONRPCConfig) *RequestLimiter {
+	l := &RequestLimiter{
+		rate:             rate.Limit(cfg.RateLimit),
+		burst:            cfg.RateLimitBurst,
+		maxBatch:         cfg.BatchRequestLimit,
+		maxResponseBytes: cfg.MaxResponseBytes,
+		costs:            make(map[string]int),
+		clients:          make(map[string]*rate.Limiter),
+		lastPrune:        time.Now(),
+	}
+	for _, proxy := range cfg.TrustedProxies {
+		// the proxies are validated with the config
+		if prefix, err := config.ParseTrustedProxy(proxy); err == nil {
+			l.trustedProxies = append(l.trustedProxies, prefix)
+		}
+	}
+	for method, cost := range cfg.MethodCosts {
+		method = strings.ToLower(method)
+		if prefix, ok := strings.CutSuffix(method, "*"); ok {
+			l.prefixCosts = append(l.prefixCosts, methodCost{prefix, cost})
+		} else {
+			l.costs[method] = cost
+		}
+	}
+	// the longest prefix matches first
+	sort.Slice(l.prefixCosts, func(i, j int) bool {
+		return len(l.prefixCosts[i].prefix) > len(l.prefixCosts[j].prefix)
+	})
+	return l
+}
+
+// Cost returns the cost of a method, 1 by default.
+func (l *RequestLimiter) Cost(method string) int {
+	method = strings.ToLower(method)
+	if cost, ok := l.costs[method]; ok {
+		return cost
+	}
+	for _, pc := range l.prefixCosts {
+		if strings.HasPrefix(method, pc.prefix) {
+			return pc.cost
+		}
+	}
+	return 1
+}
+
+// allow charges the cost to the token bucket of the client, it returns false if the bucket
+// doesn't have enough tokens.
+func (l *RequestLimiter) allow(client string, cost int) bool {
+	if l.rate <= 0 {
+		return true
+	}
+	// the requests more expensive than the burst are allowed with a full bucket
+	return l.clientLimiter(client).AllowN(time.Now(), min(cost, l.burst))
+}
+
+// wait charges the cost to the token bucket of the client, it waits for the bucket to have
+// enough tokens or the context to be done.
+func (l *RequestLimiter) wait(ctx context.Context, client string, cost int) error {
+	if l.rate <= 0 {
+		return nil
+	}
+	return l.clientLimiter(client).WaitN(ctx, min(cost, l.burst))
+}
+
+// clientLimiter returns the token bucket of a client, the idle clients are pruned.
+func (l *RequestLimiter) clientLimiter(client string) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastPrune) > limiterPruneInterval {
+		// a full bucket is the same as a new one
+		for ip, limiter := range l.clients {
+			if limiter.TokensAt(now) >= float64(l.burst) {
+				delete(l.clients, ip)
+			}
+		}
+		l.lastPrune = now
+	}
+
+	limiter, ok := l.clients[client]
+	if !ok {
+		limiter = rate.NewLimiter(l.rate, l.burst)
+		l.clients[client] = limiter
+	}
+	return limiter
+}
+
+// rpcMessage is the part of a JSON-RPC request used by the limits.
+type rpcMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+}
+
+// limitError is a JSON-RPC error for a request exceeding a limit.
+type limitError struct {
+	code    int
+	message string
+	status  int
+}
+
+// parseRequest returns the messages of a request, and the id of a single request for the
+// error responses. The invalid requests are returned as a message without method, the rpc
+// server returns the parse errors.
+func parseRequest(body []byte) ([]rpcMessage, json.RawMessage) {
+	if isBatch(body) {
+		var msgs []rpcMessage
+		if err := json.Unmarshal(body, &msgs); err != nil {
+			return []rpcMessage{{}}, nil
+		}
+		return msgs, nil
+	}
+	var msg rpcMessage
+	_ = json.Unmarshal(body, &msg)
+	return []rpcMessage{msg}, msg.ID
+}
+
+// checkRequest applies the batch length and rate limits to the messages of a client.
+func (l *RequestLimiter) checkRequest(client string, msgs []rpcMessage, batch bool) *limitError {
+	if batch && l.maxBatch > 0 && len(msgs) > l.maxBatch {
+		return &limitError{errCodeInvalidRequest, "batch too large, max length " + strconv.Itoa(l.maxBatch), http.StatusOK}
+	}
+
+	var cost int
+	for _, msg := range msgs {
+		cost += l.Cost(msg.Method)
+	}
+	if !l.allow(client, cost) {
+		return &limitError{errCodeLimitExceeded, "rate limit exceeded", http.StatusTooManyRequests}
+	}
+	return nil
+}
+
+// Handler applies the limits to the requests of the http handler, a JSON-RPC error is
+// returned if a limit is exceeded.
+func (l *RequestLimiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := readBody(w, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		msgs, id := parseRequest(body)
+		if r.Header.Get(forwardedHeader) != forwardToken {
+			if limitErr := l.checkRequest(l.clientIP(r), msgs, isBatch(body)); limitErr != nil {
+				writeLimitError(w, id, limitErr)
+				return
+			}
+		}
+
+		if l.maxResponseBytes <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rw := &bufferedResponseWriter{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(rw, r)
+		if rw.body.Len() > l.maxResponseBytes {
+			writeLimitError(w, id, &limitError{
+				errCodeResponseTooLarge,
+				"response too large, max size " + strconv.Itoa(l.maxResponseBytes),
+				http.StatusOK,
+			})
+			return
+		}
+		rw.writeTo(w)
+	})
+}
+
+// readBody reads the body of the request, and replaces it for the next handler.
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(http.MaxBytesReader(w, r.Body, maxRequestContentLength)); err != nil {
+		return nil, err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(buf.Bytes()))
+	return buf.Bytes(), nil
+}
+
+// bufferedResponseWriter buffers a response to check its size.
+type bufferedResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (rw *bufferedResponseWriter) Header() http.Header          { return rw.header }
+func (rw *bufferedResponseWriter) WriteHeader(status int)       { rw.status = status }
+func (rw *bufferedResponseWriter) Write(bz []byte) (int, error) { return rw.body.Write(bz) }
+
+// writeTo writes the buffered response.
+func (rw *bufferedResponseWriter) writeTo(w http.ResponseWriter) {
+	for k, v := range rw.header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(rw.status)
+	_, _ = w.Write(rw.body.Bytes())
+}
+
+// limitErrorResponse returns the JSON-RPC error response of a limit error.
+func limitErrorResponse(id json.RawMessage, limitErr *limitError) map[string]interface{} {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error": map[string]interface{}{
+			"code":    limitErr.code,
+			"message": limitErr.message,
+		},
+	}
+}
+
+func writeLimitError(w http.ResponseWriter, id json.RawMessage, limitErr *limitError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(limitErr.status)
+	_ = json.NewEncoder(w).Encode(limitErrorResponse(id, limitErr))
+}
+
+// clientIP returns the IP of the client of a request. The requests of the trusted proxies
+// are attributed to the last address of their X-Forwarded-For header which isn't a trusted
+// proxy, the other requests to their remote address.
+func (l *RequestLimiter) clientIP(r *http.Request) string {
+	ip := remoteIP(r.RemoteAddr)
+	if !l.isTrustedProxy(ip) {
+		return ip
+	}
+	hops := strings.Split(strings.Join(r.Header.Values(forwardedForHeader), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		ip = hop
+		if !l.isTrustedProxy(ip) {
+			break
+		}
+	}
+	return ip
+}
+
+// isTrustedProxy returns true if the IP is one of the trusted proxies.
+func (l *RequestLimiter) isTrustedProxy(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range l.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteIP returns the IP of a remote address.
+func remoteIP(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
@@ -0,0 +1,154 @@
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/evmos/ethermint/server/config"
+)
+
+func newTestLimiter(rateLimit float64, burst int) *RequestLimiter {
+	cfg := config.DefaultConfig().JSONRPC
+	cfg.RateLimit = rateLimit
+	cfg.RateLimitBurst = burst
+	cfg.BatchRequestLimit = 3
+	cfg.MaxResponseBytes = 64
+	cfg.MethodCosts = map[string]int{
+		"debug_*":           20,
+		"debug_traceCall":   30,
+		"debug_traceBlock*": 25,
+		"eth_getLogs":       10,
+	}
+	cfg.TrustedProxies = []string{"10.0.0.1", "192.168.0.0/16"}
+	return NewRequestLimiter(cfg)
+}
+
+func TestRequestLimiterCost(t *testing.T) {
+	l := newTestLimiter(1, 10)
+	testCases := []struct {
+		method string
+		exp    int
+	}{
+		{"eth_getLogs", 10},
+		{"ETH_GETLOGS", 10},
+		{"debug_traceCall", 30},
+		// the longest prefix matches first
+		{"debug_traceBlockByNumber", 25},
+		{"debug_traceTransaction", 20},
+		{"eth_blockNumber", 1},
+		{"", 1},
+	}
+	for _, tc := range testCases {
+		require.Equal(t, tc.exp, l.Cost(tc.method), tc.method)
+	}
+}
+
+func TestRequestLimiterTokenBucket(t *testing.T) {
+	l := newTestLimiter(0.001, 10)
+
+	// the buckets are per client
+	require.True(t, l.allow("a", 8))
+	require.False(t, l.allow("a", 3))
+	require.True(t, l.allow("a", 2))
+	require.False(t, l.allow("a", 1))
+	require.True(t, l.allow("b", 10))
+
+	// a request more expensive than the burst needs a full bucket
+	require.True(t, l.allow("c", 30))
+	require.False(t, l.allow("c", 30))
+
+	// the rate limit is disabled without rate
+	l = newTestLimiter(0, 10)
+	for i := 0; i < 100; i++ {
+		require.True(t, l.allow("a", 10))
+	}
+}
+
+func TestRequestLimiterCheckRequest(t *testing.T) {
+	l := newTestLimiter(0.001, 20)
+
+	msgs := []rpcMessage{{Method: "eth_getLogs"}, {Method: "eth_getLogs"}, {Method: "eth_chainId"}, {Method: "eth_chainId"}}
+	limitErr := l.checkRequest("a", msgs, true)
+	require.NotNil(t, limitErr)
+	require.Equal(t, errCodeInvalidRequest, limitErr.code)
+
+	// a batch is charged the cost of its requests
+	require.Nil(t, l.checkRequest("a", msgs[:2], true))
+	limitErr = l.checkRequest("a", msgs[2:3], false)
+	require.NotNil(t, limitErr)
+	require.Equal(t, errCodeLimitExceeded, limitErr.code)
+	require.Equal(t, http.StatusTooManyRequests, limitErr.status)
+}
+
+func TestRequestLimiterClientIP(t *testing.T) {
+	l := newTestLimiter(1, 10)
+	testCases := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		exp        string
+	}{
+		{"remote address", "1.2.3.4:1234", nil, "1.2.3.4"},
+		{"untrusted proxy", "1.2.3.4:1234", []string{"5.6.7.8"}, "1.2.3.4"},
+		{"trusted proxy", "10.0.0.1:1234", []string{"5.6.7.8"}, "5.6.7.8"},
+		{"spoofed hops", "10.0.0.1:1234", []string{"9.9.9.9, 5.6.7.8"}, "5.6.7.8"},
+		{"chained trusted proxies", "10.0.0.1:1234", []string{"5.6.7.8, 192.168.1.1", "192.168.2.2"}, "5.6.7.8"},
+		{"trusted proxy without header", "10.0.0.1:1234", nil, "10.0.0.1"},
+		{"only trusted hops", "10.0.0.1:1234", []string{"192.168.1.1"}, "192.168.1.1"},
+		{"mapped ipv4 proxy", "[::ffff:10.0.0.1]:1234", []string{"5.6.7.8"}, "5.6.7.8"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			r.RemoteAddr = tc.remoteAddr
+			for _, value := range tc.forwarded {
+				r.Header.Add(forwardedForHeader, value)
+			}
+			require.Equal(t, tc.exp, l.clientIP(r))
+		})
+	}
+}
+
+func TestRequestLimiterHandler(t *testing.T) {
+	l := newTestLimiter(0.001, 10)
+	handler := l.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.Header.Get("X-Test"), "large") {
+			_, _ = w.Write([]byte(strings.Repeat("a", 65)))
+			return
+		}
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	}))
+
+	serve := func(body string, header http.Header) (int, map[string]interface{}) {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		r.RemoteAddr = "1.2.3.4:1234"
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		var res map[string]interface{}
+		_ = json.Unmarshal(rec.Body.Bytes(), &res)
+		return rec.Code, res
+	}
+
+	status, _ := serve(`{"jsonrpc":"2.0","id":1,"method":"eth_getLogs"}`, nil)
+	require.Equal(t, http.StatusOK, status)
+	status, res := serve(`{"jsonrpc":"2.0","id":2,"method":"eth_chainId"}`, nil)
+	require.Equal(t, http.StatusTooManyRequests, status)
+	require.Equal(t, float64(2), res["id"])
+	require.Equal(t, float64(errCodeLimitExceeded), res["error"].(map[string]interface{})["code"])
+
+	// the requests forwarded by the websocket server are already charged
+	status, _ = serve(`{"jsonrpc":"2.0","id":3,"method":"eth_chainId"}`, http.Header{forwardedHeader: {forwardToken}})
+	require.Equal(t, http.StatusOK, status)
+
+	status, res = serve(`{"jsonrpc":"2.0","id":4,"method":"eth_chainId"}`, http.Header{forwardedHeader: {forwardToken}, "X-Test": {"large"}})
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, float64(errCodeResponseTooLarge), res["error"].(map[string]interface{})["code"])
+}
@@ -0,0 +1,280 @@
+// Copyright 2021 Evmos Foundation
+// This file is part of Evmos' Ethermint library.
+//
+// The Ethermint library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Ethermint library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Ethermint library. If not, see https://github.com/evmos/ethermint/blob/main/LICENSE
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"cosmossdk.io/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	transportHTTP = "http"
+	transportWS   = "ws"
+
+	// unknownMethod is the label of the methods not registered, to bound the labels cardinality
+	unknownMethod = "unknown"
+	// maxLoggedParams is the max length of the params of the slow requests logs
+	maxLoggedParams = 1024
+	// redactedParams replaces the params of the sensitive methods in the logs
+	redactedParams = "<redacted>"
+)
+
+var (
+	requestLabels = []string{"transport", "namespace", "method"}
+
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "ethermint",
+		Subsystem: "jsonrpc",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of the JSON-RPC requests.",
+		Buckets:   []float64{.001, .005, .01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, requestLabels)
+
+	requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "ethermint",
+		Subsystem: "jsonrpc",
+		Name:      "requests_total",
+		Help:      "Number of JSON-RPC requests by error code, 0 for the successful ones.",
+	}, append(requestLabels, "code"))
+
+	requestSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "ethermint",
+		Subsystem: "jsonrpc",
+		Name:      "request_size_bytes",
+		Help:      "Size of the JSON-RPC requests.",
+		Buckets:   prometheus.ExponentialBuckets(64, 4, 10),
+	}, requestLabels)
+
+	responseSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "ethermint",
+		Subsystem: "jsonrpc",
+		Name:      "response_size_bytes",
+		Help:      "Size of the JSON-RPC responses.",
+		Buckets:   prometheus.ExponentialBuckets(64, 4, 12),
+	}, requestLabels)
+
+	batchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "ethermint",
+		Subsystem: "jsonrpc",
+		Name:      "batch_duration_seconds",
+		Help:      "Duration of the JSON-RPC batches, their requests are not timed one by one.",
+		Buckets:   []float64{.001, .005, .01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"transport"})
+)
+
+// requestMetrics records the metrics of the JSON-RPC requests, and logs the slow ones.
+// Only the registered methods are labelled by name, the other ones are labelled as
+// unknown methods.
+type requestMetrics struct {
+	logger        log.Logger
+	slowThreshold time.Duration
+	methods       map[string]bool
+}
+
+// rpcCall is the part of a JSON-RPC request or response used by the metrics.
+type rpcCall struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Error  *struct {
+		Code int `json:"code"`
+	} `json:"error"`
+}
+
+// code returns the error code of a response, 0 for a successful one.
+func (c rpcCall) code() int {
+	if c.Error == nil {
+		return 0
+	}
+	return c.Error.Code
+}
+
+// record records the metrics of a request, the response is parsed for the error code.
+func (m requestMetrics) record(transport string, req, res []byte, duration time.Duration) {
+	var msg, result rpcCall
+	_ = json.Unmarshal(req, &msg)
+	_ = json.Unmarshal(res, &result)
+
+	code := result.code()
+	m.observe(transport, msg.Method, code, len(req), len(res), duration)
+
+	if m.slowThreshold > 0 && duration >= m.slowThreshold {
+		m.logger.Info(
+			"slow JSON-RPC request",
+			"transport", transport, "method", msg.Method, "duration", duration, "code", code,
+			"params", loggedParams(msg.Method, msg.Params),
+		)
+	}
+}
+
+// recordBatch records the metrics of the requests of a batch, served as a whole by the
+// rpc server. The responses are matched to the requests by id, the notifications have
+// no response. The requests of a batch are counted by method, the batch is timed as a whole.
+func (m requestMetrics) recordBatch(transport string, reqs []json.RawMessage, res []byte, duration time.Duration) {
+	var responses []json.RawMessage
+	_ = json.Unmarshal(res, &responses)
+	byID := make(map[string][]json.RawMessage)
+	for _, response := range responses {
+		var result rpcCall
+		if json.Unmarshal(response, &result) == nil && len(result.ID) > 0 {
+			byID[string(result.ID)] = append(byID[string(result.ID)], response)
+		}
+	}
+
+	methods := make([]string, len(reqs))
+	for i, req := range reqs {
+		var msg, result rpcCall
+		_ = json.Unmarshal(req, &msg)
+		methods[i] = msg.Method
+
+		var response json.RawMessage
+		if queue := byID[string(msg.ID)]; len(msg.ID) > 0 && len(queue) > 0 {
+			response, byID[string(msg.ID)] = queue[0], queue[1:]
+			_ = json.Unmarshal(response, &result)
+		}
+		m.observeCount(transport, msg.Method, result.code(), len(req), len(response))
+	}
+	batchDuration.WithLabelValues(transport).Observe(duration.Seconds())
+
+	if m.slowThreshold > 0 && duration >= m.slowThreshold {
+		m.logger.Info(
+			"slow JSON-RPC batch",
+			"transport", transport, "length", len(reqs), "duration", duration, "methods", strings.Join(methods, ","),
+		)
+	}
+}
+
+// observe records the metrics of a request.
+func (m requestMetrics) observe(transport, method string, code, reqSize, resSize int, duration time.Duration) {
+	namespace, label := m.methodLabels(method)
+	requestDuration.WithLabelValues(transport, namespace, label).Observe(duration.Seconds())
+	m.observeCount(transport, method, code, reqSize, resSize)
+}
+
+// observeCount records the metrics of a request but its duration.
+func (m requestMetrics) observeCount(transport, method string, code, reqSize, resSize int) {
+	namespace, method := m.methodLabels(method)
+	requestsTotal.WithLabelValues(transport, namespace, method, strconv.Itoa(code)).Inc()
+	requestSize.WithLabelValues(transport, namespace, method).Observe(float64(reqSize))
+	responseSize.WithLabelValues(transport, namespace, method).Observe(float64(resSize))
+}
+
+// methodLabels returns the namespace and method labels of a method. The methods not
+// registered, including the ones of the notifications which have no response, are
+// labelled as unknown.
+func (m requestMetrics) methodLabels(method string) (string, string) {
+	if !m.methods[method] {
+		return unknownMethod, unknownMethod
+	}
+	namespace, _, _ := strings.Cut(method, "_")
+	return namespace, method
+}
+
+// RegisteredMethods returns the methods served by the rpc server for the apis, named
+// as the server does: the namespace and the method name with a lowercase first letter.
+// The apis with subscriptions also serve the subscribe and unsubscribe methods.
+func RegisteredMethods(apis []rpc.API) map[string]bool {
+	subscriptionType := reflect.TypeOf((*rpc.Subscription)(nil))
+	methods := map[string]bool{"rpc_modules": true}
+	for _, api := range apis {
+		typ := reflect.TypeOf(api.Service)
+		for i := 0; i < typ.NumMethod(); i++ {
+			method := typ.Method(i)
+			name := []rune(method.Name)
+			name[0] = unicode.ToLower(name[0])
+			methods[api.Namespace+"_"+string(name)] = true
+			if method.Type.NumOut() > 0 && method.Type.Out(0) == subscriptionType {
+				methods[api.Namespace+"_subscribe"] = true
+				methods[api.Namespace+"_unsubscribe"] = true
+			}
+		}
+	}
+	return methods
+}
+
+// loggedParams returns the params of a request for the logs. The params of the personal
+// namespace and of the signing methods carry private keys and passphrases, they're
+// redacted.
+func loggedParams(method string, params json.RawMessage) string {
+	method = strings.ToLower(method)
+	if strings.HasPrefix(method, "personal_") || strings.HasPrefix(method, "eth_sign") {
+		return redactedParams
+	}
+	logged := string(params)
+	if len(logged) > maxLoggedParams {
+		logged = logged[:maxLoggedParams] + "..."
+	}
+	return logged
+}
+
+// NewMetricsHandler records the metrics of the requests of the http handler, with the
+// requests forwarded by the websocket server labelled as such. A batch is served as a
+// whole, the metrics of its requests are recorded from the matching responses. The
+// methods are the registered ones, see RegisteredMethods.
+func NewMetricsHandler(logger log.Logger, slowThreshold time.Duration, methods map[string]bool, next http.Handler) http.Handler {
+	m := requestMetrics{logger: logger.With("module", "jsonrpc-metrics"), slowThreshold: slowThreshold, methods: methods}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := readBody(w, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		transport := transportHTTP
+		if r.Header.Get(forwardedHeader) == forwardToken {
+			transport = transportWS
+		}
+
+		rw := serveBuffered(next, r, body)
+		var batch []json.RawMessage
+		if isBatch(body) && json.Unmarshal(body, &batch) == nil && len(batch) > 0 {
+			m.recordBatch(transport, batch, rw.body.Bytes(), rw.duration)
+		} else {
+			m.record(transport, body, rw.body.Bytes(), rw.duration)
+		}
+		rw.writeTo(w)
+	})
+}
+
+// timedResponseWriter is a bufferedResponseWriter with the duration of the response.
+type timedResponseWriter struct {
+	bufferedResponseWriter
+	duration time.Duration
+}
+
+// serveBuffered serves a request with the body, and returns the buffered response.
+func serveBuffered(next http.Handler, r *http.Request, body []byte) *timedResponseWriter {
+	req := r.Clone(r.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+
+	rw := &timedResponseWriter{bufferedResponseWriter: bufferedResponseWriter{header: make(http.Header), status: http.StatusOK}}
+	start := time.Now()
+	next.ServeHTTP(rw, req)
+	rw.duration = time.Since(start)
+	return rw
+}
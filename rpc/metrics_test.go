@@ -0,0 +1,79 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cosmossdk.io/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggedParams(t *testing.T) {
+	params := json.RawMessage(`["0xkey","passphrase"]`)
+	for _, method := range []string{
+		"personal_importRawKey", "personal_unlockAccount", "personal_sendTransaction", "personal_sign",
+		"eth_sign", "eth_signTransaction", "eth_signTypedData", "Personal_sign",
+	} {
+		require.Equal(t, redactedParams, loggedParams(method, params), method)
+	}
+	require.Equal(t, string(params), loggedParams("eth_getBalance", params))
+
+	long := json.RawMessage(strings.Repeat("a", maxLoggedParams+1))
+	require.Equal(t, strings.Repeat("a", maxLoggedParams)+"...", loggedParams("eth_call", long))
+}
+
+func TestMetricsHandlerBatch(t *testing.T) {
+	served := 0
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served++
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.True(t, isBatch(body))
+		// the responses are out of order, the notification has none
+		_, _ = w.Write([]byte(`[{"id":2,"error":{"code":-32000}},{"id":1,"result":"0x1"}]`))
+	})
+
+	counter := func(namespace, method, code string) float64 {
+		return testutil.ToFloat64(requestsTotal.WithLabelValues(transportHTTP, namespace, method, code))
+	}
+	blockNumber, call := counter("eth", "eth_blockNumber", "0"), counter("eth", "eth_call", "-32000")
+	notification, unknown := counter("eth", "eth_chainId", "0"), counter(unknownMethod, unknownMethod, "0")
+
+	methods := map[string]bool{"eth_blockNumber": true, "eth_call": true, "eth_chainId": true}
+	handler := NewMetricsHandler(log.NewNopLogger(), 0, methods, next)
+	// the notifications have no response, the unregistered methods are labelled as unknown
+	body := `[{"id":1,"method":"eth_blockNumber"},{"id":2,"method":"eth_call"},{"method":"eth_chainId"},{"method":"eth_random1"},{"method":"foo"}]`
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(body)))
+
+	// the batch is served once, its response is written through
+	require.Equal(t, 1, served)
+	require.JSONEq(t, `[{"id":2,"error":{"code":-32000}},{"id":1,"result":"0x1"}]`, rec.Body.String())
+
+	require.Equal(t, blockNumber+1, counter("eth", "eth_blockNumber", "0"))
+	require.Equal(t, call+1, counter("eth", "eth_call", "-32000"))
+	require.Equal(t, notification+1, counter("eth", "eth_chainId", "0"))
+	require.Equal(t, unknown+2, counter(unknownMethod, unknownMethod, "0"))
+}
+
+type testService struct{}
+
+func (testService) BlockNumber() uint64                                 { return 0 }
+func (testService) NewHeads(context.Context) (*rpc.Subscription, error) { return nil, nil }
+
+func TestRegisteredMethods(t *testing.T) {
+	methods := RegisteredMethods([]rpc.API{{Namespace: "eth", Service: testService{}}, {Namespace: "net", Service: &testService{}}})
+	require.Equal(t, map[string]bool{
+		"rpc_modules":     true,
+		"eth_blockNumber": true, "eth_newHeads": true, "eth_subscribe": true, "eth_unsubscribe": true,
+		"net_blockNumber": true, "net_newHeads": true, "net_subscribe": true, "net_unsubscribe": true,
+	}, methods)
+}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/evmos/ethermint/rpc/backend"
 	"github.com/evmos/ethermint/rpc/types"
+	ethermint "github.com/evmos/ethermint/types"
 
 	"cosmossdk.io/log"
 	tmrpctypes "github.com/cometbft/cometbft/rpc/core/types"
@@ -108,9 +109,6 @@ const (
 // Logs searches the blockchain for matching log entries, returning all from the
 // first block that contains matches, updating the start of the filter accordingly.
 func (f *Filter) Logs(_ context.Context, logLimit int, blockLimit int64) ([]*ethtypes.Log, error) {
-	logs := []*ethtypes.Log{}
-	var err error
-
 	// If we're doing singleton block filtering, execute and return
 	if f.criteria.BlockHash != nil && *f.criteria.BlockHash != (common.Hash{}) {
 		resBlock, err := f.backend.TendermintBlockByHash(*f.criteria.BlockHash)
@@ -155,10 +153,6 @@ func (f *Filter) Logs(_ context.Context, logLimit int, blockLimit int64) ([]*eth
 		f.criteria.ToBlock = big.NewInt(1)
 	}
 
-	if f.criteria.ToBlock.Int64()-f.criteria.FromBlock.Int64() > blockLimit {
-		return nil, fmt.Errorf("maximum [from, to] blocks distance: %d", blockLimit)
-	}
-
 	// check bounds
 	if f.criteria.FromBlock.Int64() > head {
 		return []*ethtypes.Log{}, nil
@@ -169,6 +163,25 @@ func (f *Filter) Logs(_ context.Context, logLimit int, blockLimit int64) ([]*eth
 	from := f.criteria.FromBlock.Int64()
 	to := f.criteria.ToBlock.Int64()
 
+	// serve the indexed part of the range from the indexer, the blocks above head don't exist
+	logs, indexedLast, err := f.backend.GetIndexedLogs(from, min(to, head), f.criteria.Addresses, f.criteria.Topics, logLimit)
+	if err != nil {
+		return nil, err
+	}
+	if logs == nil {
+		logs = []*ethtypes.Log{}
+	}
+	if indexedLast >= min(to, head) {
+		return logs, nil
+	}
+
+	// scan only the unindexed tail of the range from the block results, the block range
+	// cap bounds the scan while the indexed part is bounded by the logs cap
+	from = indexedLast + 1
+	if to-from > blockLimit {
+		return nil, fmt.Errorf("maximum [from, to] blocks distance: %d", blockLimit)
+	}
+
 	for height := from; height <= to; height++ {
 		blockRes, err := f.backend.TendermintBlockResultByNumber(&height)
 		if err != nil {
@@ -188,7 +201,7 @@ func (f *Filter) Logs(_ context.Context, logLimit int, blockLimit int64) ([]*eth
 
 		// check logs limit
 		if len(logs)+len(filtered) > logLimit {
-			return nil, fmt.Errorf("query returned more than %d results", logLimit)
+			return nil, ethermint.LogsLimitError{Limit: logLimit}
 		}
 		logs = append(logs, filtered...)
 	}
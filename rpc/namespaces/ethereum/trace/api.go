@@ -0,0 +1,80 @@
+// Copyright 2021 Evmos Foundation
+// This file is part of Evmos' Ethermint library.
+//
+// The Ethermint library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Ethermint library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Ethermint library. If not, see https://github.com/evmos/ethermint/blob/main/LICENSE
+package trace
+
+import (
+	"errors"
+
+	"cosmossdk.io/log"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/evmos/ethermint/rpc/backend"
+	rpctypes "github.com/evmos/ethermint/rpc/types"
+	"github.com/evmos/ethermint/x/evm/tracers"
+)
+
+// API offers the OpenEthereum (parity) style tracing methods, returning the flat
+// call traces of transactions with their position in the call tree.
+type API struct {
+	logger  log.Logger
+	backend backend.EVMBackend
+}
+
+// NewAPI creates a new API definition for the trace methods.
+func NewAPI(logger log.Logger, backend backend.EVMBackend) *API {
+	return &API{
+		logger:  logger.With("module", "trace"),
+		backend: backend,
+	}
+}
+
+// Transaction returns the call traces of a transaction.
+func (a *API) Transaction(hash common.Hash) ([]tracers.FlatCallTrace, error) {
+	a.logger.Debug("trace_transaction", "hash", hash)
+	return a.backend.TraceCalls(hash)
+}
+
+// Block returns the call traces of the transactions of a block.
+func (a *API) Block(blockNum rpctypes.BlockNumber) ([]tracers.FlatCallTrace, error) {
+	a.logger.Debug("trace_block", "number", blockNum)
+	if blockNum == 0 {
+		return nil, errors.New("genesis is not traceable")
+	}
+
+	block, err := a.backend.TendermintBlockByNumber(blockNum)
+	if err != nil {
+		a.logger.Debug("get block failed", "number", blockNum, "error", err.Error())
+		return nil, err
+	}
+	if block == nil || block.Block == nil {
+		return nil, nil
+	}
+	return a.backend.TraceBlockCalls(block)
+}
+
+// Filter returns the call traces of a block range, filtered by sender and recipient.
+func (a *API) Filter(args rpctypes.TraceFilterArgs) ([]tracers.FlatCallTrace, error) {
+	a.logger.Debug("trace_filter", "from", args.FromBlock, "to", args.ToBlock)
+	return a.backend.TraceFilter(args)
+}
+
+// ReplayTransaction replays a transaction and returns the requested traces, the
+// supported trace types are "trace" and "stateDiff".
+func (a *API) ReplayTransaction(hash common.Hash, traceTypes []string) (*rpctypes.TraceResults, error) {
+	a.logger.Debug("trace_replayTransaction", "hash", hash, "types", traceTypes)
+	return a.backend.TraceReplayTransaction(hash, traceTypes)
+}
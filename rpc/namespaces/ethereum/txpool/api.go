@@ -16,51 +16,151 @@
 package txpool
 
 import (
+	"fmt"
+
 	"cosmossdk.io/log"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
 
+	"github.com/evmos/ethermint/rpc/backend"
 	"github.com/evmos/ethermint/rpc/types"
 )
 
 // PublicAPI offers and API for the transaction pool. It only operates on data that is non-confidential.
-// NOTE: For more info about the current status of this endpoints see https://github.com/evmos/ethermint/issues/124
+// The content is built from the ethereum transactions of the CometBFT mempool.
 type PublicAPI struct {
-	logger log.Logger
+	logger  log.Logger
+	backend backend.EVMBackend
 }
 
 // NewPublicAPI creates a new tx pool service that gives information about the transaction pool.
-func NewPublicAPI(logger log.Logger) *PublicAPI {
+func NewPublicAPI(logger log.Logger, backend backend.EVMBackend) *PublicAPI {
 	return &PublicAPI{
-		logger: logger.With("module", "txpool"),
+		logger:  logger.With("module", "txpool"),
+		backend: backend,
 	}
 }
 
 // Content returns the transactions contained within the transaction pool
 func (api *PublicAPI) Content() (map[string]map[string]map[string]*types.RPCTransaction, error) {
 	api.logger.Debug("txpool_content")
+	pending, queued, err := api.backend.TxPoolContent()
+	if err != nil {
+		return nil, err
+	}
+	chainID, err := api.backend.ChainID()
+	if err != nil {
+		return nil, err
+	}
+
 	content := map[string]map[string]map[string]*types.RPCTransaction{
 		"pending": make(map[string]map[string]*types.RPCTransaction),
 		"queued":  make(map[string]map[string]*types.RPCTransaction),
 	}
+	for sender, txs := range pending {
+		if content["pending"][sender.Hex()], err = rpcTransactions(txs, chainID); err != nil {
+			return nil, err
+		}
+	}
+	for sender, txs := range queued {
+		if content["queued"][sender.Hex()], err = rpcTransactions(txs, chainID); err != nil {
+			return nil, err
+		}
+	}
+	return content, nil
+}
+
+// ContentFrom returns the transactions contained within the transaction pool
+// sent by the given address.
+func (api *PublicAPI) ContentFrom(address common.Address) (map[string]map[string]*types.RPCTransaction, error) {
+	api.logger.Debug("txpool_contentFrom", "address", address.Hex())
+	pending, queued, err := api.backend.TxPoolContentFrom(address)
+	if err != nil {
+		return nil, err
+	}
+	chainID, err := api.backend.ChainID()
+	if err != nil {
+		return nil, err
+	}
+
+	content := make(map[string]map[string]*types.RPCTransaction, 2)
+	if content["pending"], err = rpcTransactions(pending, chainID); err != nil {
+		return nil, err
+	}
+	if content["queued"], err = rpcTransactions(queued, chainID); err != nil {
+		return nil, err
+	}
 	return content, nil
 }
 
 // Inspect returns the content of the transaction pool and flattens it into an
+// easily inspectable list.
 func (api *PublicAPI) Inspect() (map[string]map[string]map[string]string, error) {
 	api.logger.Debug("txpool_inspect")
+	pending, queued, err := api.backend.TxPoolContent()
+	if err != nil {
+		return nil, err
+	}
+
 	content := map[string]map[string]map[string]string{
 		"pending": make(map[string]map[string]string),
 		"queued":  make(map[string]map[string]string),
 	}
+	for sender, txs := range pending {
+		content["pending"][sender.Hex()] = inspectTransactions(txs)
+	}
+	for sender, txs := range queued {
+		content["queued"][sender.Hex()] = inspectTransactions(txs)
+	}
 	return content, nil
 }
 
 // Status returns the number of pending and queued transaction in the pool.
-func (api *PublicAPI) Status() map[string]hexutil.Uint {
+func (api *PublicAPI) Status() (map[string]hexutil.Uint, error) {
 	api.logger.Debug("txpool_status")
+	pending, queued, err := api.backend.TxPoolContent()
+	if err != nil {
+		return nil, err
+	}
+
+	var pendingCount, queuedCount int
+	for _, txs := range pending {
+		pendingCount += len(txs)
+	}
+	for _, txs := range queued {
+		queuedCount += len(txs)
+	}
 	return map[string]hexutil.Uint{
-		"pending": hexutil.Uint(0),
-		"queued":  hexutil.Uint(0),
+		"pending": hexutil.Uint(pendingCount),
+		"queued":  hexutil.Uint(queuedCount),
+	}, nil
+}
+
+// rpcTransactions formats the transactions of a sender indexed by their nonce.
+func rpcTransactions(txs []*ethtypes.Transaction, chainID *hexutil.Big) (map[string]*types.RPCTransaction, error) {
+	result := make(map[string]*types.RPCTransaction, len(txs))
+	for _, tx := range txs {
+		rpcTx, err := types.NewRPCTransaction(tx, common.Hash{}, 0, 0, nil, chainID.ToInt())
+		if err != nil {
+			return nil, err
+		}
+		result[fmt.Sprintf("%d", tx.Nonce())] = rpcTx
+	}
+	return result, nil
+}
+
+// inspectTransactions summarizes the transactions of a sender indexed by their nonce.
+func inspectTransactions(txs []*ethtypes.Transaction) map[string]string {
+	result := make(map[string]string, len(txs))
+	for _, tx := range txs {
+		nonce := fmt.Sprintf("%d", tx.Nonce())
+		if to := tx.To(); to != nil {
+			result[nonce] = fmt.Sprintf("%s: %v wei + %v gas × %v wei", to.Hex(), tx.Value(), tx.Gas(), tx.GasPrice())
+		} else {
+			result[nonce] = fmt.Sprintf("contract creation: %v wei + %v gas × %v wei", tx.Value(), tx.Gas(), tx.GasPrice())
+		}
 	}
+	return result
 }
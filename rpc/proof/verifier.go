@@ -0,0 +1,205 @@
+// Copyright 2021 Evmos Foundation
+// This file is part of Evmos' Ethermint library.
+//
+// The Ethermint library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Ethermint library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Ethermint library. If not, see https://github.com/evmos/ethermint/blob/main/LICENSE
+
+// Package proof verifies the results of `eth_getProof` offline. Ethermint state is
+// committed in IAVL trees, the proofs of the account and of the storage slots are
+// checked against the app hash of a trusted block header.
+package proof
+
+import (
+	"fmt"
+	"math/big"
+
+	"cosmossdk.io/store/rootmulti"
+	storetypes "cosmossdk.io/store/types"
+	"github.com/cometbft/cometbft/crypto/merkle"
+	cmtcrypto "github.com/cometbft/cometbft/proto/tendermint/crypto"
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkaddress "github.com/cosmos/cosmos-sdk/types/address"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	rpctypes "github.com/evmos/ethermint/rpc/types"
+	ethermint "github.com/evmos/ethermint/types"
+	evmtypes "github.com/evmos/ethermint/x/evm/types"
+)
+
+// VerifyAccountResult verifies the result of `eth_getProof` against the app hash
+// committing the state of the queried height, i.e. the app hash of the next block header.
+//
+// The account proof is verified against the auth store, and the nonce and code hash
+// are compared with the proven account, which is decoded with the given codec. The balance
+// is compared with the proven bank balance of the evm denom of the params, plus the proven
+// fractional balance if the evm denom has a conversion factor. The storage hash and the
+// storage proofs are verified against the evm store.
+func VerifyAccountResult(cdc codec.BinaryCodec, appHash []byte, params evmtypes.Params, res *rpctypes.AccountResult) error {
+	accountKey := append(authtypes.AddressStoreKeyPrefix.Bytes(), res.Address.Bytes()...)
+	value, err := verifyProof(appHash, authtypes.StoreKey, accountKey, res.AccountProof)
+	if err != nil {
+		return fmt.Errorf("invalid account proof: %w", err)
+	}
+	if err := verifyAccount(cdc, value, res); err != nil {
+		return err
+	}
+	if err := verifyBalance(appHash, params, res); err != nil {
+		return err
+	}
+	value, err = verifyProof(appHash, evmtypes.StoreKey, evmtypes.StorageHashKey(res.Address), res.StorageHashProof)
+	if err != nil {
+		return fmt.Errorf("invalid storage hash proof: %w", err)
+	}
+	if storageHash := common.BytesToHash(value); res.StorageHash != storageHash {
+		return fmt.Errorf("storage hash mismatch: expected %s, proven %s", res.StorageHash, storageHash)
+	}
+
+	for _, storage := range res.StorageProof {
+		slot := common.HexToHash(storage.Key)
+		key := evmtypes.StateKey(res.Address, slot.Bytes())
+		value, err := verifyProof(appHash, evmtypes.StoreKey, key, storage.Proof)
+		if err != nil {
+			return fmt.Errorf("invalid storage proof for key %s: %w", storage.Key, err)
+		}
+
+		expected := new(big.Int)
+		if storage.Value != nil {
+			expected = storage.Value.ToInt()
+		}
+		if new(big.Int).SetBytes(value).Cmp(expected) != 0 {
+			return fmt.Errorf("storage value mismatch for key %s: expected %s, proven %s", storage.Key, expected, hexutil.Encode(value))
+		}
+	}
+	return nil
+}
+
+// verifyAccount compares the fields of the result with the proven account,
+// a nil value means the account doesn't exist.
+func verifyAccount(cdc codec.BinaryCodec, value []byte, res *rpctypes.AccountResult) error {
+	var (
+		nonce    uint64
+		codeHash = common.BytesToHash(evmtypes.EmptyCodeHash)
+	)
+	if value != nil {
+		var acc sdk.AccountI
+		if err := cdc.UnmarshalInterface(value, &acc); err != nil {
+			return fmt.Errorf("failed to decode proven account: %w", err)
+		}
+		nonce = acc.GetSequence()
+		if ethAcc, ok := acc.(ethermint.EthAccountI); ok {
+			codeHash = ethAcc.GetCodeHash()
+		}
+	}
+
+	if uint64(res.Nonce) != nonce {
+		return fmt.Errorf("nonce mismatch: expected %d, proven %d", res.Nonce, nonce)
+	}
+	if res.CodeHash != codeHash {
+		return fmt.Errorf("code hash mismatch: expected %s, proven %s", res.CodeHash, codeHash)
+	}
+	return nil
+}
+
+// verifyBalance compares the balance of the result with the proven bank balance of the
+// evm denom, converted to the evm decimals, plus the proven fractional balance.
+func verifyBalance(appHash []byte, params evmtypes.Params, res *rpctypes.AccountResult) error {
+	value, err := verifyProof(appHash, banktypes.StoreKey, BalanceKey(res.Address, params.EvmDenom), res.BalanceProof)
+	if err != nil {
+		return fmt.Errorf("invalid balance proof: %w", err)
+	}
+	balance := new(big.Int)
+	if value != nil {
+		amount, err := banktypes.BalanceValueCodec.Decode(value)
+		if err != nil {
+			return fmt.Errorf("failed to decode proven balance: %w", err)
+		}
+		balance = amount.BigInt()
+	}
+
+	if factor := params.EVMConversionFactor(); factor.Cmp(big.NewInt(1)) > 0 {
+		value, err := verifyProof(appHash, evmtypes.StoreKey, evmtypes.FractionalBalanceKey(res.Address), res.FractionalBalanceProof)
+		if err != nil {
+			return fmt.Errorf("invalid fractional balance proof: %w", err)
+		}
+		balance.Mul(balance, factor).Add(balance, new(big.Int).SetBytes(value))
+	}
+
+	expected := new(big.Int)
+	if res.Balance != nil {
+		expected = res.Balance.ToInt()
+	}
+	if expected.Cmp(balance) != 0 {
+		return fmt.Errorf("balance mismatch: expected %s, proven %s", expected, balance)
+	}
+	return nil
+}
+
+// BalanceKey returns the key of the bank balance of an address in a denom.
+func BalanceKey(address common.Address, denom string) []byte {
+	key := append(banktypes.BalancesPrefix.Bytes(), sdkaddress.MustLengthPrefix(address.Bytes())...)
+	return append(key, denom...)
+}
+
+// verifyProof rebuilds the proof operations of a store key from the hex encoded
+// proofs returned by the json rpc, and verifies them against the app hash.
+// It returns the proven value, or nil if the proof is an absence proof.
+func verifyProof(appHash []byte, storeName string, key []byte, hexProofs []string) ([]byte, error) {
+	if len(hexProofs) != 2 {
+		return nil, fmt.Errorf("expected 2 proof operations, got %d", len(hexProofs))
+	}
+	iavlData, err := hexutil.Decode(hexProofs[0])
+	if err != nil {
+		return nil, err
+	}
+	storeData, err := hexutil.Decode(hexProofs[1])
+	if err != nil {
+		return nil, err
+	}
+
+	proofOps := &cmtcrypto.ProofOps{
+		Ops: []cmtcrypto.ProofOp{
+			{Type: storetypes.ProofOpIAVLCommitment, Key: key, Data: iavlData},
+			{Type: storetypes.ProofOpSimpleMerkleCommitment, Key: []byte(storeName), Data: storeData},
+		},
+	}
+
+	op, err := storetypes.CommitmentOpDecoder(proofOps.Ops[0])
+	if err != nil {
+		return nil, err
+	}
+	commitmentOp, ok := op.(storetypes.CommitmentOp)
+	if !ok {
+		return nil, fmt.Errorf("unexpected proof operation %T", op)
+	}
+
+	keyPath := merkle.KeyPath{}.
+		AppendKey([]byte(storeName), merkle.KeyEncodingURL).
+		AppendKey(key, merkle.KeyEncodingHex).
+		String()
+
+	prt := rootmulti.DefaultProofRuntime()
+	if exist := commitmentOp.Proof.GetExist(); exist != nil {
+		if err := prt.VerifyValue(proofOps, appHash, keyPath, exist.Value); err != nil {
+			return nil, err
+		}
+		return exist.Value, nil
+	}
+	if err := prt.VerifyAbsence(proofOps, appHash, keyPath); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
@@ -0,0 +1,284 @@
+package proof
+
+import (
+	"math/big"
+	"testing"
+
+	"cosmossdk.io/log"
+	sdkmath "cosmossdk.io/math"
+	"cosmossdk.io/store/metrics"
+	"cosmossdk.io/store/rootmulti"
+	storetypes "cosmossdk.io/store/types"
+	dbm "github.com/cosmos/cosmos-db"
+	"github.com/cosmos/cosmos-sdk/codec"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/evmos/ethermint/encoding"
+	rpctypes "github.com/evmos/ethermint/rpc/types"
+	"github.com/evmos/ethermint/tests"
+	ethermint "github.com/evmos/ethermint/types"
+	evmtypes "github.com/evmos/ethermint/x/evm/types"
+)
+
+const testDenom = "aphoton"
+
+type testChain struct {
+	cdc     codec.Codec
+	store   *rootmulti.Store
+	appHash []byte
+}
+
+// testBalance is the bank and fractional balances of an account in the test chain.
+type testBalance struct {
+	bank, fractional *big.Int
+}
+
+func setupTestChain(
+	t *testing.T,
+	accounts []ethermint.EthAccount,
+	balances map[common.Address]testBalance,
+	storage map[common.Address]map[common.Hash]common.Hash,
+) *testChain {
+	encodingConfig := encoding.MakeTestEncodingConfig()
+	authtypes.RegisterInterfaces(encodingConfig.InterfaceRegistry)
+
+	accKey := storetypes.NewKVStoreKey(authtypes.StoreKey)
+	bankKey := storetypes.NewKVStoreKey(banktypes.StoreKey)
+	evmKey := storetypes.NewKVStoreKey(evmtypes.StoreKey)
+	store := rootmulti.NewStore(dbm.NewMemDB(), log.NewNopLogger(), metrics.NewNoOpMetrics())
+	store.MountStoreWithDB(accKey, storetypes.StoreTypeIAVL, nil)
+	store.MountStoreWithDB(bankKey, storetypes.StoreTypeIAVL, nil)
+	store.MountStoreWithDB(evmKey, storetypes.StoreTypeIAVL, nil)
+	require.NoError(t, store.LoadLatestVersion())
+
+	accStore := store.GetKVStore(accKey)
+	for i := range accounts {
+		bz, err := encodingConfig.Codec.MarshalInterface(&accounts[i])
+		require.NoError(t, err)
+		key := append(authtypes.AddressStoreKeyPrefix.Bytes(), accounts[i].GetAddress().Bytes()...)
+		accStore.Set(key, bz)
+	}
+	bankStore := store.GetKVStore(bankKey)
+	evmStore := store.GetKVStore(evmKey)
+	for addr, balance := range balances {
+		bz, err := banktypes.BalanceValueCodec.Encode(sdkmath.NewIntFromBigInt(balance.bank))
+		require.NoError(t, err)
+		bankStore.Set(BalanceKey(addr, testDenom), bz)
+		if balance.fractional != nil {
+			evmStore.Set(evmtypes.FractionalBalanceKey(addr), balance.fractional.Bytes())
+		}
+	}
+	for addr, slots := range storage {
+		for key, value := range slots {
+			evmStore.Set(evmtypes.StateKey(addr, key.Bytes()), value.Bytes())
+		}
+		evmStore.Set(evmtypes.StorageHashKey(addr), evmtypes.StorageHash(slots).Bytes())
+	}
+
+	commitID := store.Commit()
+	return &testChain{cdc: encodingConfig.Codec, store: store, appHash: commitID.Hash}
+}
+
+// proof mimics the hex encoded proofs returned by `eth_getProof`
+func (c *testChain) proof(t *testing.T, storeName string, key []byte) []string {
+	res, err := c.store.Query(&storetypes.RequestQuery{
+		Path:   "/" + storeName + "/key",
+		Data:   key,
+		Height: c.store.LastCommitID().Version,
+		Prove:  true,
+	})
+	require.NoError(t, err)
+
+	proofs := make([]string, len(res.ProofOps.Ops))
+	for i, op := range res.ProofOps.Ops {
+		proofs[i] = hexutil.Encode(op.Data)
+	}
+	return proofs
+}
+
+func TestVerifyAccountResult(t *testing.T) {
+	addr := tests.GenerateAddress()
+	missingAddr := tests.GenerateAddress()
+	codeHash := crypto.Keccak256Hash([]byte("code"))
+	account := ethermint.EthAccount{
+		BaseAccount: authtypes.NewBaseAccount(addr.Bytes(), nil, 0, 5),
+		CodeHash:    codeHash.Hex(),
+	}
+	slot, value := common.BigToHash(big.NewInt(1)), common.BigToHash(big.NewInt(42))
+	emptySlot := common.BigToHash(big.NewInt(2))
+
+	params := evmtypes.Params{EvmDenom: testDenom, ConversionFactor: 1e12}
+	balance := new(big.Int).Add(big.NewInt(3e12), big.NewInt(7))
+
+	chain := setupTestChain(t,
+		[]ethermint.EthAccount{account},
+		map[common.Address]testBalance{addr: {bank: big.NewInt(3), fractional: big.NewInt(7)}},
+		map[common.Address]map[common.Hash]common.Hash{addr: {slot: value}},
+	)
+
+	accountResult := func() *rpctypes.AccountResult {
+		return &rpctypes.AccountResult{
+			Address:                addr,
+			AccountProof:           chain.proof(t, authtypes.StoreKey, append(authtypes.AddressStoreKeyPrefix.Bytes(), addr.Bytes()...)),
+			Balance:                (*hexutil.Big)(balance),
+			BalanceProof:           chain.proof(t, banktypes.StoreKey, BalanceKey(addr, testDenom)),
+			FractionalBalanceProof: chain.proof(t, evmtypes.StoreKey, evmtypes.FractionalBalanceKey(addr)),
+			CodeHash:               codeHash,
+			Nonce:                  5,
+			StorageHash:            evmtypes.StorageHash(map[common.Hash]common.Hash{slot: value}),
+			StorageHashProof:       chain.proof(t, evmtypes.StoreKey, evmtypes.StorageHashKey(addr)),
+			StorageProof: []rpctypes.StorageResult{
+				{
+					Key:   slot.Hex(),
+					Value: (*hexutil.Big)(value.Big()),
+					Proof: chain.proof(t, evmtypes.StoreKey, evmtypes.StateKey(addr, slot.Bytes())),
+				},
+				{
+					Key:   emptySlot.Hex(),
+					Value: (*hexutil.Big)(big.NewInt(0)),
+					Proof: chain.proof(t, evmtypes.StoreKey, evmtypes.StateKey(addr, emptySlot.Bytes())),
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		malleate func(*rpctypes.AccountResult)
+		expPass  bool
+	}{
+		{
+			"pass - existing account and slots",
+			func(*rpctypes.AccountResult) {},
+			true,
+		},
+		{
+			"pass - missing account",
+			func(res *rpctypes.AccountResult) {
+				res.Address = missingAddr
+				res.AccountProof = chain.proof(t, authtypes.StoreKey, append(authtypes.AddressStoreKeyPrefix.Bytes(), missingAddr.Bytes()...))
+				res.Balance = (*hexutil.Big)(big.NewInt(0))
+				res.BalanceProof = chain.proof(t, banktypes.StoreKey, BalanceKey(missingAddr, testDenom))
+				res.FractionalBalanceProof = chain.proof(t, evmtypes.StoreKey, evmtypes.FractionalBalanceKey(missingAddr))
+				res.CodeHash = common.BytesToHash(evmtypes.EmptyCodeHash)
+				res.Nonce = 0
+				res.StorageHash = common.Hash{}
+				res.StorageHashProof = chain.proof(t, evmtypes.StoreKey, evmtypes.StorageHashKey(missingAddr))
+				res.StorageProof = nil
+			},
+			true,
+		},
+		{
+			"fail - wrong nonce",
+			func(res *rpctypes.AccountResult) {
+				res.Nonce = 6
+			},
+			false,
+		},
+		{
+			"fail - wrong balance",
+			func(res *rpctypes.AccountResult) {
+				res.Balance = (*hexutil.Big)(new(big.Int).Add(balance, big.NewInt(1)))
+			},
+			false,
+		},
+		{
+			"fail - balance without the fractional balance",
+			func(res *rpctypes.AccountResult) {
+				res.Balance = (*hexutil.Big)(big.NewInt(3e12))
+			},
+			false,
+		},
+		{
+			"fail - missing fractional balance proof",
+			func(res *rpctypes.AccountResult) {
+				res.FractionalBalanceProof = nil
+			},
+			false,
+		},
+		{
+			"fail - balance proof of another denom",
+			func(res *rpctypes.AccountResult) {
+				res.BalanceProof = chain.proof(t, banktypes.StoreKey, BalanceKey(addr, "stake"))
+			},
+			false,
+		},
+		{
+			"fail - wrong code hash",
+			func(res *rpctypes.AccountResult) {
+				res.CodeHash = common.Hash{}
+			},
+			false,
+		},
+		{
+			"fail - wrong storage hash",
+			func(res *rpctypes.AccountResult) {
+				res.StorageHash = evmtypes.StorageHash(map[common.Hash]common.Hash{slot: common.BigToHash(big.NewInt(43))})
+			},
+			false,
+		},
+		{
+			"fail - missing storage hash proof",
+			func(res *rpctypes.AccountResult) {
+				res.StorageHashProof = nil
+			},
+			false,
+		},
+		{
+			"fail - wrong storage value",
+			func(res *rpctypes.AccountResult) {
+				res.StorageProof[0].Value = (*hexutil.Big)(big.NewInt(43))
+			},
+			false,
+		},
+		{
+			"fail - existing slot claimed empty",
+			func(res *rpctypes.AccountResult) {
+				res.StorageProof[0].Value = (*hexutil.Big)(big.NewInt(0))
+			},
+			false,
+		},
+		{
+			"fail - proof of another key",
+			func(res *rpctypes.AccountResult) {
+				res.StorageProof[1].Proof = res.StorageProof[0].Proof
+			},
+			false,
+		},
+		{
+			"fail - missing proof",
+			func(res *rpctypes.AccountResult) {
+				res.AccountProof = []string{""}
+			},
+			false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := accountResult()
+			tc.malleate(res)
+
+			err := VerifyAccountResult(chain.cdc, chain.appHash, params, res)
+			if tc.expPass {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+
+	// the fractional balance isn't proven without conversion factor
+	res := accountResult()
+	res.Balance = (*hexutil.Big)(big.NewInt(3))
+	res.FractionalBalanceProof = nil
+	require.NoError(t, VerifyAccountResult(chain.cdc, chain.appHash, evmtypes.Params{EvmDenom: testDenom}, res))
+
+	// the proofs don't verify against another app hash
+	require.Error(t, VerifyAccountResult(chain.cdc, crypto.Keccak256([]byte("app hash")), params, accountResult()))
+}
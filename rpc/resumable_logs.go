@@ -0,0 +1,403 @@
+// Copyright 2021 Evmos Foundation
+// This file is part of Evmos' Ethermint library.
+//
+// The Ethermint library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Ethermint library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Ethermint library. If not, see https://github.com/evmos/ethermint/blob/main/LICENSE
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+
+	tmtypes "github.com/cometbft/cometbft/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/evmos/ethermint/rpc/ethereum/pubsub"
+	rpcfilters "github.com/evmos/ethermint/rpc/namespaces/ethereum/eth/filters"
+	"github.com/evmos/ethermint/rpc/types"
+	ethermint "github.com/evmos/ethermint/types"
+	evmtypes "github.com/evmos/ethermint/x/evm/types"
+)
+
+// logCursor is the position of a log in the chain. The logs of the resumable logs
+// subscriptions carry their cursor, a client resumes a subscription after the last
+// log it received with `["logs", {"cursor": "0x..."}]`.
+type logCursor struct {
+	BlockNumber uint64
+	LogIndex    uint64
+}
+
+// newLogCursor returns the cursor of a log.
+func newLogCursor(log *ethtypes.Log) logCursor {
+	return logCursor{BlockNumber: log.BlockNumber, LogIndex: uint64(log.Index)}
+}
+
+// parseLogCursor parses a cursor encoded by String.
+func parseLogCursor(s string) (logCursor, error) {
+	bz, err := hexutil.Decode(s)
+	if err != nil || len(bz) != 16 {
+		return logCursor{}, errors.Errorf("invalid cursor %s", s)
+	}
+	return logCursor{
+		BlockNumber: sdk.BigEndianToUint64(bz[:8]),
+		LogIndex:    sdk.BigEndianToUint64(bz[8:]),
+	}, nil
+}
+
+// String encodes the cursor as the hex of the big endian block number and log index.
+func (c logCursor) String() string {
+	return hexutil.Encode(append(sdk.Uint64ToBigEndian(c.BlockNumber), sdk.Uint64ToBigEndian(c.LogIndex)...))
+}
+
+// before checks if the log comes after the cursor.
+func (c logCursor) before(log *ethtypes.Log) bool {
+	return log.BlockNumber > c.BlockNumber ||
+		(log.BlockNumber == c.BlockNumber && uint64(log.Index) > c.LogIndex)
+}
+
+// logsReplay is the start of the replay of a resumable logs subscription, either a
+// block or the cursor of the last log received by the client.
+type logsReplay struct {
+	fromBlock types.BlockNumber
+	cursor    *logCursor
+}
+
+// maxPendingLogEvents is the max number of live logs events buffered by a resumable logs
+// subscription while the replayed logs are sent. The client is dropped if the buffer is
+// full, it resumes the subscription from the cursor of the last log it received.
+const maxPendingLogEvents = 1024
+
+// errPendingLogsOverflow is returned when the live logs buffer of a subscription is full.
+var errPendingLogsOverflow = errors.New("too many pending live logs")
+
+// replayedLogs are the logs of the replayed blocks up to a block.
+type replayedLogs struct {
+	logs []*ethtypes.Log
+	to   int64
+	err  error
+}
+
+// liveLogs buffers the logs of the live events of a subscription, up to
+// maxPendingLogEvents events. The events bus drops the events of the subscribers
+// not ready to receive them, the events are drained into the buffer as they come.
+type liveLogs struct {
+	ch       chan []*ethtypes.Log
+	overflow chan struct{}
+}
+
+func newLiveLogs() *liveLogs {
+	return &liveLogs{
+		ch:       make(chan []*ethtypes.Log, maxPendingLogEvents),
+		overflow: make(chan struct{}),
+	}
+}
+
+// push buffers the logs of an event, it returns false and signals the overflow if the
+// buffer is full.
+func (l *liveLogs) push(logs []*ethtypes.Log) bool {
+	select {
+	case l.ch <- logs:
+		return true
+	default:
+		close(l.overflow)
+		return false
+	}
+}
+
+// subscribeResumableLogs streams the historical logs from the replay start, found with
+// the same filters as `eth_getLogs`, then switches to the live logs. The live logs are
+// subscribed before the chain head of the replay is known, they're buffered during the
+// replay and the ones of the replayed blocks are dropped, so no log is missed nor sent
+// twice. CometBFT blocks are final, so the replayed logs are never reorged.
+func (api *pubSubAPI) subscribeResumableLogs(
+	wsConn *wsConn,
+	subID rpc.ID,
+	crit filters.FilterCriteria,
+	replay logsReplay,
+	ready <-chan struct{},
+) (pubsub.UnsubscribeFunc, error) {
+	sub, unsubFn, err := api.events.SubscribeLogs(crit)
+	if err != nil {
+		api.logger.Error("failed to subscribe logs", "error", err.Error())
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	live := newLiveLogs()
+	go api.drainLogs(ctx, sub, crit, live)
+
+	replayCh := make(chan replayedLogs)
+	go api.replayLogs(ctx, wsConn.client, crit, replay, ready, replayCh)
+
+	go func() {
+		defer cancel()
+
+		var cursor logCursor
+		if replay.cursor != nil {
+			cursor = *replay.cursor
+		}
+
+		// send writes the logs after the cursor, it closes the peer on failure
+		send := func(logs []*ethtypes.Log) bool {
+			for _, ethLog := range logs {
+				if !cursor.before(ethLog) {
+					continue
+				}
+				cursor = newLogCursor(ethLog)
+
+				result, err := jsonWithFields(ethLog, map[string]interface{}{"cursor": cursor.String()})
+				if err != nil {
+					api.logger.Error("failed to encode log", "error", err.Error())
+					return false
+				}
+				res := &SubscriptionNotification{
+					Jsonrpc: "2.0",
+					Method:  "eth_subscription",
+					Params: &SubscriptionResult{
+						Subscription: subID,
+						Result:       result,
+					},
+				}
+				if err := wsConn.WriteJSON(res); err != nil {
+					api.logger.Debug("error writing log, will drop peer", "error", err.Error())
+					try(func() {
+						if err != websocket.ErrCloseSent {
+							_ = wsConn.Close()
+						}
+					}, api.logger, "closing websocket peer sub")
+					return false
+				}
+			}
+			return true
+		}
+
+		if err := handoverLogs(ctx, replayCh, live, send); err != nil {
+			// the client resumes the subscription from the cursor of the last log
+			api.logger.Error("failed to stream logs, will drop peer", "subscription-id", subID, "error", err.Error())
+			try(func() { _ = wsConn.Close() }, api.logger, "closing websocket peer sub")
+		}
+	}()
+
+	return func() {
+		cancel()
+		unsubFn()
+	}, nil
+}
+
+// handoverLogs sends the replayed logs, then the live logs of the blocks after the
+// replay. The live logs are buffered until the replay is done. It returns when the
+// context is done, a channel is closed or a send fails.
+func handoverLogs(
+	ctx context.Context,
+	replayCh <-chan replayedLogs,
+	live *liveLogs,
+	send func(logs []*ethtypes.Log) bool,
+) error {
+	var (
+		// nil during the replay
+		liveCh     <-chan []*ethtypes.Log
+		replayedTo int64
+	)
+	for {
+		select {
+		case res, ok := <-replayCh:
+			if !ok {
+				// the replay is done, flush the live logs of the next blocks
+				replayCh = nil
+				liveCh = live.ch
+				continue
+			}
+			if res.err != nil {
+				return res.err
+			}
+			replayedTo = res.to
+			if !send(res.logs) {
+				return nil
+			}
+		case logs, ok := <-liveCh:
+			if !ok {
+				return nil
+			}
+			if !send(afterBlock(logs, replayedTo)) {
+				return nil
+			}
+		case <-live.overflow:
+			return errPendingLogsOverflow
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// drainLogs buffers the logs of the live events of the subscription matching the
+// criteria, the buffer channel is closed when the events channel is.
+func (api *pubSubAPI) drainLogs(ctx context.Context, sub *rpcfilters.Subscription, crit filters.FilterCriteria, live *liveLogs) {
+	ch := sub.Event()
+	errCh := sub.Err()
+	for {
+		select {
+		case event, ok := <-ch:
+			if !ok {
+				close(live.ch)
+				return
+			}
+
+			dataTx, ok := event.Data.(tmtypes.EventDataTx)
+			if !ok {
+				api.logger.Debug("event data type mismatch", "type", fmt.Sprintf("%T", event.Data))
+				continue
+			}
+
+			txResponse, err := evmtypes.DecodeTxResponse(dataTx.TxResult.Result.Data)
+			if err != nil {
+				api.logger.Error("failed to decode tx response", "error", err.Error())
+				continue
+			}
+
+			logs := rpcfilters.FilterLogs(evmtypes.LogsToEthereum(txResponse.Logs), nil, nil, crit.Addresses, crit.Topics)
+			if len(logs) > 0 && !live.push(logs) {
+				return
+			}
+		case err, ok := <-errCh:
+			if !ok {
+				close(live.ch)
+				return
+			}
+			api.logger.Debug("dropping Logs WebSocket subscription", "subscription-id", sub.ID(), "error", err.Error())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// replayLogs replays the logs from the replay start to the chain head once the
+// subscription is sent to the client. The range is split by the block range cap of
+// `eth_getLogs`, each part is charged to the rate limit of the client as an
+// `eth_getLogs` request. The channel is closed when the replay is done.
+func (api *pubSubAPI) replayLogs(
+	ctx context.Context,
+	client string,
+	crit filters.FilterCriteria,
+	replay logsReplay,
+	ready <-chan struct{},
+	replayCh chan<- replayedLogs,
+) {
+	select {
+	case <-ready:
+	case <-ctx.Done():
+		return
+	}
+
+	publish := func(res replayedLogs) bool {
+		select {
+		case replayCh <- res:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	head, err := api.backend.BlockNumber()
+	if err != nil {
+		publish(replayedLogs{err: errors.Wrap(err, "failed to get the chain head")})
+		return
+	}
+
+	from := int64(replay.fromBlock)
+	switch {
+	case replay.cursor != nil:
+		from = int64(replay.cursor.BlockNumber) //#nosec G115
+	case from < 0:
+		// latest or pending
+		from = int64(head) //#nosec G115
+	}
+	from = max(from, 1)
+	// the live logs of the blocks before the start are dropped
+	if !publish(replayedLogs{to: from - 1}) {
+		return
+	}
+
+	blockRange := max(int64(api.backend.RPCBlockRangeCap()), 1)
+	cost := api.limiter.Cost("eth_getLogs")
+
+	// replayRange publishes the logs of the blocks [from, to]. The ranges with more
+	// logs than the logs cap are split, the logs of a single block are bounded by the
+	// block gas limit, they're replayed without cap.
+	var replayRange func(from, to int64) bool
+	replayRange = func(from, to int64) bool {
+		if err := api.limiter.wait(ctx, client, cost); err != nil {
+			return false
+		}
+		logsCap := int(api.backend.RPCLogsCap())
+		if from == to {
+			logsCap = math.MaxInt32
+		}
+		filter := rpcfilters.NewRangeFilter(api.logger, api.backend, from, to, crit.Addresses, crit.Topics)
+		logs, err := filter.Logs(ctx, logsCap, blockRange)
+		var limitErr ethermint.LogsLimitError
+		if errors.As(err, &limitErr) && from < to {
+			mid := from + (to-from)/2
+			return replayRange(from, mid) && replayRange(mid+1, to)
+		}
+		if err != nil {
+			publish(replayedLogs{err: errors.Wrapf(err, "failed to replay the logs of blocks %d to %d", from, to)})
+			return false
+		}
+		return publish(replayedLogs{logs: logs, to: to})
+	}
+
+	for from <= int64(head) { //#nosec G115
+		to := min(from+blockRange-1, int64(head)) //#nosec G115
+		if !replayRange(from, to) {
+			return
+		}
+		from = to + 1
+	}
+	close(replayCh)
+}
+
+// afterBlock returns the logs of the blocks after a block.
+func afterBlock(logs []*ethtypes.Log, block int64) []*ethtypes.Log {
+	var result []*ethtypes.Log
+	for _, ethLog := range logs {
+		if int64(ethLog.BlockNumber) > block { //#nosec G115
+			result = append(result, ethLog)
+		}
+	}
+	return result
+}
+
+// jsonWithFields returns the json fields of a value with extra fields.
+func jsonWithFields(v interface{}, fields map[string]interface{}) (map[string]interface{}, error) {
+	bz, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(bz, &result); err != nil {
+		return nil, err
+	}
+	for key, value := range fields {
+		result[key] = value
+	}
+	return result, nil
+}
@@ -0,0 +1,96 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogCursor(t *testing.T) {
+	cursor := logCursor{BlockNumber: 258, LogIndex: 3}
+	require.Equal(t, "0x00000000000001020000000000000003", cursor.String())
+
+	parsed, err := parseLogCursor(cursor.String())
+	require.NoError(t, err)
+	require.Equal(t, cursor, parsed)
+
+	for _, invalid := range []string{"", "0x", "0x0102", "00000000000001020000000000000003", "0x000000000000010200000000000000030"} {
+		_, err := parseLogCursor(invalid)
+		require.Error(t, err, invalid)
+	}
+
+	require.False(t, cursor.before(&ethtypes.Log{BlockNumber: 257, Index: 9}))
+	require.False(t, cursor.before(&ethtypes.Log{BlockNumber: 258, Index: 3}))
+	require.True(t, cursor.before(&ethtypes.Log{BlockNumber: 258, Index: 4}))
+	require.True(t, cursor.before(&ethtypes.Log{BlockNumber: 259, Index: 0}))
+	require.Equal(t, logCursor{BlockNumber: 259, LogIndex: 1}, newLogCursor(&ethtypes.Log{BlockNumber: 259, Index: 1}))
+}
+
+func TestAfterBlock(t *testing.T) {
+	logs := []*ethtypes.Log{{BlockNumber: 1}, {BlockNumber: 2}, {BlockNumber: 2, Index: 1}, {BlockNumber: 3}}
+	require.Equal(t, logs[1:], afterBlock(logs, 1))
+	require.Equal(t, logs, afterBlock(logs, 0))
+	require.Empty(t, afterBlock(logs, 3))
+	require.Empty(t, afterBlock(nil, 0))
+}
+
+func TestHandoverLogs(t *testing.T) {
+	blockLogs := func(block uint64) []*ethtypes.Log {
+		return []*ethtypes.Log{{BlockNumber: block}, {BlockNumber: block, Index: 1}}
+	}
+
+	live := newLiveLogs()
+	replayCh := make(chan replayedLogs)
+	var sent []*ethtypes.Log
+	send := func(logs []*ethtypes.Log) bool {
+		sent = append(sent, logs...)
+		return true
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errCh := make(chan error, 1)
+	go func() { errCh <- handoverLogs(ctx, replayCh, live, send) }()
+
+	// the live logs of the replayed blocks are buffered and dropped
+	replayCh <- replayedLogs{to: 1}
+	require.True(t, live.push(blockLogs(2)))
+	replayCh <- replayedLogs{logs: blockLogs(2), to: 2}
+	require.True(t, live.push(blockLogs(3)))
+	replayCh <- replayedLogs{logs: blockLogs(3), to: 3}
+	require.True(t, live.push(blockLogs(4)))
+	close(replayCh)
+
+	// the live logs of the next blocks are sent once the replay is done
+	require.True(t, live.push(blockLogs(5)))
+	close(live.ch)
+	require.NoError(t, <-errCh)
+
+	var expected []*ethtypes.Log
+	for block := uint64(2); block <= 5; block++ {
+		expected = append(expected, blockLogs(block)...)
+	}
+	require.Equal(t, expected, sent)
+}
+
+func TestHandoverLogsOverflow(t *testing.T) {
+	live := newLiveLogs()
+	for i := 0; i < maxPendingLogEvents; i++ {
+		require.True(t, live.push([]*ethtypes.Log{{BlockNumber: 2}}))
+	}
+	require.False(t, live.push([]*ethtypes.Log{{BlockNumber: 2}}))
+
+	// the client is dropped while the replay is running
+	replayCh := make(chan replayedLogs)
+	err := handoverLogs(context.Background(), replayCh, live, func([]*ethtypes.Log) bool { return true })
+	require.ErrorIs(t, err, errPendingLogsOverflow)
+}
+
+func TestHandoverLogsReplayError(t *testing.T) {
+	replayCh := make(chan replayedLogs, 1)
+	replayCh <- replayedLogs{err: context.DeadlineExceeded}
+	err := handoverLogs(context.Background(), replayCh, newLiveLogs(), func([]*ethtypes.Log) bool { return true })
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
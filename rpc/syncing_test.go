@@ -0,0 +1,58 @@
+package rpc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"cosmossdk.io/log"
+	tmrpctypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/evmos/ethermint/rpc/backend/mocks"
+	"github.com/evmos/ethermint/rpc/types"
+)
+
+func TestSyncingPoller(t *testing.T) {
+	tmClient := mocks.NewClient(t)
+	status := &tmrpctypes.ResultStatus{}
+	status.SyncInfo.CatchingUp = true
+	status.SyncInfo.LatestBlockHeight = 5
+	tmClient.On("Status", mock.Anything).Return(status, nil)
+	tmClient.On("DumpConsensusState", mock.Anything).Return(nil, errors.New("unavailable"))
+
+	tracker := types.NewStateSyncTracker()
+	tracker.SnapshotAccepted(4)
+	tracker.ChunkApplied()
+	poller := newSyncingPoller(client.Context{Client: tmClient}, tracker, log.NewNopLogger())
+
+	// the subscribers share the poller
+	subs := []<-chan *types.SyncProgress{poller.subscribe("a"), poller.subscribe("b")}
+	stop := poller.stop
+	expected := &types.SyncProgress{CurrentBlock: 5, HighestBlock: 5, PulledStates: 1, KnownStates: 4}
+	for _, ch := range subs {
+		select {
+		case progress := <-ch:
+			require.Equal(t, expected, progress)
+		case <-time.After(2 * syncingPollInterval):
+			t.Fatal("no sync progress polled")
+		}
+	}
+
+	poller.unsubscribe("a")
+	select {
+	case <-stop:
+		t.Fatal("poller stopped with a subscriber left")
+	default:
+	}
+	poller.unsubscribe("b")
+	poller.unsubscribe("b")
+	require.Empty(t, poller.subscribers)
+	select {
+	case <-stop:
+	default:
+		t.Fatal("poller not stopped")
+	}
+}
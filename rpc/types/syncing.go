@@ -0,0 +1,134 @@
+// Copyright 2021 Evmos Foundation
+// This file is part of Evmos' Ethermint library.
+//
+// The Ethermint library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Ethermint library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Ethermint library. If not, see https://github.com/evmos/ethermint/blob/main/LICENSE
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+
+	tmrpcclient "github.com/cometbft/cometbft/rpc/client"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// SyncProgress is the `eth_syncing` result of a node catching up with the network.
+type SyncProgress struct {
+	StartingBlock hexutil.Uint64 `json:"startingBlock"`
+	CurrentBlock  hexutil.Uint64 `json:"currentBlock"`
+	HighestBlock  hexutil.Uint64 `json:"highestBlock"`
+	// PulledStates and KnownStates count the state sync snapshot chunks
+	// applied by the application and announced by the accepted snapshot.
+	PulledStates hexutil.Uint64 `json:"pulledStates"`
+	KnownStates  hexutil.Uint64 `json:"knownStates"`
+}
+
+// SyncingResult is the notification of the `syncing` websocket subscription
+// when the node starts syncing or makes progress.
+type SyncingResult struct {
+	Syncing bool          `json:"syncing"`
+	Status  *SyncProgress `json:"status"`
+}
+
+// StateSyncTracker tracks the restoration of the state sync snapshot. It is updated
+// by the ABCI snapshot calls of the application running in the same process, and
+// read by the JSON-RPC servers.
+type StateSyncTracker struct {
+	knownChunks  atomic.Uint64
+	pulledChunks atomic.Uint64
+}
+
+// NewStateSyncTracker creates a tracker without snapshot.
+func NewStateSyncTracker() *StateSyncTracker {
+	return &StateSyncTracker{}
+}
+
+// SnapshotAccepted resets the state sync progress to the chunks of a newly accepted
+// snapshot.
+func (t *StateSyncTracker) SnapshotAccepted(chunks uint32) {
+	t.pulledChunks.Store(0)
+	t.knownChunks.Store(uint64(chunks))
+}
+
+// ChunkApplied records a snapshot chunk applied by the application.
+func (t *StateSyncTracker) ChunkApplied() {
+	t.pulledChunks.Add(1)
+}
+
+// chunks returns the applied and known chunks of the snapshot, 0 without tracker.
+func (t *StateSyncTracker) chunks() (pulled, known uint64) {
+	if t == nil {
+		return 0, 0
+	}
+	return t.pulledChunks.Load(), t.knownChunks.Load()
+}
+
+// GetSyncProgress returns the sync progress of the node, or nil if it's not
+// catching up with the network. The state sync progress is the one of the tracker,
+// which is nil if the application doesn't run in the same process.
+func GetSyncProgress(goCtx context.Context, clientCtx client.Context, tracker *StateSyncTracker) (*SyncProgress, error) {
+	status, err := clientCtx.Client.Status(goCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !status.SyncInfo.CatchingUp {
+		return nil, nil
+	}
+
+	currentBlock := status.SyncInfo.LatestBlockHeight
+	pulled, known := tracker.chunks()
+	return &SyncProgress{
+		StartingBlock: hexutil.Uint64(status.SyncInfo.EarliestBlockHeight),
+		CurrentBlock:  hexutil.Uint64(currentBlock),
+		HighestBlock:  hexutil.Uint64(max(currentBlock, peersHighestBlock(goCtx, clientCtx))),
+		PulledStates:  hexutil.Uint64(pulled),
+		KnownStates:   hexutil.Uint64(known),
+	}, nil
+}
+
+// peerState is the part of the consensus peer state holding the height of the peer.
+type peerState struct {
+	RoundState struct {
+		Height int64 `json:"height,string"`
+	} `json:"round_state"`
+}
+
+// peersHighestBlock returns the highest block committed by the peers of the node.
+// The consensus state of a peer is the height it is voting on, so the latest
+// block of the peer is the previous one. It returns 0 if the peer states are
+// not available.
+func peersHighestBlock(goCtx context.Context, clientCtx client.Context) int64 {
+	tmrpcClient, ok := clientCtx.Client.(tmrpcclient.NetworkClient)
+	if !ok {
+		return 0
+	}
+
+	res, err := tmrpcClient.DumpConsensusState(goCtx)
+	if err != nil {
+		return 0
+	}
+
+	var highest int64
+	for _, peer := range res.Peers {
+		var state peerState
+		if err := json.Unmarshal(peer.PeerState, &state); err != nil {
+			continue
+		}
+		highest = max(highest, state.RoundState.Height-1)
+	}
+	return highest
+}
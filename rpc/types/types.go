@@ -21,20 +21,34 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/evmos/ethermint/x/evm/statedb"
+	"github.com/evmos/ethermint/x/evm/tracers"
+	evmtypes "github.com/evmos/ethermint/x/evm/types"
 )
 
 // Copied the Account and StorageResult types since they are registered under an
 // internal pkg on geth.
 
-// AccountResult struct for account proof
+// AccountResult struct for account proof. The proofs are IAVL proofs of the Cosmos
+// stores, verified against the app hash by the rpc/proof package, not Merkle Patricia
+// proofs. They're extended with fields unknown to the ethereum clients:
+//   - balanceProof proves the bank balance of the evm denom, and fractionalBalanceProof
+//     the sub-unit remainder kept in the evm store when the evm denom has a conversion
+//     factor, the balance isn't part of the auth account.
+//   - storageHashProof proves the storage hash of the evm store, the sum of the hashes
+//     of the account slots rather than the root of a storage trie.
 type AccountResult struct {
-	Address      common.Address  `json:"address"`
-	AccountProof []string        `json:"accountProof"`
-	Balance      *hexutil.Big    `json:"balance"`
-	CodeHash     common.Hash     `json:"codeHash"`
-	Nonce        hexutil.Uint64  `json:"nonce"`
-	StorageHash  common.Hash     `json:"storageHash"`
-	StorageProof []StorageResult `json:"storageProof"`
+	Address                common.Address  `json:"address"`
+	AccountProof           []string        `json:"accountProof"`
+	Balance                *hexutil.Big    `json:"balance"`
+	BalanceProof           []string        `json:"balanceProof"`
+	FractionalBalanceProof []string        `json:"fractionalBalanceProof,omitempty"`
+	CodeHash               common.Hash     `json:"codeHash"`
+	Nonce                  hexutil.Uint64  `json:"nonce"`
+	StorageHash            common.Hash     `json:"storageHash"`
+	StorageHashProof       []string        `json:"storageHashProof"`
+	StorageProof           []StorageResult `json:"storageProof"`
 }
 
 // StorageResult defines the format for storage proof return
@@ -68,21 +82,11 @@ type RPCTransaction struct {
 }
 
 // StateOverride is the collection of overridden accounts.
-type StateOverride map[common.Address]OverrideAccount
+type StateOverride = statedb.StateOverride
 
 // OverrideAccount indicates the overriding fields of account during the execution of
 // a message call.
-// Note, state and stateDiff can't be specified at the same time. If state is
-// set, message execution will only use the data in the given state. Otherwise
-// if statDiff is set, all diff will be applied first and then execute the call
-// message.
-type OverrideAccount struct {
-	Nonce     *hexutil.Uint64              `json:"nonce"`
-	Code      *hexutil.Bytes               `json:"code"`
-	Balance   **hexutil.Big                `json:"balance"`
-	State     *map[common.Hash]common.Hash `json:"state"`
-	StateDiff *map[common.Hash]common.Hash `json:"stateDiff"`
-}
+type OverrideAccount = statedb.OverrideAccount
 
 type FeeHistoryResult struct {
 	OldestBlock  *hexutil.Big     `json:"oldestBlock"`
@@ -97,6 +101,44 @@ type SignTransactionResult struct {
 	Tx  *ethtypes.Transaction `json:"tx"`
 }
 
+// AccessListResult returns an optional accesslist
+// It's the result of the `eth_createAccessList` RPC call.
+// It contains an error if the transaction itself failed.
+type AccessListResult struct {
+	Accesslist *ethtypes.AccessList `json:"accessList"`
+	Error      string               `json:"error,omitempty"`
+	GasUsed    hexutil.Uint64       `json:"gasUsed"`
+}
+
+// TraceFilterArgs are the arguments of the `trace_filter` RPC call. The block range
+// defaults to the latest block, and the traces match any address when the address
+// lists are empty.
+type TraceFilterArgs struct {
+	FromBlock   *BlockNumber     `json:"fromBlock"`
+	ToBlock     *BlockNumber     `json:"toBlock"`
+	FromAddress []common.Address `json:"fromAddress"`
+	ToAddress   []common.Address `json:"toAddress"`
+	After       *uint64          `json:"after"`
+	Count       *uint64          `json:"count"`
+}
+
+// TraceResults is the result of the `trace_replayTransaction` RPC call. The
+// results of the trace types which were not requested are null.
+type TraceResults struct {
+	Output    hexutil.Bytes           `json:"output"`
+	StateDiff interface{}             `json:"stateDiff"`
+	Trace     []tracers.FlatCallTrace `json:"trace"`
+	VMTrace   interface{}             `json:"vmTrace"`
+}
+
+// TraceCallConfig is the config of the `debug_traceCall` RPC call, it extends the
+// trace config with the state and block overrides of the traced call.
+type TraceCallConfig struct {
+	evmtypes.TraceConfig
+	StateOverrides *StateOverride           `json:"stateOverrides"`
+	BlockOverrides *evmtypes.BlockOverrides `json:"blockOverrides"`
+}
+
 type OneFeeHistory struct {
 	BaseFee, NextBaseFee *big.Int   // base fee for each block
 	Reward               []*big.Int // each element of the array will have the tip provided to miners for the percentile given
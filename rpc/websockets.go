@@ -24,7 +24,9 @@ import (
 	"math/big"
 	"net"
 	"net/http"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/gorilla/mux"
@@ -37,9 +39,11 @@ import (
 	"github.com/ethereum/go-ethereum/rpc"
 
 	"cosmossdk.io/log"
+	tmrpctypes "github.com/cometbft/cometbft/rpc/core/types"
 	rpcclient "github.com/cometbft/cometbft/rpc/jsonrpc/client"
 	tmtypes "github.com/cometbft/cometbft/types"
 
+	"github.com/evmos/ethermint/rpc/backend"
 	"github.com/evmos/ethermint/rpc/ethereum/pubsub"
 	rpcfilters "github.com/evmos/ethermint/rpc/namespaces/ethereum/eth/filters"
 	"github.com/evmos/ethermint/rpc/types"
@@ -47,6 +51,10 @@ import (
 	evmtypes "github.com/evmos/ethermint/x/evm/types"
 )
 
+// syncingPollInterval is the interval between two sync status polls of the
+// `syncing` subscription.
+const syncingPollInterval = time.Second
+
 type WebsocketsServer interface {
 	Start()
 }
@@ -85,10 +93,20 @@ type websocketsServer struct {
 	certFile string
 	keyFile  string
 	api      *pubSubAPI
+	limiter  *RequestLimiter
+	metrics  requestMetrics
 	logger   log.Logger
 }
 
-func NewWebsocketsServer(clientCtx client.Context, logger log.Logger, tmWSClient *rpcclient.WSClient, cfg *config.Config) WebsocketsServer {
+func NewWebsocketsServer(
+	clientCtx client.Context,
+	logger log.Logger,
+	tmWSClient *rpcclient.WSClient,
+	evmBackend backend.EVMBackend,
+	cfg *config.Config,
+	limiter *RequestLimiter,
+	syncTracker *types.StateSyncTracker,
+) WebsocketsServer {
 	logger = logger.With("api", "websocket-server")
 	_, port, _ := net.SplitHostPort(cfg.JSONRPC.Address)
 
@@ -97,8 +115,15 @@ func NewWebsocketsServer(clientCtx client.Context, logger log.Logger, tmWSClient
 		wsAddr:   cfg.JSONRPC.WsAddress,
 		certFile: cfg.TLS.CertificatePath,
 		keyFile:  cfg.TLS.KeyPath,
-		api:      newPubSubAPI(clientCtx, logger, tmWSClient),
-		logger:   logger,
+		api:      newPubSubAPI(clientCtx, logger, tmWSClient, evmBackend, limiter, syncTracker),
+		limiter:  limiter,
+		metrics: requestMetrics{
+			logger:        logger,
+			slowThreshold: cfg.JSONRPC.SlowRequestThreshold,
+			// the other methods are forwarded to the rpc server, which records them
+			methods: map[string]bool{"eth_subscribe": true, "eth_unsubscribe": true},
+		},
+		logger: logger,
 	}
 }
 
@@ -138,8 +163,9 @@ func (s *websocketsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	s.readLoop(&wsConn{
-		mux:  new(sync.Mutex),
-		conn: conn,
+		mux:    new(sync.Mutex),
+		conn:   conn,
+		client: s.limiter.clientIP(r),
 	})
 }
 
@@ -159,6 +185,8 @@ func (s *websocketsServer) sendErrResponse(wsConn *wsConn, msg string) {
 type wsConn struct {
 	conn *websocket.Conn
 	mux  *sync.Mutex
+	// client is the key of the client in the rate limits
+	client string
 }
 
 func (w *wsConn) WriteJSON(v interface{}) error {
@@ -199,6 +227,12 @@ func (s *websocketsServer) readLoop(wsConn *wsConn) {
 			return
 		}
 
+		msgs, id := parseRequest(mb)
+		if limitErr := s.limiter.checkRequest(wsConn.client, msgs, isBatch(mb)); limitErr != nil {
+			_ = wsConn.WriteJSON(limitErrorResponse(id, limitErr))
+			continue
+		}
+
 		if isBatch(mb) {
 			if err := s.tcpGetAndSendResponse(wsConn, mb); err != nil {
 				s.sendErrResponse(wsConn, err.Error())
@@ -232,6 +266,7 @@ func (s *websocketsServer) readLoop(wsConn *wsConn) {
 			continue
 		}
 
+		start := time.Now()
 		switch method {
 		case "eth_subscribe":
 			params, ok := s.getParamsAndCheckValid(msg, wsConn)
@@ -240,12 +275,16 @@ func (s *websocketsServer) readLoop(wsConn *wsConn) {
 			}
 
 			subID := rpc.NewID()
-			unsubFn, err := s.api.subscribe(wsConn, subID, params)
+			// closed once the subscription id is sent, before the replayed logs
+			ready := make(chan struct{})
+			unsubFn, err := s.api.subscribe(wsConn, subID, params, ready)
 			if err != nil {
+				s.metrics.observe(transportWS, method, errCodeInvalidRequest, len(mb), 0, time.Since(start))
 				s.sendErrResponse(wsConn, err.Error())
 				continue
 			}
 			subscriptions[subID] = unsubFn
+			s.metrics.record(transportWS, mb, nil, time.Since(start))
 
 			res := &SubscriptionResponseJSON{
 				Jsonrpc: "2.0",
@@ -253,7 +292,9 @@ func (s *websocketsServer) readLoop(wsConn *wsConn) {
 				Result:  subID,
 			}
 
-			if err := wsConn.WriteJSON(res); err != nil {
+			err = wsConn.WriteJSON(res)
+			close(ready)
+			if err != nil {
 				break
 			}
 		case "eth_unsubscribe":
@@ -274,6 +315,7 @@ func (s *websocketsServer) readLoop(wsConn *wsConn) {
 				delete(subscriptions, subID)
 				unsubFn()
 			}
+			s.metrics.record(transportWS, mb, nil, time.Since(start))
 
 			res := &SubscriptionResponseJSON{
 				Jsonrpc: "2.0",
@@ -318,6 +360,8 @@ func (s *websocketsServer) tcpGetAndSendResponse(wsConn *wsConn, mb []byte) erro
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	// the limits are already applied with the address of the websocket client
+	req.Header.Set(forwardedHeader, forwardToken)
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
@@ -345,35 +389,168 @@ type pubSubAPI struct {
 	events    *rpcfilters.EventSystem
 	logger    log.Logger
 	clientCtx client.Context
+	backend   backend.EVMBackend
+	limiter   *RequestLimiter
+	syncing   *syncingPoller
 }
 
 // newPubSubAPI creates an instance of the ethereum PubSub API.
-func newPubSubAPI(clientCtx client.Context, logger log.Logger, tmWSClient *rpcclient.WSClient) *pubSubAPI {
+func newPubSubAPI(
+	clientCtx client.Context,
+	logger log.Logger,
+	tmWSClient *rpcclient.WSClient,
+	evmBackend backend.EVMBackend,
+	limiter *RequestLimiter,
+	syncTracker *types.StateSyncTracker,
+) *pubSubAPI {
 	logger = logger.With("module", "websocket-client")
 	return &pubSubAPI{
 		events:    rpcfilters.NewEventSystem(logger, tmWSClient),
 		logger:    logger,
 		clientCtx: clientCtx,
+		backend:   evmBackend,
+		limiter:   limiter,
+		syncing:   newSyncingPoller(clientCtx, syncTracker, logger),
+	}
+}
+
+// newHeadsOptions are the options of the `newHeads` subscription, e.g.
+// `["newHeads", {"includeReceipts": true}]`.
+type newHeadsOptions struct {
+	// IncludeReceipts adds the receipts of the block transactions to the headers.
+	IncludeReceipts bool `json:"includeReceipts"`
+}
+
+// pendingTxsFilter is the filter of the `newPendingTransactions` subscription, it's
+// either `true` for the full transactions, like geth, or an object, e.g.
+// `["newPendingTransactions", {"fromAddress": ["0x..."], "toAddress": "0x...", "fullTx": true}]`.
+// A transaction matches if its sender is in the from addresses or its recipient is in the
+// to addresses, all the transactions match without addresses.
+type pendingTxsFilter struct {
+	FromAddresses []common.Address
+	ToAddresses   []common.Address
+	FullTx        bool
+}
+
+// matches checks if a transaction matches the filter.
+func (f pendingTxsFilter) matches(tx *types.RPCTransaction) bool {
+	if len(f.FromAddresses) == 0 && len(f.ToAddresses) == 0 {
+		return true
+	}
+	for _, address := range f.FromAddresses {
+		if tx.From == address {
+			return true
+		}
+	}
+	if tx.To == nil {
+		return false
 	}
+	for _, address := range f.ToAddresses {
+		if *tx.To == address {
+			return true
+		}
+	}
+	return false
+}
+
+// parseNewHeadsOptions parses the extra param of the `newHeads` subscription.
+func parseNewHeadsOptions(extra interface{}) (newHeadsOptions, error) {
+	var opts newHeadsOptions
+	params, ok := extra.(map[string]interface{})
+	if !ok {
+		return opts, errors.New("invalid newHeads options")
+	}
+	if params["includeReceipts"] != nil {
+		if opts.IncludeReceipts, ok = params["includeReceipts"].(bool); !ok {
+			return opts, errors.New("invalid includeReceipts; must be a boolean")
+		}
+	}
+	return opts, nil
 }
 
-func (api *pubSubAPI) subscribe(wsConn *wsConn, subID rpc.ID, params []interface{}) (pubsub.UnsubscribeFunc, error) {
+// parsePendingTxsFilter parses the extra param of the `newPendingTransactions` subscription.
+func parsePendingTxsFilter(extra interface{}) (pendingTxsFilter, error) {
+	var (
+		filter pendingTxsFilter
+		err    error
+	)
+	if fullTx, ok := extra.(bool); ok {
+		filter.FullTx = fullTx
+		return filter, nil
+	}
+
+	params, ok := extra.(map[string]interface{})
+	if !ok {
+		return filter, errors.New("invalid pending transactions filter; must be a boolean or an object")
+	}
+	if params["fullTx"] != nil {
+		if filter.FullTx, ok = params["fullTx"].(bool); !ok {
+			return filter, errors.New("invalid fullTx; must be a boolean")
+		}
+	}
+	if filter.FromAddresses, err = parseAddresses(params["fromAddress"]); err != nil {
+		return filter, errors.Wrap(err, "invalid fromAddress")
+	}
+	if filter.ToAddresses, err = parseAddresses(params["toAddress"]); err != nil {
+		return filter, errors.Wrap(err, "invalid toAddress")
+	}
+	return filter, nil
+}
+
+// parseAddresses parses an address or an array of addresses.
+func parseAddresses(value interface{}) ([]common.Address, error) {
+	var values []interface{}
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		values = []interface{}{v}
+	case []interface{}:
+		values = v
+	default:
+		return nil, errors.New("must be address or array of addresses")
+	}
+
+	addresses := make([]common.Address, len(values))
+	for i, v := range values {
+		address, ok := v.(string)
+		if !ok || !common.IsHexAddress(address) {
+			return nil, errors.Errorf("invalid address %v", v)
+		}
+		addresses[i] = common.HexToAddress(address)
+	}
+	return addresses, nil
+}
+
+func (api *pubSubAPI) subscribe(wsConn *wsConn, subID rpc.ID, params []interface{}, ready <-chan struct{}) (pubsub.UnsubscribeFunc, error) {
 	method, ok := params[0].(string)
 	if !ok {
 		return nil, errors.New("invalid parameters")
 	}
 
+	var err error
 	switch method {
 	case "newHeads":
-		// TODO: handle extra params
-		return api.subscribeNewHeads(wsConn, subID)
+		var opts newHeadsOptions
+		if len(params) > 1 {
+			if opts, err = parseNewHeadsOptions(params[1]); err != nil {
+				return nil, err
+			}
+		}
+		return api.subscribeNewHeads(wsConn, subID, opts)
 	case "logs":
 		if len(params) > 1 {
-			return api.subscribeLogs(wsConn, subID, params[1])
+			return api.subscribeLogs(wsConn, subID, params[1], ready)
 		}
-		return api.subscribeLogs(wsConn, subID, nil)
+		return api.subscribeLogs(wsConn, subID, nil, ready)
 	case "newPendingTransactions":
-		return api.subscribePendingTransactions(wsConn, subID)
+		var filter pendingTxsFilter
+		if len(params) > 1 {
+			if filter, err = parsePendingTxsFilter(params[1]); err != nil {
+				return nil, err
+			}
+		}
+		return api.subscribePendingTransactions(wsConn, subID, filter)
 	case "syncing":
 		return api.subscribeSyncing(wsConn, subID)
 	default:
@@ -381,7 +558,7 @@ func (api *pubSubAPI) subscribe(wsConn *wsConn, subID rpc.ID, params []interface
 	}
 }
 
-func (api *pubSubAPI) subscribeNewHeads(wsConn *wsConn, subID rpc.ID) (pubsub.UnsubscribeFunc, error) {
+func (api *pubSubAPI) subscribeNewHeads(wsConn *wsConn, subID rpc.ID, opts newHeadsOptions) (pubsub.UnsubscribeFunc, error) {
 	sub, unsubFn, err := api.events.SubscribeNewBlocks()
 	if err != nil {
 		return nil, errors.Wrap(err, "error creating block filter")
@@ -404,7 +581,14 @@ func (api *pubSubAPI) subscribeNewHeads(wsConn *wsConn, subID rpc.ID) (pubsub.Un
 				}
 
 				baseFee := types.BaseFeeFromEvents(data.ResultFinalizeBlock.Events)
-				header := types.EthHeaderFromTendermint(data.Block.Header, ethtypes.Bloom{}, baseFee)
+				var result interface{} = types.EthHeaderFromTendermint(data.Block.Header, ethtypes.Bloom{}, baseFee)
+				if opts.IncludeReceipts {
+					result, err = api.headerWithReceipts(result, data)
+					if err != nil {
+						api.logger.Error("failed to build the block receipts", "height", data.Block.Height, "error", err.Error())
+						continue
+					}
+				}
 
 				// write to ws conn
 				res := &SubscriptionNotification{
@@ -412,7 +596,7 @@ func (api *pubSubAPI) subscribeNewHeads(wsConn *wsConn, subID rpc.ID) (pubsub.Un
 					Method:  "eth_subscription",
 					Params: &SubscriptionResult{
 						Subscription: subID,
-						Result:       header,
+						Result:       result,
 					},
 				}
 
@@ -438,6 +622,27 @@ func (api *pubSubAPI) subscribeNewHeads(wsConn *wsConn, subID rpc.ID) (pubsub.Un
 	return unsubFn, nil
 }
 
+// headerWithReceipts adds the receipts of the block transactions to the json fields of
+// the header. The receipts are built from the block results of the event, the block
+// may not be indexed yet.
+func (api *pubSubAPI) headerWithReceipts(header interface{}, data tmtypes.EventDataNewBlock) (map[string]interface{}, error) {
+	resBlock := &tmrpctypes.ResultBlock{BlockID: data.BlockID, Block: data.Block}
+	blockRes := &tmrpctypes.ResultBlockResults{
+		Height:                data.Block.Height,
+		TxsResults:            data.ResultFinalizeBlock.TxResults,
+		FinalizeBlockEvents:   data.ResultFinalizeBlock.Events,
+		ValidatorUpdates:      data.ResultFinalizeBlock.ValidatorUpdates,
+		ConsensusParamUpdates: data.ResultFinalizeBlock.ConsensusParamUpdates,
+		AppHash:               data.ResultFinalizeBlock.AppHash,
+	}
+	receipts, err := api.backend.ReceiptsFromBlockResults(resBlock, blockRes)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonWithFields(header, map[string]interface{}{"receipts": receipts})
+}
+
 func try(fn func(), l log.Logger, desc string) {
 	defer func() {
 		if x := recover(); x != nil {
@@ -455,8 +660,12 @@ func try(fn func(), l log.Logger, desc string) {
 	fn()
 }
 
-func (api *pubSubAPI) subscribeLogs(wsConn *wsConn, subID rpc.ID, extra interface{}) (pubsub.UnsubscribeFunc, error) {
+// subscribeLogs streams the logs matching the criteria. With a `fromBlock` or a `cursor`,
+// the historical logs are replayed first and the logs carry their cursor, see
+// subscribeResumableLogs.
+func (api *pubSubAPI) subscribeLogs(wsConn *wsConn, subID rpc.ID, extra interface{}, ready <-chan struct{}) (pubsub.UnsubscribeFunc, error) {
 	crit := filters.FilterCriteria{}
+	var replay *logsReplay
 
 	if extra != nil {
 		params, ok := extra.(map[string]interface{})
@@ -553,6 +762,36 @@ func (api *pubSubAPI) subscribeLogs(wsConn *wsConn, subID rpc.ID, extra interfac
 				crit.Topics[topicIdx] = subtopicsCollect
 			}
 		}
+
+		if params["fromBlock"] != nil {
+			fromBlock, ok := params["fromBlock"].(string)
+			if !ok {
+				return nil, errors.New("invalid fromBlock; must be a block number or tag")
+			}
+			replay = &logsReplay{}
+			if err := replay.fromBlock.UnmarshalJSON([]byte(strconv.Quote(fromBlock))); err != nil {
+				return nil, errors.Wrap(err, "invalid fromBlock")
+			}
+		}
+
+		if params["cursor"] != nil {
+			str, ok := params["cursor"].(string)
+			if !ok {
+				return nil, errors.New("invalid cursor; must be a string")
+			}
+			cursor, err := parseLogCursor(str)
+			if err != nil {
+				return nil, err
+			}
+			if replay == nil {
+				replay = &logsReplay{}
+			}
+			replay.cursor = &cursor
+		}
+	}
+
+	if replay != nil {
+		return api.subscribeResumableLogs(wsConn, subID, crit, *replay, ready)
 	}
 
 	sub, unsubFn, err := api.events.SubscribeLogs(crit)
@@ -619,7 +858,12 @@ func (api *pubSubAPI) subscribeLogs(wsConn *wsConn, subID rpc.ID, extra interfac
 	return unsubFn, nil
 }
 
-func (api *pubSubAPI) subscribePendingTransactions(wsConn *wsConn, subID rpc.ID) (pubsub.UnsubscribeFunc, error) {
+func (api *pubSubAPI) subscribePendingTransactions(wsConn *wsConn, subID rpc.ID, filter pendingTxsFilter) (pubsub.UnsubscribeFunc, error) {
+	chainID, err := api.backend.ChainID()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the chain id")
+	}
+
 	sub, unsubFn, err := api.events.SubscribePendingTxs()
 	if err != nil {
 		return nil, errors.Wrap(err, "error creating block filter: %s")
@@ -644,13 +888,27 @@ func (api *pubSubAPI) subscribePendingTransactions(wsConn *wsConn, subID rpc.ID)
 				}
 
 				for _, ethTx := range ethTxs {
+					rpcTx, err := types.NewTransactionFromMsg(ethTx, common.Hash{}, 0, 0, nil, chainID.ToInt())
+					if err != nil {
+						api.logger.Debug("failed to build the rpc transaction", "hash", ethTx.Hash, "error", err.Error())
+						continue
+					}
+					if !filter.matches(rpcTx) {
+						continue
+					}
+
+					var result interface{} = rpcTx.Hash
+					if filter.FullTx {
+						result = rpcTx
+					}
+
 					// write to ws conn
 					res := &SubscriptionNotification{
 						Jsonrpc: "2.0",
 						Method:  "eth_subscription",
 						Params: &SubscriptionResult{
 							Subscription: subID,
-							Result:       ethTx.Hash,
+							Result:       result,
 						},
 					}
 
@@ -677,8 +935,152 @@ func (api *pubSubAPI) subscribePendingTransactions(wsConn *wsConn, subID rpc.ID)
 	return unsubFn, nil
 }
 
-func (api *pubSubAPI) subscribeSyncing(_ *wsConn, _ rpc.ID) (pubsub.UnsubscribeFunc, error) {
-	return nil, errors.New("syncing subscription is not implemented")
+// syncingPoller polls the sync status of the node for the `syncing` subscriptions. A
+// single poller is shared by the subscriptions and runs while there's one, each poll is
+// bounded by the poll interval.
+type syncingPoller struct {
+	clientCtx   client.Context
+	syncTracker *types.StateSyncTracker
+	logger      log.Logger
+
+	mu          sync.Mutex
+	subscribers map[rpc.ID]chan *types.SyncProgress
+	stop        chan struct{}
+}
+
+func newSyncingPoller(clientCtx client.Context, syncTracker *types.StateSyncTracker, logger log.Logger) *syncingPoller {
+	return &syncingPoller{
+		clientCtx:   clientCtx,
+		syncTracker: syncTracker,
+		logger:      logger,
+		subscribers: make(map[rpc.ID]chan *types.SyncProgress),
+	}
+}
+
+// subscribe returns the channel of the polled sync progress, nil once the node is
+// synced. Only the latest progress is kept for a slow subscriber.
+func (p *syncingPoller) subscribe(subID rpc.ID) <-chan *types.SyncProgress {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ch := make(chan *types.SyncProgress, 1)
+	p.subscribers[subID] = ch
+	if len(p.subscribers) == 1 {
+		p.stop = make(chan struct{})
+		go p.run(p.stop)
+	}
+	return ch
+}
+
+// unsubscribe removes a subscriber, the poller stops with the last one.
+func (p *syncingPoller) unsubscribe(subID rpc.ID) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.subscribers[subID]; !ok {
+		return
+	}
+	delete(p.subscribers, subID)
+	if len(p.subscribers) == 0 {
+		close(p.stop)
+	}
+}
+
+// run polls the sync status until it's stopped.
+func (p *syncingPoller) run(stop chan struct{}) {
+	ticker := time.NewTicker(syncingPollInterval)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), syncingPollInterval)
+		progress, err := types.GetSyncProgress(ctx, p.clientCtx, p.syncTracker)
+		cancel()
+		if err != nil {
+			p.logger.Debug("failed to get sync progress", "error", err.Error())
+		} else {
+			p.broadcast(progress)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// broadcast sends the progress to the subscribers, replacing the progress not
+// received yet.
+func (p *syncingPoller) broadcast(progress *types.SyncProgress) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, ch := range p.subscribers {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- progress
+	}
+}
+
+// subscribeSyncing notifies the sync progress polled by the shared poller when the
+// node starts catching up with the network or makes progress, and false once it's
+// synced.
+func (api *pubSubAPI) subscribeSyncing(wsConn *wsConn, subID rpc.ID) (pubsub.UnsubscribeFunc, error) {
+	progressCh := api.syncing.subscribe(subID)
+	done := make(chan struct{})
+	var once sync.Once
+	unsubFn := func() {
+		once.Do(func() {
+			api.syncing.unsubscribe(subID)
+			close(done)
+		})
+	}
+
+	go func() {
+		var prev *types.SyncProgress
+		for {
+			var progress *types.SyncProgress
+			select {
+			case <-done:
+				return
+			case progress = <-progressCh:
+			}
+			if changed := (progress == nil) != (prev == nil) || (progress != nil && *progress != *prev); !changed {
+				continue
+			}
+			var result interface{} = false
+			if progress != nil {
+				result = &types.SyncingResult{Syncing: true, Status: progress}
+			}
+			prev = progress
+
+			// write to ws conn
+			res := &SubscriptionNotification{
+				Jsonrpc: "2.0",
+				Method:  "eth_subscription",
+				Params: &SubscriptionResult{
+					Subscription: subID,
+					Result:       result,
+				},
+			}
+
+			if err := wsConn.WriteJSON(res); err != nil {
+				api.logger.Debug("error writing sync progress, will drop peer", "error", err.Error())
+
+				try(func() {
+					if err != websocket.ErrCloseSent {
+						_ = wsConn.Close()
+					}
+				}, api.logger, "closing websocket peer sub")
+				unsubFn()
+				return
+			}
+		}
+	}()
+
+	return unsubFn, nil
 }
 
 // copy from github.com/ethereum/go-ethereum/rpc/json.go
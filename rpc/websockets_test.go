@@ -0,0 +1,97 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/evmos/ethermint/rpc/types"
+)
+
+func TestParsePendingTxsFilter(t *testing.T) {
+	addr1 := common.HexToAddress("0x57f96e6B86CdeFdB3d412547816a82E3E0EbF9D2")
+	addr2 := common.HexToAddress("0x775b87ef5D82ca211811C1a02CE0fE0CA3a455d7")
+
+	testCases := []struct {
+		name      string
+		extra     interface{}
+		expFilter pendingTxsFilter
+		expErr    string
+	}{
+		{"full tx flag", true, pendingTxsFilter{FullTx: true}, ""},
+		{"hashes flag", false, pendingTxsFilter{}, ""},
+		{"empty object", map[string]interface{}{}, pendingTxsFilter{}, ""},
+		{
+			"single addresses",
+			map[string]interface{}{"fullTx": true, "fromAddress": addr1.Hex(), "toAddress": addr2.Hex()},
+			pendingTxsFilter{FullTx: true, FromAddresses: []common.Address{addr1}, ToAddresses: []common.Address{addr2}},
+			"",
+		},
+		{
+			"array of addresses",
+			map[string]interface{}{"toAddress": []interface{}{addr1.Hex(), addr2.Hex()}},
+			pendingTxsFilter{ToAddresses: []common.Address{addr1, addr2}},
+			"",
+		},
+		{"invalid type", "true", pendingTxsFilter{}, "must be a boolean or an object"},
+		{"invalid fullTx", map[string]interface{}{"fullTx": "true"}, pendingTxsFilter{}, "invalid fullTx"},
+		{"invalid fromAddress", map[string]interface{}{"fromAddress": "0x01"}, pendingTxsFilter{}, "invalid fromAddress"},
+		{"invalid toAddress type", map[string]interface{}{"toAddress": 1}, pendingTxsFilter{}, "invalid toAddress"},
+		{
+			"invalid address in array",
+			map[string]interface{}{"toAddress": []interface{}{addr1.Hex(), 1}},
+			pendingTxsFilter{}, "invalid toAddress",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := parsePendingTxsFilter(tc.extra)
+			if tc.expErr != "" {
+				require.ErrorContains(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expFilter, filter)
+		})
+	}
+}
+
+func TestPendingTxsFilterMatches(t *testing.T) {
+	addr1 := common.HexToAddress("0x01")
+	addr2 := common.HexToAddress("0x02")
+	addr3 := common.HexToAddress("0x03")
+
+	testCases := []struct {
+		name   string
+		filter pendingTxsFilter
+		tx     *types.RPCTransaction
+		exp    bool
+	}{
+		{"no addresses", pendingTxsFilter{}, &types.RPCTransaction{From: addr1}, true},
+		{"from address", pendingTxsFilter{FromAddresses: []common.Address{addr1}}, &types.RPCTransaction{From: addr1, To: &addr3}, true},
+		{"to address", pendingTxsFilter{ToAddresses: []common.Address{addr2}}, &types.RPCTransaction{From: addr1, To: &addr2}, true},
+		{"contract creation", pendingTxsFilter{ToAddresses: []common.Address{addr2}}, &types.RPCTransaction{From: addr1}, false},
+		{
+			"no match",
+			pendingTxsFilter{FromAddresses: []common.Address{addr2}, ToAddresses: []common.Address{addr2}},
+			&types.RPCTransaction{From: addr1, To: &addr3},
+			false,
+		},
+	}
+	for _, tc := range testCases {
+		require.Equal(t, tc.exp, tc.filter.matches(tc.tx), tc.name)
+	}
+}
+
+func TestParseNewHeadsOptions(t *testing.T) {
+	opts, err := parseNewHeadsOptions(map[string]interface{}{"includeReceipts": true})
+	require.NoError(t, err)
+	require.True(t, opts.IncludeReceipts)
+
+	_, err = parseNewHeadsOptions(map[string]interface{}{"includeReceipts": "true"})
+	require.ErrorContains(t, err, "invalid includeReceipts")
+	_, err = parseNewHeadsOptions(true)
+	require.ErrorContains(t, err, "invalid newHeads options")
+}
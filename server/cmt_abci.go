@@ -6,14 +6,19 @@ import (
 	abci "github.com/cometbft/cometbft/abci/types"
 
 	servertypes "github.com/cosmos/cosmos-sdk/server/types"
+
+	rpctypes "github.com/evmos/ethermint/rpc/types"
 )
 
 type cometABCIWrapper struct {
-	app servertypes.ABCI
+	app         servertypes.ABCI
+	syncTracker *rpctypes.StateSyncTracker
 }
 
-func NewCometABCIWrapper(app servertypes.ABCI) abci.Application {
-	return cometABCIWrapper{app: app}
+// NewCometABCIWrapper wraps the application for CometBFT, the restoration of the
+// state sync snapshots is recorded in the tracker.
+func NewCometABCIWrapper(app servertypes.ABCI, syncTracker *rpctypes.StateSyncTracker) abci.Application {
+	return cometABCIWrapper{app: app, syncTracker: syncTracker}
 }
 
 func (w cometABCIWrapper) Info(_ context.Context, req *abci.RequestInfo) (*abci.ResponseInfo, error) {
@@ -61,7 +66,11 @@ func (w cometABCIWrapper) ListSnapshots(_ context.Context, req *abci.RequestList
 }
 
 func (w cometABCIWrapper) OfferSnapshot(_ context.Context, req *abci.RequestOfferSnapshot) (*abci.ResponseOfferSnapshot, error) {
-	return w.app.OfferSnapshot(req)
+	res, err := w.app.OfferSnapshot(req)
+	if err == nil && res.Result == abci.ResponseOfferSnapshot_ACCEPT {
+		w.syncTracker.SnapshotAccepted(req.Snapshot.Chunks)
+	}
+	return res, err
 }
 
 func (w cometABCIWrapper) LoadSnapshotChunk(_ context.Context, req *abci.RequestLoadSnapshotChunk) (*abci.ResponseLoadSnapshotChunk, error) {
@@ -69,5 +78,9 @@ func (w cometABCIWrapper) LoadSnapshotChunk(_ context.Context, req *abci.Request
 }
 
 func (w cometABCIWrapper) ApplySnapshotChunk(_ context.Context, req *abci.RequestApplySnapshotChunk) (*abci.ResponseApplySnapshotChunk, error) {
-	return w.app.ApplySnapshotChunk(req)
+	res, err := w.app.ApplySnapshotChunk(req)
+	if err == nil && res.Result == abci.ResponseApplySnapshotChunk_ACCEPT {
+		w.syncTracker.ChunkApplied()
+	}
+	return res, err
 }
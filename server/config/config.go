@@ -18,9 +18,11 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/netip"
 	"path"
 	"time"
 
+	"github.com/spf13/cast"
 	"github.com/spf13/viper"
 
 	"github.com/cometbft/cometbft/libs/strings"
@@ -40,6 +42,19 @@ const (
 	// DefaultJSONRPCWsAddress is the default address the JSON-RPC WebSocket server binds to.
 	DefaultJSONRPCWsAddress = "127.0.0.1:8546"
 
+	// DefaultRateLimitBurst is the default size of the token bucket of each client
+	DefaultRateLimitBurst = 100
+
+	// DefaultBatchRequestLimit is the default max number of requests in a batch
+	DefaultBatchRequestLimit = 1000
+
+	// DefaultMaxResponseBytes is the default max size of a response
+	DefaultMaxResponseBytes = 25 * 1000 * 1000
+
+	// DefaultJWTSecretPath is the default path of the jwt secret file of the authenticated JSON-RPC endpoint,
+	// relative to the node home directory.
+	DefaultJWTSecretPath = "config/jwtsecret"
+
 	// DefaultJsonRPCMetricsAddress is the default address the JSON-RPC Metrics server binds to.
 	DefaultJSONRPCMetricsAddress = "127.0.0.1:6065"
 
@@ -49,6 +64,18 @@ const (
 	// DefaultFixRevertGasRefundHeight is the default height at which to overwrite gas refund
 	DefaultFixRevertGasRefundHeight = 0
 
+	// IndexerBackendKV is the custom indexer backend on a KV db, using the same db backend as the main app
+	IndexerBackendKV = "kv"
+
+	// IndexerBackendSQL is the custom indexer backend on a SQL database
+	IndexerBackendSQL = "sql"
+
+	// DefaultIndexerBackend is the default custom indexer backend
+	DefaultIndexerBackend = IndexerBackendKV
+
+	// DefaultIndexerSQLDriver is the default database driver of the sql indexer backend
+	DefaultIndexerSQLDriver = "sqlite3"
+
 	DefaultMaxTxGasWanted = 0
 
 	DefaultGasCap uint64 = 25000000
@@ -138,6 +165,47 @@ type JSONRPCConfig struct {
 	MetricsAddress string `mapstructure:"metrics-address"`
 	// FixRevertGasRefundHeight defines the upgrade height for fix of revert gas refund logic when transaction reverted
 	FixRevertGasRefundHeight int64 `mapstructure:"fix-revert-gas-refund-height"`
+	// IndexerBackend defines the storage backend of the custom indexer, kv or sql.
+	IndexerBackend string `mapstructure:"indexer-backend"`
+	// IndexerSQLDriver defines the database driver of the sql indexer backend, sqlite3 or postgres.
+	IndexerSQLDriver string `mapstructure:"indexer-sql-driver"`
+	// IndexerSQLDSN defines the data source name of the sql indexer backend, the SQLite
+	// database defaults to the evmindexer.sqlite file of the data directory.
+	IndexerSQLDSN string `mapstructure:"indexer-sql-dsn"`
+	// AuthAddress defines the HTTP server of the JWT authenticated endpoint, disabled if empty.
+	AuthAddress string `mapstructure:"auth-address"`
+	// AuthAPI defines the JSON-RPC namespaces only served by the authenticated and IPC
+	// endpoints, these endpoints serve all the namespaces.
+	AuthAPI []string `mapstructure:"auth-api"`
+	// JWTSecret defines the path of the file with the hex encoded HS256 secret of the
+	// authenticated endpoint, relative to the node home directory if not absolute.
+	JWTSecret string `mapstructure:"jwt-secret"`
+	// RateLimit defines the cost units refilled per second in the token bucket of each
+	// client IP, the rate limit is disabled if 0.
+	RateLimit float64 `mapstructure:"rate-limit"`
+	// RateLimitBurst defines the size of the token bucket of each client IP.
+	RateLimitBurst int `mapstructure:"rate-limit-burst"`
+	// TrustedProxies defines the IPs or CIDRs of the reverse proxies of the JSON-RPC
+	// servers, the client IP of their requests is the one of the X-Forwarded-For header.
+	// The client IP is the remote address otherwise, so the clients behind an untrusted
+	// proxy share its token bucket.
+	TrustedProxies []string `mapstructure:"trusted-proxies"`
+	// BatchRequestLimit defines the max number of requests in a batch, unlimited if 0.
+	BatchRequestLimit int `mapstructure:"batch-request-limit"`
+	// MaxResponseBytes defines the max size of a response, unlimited if 0.
+	MaxResponseBytes int `mapstructure:"max-response-bytes"`
+	// MethodCosts defines the rate limit cost of the methods, 1 by default. A trailing `*`
+	// matches the methods with the prefix.
+	MethodCosts map[string]int `mapstructure:"method-costs"`
+	// SlowRequestThreshold defines the duration above which the requests are logged with
+	// their params, disabled if 0.
+	SlowRequestThreshold time.Duration `mapstructure:"slow-request-threshold"`
+	// IPCPath defines the unix socket of the IPC endpoint, relative to the node home
+	// directory if not absolute, disabled if empty.
+	IPCPath string `mapstructure:"ipc-path"`
+	// EnableGraphQL defines if the EIP-1767 GraphQL queries are served on the /graphql
+	// path of the JSON-RPC server.
+	EnableGraphQL bool `mapstructure:"enable-graphql"`
 }
 
 // TLSConfig defines the certificate and matching private key for the server.
@@ -217,7 +285,7 @@ func GetDefaultAPINamespaces() []string {
 
 // GetAPINamespaces returns the all the available JSON-RPC API namespaces.
 func GetAPINamespaces() []string {
-	return []string{"web3", "eth", "personal", "net", "txpool", "debug", "miner"}
+	return []string{"web3", "eth", "personal", "net", "txpool", "debug", "miner", "trace"}
 }
 
 // DefaultJSONRPCConfig returns an EVM config with the JSON-RPC API enabled by default
@@ -241,6 +309,29 @@ func DefaultJSONRPCConfig() *JSONRPCConfig {
 		EnableIndexer:            false,
 		MetricsAddress:           DefaultJSONRPCMetricsAddress,
 		FixRevertGasRefundHeight: DefaultFixRevertGasRefundHeight,
+		IndexerBackend:           DefaultIndexerBackend,
+		IndexerSQLDriver:         DefaultIndexerSQLDriver,
+		IndexerSQLDSN:            "",
+		AuthAddress:              "",
+		AuthAPI:                  []string{},
+		JWTSecret:                DefaultJWTSecretPath,
+		RateLimit:                0,
+		RateLimitBurst:           DefaultRateLimitBurst,
+		BatchRequestLimit:        DefaultBatchRequestLimit,
+		MaxResponseBytes:         DefaultMaxResponseBytes,
+		MethodCosts:              DefaultMethodCosts(),
+		SlowRequestThreshold:     0,
+		IPCPath:                  "",
+		EnableGraphQL:            false,
+	}
+}
+
+// DefaultMethodCosts returns the default rate limit cost of the expensive methods.
+func DefaultMethodCosts() map[string]int {
+	return map[string]int{
+		"debug_trace*": 20,
+		"trace_*":      20,
+		"eth_getLogs":  10,
 	}
 }
 
@@ -282,6 +373,19 @@ func (c JSONRPCConfig) Validate() error {
 		return errors.New("JSON-RPC HTTP idle timeout duration cannot be negative")
 	}
 
+	switch c.IndexerBackend {
+	case IndexerBackendKV:
+	case IndexerBackendSQL:
+		if c.IndexerSQLDriver != "sqlite3" && c.IndexerSQLDriver != "postgres" {
+			return fmt.Errorf("invalid JSON-RPC indexer sql driver '%s', expected 'sqlite3' or 'postgres'", c.IndexerSQLDriver)
+		}
+		if c.IndexerSQLDriver == "postgres" && c.IndexerSQLDSN == "" {
+			return errors.New("JSON-RPC indexer sql dsn is required for the postgres driver")
+		}
+	default:
+		return fmt.Errorf("invalid JSON-RPC indexer backend '%s', expected '%s' or '%s'", c.IndexerBackend, IndexerBackendKV, IndexerBackendSQL)
+	}
+
 	// check for duplicates
 	seenAPIs := make(map[string]bool)
 	for _, api := range c.API {
@@ -292,6 +396,52 @@ func (c JSONRPCConfig) Validate() error {
 		seenAPIs[api] = true
 	}
 
+	if c.RateLimit < 0 {
+		return errors.New("JSON-RPC rate limit cannot be negative")
+	}
+
+	if c.RateLimit > 0 && c.RateLimitBurst <= 0 {
+		return errors.New("JSON-RPC rate limit burst must be positive")
+	}
+
+	for _, proxy := range c.TrustedProxies {
+		if _, err := ParseTrustedProxy(proxy); err != nil {
+			return fmt.Errorf("invalid JSON-RPC trusted proxy '%s': %w", proxy, err)
+		}
+	}
+
+	if c.BatchRequestLimit < 0 {
+		return errors.New("JSON-RPC batch request limit cannot be negative")
+	}
+
+	if c.MaxResponseBytes < 0 {
+		return errors.New("JSON-RPC max response bytes cannot be negative")
+	}
+
+	if c.SlowRequestThreshold < 0 {
+		return errors.New("JSON-RPC slow request threshold cannot be negative")
+	}
+
+	for method, cost := range c.MethodCosts {
+		if cost <= 0 {
+			return fmt.Errorf("JSON-RPC cost of method '%s' must be positive", method)
+		}
+	}
+
+	if len(c.AuthAPI) > 0 && c.AuthAddress == "" && c.IPCPath == "" {
+		return errors.New("JSON-RPC auth-api requires the authenticated endpoint auth-address or the ipc-path")
+	}
+
+	if c.AuthAddress != "" && c.JWTSecret == "" {
+		return errors.New("JSON-RPC authenticated endpoint requires a jwt-secret file")
+	}
+
+	for _, api := range c.AuthAPI {
+		if !seenAPIs[api] {
+			return fmt.Errorf("authenticated API namespace '%s' is not enabled", api)
+		}
+	}
+
 	return nil
 }
 
@@ -351,6 +501,21 @@ func GetConfig(v *viper.Viper) (Config, error) {
 			EnableIndexer:            v.GetBool("json-rpc.enable-indexer"),
 			MetricsAddress:           v.GetString("json-rpc.metrics-address"),
 			FixRevertGasRefundHeight: v.GetInt64("json-rpc.fix-revert-gas-refund-height"),
+			IndexerBackend:           v.GetString("json-rpc.indexer-backend"),
+			IndexerSQLDriver:         v.GetString("json-rpc.indexer-sql-driver"),
+			IndexerSQLDSN:            v.GetString("json-rpc.indexer-sql-dsn"),
+			AuthAddress:              v.GetString("json-rpc.auth-address"),
+			AuthAPI:                  v.GetStringSlice("json-rpc.auth-api"),
+			JWTSecret:                v.GetString("json-rpc.jwt-secret"),
+			RateLimit:                v.GetFloat64("json-rpc.rate-limit"),
+			RateLimitBurst:           v.GetInt("json-rpc.rate-limit-burst"),
+			TrustedProxies:           v.GetStringSlice("json-rpc.trusted-proxies"),
+			BatchRequestLimit:        v.GetInt("json-rpc.batch-request-limit"),
+			MaxResponseBytes:         v.GetInt("json-rpc.max-response-bytes"),
+			MethodCosts:              getMethodCosts(v),
+			SlowRequestThreshold:     v.GetDuration("json-rpc.slow-request-threshold"),
+			IPCPath:                  v.GetString("json-rpc.ipc-path"),
+			EnableGraphQL:            v.GetBool("json-rpc.enable-graphql"),
 		},
 		TLS: TLSConfig{
 			CertificatePath: v.GetString("tls.certificate-path"),
@@ -384,3 +549,30 @@ func (c Config) ValidateBasic() error {
 
 	return c.Config.ValidateBasic()
 }
+
+// getMethodCosts parses the method costs of the config, the config keys are lower cased by
+// viper, so the methods are matched case insensitively. The default costs apply if the
+// key is missing, e.g. in the app.toml files of the previous versions.
+func getMethodCosts(v *viper.Viper) map[string]int {
+	if !v.IsSet("json-rpc.method-costs") {
+		return DefaultMethodCosts()
+	}
+	costs := make(map[string]int)
+	for method, cost := range v.GetStringMap("json-rpc.method-costs") {
+		costs[method] = cast.ToInt(cost)
+	}
+	return costs
+}
+
+// ParseTrustedProxy parses a trusted proxy IP or CIDR as a prefix.
+func ParseTrustedProxy(proxy string) (netip.Prefix, error) {
+	if prefix, err := netip.ParsePrefix(proxy); err == nil {
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(proxy)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"testing"
 
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
 )
 
@@ -11,4 +12,50 @@ func TestDefaultConfig(t *testing.T) {
 	require.True(t, cfg.JSONRPC.Enable)
 	require.Equal(t, cfg.JSONRPC.Address, DefaultJSONRPCAddress)
 	require.Equal(t, cfg.JSONRPC.WsAddress, DefaultJSONRPCWsAddress)
+	require.Equal(t, cfg.JSONRPC.IndexerBackend, DefaultIndexerBackend)
+	require.NoError(t, cfg.JSONRPC.Validate())
+}
+
+func TestJSONRPCConfigAuthAPI(t *testing.T) {
+	cfg := DefaultConfig().JSONRPC
+	cfg.AuthAPI = []string{"eth"}
+	require.Error(t, cfg.Validate())
+
+	// the auth-api namespaces are served by the IPC endpoint
+	cfg.IPCPath = "data/ethermint.ipc"
+	require.NoError(t, cfg.Validate())
+
+	cfg.AuthAPI = []string{"debug"}
+	require.Error(t, cfg.Validate())
+}
+
+func TestGetMethodCosts(t *testing.T) {
+	// the default costs apply without the key
+	v := viper.New()
+	require.Equal(t, DefaultMethodCosts(), getMethodCosts(v))
+
+	v.Set("json-rpc.method-costs", map[string]interface{}{"eth_call": 5, "debug_*": "20"})
+	require.Equal(t, map[string]int{"eth_call": 5, "debug_*": 20}, getMethodCosts(v))
+
+	// an empty table disables the default costs
+	v.Set("json-rpc.method-costs", map[string]interface{}{})
+	require.Empty(t, getMethodCosts(v))
+}
+
+func TestJSONRPCConfigTrustedProxies(t *testing.T) {
+	cfg := DefaultConfig().JSONRPC
+	cfg.TrustedProxies = []string{"10.0.0.1", "192.168.0.0/16", "::1", "fd00::/8"}
+	require.NoError(t, cfg.Validate())
+
+	for _, invalid := range []string{"proxy", "10.0.0.300", "10.0.0.0/33"} {
+		cfg.TrustedProxies = []string{invalid}
+		require.Error(t, cfg.Validate(), invalid)
+	}
+
+	prefix, err := ParseTrustedProxy("::ffff:10.0.0.1")
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.1/32", prefix.String())
+	prefix, err = ParseTrustedProxy("192.168.1.1/16")
+	require.NoError(t, err)
+	require.Equal(t, "192.168.0.0/16", prefix.String())
 }
@@ -47,7 +47,7 @@ address = "{{ .JSONRPC.Address }}"
 ws-address = "{{ .JSONRPC.WsAddress }}"
 
 # API defines a list of JSON-RPC namespaces that should be enabled
-# Example: "eth,txpool,personal,net,debug,web3"
+# Example: "eth,txpool,personal,net,debug,web3,trace"
 api = "{{range $index, $elmt := .JSONRPC.API}}{{if $index}},{{$elmt}}{{else}}{{$elmt}}{{end}}{{end}}"
 
 # GasCap sets a cap on gas that can be used in eth_call/estimateGas (0=infinite). Default: 25,000,000.
@@ -85,11 +85,64 @@ allow-unprotected-txs = {{ .JSONRPC.AllowUnprotectedTxs }}
 # for the server listener.
 max-open-connections = {{ .JSONRPC.MaxOpenConnections }}
 
+# AuthAddress defines the HTTP server address of the JWT authenticated endpoint, disabled if empty.
+# The endpoint serves all the enabled namespaces, to the callers with a HS256 token signed with the jwt secret.
+auth-address = "{{ .JSONRPC.AuthAddress }}"
+
+# AuthAPI defines the namespaces only served by the authenticated and IPC endpoints, e.g. ["personal", "debug", "miner"]
+auth-api = [{{range $index, $elmt := .JSONRPC.AuthAPI}}{{if $index}}, {{end}}"{{$elmt}}"{{end}}]
+
+# JWTSecret defines the path of the hex encoded jwt secret file, relative to the node home directory
+# if not absolute. A new secret is generated if the file doesn't exist.
+jwt-secret = "{{ .JSONRPC.JWTSecret }}"
+
+# RateLimit defines the cost units refilled per second in the token bucket of each client IP,
+# the requests are charged with the cost of their methods. The rate limit is disabled if 0.
+rate-limit = {{ .JSONRPC.RateLimit }}
+
+# RateLimitBurst defines the size of the token bucket of each client IP.
+rate-limit-burst = {{ .JSONRPC.RateLimitBurst }}
+
+# TrustedProxies defines the IPs or CIDRs of the reverse proxies of the JSON-RPC servers, the client IP
+# of their requests is the one of the X-Forwarded-For header. Otherwise the client IP is the remote
+# address, so the clients behind an untrusted proxy share its token bucket.
+trusted-proxies = [{{range $index, $elmt := .JSONRPC.TrustedProxies}}{{if $index}}, {{end}}"{{$elmt}}"{{end}}]
+
+# BatchRequestLimit defines the max number of requests in a batch (0=unlimited).
+batch-request-limit = {{ .JSONRPC.BatchRequestLimit }}
+
+# MaxResponseBytes defines the max size of a response in bytes (0=unlimited).
+max-response-bytes = {{ .JSONRPC.MaxResponseBytes }}
+
+# MethodCosts defines the rate limit cost of the methods, 1 by default.
+# A trailing '*' matches the methods with the prefix.
+method-costs = { {{- $first := true}}{{range $method, $cost := .JSONRPC.MethodCosts}}{{if not $first}},{{end}}{{$first = false}} "{{$method}}" = {{$cost}}{{end}} }
+
+# SlowRequestThreshold defines the duration above which the requests are logged with their params (0=disabled).
+slow-request-threshold = "{{ .JSONRPC.SlowRequestThreshold }}"
+
+# IPCPath defines the unix socket of the IPC endpoint, relative to the node home directory if not absolute,
+# e.g. "data/ethermint.ipc". The endpoint serves all the enabled namespaces to the local users, it's disabled if empty.
+ipc-path = "{{ .JSONRPC.IPCPath }}"
+
+# EnableGraphQL defines if the EIP-1767 GraphQL queries are served on the /graphql path of the JSON-RPC server.
+enable-graphql = {{ .JSONRPC.EnableGraphQL }}
+
 # EnableIndexer enables the custom transaction indexer for the EVM (ethereum transactions).
 enable-indexer = {{ .JSONRPC.EnableIndexer }}
 
+# IndexerBackend defines the storage backend of the custom indexer: kv | sql
+indexer-backend = "{{ .JSONRPC.IndexerBackend }}"
+
+# IndexerSQLDriver defines the database driver of the sql indexer backend: sqlite3 | postgres
+indexer-sql-driver = "{{ .JSONRPC.IndexerSQLDriver }}"
+
+# IndexerSQLDSN defines the data source name of the sql indexer backend,
+# the SQLite database defaults to the file data/evmindexer.sqlite if empty.
+indexer-sql-dsn = "{{ .JSONRPC.IndexerSQLDSN }}"
+
 # MetricsAddress defines the EVM Metrics server address to bind to. Pass --metrics in CLI to enable
-# Prometheus metrics path: /debug/metrics/prometheus
+# Prometheus metrics path: /debug/metrics/prometheus, and /metrics for the JSON-RPC requests metrics
 metrics-address = "{{ .JSONRPC.MetricsAddress }}"
 
 # Upgrade height for fix of revert gas refund logic when transaction reverted.
@@ -49,21 +49,34 @@ const (
 
 // JSON-RPC flags
 const (
-	JSONRPCEnable              = "json-rpc.enable"
-	JSONRPCAPI                 = "json-rpc.api"
-	JSONRPCAddress             = "json-rpc.address"
-	JSONWsAddress              = "json-rpc.ws-address"
-	JSONRPCGasCap              = "json-rpc.gas-cap"
-	JSONRPCEVMTimeout          = "json-rpc.evm-timeout"
-	JSONRPCTxFeeCap            = "json-rpc.txfee-cap"
-	JSONRPCFilterCap           = "json-rpc.filter-cap"
-	JSONRPCLogsCap             = "json-rpc.logs-cap"
-	JSONRPCBlockRangeCap       = "json-rpc.block-range-cap"
-	JSONRPCHTTPTimeout         = "json-rpc.http-timeout"
-	JSONRPCHTTPIdleTimeout     = "json-rpc.http-idle-timeout"
-	JSONRPCAllowUnprotectedTxs = "json-rpc.allow-unprotected-txs"
-	JSONRPCMaxOpenConnections  = "json-rpc.max-open-connections"
-	JSONRPCEnableIndexer       = "json-rpc.enable-indexer"
+	JSONRPCEnable               = "json-rpc.enable"
+	JSONRPCAPI                  = "json-rpc.api"
+	JSONRPCAddress              = "json-rpc.address"
+	JSONWsAddress               = "json-rpc.ws-address"
+	JSONRPCGasCap               = "json-rpc.gas-cap"
+	JSONRPCEVMTimeout           = "json-rpc.evm-timeout"
+	JSONRPCTxFeeCap             = "json-rpc.txfee-cap"
+	JSONRPCFilterCap            = "json-rpc.filter-cap"
+	JSONRPCLogsCap              = "json-rpc.logs-cap"
+	JSONRPCBlockRangeCap        = "json-rpc.block-range-cap"
+	JSONRPCHTTPTimeout          = "json-rpc.http-timeout"
+	JSONRPCHTTPIdleTimeout      = "json-rpc.http-idle-timeout"
+	JSONRPCAllowUnprotectedTxs  = "json-rpc.allow-unprotected-txs"
+	JSONRPCMaxOpenConnections   = "json-rpc.max-open-connections"
+	JSONRPCEnableIndexer        = "json-rpc.enable-indexer"
+	JSONRPCIndexerBackend       = "json-rpc.indexer-backend"
+	JSONRPCIndexerSQLDriver     = "json-rpc.indexer-sql-driver"
+	JSONRPCIndexerSQLDSN        = "json-rpc.indexer-sql-dsn"
+	JSONRPCAuthAddress          = "json-rpc.auth-address"
+	JSONRPCAuthAPI              = "json-rpc.auth-api"
+	JSONRPCJWTSecret            = "json-rpc.jwt-secret"
+	JSONRPCRateLimit            = "json-rpc.rate-limit"
+	JSONRPCRateLimitBurst       = "json-rpc.rate-limit-burst"
+	JSONRPCBatchRequestLimit    = "json-rpc.batch-request-limit"
+	JSONRPCMaxResponseBytes     = "json-rpc.max-response-bytes"
+	JSONRPCSlowRequestThreshold = "json-rpc.slow-request-threshold"
+	JSONRPCIPCPath              = "json-rpc.ipc-path"
+	JSONRPCEnableGraphQL        = "json-rpc.enable-graphql"
 	// JSONRPCEnableMetrics enables EVM RPC metrics server.
 	// Set to `metrics` which is hardcoded flag from go-ethereum.
 	// https://github.com/ethereum/go-ethereum/blob/master/metrics/metrics.go#L35-L55
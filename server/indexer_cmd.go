@@ -16,16 +16,41 @@
 package server
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
 
+	"cosmossdk.io/log"
+	abci "github.com/cometbft/cometbft/abci/types"
 	tmcfg "github.com/cometbft/cometbft/config"
 	sm "github.com/cometbft/cometbft/state"
 	tmstore "github.com/cometbft/cometbft/store"
+	tmtypes "github.com/cometbft/cometbft/types"
+	dbm "github.com/cosmos/cosmos-db"
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/server"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/evmos/ethermint/indexer"
+	"github.com/evmos/ethermint/server/config"
+)
+
+const (
+	flagWorkers = "workers"
+	flagFrom    = "from"
+	flagTo      = "to"
+
+	// progressInterval is the interval between two progress reports of the index commands.
+	progressInterval = 10 * time.Second
 )
 
 func NewIndexTxCmd() *cobra.Command {
@@ -38,96 +63,448 @@ func NewIndexTxCmd() *cobra.Command {
 
 		When start the node, the indexer start from the latest indexed block to avoid creating gap.
         Backward mode should be used most of the time, so the latest indexed block is always up-to-date.
+
+		The blocks are loaded concurrently by the workers, and indexed in the traverse order, so an
+		interrupted command resumes where it stopped when it's run again.
 		`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			serverCtx := server.GetServerContextFromCmd(cmd)
-			clientCtx, err := client.GetClientQueryContext(cmd)
-			if err != nil {
-				return err
-			}
-
 			direction := args[0]
 			if direction != "backward" && direction != "forward" {
 				return fmt.Errorf("unknown index direction, expect: backward|forward, got: %s", direction)
 			}
-
-			cfg := serverCtx.Config
-			home := cfg.RootDir
-			logger := serverCtx.Logger
-			idxDB, err := OpenIndexerDB(home, server.GetAppDBBackend(serverCtx.Viper))
+			workers, err := cmd.Flags().GetInt(flagWorkers)
 			if err != nil {
-				logger.Error("failed to open evm indexer DB", "error", err.Error())
 				return err
 			}
-			idxer := indexer.NewKVIndexer(idxDB, logger.With("module", "evmindex"), clientCtx)
 
-			// open local tendermint db, because the local rpc won't be available.
-			tmdb, err := tmcfg.DefaultDBProvider(&tmcfg.DBContext{ID: "blockstore", Config: cfg})
+			env, err := openIndexEnv(cmd)
 			if err != nil {
 				return err
 			}
-			blockStore := tmstore.NewBlockStore(tmdb)
-
-			stateDB, err := tmcfg.DefaultDBProvider(&tmcfg.DBContext{ID: "state", Config: cfg})
+			first, latest, err := indexedRange(env.idxer)
 			if err != nil {
 				return err
 			}
-			stateStore := sm.NewStore(stateDB, sm.StoreOptions{
-				DiscardABCIResponses: cfg.Storage.DiscardABCIResponses,
-			})
 
-			indexBlock := func(height int64) error {
-				blk := blockStore.LoadBlock(height)
-				if blk == nil {
-					return fmt.Errorf("block not found %d", height)
+			var start, count, step int64
+			switch direction {
+			case "backward":
+				if first == -1 {
+					// start from the latest block if indexer db is empty
+					first = env.blockStore.Height()
 				}
-				resBlk, err := stateStore.LoadLastFinalizeBlockResponse(height)
-				if err != nil {
-					return err
+				start, count, step = first-1, first-1, -1
+			case "forward":
+				if latest == -1 {
+					// start from genesis if empty
+					latest = 0
 				}
-				if err := idxer.IndexBlock(blk, resBlk); err != nil {
+				start, count, step = latest+1, env.blockStore.Height()-latest, 1
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			progress := newIndexProgress(env.logger, "indexed blocks", count)
+			return processBlocks(ctx, start, count, step, workers, env.loadBlock, func(data *blockData) error {
+				if err := env.idxer.IndexBlock(data.block, data.result); err != nil {
 					return err
 				}
-				fmt.Println(height)
+				progress.done(data.height)
 				return nil
+			})
+		},
+	}
+	cmd.Flags().Int(flagWorkers, runtime.NumCPU(), "Number of workers loading the blocks concurrently")
+	cmd.AddCommand(newVerifyIndexCmd())
+	return cmd
+}
+
+func newVerifyIndexCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify the indexed eth txs against the block store",
+		Long: `Verify the indexed eth txs against the block store, the txs, the logs and the bloom of every block of
+		the range are indexed again in memory and compared with the ones of the indexer db. The blocks with missing txs
+		or logs are reported as gaps of blocks, and the command fails if anything is missing or differs. The range
+		defaults to the indexed blocks.
+		`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			workers, err := cmd.Flags().GetInt(flagWorkers)
+			if err != nil {
+				return err
+			}
+			from, err := cmd.Flags().GetInt64(flagFrom)
+			if err != nil {
+				return err
+			}
+			to, err := cmd.Flags().GetInt64(flagTo)
+			if err != nil {
+				return err
 			}
 
-			switch args[0] {
-			case "backward":
-				first, err := idxer.FirstIndexedBlock()
-				if err != nil {
-					return err
-				}
-				if first == -1 {
-					// start from the latest block if indexer db is empty
-					first = blockStore.Height()
-				}
-				for i := first - 1; i > 0; i-- {
-					if err := indexBlock(i); err != nil {
-						return err
-					}
+			env, err := openIndexEnv(cmd)
+			if err != nil {
+				return err
+			}
+			first, latest, err := indexedRange(env.idxer)
+			if err != nil {
+				return err
+			}
+			if from <= 0 {
+				from = first
+			}
+			if to <= 0 {
+				to = latest
+			}
+			if from <= 0 || to < from {
+				return fmt.Errorf("nothing to verify in blocks [%d, %d]", from, to)
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			var (
+				progress           = newIndexProgress(env.logger, "verified blocks", to-from+1)
+				gapStart, gapEnd   int64
+				txs, missing, diff int
+				missingLogs        int
+			)
+			reportGap := func() {
+				if gapStart > 0 {
+					cmd.Printf("gap: blocks %d-%d\n", gapStart, gapEnd)
+					gapStart = 0
 				}
-			case "forward":
-				latest, err := idxer.LastIndexedBlock()
+			}
+			err = processBlocks(ctx, from, to-from+1, 1, workers, env.loadBlock, func(data *blockData) error {
+				res, err := verifyBlock(env, data)
 				if err != nil {
 					return err
 				}
-				if latest == -1 {
-					// start from genesis if empty
-					latest = 0
+				for _, mismatch := range res.mismatches {
+					cmd.Println(mismatch)
 				}
-				for i := latest + 1; i <= blockStore.Height(); i++ {
-					if err := indexBlock(i); err != nil {
-						return err
+				txs += res.txs
+				missing += res.missing
+				diff += len(res.mismatches)
+				if res.missingLogs {
+					missingLogs++
+				}
+
+				if res.missing > 0 || res.missingLogs {
+					if gapStart == 0 {
+						gapStart = data.height
 					}
+					gapEnd = data.height
+				} else {
+					reportGap()
 				}
-			default:
-				return fmt.Errorf("unknown direction %s", args[0])
+				progress.done(data.height)
+				return nil
+			})
+			reportGap()
+			if err != nil {
+				return err
 			}
 
+			cmd.Printf("verified blocks %d-%d: %d txs, %d missing, %d mismatched, %d blocks without logs\n", from, to, txs, missing, diff, missingLogs)
+			if missing > 0 || diff > 0 || missingLogs > 0 {
+				return fmt.Errorf("indexer db is inconsistent with the block store")
+			}
 			return nil
 		},
 	}
+	cmd.Flags().Int(flagWorkers, runtime.NumCPU(), "Number of workers loading the blocks concurrently")
+	cmd.Flags().Int64(flagFrom, 0, "First block to verify, defaults to the first indexed block")
+	cmd.Flags().Int64(flagTo, 0, "Last block to verify, defaults to the last indexed block")
 	return cmd
 }
+
+// indexEnv is the indexer db and the local block stores used by the index commands.
+type indexEnv struct {
+	logger     log.Logger
+	clientCtx  client.Context
+	idxer      evmIndexer
+	blockStore *tmstore.BlockStore
+	stateStore sm.Store
+}
+
+func openIndexEnv(cmd *cobra.Command) (*indexEnv, error) {
+	serverCtx := server.GetServerContextFromCmd(cmd)
+	clientCtx, err := client.GetClientQueryContext(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := serverCtx.Config
+	logger := serverCtx.Logger
+	svrCfg, err := config.GetConfig(serverCtx.Viper)
+	if err != nil {
+		return nil, err
+	}
+	idxer, err := openEVMIndexer(serverCtx, svrCfg.JSONRPC, logger.With("module", "evmindex"), clientCtx)
+	if err != nil {
+		logger.Error("failed to open evm indexer", "error", err.Error())
+		return nil, err
+	}
+
+	// open local tendermint db, because the local rpc won't be available.
+	tmdb, err := tmcfg.DefaultDBProvider(&tmcfg.DBContext{ID: "blockstore", Config: cfg})
+	if err != nil {
+		return nil, err
+	}
+	blockStore := tmstore.NewBlockStore(tmdb)
+
+	stateDB, err := tmcfg.DefaultDBProvider(&tmcfg.DBContext{ID: "state", Config: cfg})
+	if err != nil {
+		return nil, err
+	}
+	stateStore := sm.NewStore(stateDB, sm.StoreOptions{
+		DiscardABCIResponses: cfg.Storage.DiscardABCIResponses,
+	})
+
+	return &indexEnv{
+		logger:     logger,
+		clientCtx:  clientCtx,
+		idxer:      idxer,
+		blockStore: blockStore,
+		stateStore: stateStore,
+	}, nil
+}
+
+// blockData is a block and its results loaded from the local block stores.
+type blockData struct {
+	height int64
+	block  *tmtypes.Block
+	result *abci.ResponseFinalizeBlock
+}
+
+func (env *indexEnv) loadBlock(height int64) (*blockData, error) {
+	blk := env.blockStore.LoadBlock(height)
+	if blk == nil {
+		return nil, fmt.Errorf("block not found %d", height)
+	}
+	resBlk, err := env.stateStore.LoadLastFinalizeBlockResponse(height)
+	if err != nil {
+		return nil, err
+	}
+	return &blockData{height: height, block: blk, result: resBlk}, nil
+}
+
+// indexedRange returns the first and last indexed blocks, -1 if the indexer db is empty.
+// The block blooms are indexed for every block, but not by the older versions, so the
+// range of the indexed txs is taken into account too.
+func indexedRange(idxer evmIndexer) (int64, int64, error) {
+	firstTx, err := idxer.FirstIndexedBlock()
+	if err != nil {
+		return 0, 0, err
+	}
+	lastTx, err := idxer.LastIndexedBlock()
+	if err != nil {
+		return 0, 0, err
+	}
+	firstBloom, lastBloom, err := idxer.LogsIndexedRange()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	first, last := firstTx, max(lastTx, lastBloom)
+	if first == -1 || (firstBloom != -1 && firstBloom < first) {
+		first = firstBloom
+	}
+	return first, last, nil
+}
+
+// verifyResult is the result of the verification of a block.
+type verifyResult struct {
+	txs     int
+	missing int
+	// missingLogs is set if the logs of the block are not indexed
+	missingLogs bool
+	mismatches  []string
+}
+
+// blockBloomIndexer is implemented by the indexers storing the blooms of the blocks.
+type blockBloomIndexer interface {
+	GetBlockBloom(height int64) (ethtypes.Bloom, bool, error)
+}
+
+// verifyBlock indexes the block in memory and compares the tx results, the logs and the
+// bloom with the ones of the indexer db.
+func verifyBlock(env *indexEnv, data *blockData) (*verifyResult, error) {
+	expected := indexer.NewKVIndexer(dbm.NewMemDB(), log.NewNopLogger(), env.clientCtx)
+	if err := expected.IndexBlock(data.block, data.result); err != nil {
+		return nil, err
+	}
+
+	res := &verifyResult{}
+	for i := int32(0); ; i++ {
+		expTx, err := expected.GetByBlockAndIndex(data.height, i)
+		if err != nil {
+			// no more eth txs in the block, the indexer db must not have more either
+			if _, err := env.idxer.GetByBlockAndIndex(data.height, i); err == nil {
+				res.mismatches = append(res.mismatches, fmt.Sprintf("block %d: unexpected eth tx %d", data.height, i))
+			}
+			break
+		}
+		res.txs++
+
+		tx, err := env.idxer.GetByBlockAndIndex(data.height, i)
+		switch {
+		case err != nil:
+			res.missing++
+		case *tx != *expTx:
+			res.mismatches = append(res.mismatches, fmt.Sprintf("block %d: eth tx %d mismatch, indexed: %s, expected: %s", data.height, i, tx, expTx))
+		}
+	}
+
+	if err := verifyLogs(env.idxer, expected, data.height, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// verifyLogs compares the logs and the bloom of the block with the ones of the indexer
+// db, the bloom is only compared if the indexer stores the blooms of the blocks.
+func verifyLogs(idxer evmIndexer, expected *indexer.KVIndexer, height int64, res *verifyResult) error {
+	if bloomIndexer, ok := idxer.(blockBloomIndexer); ok {
+		bloom, found, err := bloomIndexer.GetBlockBloom(height)
+		if err != nil {
+			return err
+		}
+		if !found {
+			res.missingLogs = true
+			return nil
+		}
+		expBloom, _, err := expected.GetBlockBloom(height)
+		if err != nil {
+			return err
+		}
+		if bloom != expBloom {
+			res.mismatches = append(res.mismatches, fmt.Sprintf("block %d: bloom mismatch, indexed: %x, expected: %x", height, bloom, expBloom))
+		}
+	}
+
+	expLogs, err := expected.GetLogs(height, height, nil, nil, math.MaxInt32)
+	if err != nil {
+		return err
+	}
+	logs, err := idxer.GetLogs(height, height, nil, nil, math.MaxInt32)
+	if err != nil {
+		return err
+	}
+	if len(logs) != len(expLogs) {
+		res.mismatches = append(res.mismatches, fmt.Sprintf("block %d: %d logs indexed, expected: %d", height, len(logs), len(expLogs)))
+		return nil
+	}
+	for i := range logs {
+		bz, err := json.Marshal(logs[i])
+		if err != nil {
+			return err
+		}
+		expBz, err := json.Marshal(expLogs[i])
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(bz, expBz) {
+			res.mismatches = append(res.mismatches, fmt.Sprintf("block %d: log %d mismatch, indexed: %s, expected: %s", height, i, bz, expBz))
+		}
+	}
+	return nil
+}
+
+// processBlocks loads `count` blocks from the height `start` by `step`, concurrently with
+// the workers, and handles them in the traverse order. The loaded blocks waiting to be
+// handled are bounded to limit the memory usage.
+func processBlocks(
+	ctx context.Context,
+	start, count, step int64,
+	workers int,
+	load func(int64) (*blockData, error),
+	handle func(*blockData) error,
+) error {
+	type job struct {
+		height int64
+		result chan *blockData
+	}
+
+	workers = max(workers, 1)
+	g, ctx := errgroup.WithContext(ctx)
+	jobs := make(chan job)
+	ordered := make(chan job, 2*workers)
+
+	g.Go(func() error {
+		defer close(jobs)
+		defer close(ordered)
+		for i := int64(0); i < count; i++ {
+			j := job{height: start + i*step, result: make(chan *blockData, 1)}
+			select {
+			case ordered <- j:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			select {
+			case jobs <- j:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+
+	for w := 0; w < workers; w++ {
+		g.Go(func() error {
+			for j := range jobs {
+				data, err := load(j.height)
+				if err != nil {
+					return err
+				}
+				j.result <- data
+			}
+			return nil
+		})
+	}
+
+	g.Go(func() error {
+		for j := range ordered {
+			select {
+			case data := <-j.result:
+				if err := handle(data); err != nil {
+					return err
+				}
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+
+	return g.Wait()
+}
+
+// indexProgress reports periodically the progress of the index commands.
+type indexProgress struct {
+	logger     log.Logger
+	msg        string
+	total      int64
+	count      int64
+	start      time.Time
+	lastReport time.Time
+}
+
+func newIndexProgress(logger log.Logger, msg string, total int64) *indexProgress {
+	now := time.Now()
+	return &indexProgress{logger: logger, msg: msg, total: total, start: now, lastReport: now}
+}
+
+func (p *indexProgress) done(height int64) {
+	p.count++
+	if p.count != p.total && time.Since(p.lastReport) < progressInterval {
+		return
+	}
+	p.lastReport = time.Now()
+	rate := float64(p.count) / time.Since(p.start).Seconds()
+	p.logger.Info(p.msg, "height", height, "done", p.count, "total", p.total, "blocks/s", fmt.Sprintf("%.1f", rate))
+}
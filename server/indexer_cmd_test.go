@@ -0,0 +1,211 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"cosmossdk.io/log"
+	abci "github.com/cometbft/cometbft/abci/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	dbm "github.com/cosmos/cosmos-db"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/evmos/ethermint/crypto/ethsecp256k1"
+	evmenc "github.com/evmos/ethermint/encoding"
+	"github.com/evmos/ethermint/indexer"
+	"github.com/evmos/ethermint/tests"
+	"github.com/evmos/ethermint/x/evm"
+	evmtypes "github.com/evmos/ethermint/x/evm/types"
+)
+
+func TestProcessBlocks(t *testing.T) {
+	load := func(height int64) (*blockData, error) {
+		return &blockData{height: height}, nil
+	}
+
+	testCases := []struct {
+		name               string
+		start, count, step int64
+		expHeights         []int64
+	}{
+		{"forward", 1, 10, 1, []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+		{"backward", 10, 5, -1, []int64{10, 9, 8, 7, 6}},
+		{"nothing to process", 1, 0, 1, nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, workers := range []int{0, 1, 4} {
+				var heights []int64
+				err := processBlocks(context.Background(), tc.start, tc.count, tc.step, workers, load, func(data *blockData) error {
+					heights = append(heights, data.height)
+					return nil
+				})
+				require.NoError(t, err)
+				// the blocks are handled in the traverse order
+				require.Equal(t, tc.expHeights, heights, workers)
+			}
+		})
+	}
+
+	t.Run("load error", func(t *testing.T) {
+		var handled int64
+		err := processBlocks(context.Background(), 1, 100, 1, 4, func(height int64) (*blockData, error) {
+			if height == 5 {
+				return nil, errors.New("block not found 5")
+			}
+			return load(height)
+		}, func(*blockData) error {
+			atomic.AddInt64(&handled, 1)
+			return nil
+		})
+		require.EqualError(t, err, "block not found 5")
+		require.Less(t, handled, int64(5))
+	})
+
+	t.Run("handle error", func(t *testing.T) {
+		var loaded int64
+		err := processBlocks(context.Background(), 1, 100, 1, 4, func(height int64) (*blockData, error) {
+			atomic.AddInt64(&loaded, 1)
+			return load(height)
+		}, func(data *blockData) error {
+			if data.height == 3 {
+				return errors.New("index failed")
+			}
+			return nil
+		})
+		require.EqualError(t, err, "index failed")
+		// the loaded blocks are bounded
+		require.Less(t, atomic.LoadInt64(&loaded), int64(100))
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		err := processBlocks(ctx, 1, 100, 1, 4, load, func(data *blockData) error {
+			if data.height == 2 {
+				cancel()
+			}
+			return nil
+		})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestVerifyBlock(t *testing.T) {
+	encodingConfig := evmenc.MakeTestEncodingConfig(evm.AppModuleBasic{})
+	clientCtx := client.Context{}.WithTxConfig(encodingConfig.TxConfig).WithCodec(encodingConfig.Codec)
+	data := newVerifyBlockData(t, clientCtx)
+
+	// indexBlock indexes the block with the logs replaced, if any.
+	indexBlock := func(t *testing.T, idxer evmIndexer, logs ...*ethtypes.Log) {
+		result := data.result
+		if logs != nil {
+			result = &abci.ResponseFinalizeBlock{TxResults: []*abci.ExecTxResult{{
+				Code:   data.result.TxResults[0].Code,
+				Events: append([]abci.Event{data.result.TxResults[0].Events[0]}, logEvent(t, logs...)),
+			}}}
+		}
+		require.NoError(t, idxer.IndexBlock(data.block, result))
+	}
+	otherLog := &ethtypes.Log{Address: common.HexToAddress("0x03"), Topics: []common.Hash{{3}}, BlockNumber: 1}
+
+	testCases := []struct {
+		name          string
+		index         func(*testing.T, evmIndexer)
+		expMissing    int
+		expMissingLog bool
+		expMismatches int
+	}{
+		{"consistent block", func(t *testing.T, idxer evmIndexer) { indexBlock(t, idxer) }, 0, false, 0},
+		{"block not indexed", func(*testing.T, evmIndexer) {}, 1, true, 0},
+		// the bloom and the log differ
+		{"different logs", func(t *testing.T, idxer evmIndexer) { indexBlock(t, idxer, otherLog) }, 0, false, 2},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			idxer := indexer.NewKVIndexer(dbm.NewMemDB(), log.NewNopLogger(), clientCtx)
+			tc.index(t, idxer)
+
+			res, err := verifyBlock(&indexEnv{clientCtx: clientCtx, idxer: idxer}, data)
+			require.NoError(t, err)
+			require.Equal(t, 1, res.txs)
+			require.Equal(t, tc.expMissing, res.missing)
+			require.Equal(t, tc.expMissingLog, res.missingLogs)
+			require.Len(t, res.mismatches, tc.expMismatches, res.mismatches)
+		})
+	}
+
+	t.Run("sql indexer", func(t *testing.T) {
+		idxer, err := indexer.NewSQLIndexer(indexer.SQLDriverSQLite, filepath.Join(t.TempDir(), "evmindexer.db"), log.NewNopLogger(), clientCtx)
+		require.NoError(t, err)
+		defer idxer.Close()
+
+		indexBlock(t, idxer)
+		res, err := verifyBlock(&indexEnv{clientCtx: clientCtx, idxer: idxer}, data)
+		require.NoError(t, err)
+		require.Empty(t, res.mismatches)
+
+		// the sql indexer has no bloom, only the logs are compared
+		indexBlock(t, idxer, otherLog)
+		res, err = verifyBlock(&indexEnv{clientCtx: clientCtx, idxer: idxer}, data)
+		require.NoError(t, err)
+		require.False(t, res.missingLogs)
+		require.Len(t, res.mismatches, 1)
+	})
+}
+
+// newVerifyBlockData builds a block with an eth tx emitting a log.
+func newVerifyBlockData(t *testing.T, clientCtx client.Context) *blockData {
+	priv, err := ethsecp256k1.GenerateKey()
+	require.NoError(t, err)
+	to := common.BigToAddress(big.NewInt(1))
+	tx := evmtypes.NewTx(nil, 0, &to, big.NewInt(1000), 21000, nil, nil, nil, nil, nil)
+	tx.From = common.BytesToAddress(priv.PubKey().Address().Bytes()).Hex()
+	require.NoError(t, tx.Sign(ethtypes.LatestSignerForChainID(nil), tests.NewSigner(priv)))
+	txHash := tx.AsTransaction().Hash()
+
+	tmTx, err := tx.BuildTx(clientCtx.TxConfig.NewTxBuilder(), "aphoton")
+	require.NoError(t, err)
+	txBz, err := clientCtx.TxConfig.TxEncoder()(tmTx)
+	require.NoError(t, err)
+
+	block := &tmtypes.Block{Header: tmtypes.Header{Height: 1}, Data: tmtypes.Data{Txs: []tmtypes.Tx{txBz}}}
+	txLog := &ethtypes.Log{
+		Address:     to,
+		Topics:      []common.Hash{{1}},
+		Data:        []byte{1},
+		BlockNumber: 1,
+		TxHash:      txHash,
+		BlockHash:   common.BytesToHash(block.Hash()),
+	}
+	result := &abci.ResponseFinalizeBlock{TxResults: []*abci.ExecTxResult{{
+		Code: 0,
+		Events: []abci.Event{
+			{Type: evmtypes.EventTypeEthereumTx, Attributes: []abci.EventAttribute{
+				{Key: "ethereumTxHash", Value: txHash.Hex()},
+				{Key: "txIndex", Value: "0"},
+				{Key: "txGasUsed", Value: "21000"},
+			}},
+			logEvent(t, txLog),
+		},
+	}}}
+	return &blockData{height: 1, block: block, result: result}
+}
+
+// logEvent returns the event of the logs emitted by a tx.
+func logEvent(t *testing.T, logs ...*ethtypes.Log) abci.Event {
+	attrs := make([]abci.EventAttribute, len(logs))
+	for i, log := range logs {
+		bz, err := json.Marshal(evmtypes.NewLogFromEth(log))
+		require.NoError(t, err)
+		attrs[i] = abci.EventAttribute{Key: evmtypes.AttributeKeyTxLog, Value: string(bz)}
+	}
+	return abci.Event{Type: evmtypes.EventTypeTxLog, Attributes: attrs}
+}
@@ -117,6 +117,8 @@ func (eis *EVMIndexerService) OnStart() error {
 				break
 			}
 			if err := eis.txIdxr.IndexBlock(block.Block, &abci.ResponseFinalizeBlock{TxResults: blockResult.TxsResults}); err != nil {
+				// the logs range of the indexer stops before the block, the logs queries past it
+				// fall back to the block results until the block is indexed again
 				eis.Logger.Error("failed to index block", "height", i, "err", err)
 			}
 			lastBlock = blockResult.Height
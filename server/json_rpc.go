@@ -16,7 +16,10 @@
 package server
 
 import (
+	"context"
+	"net"
 	"net/http"
+	"path/filepath"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -27,6 +30,9 @@ import (
 	ethlog "github.com/ethereum/go-ethereum/log"
 	ethrpc "github.com/ethereum/go-ethereum/rpc"
 	"github.com/evmos/ethermint/rpc"
+	"github.com/evmos/ethermint/rpc/backend"
+	"github.com/evmos/ethermint/rpc/graphql"
+	rpctypes "github.com/evmos/ethermint/rpc/types"
 
 	"github.com/evmos/ethermint/server/config"
 	ethermint "github.com/evmos/ethermint/types"
@@ -43,6 +49,7 @@ func StartJSONRPC(ctx *server.Context,
 	tmEndpoint string,
 	config *config.Config,
 	indexer ethermint.EVMTxIndexer,
+	syncTracker *rpctypes.StateSyncTracker,
 ) (*http.Server, chan struct{}, error) {
 	tmWsClient := ConnectTmWS(tmRPCAddr, tmEndpoint, ctx.Logger)
 
@@ -64,21 +71,39 @@ func StartJSONRPC(ctx *server.Context,
 	allowUnprotectedTxs := config.JSONRPC.AllowUnprotectedTxs
 	rpcAPIArr := config.JSONRPC.API
 
-	apis := rpc.GetRPCAPIs(ctx, clientCtx, tmWsClient, allowUnprotectedTxs, indexer, rpcAPIArr)
+	apis := rpc.GetRPCAPIs(ctx, clientCtx, tmWsClient, allowUnprotectedTxs, indexer, syncTracker, rpcAPIArr)
 
+	// the authenticated namespaces are only served by the authenticated and IPC servers
+	authAPIs := make(map[string]bool)
+	for _, ns := range config.JSONRPC.AuthAPI {
+		authAPIs[ns] = true
+	}
+	var publicAPIs []ethrpc.API
 	for _, api := range apis {
-		if err := rpcServer.RegisterName(api.Namespace, api.Service); err != nil {
-			ctx.Logger.Error(
-				"failed to register service in JSON RPC namespace",
-				"namespace", api.Namespace,
-				"service", api.Service,
-			)
-			return nil, nil, err
+		if !authAPIs[api.Namespace] {
+			publicAPIs = append(publicAPIs, api)
 		}
 	}
+	if err := registerAPIs(ctx, rpcServer, publicAPIs); err != nil {
+		return nil, nil, err
+	}
 
 	r := mux.NewRouter()
-	r.HandleFunc("/", rpcServer.ServeHTTP).Methods("POST")
+	limiter := rpc.NewRequestLimiter(config.JSONRPC)
+	handler := rpc.NewMetricsHandler(ctx.Logger, config.JSONRPC.SlowRequestThreshold, rpc.RegisteredMethods(publicAPIs), rpcServer)
+	r.Handle("/", limiter.Handler(handler)).Methods("POST")
+
+	evmBackend := backend.NewBackend(ctx, ctx.Logger, clientCtx, allowUnprotectedTxs, indexer, syncTracker)
+	if config.JSONRPC.EnableGraphQL {
+		graphQLHandler, err := graphql.NewHandler(evmBackend, ctx.Logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		// the queries are charged the default method cost, and labelled as unknown methods,
+		// their block ranges are capped by the graphql handler
+		graphQLHandler = rpc.NewMetricsHandler(ctx.Logger, config.JSONRPC.SlowRequestThreshold, nil, graphQLHandler)
+		r.Handle("/graphql", limiter.Handler(graphQLHandler)).Methods("POST")
+	}
 
 	handlerWithCors := cors.Default()
 	if config.API.EnableUnsafeCORS {
@@ -100,6 +125,37 @@ func StartJSONRPC(ctx *server.Context,
 		return nil, nil, err
 	}
 
+	if config.JSONRPC.AuthAddress != "" {
+		authSrv, err := startAuthJSONRPC(ctx, config, apis)
+		if err != nil {
+			ln.Close()
+			return nil, nil, err
+		}
+		// the authenticated server is shut down with the public one
+		httpSrv.RegisterOnShutdown(func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), ServerStartTime)
+			defer cancel()
+			if err := authSrv.Shutdown(shutdownCtx); err != nil {
+				ctx.Logger.Error("authenticated JSON-RPC server shutdown produced a warning", "error", err.Error())
+			}
+		})
+	}
+
+	if config.JSONRPC.IPCPath != "" {
+		ipcListener, ipcSrv, err := startIPC(ctx, config, apis)
+		if err != nil {
+			ln.Close()
+			return nil, nil, err
+		}
+		// the IPC endpoint is closed with the public server
+		httpSrv.RegisterOnShutdown(func() {
+			if err := ipcListener.Close(); err != nil {
+				ctx.Logger.Error("IPC endpoint close produced a warning", "error", err.Error())
+			}
+			ipcSrv.Stop()
+		})
+	}
+
 	errCh := make(chan error)
 	go func() {
 		ctx.Logger.Info("Starting JSON-RPC server", "address", config.JSONRPC.Address)
@@ -125,7 +181,82 @@ func StartJSONRPC(ctx *server.Context,
 
 	// allocate separate WS connection to Tendermint
 	tmWsClient = ConnectTmWS(tmRPCAddr, tmEndpoint, ctx.Logger)
-	wsSrv := rpc.NewWebsocketsServer(clientCtx, ctx.Logger, tmWsClient, config)
+	wsSrv := rpc.NewWebsocketsServer(clientCtx, ctx.Logger, tmWsClient, evmBackend, config, limiter, syncTracker)
 	wsSrv.Start()
 	return httpSrv, httpSrvDone, nil
 }
+
+// startAuthJSONRPC starts the JWT authenticated JSON-RPC server, it serves all the
+// enabled namespaces.
+func startAuthJSONRPC(ctx *server.Context, config *config.Config, apis []ethrpc.API) (*http.Server, error) {
+	secretPath := config.JSONRPC.JWTSecret
+	if !filepath.IsAbs(secretPath) {
+		secretPath = filepath.Join(ctx.Config.RootDir, secretPath)
+	}
+	secret, err := obtainJWTSecret(secretPath)
+	if err != nil {
+		ctx.Logger.Error("failed to load the JWT secret", "path", secretPath, "error", err.Error())
+		return nil, err
+	}
+
+	rpcServer := ethrpc.NewServer()
+	if err := registerAPIs(ctx, rpcServer, apis); err != nil {
+		return nil, err
+	}
+
+	r := mux.NewRouter()
+	r.Handle("/", newJWTHandler(secret, rpcServer)).Methods("POST")
+
+	authSrv := &http.Server{
+		Addr:              config.JSONRPC.AuthAddress,
+		Handler:           r,
+		ReadHeaderTimeout: config.JSONRPC.HTTPTimeout,
+		ReadTimeout:       config.JSONRPC.HTTPTimeout,
+		WriteTimeout:      config.JSONRPC.HTTPTimeout,
+		IdleTimeout:       config.JSONRPC.HTTPIdleTimeout,
+	}
+	ln, err := Listen(authSrv.Addr, config)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		ctx.Logger.Info("Starting authenticated JSON-RPC server", "address", config.JSONRPC.AuthAddress)
+		if err := authSrv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			ctx.Logger.Error("failed to start authenticated JSON-RPC server", "error", err.Error())
+		}
+	}()
+	return authSrv, nil
+}
+
+// startIPC starts the JSON-RPC IPC endpoint, it serves all the enabled namespaces over
+// a unix socket only accessible to the node user.
+func startIPC(ctx *server.Context, config *config.Config, apis []ethrpc.API) (net.Listener, *ethrpc.Server, error) {
+	ipcPath := config.JSONRPC.IPCPath
+	if !filepath.IsAbs(ipcPath) {
+		ipcPath = filepath.Join(ctx.Config.RootDir, ipcPath)
+	}
+
+	ctx.Logger.Info("Starting JSON-RPC IPC endpoint", "path", ipcPath)
+	listener, ipcSrv, err := ethrpc.StartIPCEndpoint(ipcPath, apis)
+	if err != nil {
+		ctx.Logger.Error("failed to start the JSON-RPC IPC endpoint", "path", ipcPath, "error", err.Error())
+		return nil, nil, err
+	}
+	return listener, ipcSrv, nil
+}
+
+// registerAPIs registers the services of the apis in the rpc server.
+func registerAPIs(ctx *server.Context, rpcServer *ethrpc.Server, apis []ethrpc.API) error {
+	for _, api := range apis {
+		if err := rpcServer.RegisterName(api.Namespace, api.Service); err != nil {
+			ctx.Logger.Error(
+				"failed to register service in JSON RPC namespace",
+				"namespace", api.Namespace,
+				"service", api.Service,
+			)
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2021 Evmos Foundation
+// This file is part of Evmos' Ethermint library.
+//
+// The Ethermint library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Ethermint library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Ethermint library. If not, see https://github.com/evmos/ethermint/blob/main/LICENSE
+package server
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	// jwtSecretLength is the length of the HS256 secret of the authenticated endpoint.
+	jwtSecretLength = 32
+	// jwtExpiryTimeout is the max drift between the issued-at claim of a token and the
+	// server time.
+	jwtExpiryTimeout = 60 * time.Second
+)
+
+// obtainJWTSecret loads the hex encoded jwt secret from the file, a new secret is
+// generated and saved if the file doesn't exist.
+func obtainJWTSecret(path string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	switch {
+	case err == nil:
+		secret := common.FromHex(strings.TrimSpace(string(data)))
+		if len(secret) != jwtSecretLength {
+			return nil, fmt.Errorf("invalid JWT secret in %s, expected %d bytes, got %d", path, jwtSecretLength, len(secret))
+		}
+		return secret, nil
+	case errors.Is(err, os.ErrNotExist):
+		secret := make([]byte, jwtSecretLength)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, []byte(hexutil.Encode(secret)), 0o600); err != nil {
+			return nil, err
+		}
+		return secret, nil
+	default:
+		return nil, err
+	}
+}
+
+// jwtHandler authenticates the requests with a HS256 jwt token in the Authorization
+// header, the tokens must have an issued-at claim close to the server time, like the
+// authenticated endpoint of the execution clients.
+type jwtHandler struct {
+	secret []byte
+	next   http.Handler
+}
+
+// newJWTHandler creates a http.Handler with jwt authentication.
+func newJWTHandler(secret []byte, next http.Handler) http.Handler {
+	return &jwtHandler{secret: secret, next: next}
+}
+
+// ServeHTTP implements http.Handler
+func (h *jwtHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		http.Error(w, "missing token", http.StatusUnauthorized)
+		return
+	}
+
+	var claims jwt.RegisteredClaims
+	token, err := jwt.ParseWithClaims(strings.TrimPrefix(auth, "Bearer "), &claims,
+		func(*jwt.Token) (interface{}, error) { return h.secret, nil },
+		jwt.WithValidMethods([]string{"HS256"}),
+		// the issued-at claim is checked below, with some allowed drift
+		jwt.WithoutClaimsValidation(),
+	)
+	switch {
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case !token.Valid:
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+	case !claims.VerifyExpiresAt(time.Now(), false):
+		http.Error(w, "token is expired", http.StatusUnauthorized)
+	case claims.IssuedAt == nil:
+		http.Error(w, "missing issued-at", http.StatusUnauthorized)
+	case time.Since(claims.IssuedAt.Time) > jwtExpiryTimeout:
+		http.Error(w, "stale token", http.StatusUnauthorized)
+	case time.Until(claims.IssuedAt.Time) > jwtExpiryTimeout:
+		http.Error(w, "future token", http.StatusUnauthorized)
+	default:
+		h.next.ServeHTTP(w, r)
+	}
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObtainJWTSecret(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "jwt.hex")
+
+	// a new secret is saved if the file doesn't exist
+	secret, err := obtainJWTSecret(path)
+	require.NoError(t, err)
+	require.Len(t, secret, jwtSecretLength)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// the saved secret is reloaded
+	reloaded, err := obtainJWTSecret(path)
+	require.NoError(t, err)
+	require.Equal(t, secret, reloaded)
+
+	// the secrets are accepted with or without 0x prefix and surrounding whitespace
+	require.NoError(t, os.WriteFile(path, []byte(" "+hexutil.Encode(secret)[2:]+"\n"), 0o600))
+	reloaded, err = obtainJWTSecret(path)
+	require.NoError(t, err)
+	require.Equal(t, secret, reloaded)
+
+	require.NoError(t, os.WriteFile(path, []byte(hexutil.Encode(secret[:16])), 0o600))
+	_, err = obtainJWTSecret(path)
+	require.ErrorContains(t, err, "invalid JWT secret")
+}
+
+func TestJWTHandler(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	handler := newJWTHandler(secret, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	sign := func(method jwt.SigningMethod, key interface{}, claims jwt.Claims) string {
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		require.NoError(t, err)
+		return "Bearer " + token
+	}
+	issuedAt := func(d time.Duration) jwt.RegisteredClaims {
+		return jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(time.Now().Add(d))}
+	}
+
+	testCases := []struct {
+		name      string
+		auth      string
+		expStatus int
+	}{
+		{"valid token", sign(jwt.SigningMethodHS256, secret, issuedAt(0)), http.StatusOK},
+		{"drift within the timeout", sign(jwt.SigningMethodHS256, secret, issuedAt(-30*time.Second)), http.StatusOK},
+		{"missing token", "", http.StatusUnauthorized},
+		{"not a bearer token", "Basic dXNlcjpwYXNz", http.StatusUnauthorized},
+		{"malformed token", "Bearer token", http.StatusUnauthorized},
+		{"stale issued-at", sign(jwt.SigningMethodHS256, secret, issuedAt(-2*jwtExpiryTimeout)), http.StatusUnauthorized},
+		{"future issued-at", sign(jwt.SigningMethodHS256, secret, issuedAt(2*jwtExpiryTimeout)), http.StatusUnauthorized},
+		{"missing issued-at", sign(jwt.SigningMethodHS256, secret, jwt.RegisteredClaims{}), http.StatusUnauthorized},
+		{
+			"expired token",
+			sign(jwt.SigningMethodHS256, secret, jwt.RegisteredClaims{
+				IssuedAt:  jwt.NewNumericDate(time.Now()),
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Second)),
+			}),
+			http.StatusUnauthorized,
+		},
+		{"wrong secret", sign(jwt.SigningMethodHS256, []byte("wrong secret"), issuedAt(0)), http.StatusUnauthorized},
+		{"wrong alg", sign(jwt.SigningMethodHS512, secret, issuedAt(0)), http.StatusUnauthorized},
+		{"none alg", sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, issuedAt(0)), http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tc.auth != "" {
+				r.Header.Set("Authorization", tc.auth)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+			require.Equal(t, tc.expStatus, rec.Code)
+		})
+	}
+}
@@ -48,8 +48,6 @@ import (
 	"github.com/cometbft/cometbft/rpc/client/local"
 	cmttypes "github.com/cometbft/cometbft/types"
 
-	ethmetricsexp "github.com/ethereum/go-ethereum/metrics/exp"
-
 	errorsmod "cosmossdk.io/errors"
 	pruningtypes "cosmossdk.io/store/pruning/types"
 	dbm "github.com/cosmos/cosmos-db"
@@ -64,8 +62,8 @@ import (
 	"github.com/cosmos/cosmos-sdk/version"
 	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
 
-	"github.com/evmos/ethermint/indexer"
 	ethdebug "github.com/evmos/ethermint/rpc/namespaces/ethereum/debug"
+	rpctypes "github.com/evmos/ethermint/rpc/types"
 	"github.com/evmos/ethermint/server/config"
 	srvflags "github.com/evmos/ethermint/server/flags"
 	ethermint "github.com/evmos/ethermint/types"
@@ -203,6 +201,10 @@ is performed. Note, when enabled, gRPC will also be automatically enabled.
 	cmd.Flags().StringSlice(srvflags.JSONRPCAPI, config.GetDefaultAPINamespaces(), "Defines a list of JSON-RPC namespaces that should be enabled")
 	cmd.Flags().String(srvflags.JSONRPCAddress, config.DefaultJSONRPCAddress, "the JSON-RPC server address to listen on")
 	cmd.Flags().String(srvflags.JSONWsAddress, config.DefaultJSONRPCWsAddress, "the JSON-RPC WS server address to listen on")
+	cmd.Flags().String(srvflags.JSONRPCAuthAddress, "", "the JWT authenticated JSON-RPC server address to listen on (disabled if empty)")
+	cmd.Flags().Bool(srvflags.JSONRPCEnableGraphQL, false, "Serve the EIP-1767 GraphQL queries on the /graphql path of the JSON-RPC server")
+	cmd.Flags().StringSlice(srvflags.JSONRPCAuthAPI, []string{}, "Defines a list of JSON-RPC namespaces only served by the authenticated server")
+	cmd.Flags().String(srvflags.JSONRPCJWTSecret, config.DefaultJWTSecretPath, "the hex encoded jwt secret file of the authenticated JSON-RPC server")
 	cmd.Flags().Uint64(srvflags.JSONRPCGasCap, config.DefaultGasCap, "Sets a cap on gas that can be used in eth_call/estimateGas unit is aphoton (0=infinite)")     //nolint:lll
 	cmd.Flags().Float64(srvflags.JSONRPCTxFeeCap, config.DefaultTxFeeCap, "Sets a cap on transaction fee that can be sent via the RPC APIs (1 = default 1 photon)") //nolint:lll
 	cmd.Flags().Int32(srvflags.JSONRPCFilterCap, config.DefaultFilterCap, "Sets the global cap for total number of filters that can be created")
@@ -212,8 +214,17 @@ is performed. Note, when enabled, gRPC will also be automatically enabled.
 	cmd.Flags().Bool(srvflags.JSONRPCAllowUnprotectedTxs, config.DefaultAllowUnprotectedTxs, "Allow for unprotected (non EIP155 signed) transactions to be submitted via the node's RPC when the global parameter is disabled") //nolint:lll
 	cmd.Flags().Int32(srvflags.JSONRPCLogsCap, config.DefaultLogsCap, "Sets the max number of results can be returned from single `eth_getLogs` query")
 	cmd.Flags().Int32(srvflags.JSONRPCBlockRangeCap, config.DefaultBlockRangeCap, "Sets the max block range allowed for `eth_getLogs` query")
+	cmd.Flags().Float64(srvflags.JSONRPCRateLimit, 0, "Sets the cost units refilled per second in the rate limit token bucket of each client IP (0=disabled)")
+	cmd.Flags().Int(srvflags.JSONRPCRateLimitBurst, config.DefaultRateLimitBurst, "Sets the size of the rate limit token bucket of each client IP")
+	cmd.Flags().Int(srvflags.JSONRPCBatchRequestLimit, config.DefaultBatchRequestLimit, "Sets the max number of requests in a batch (0=unlimited)")
+	cmd.Flags().Int(srvflags.JSONRPCMaxResponseBytes, config.DefaultMaxResponseBytes, "Sets the max size of a response in bytes (0=unlimited)")
+	cmd.Flags().Duration(srvflags.JSONRPCSlowRequestThreshold, 0, "Sets the duration above which the json-rpc requests are logged with their params (0=disabled)")
+	cmd.Flags().String(srvflags.JSONRPCIPCPath, "", "the unix socket of the JSON-RPC IPC endpoint, relative to the node home directory if not absolute (disabled if empty)")
 	cmd.Flags().Int(srvflags.JSONRPCMaxOpenConnections, config.DefaultMaxOpenConnections, "Sets the maximum number of simultaneous connections for the server listener") //nolint:lll
 	cmd.Flags().Bool(srvflags.JSONRPCEnableIndexer, false, "Enable the custom tx indexer for json-rpc")
+	cmd.Flags().String(srvflags.JSONRPCIndexerBackend, config.DefaultIndexerBackend, "Sets the storage backend of the custom tx indexer (kv|sql)")
+	cmd.Flags().String(srvflags.JSONRPCIndexerSQLDriver, config.DefaultIndexerSQLDriver, "Sets the database driver of the sql indexer backend (sqlite3|postgres)")
+	cmd.Flags().String(srvflags.JSONRPCIndexerSQLDSN, "", "Sets the data source name of the sql indexer backend")
 	cmd.Flags().Bool(srvflags.JSONRPCEnableMetrics, false, "Define if EVM rpc metrics server should be enabled")
 
 	cmd.Flags().String(srvflags.EVMTracer, config.DefaultEVMTracer, "the EVM tracer type to collect execution traces from the EVM transaction execution (json|struct|access_list|markdown)") //nolint:lll
@@ -279,7 +290,8 @@ func startStandAlone(
 	addr := svrCtx.Viper.GetString(srvflags.Address)
 	transport := svrCtx.Viper.GetString(srvflags.Transport)
 
-	cmtApp := NewCometABCIWrapper(app)
+	// the JSON-RPC servers are not run with the stand-alone application
+	cmtApp := NewCometABCIWrapper(app, rpctypes.NewStateSyncTracker())
 	svr, err := abciserver.NewServer(addr, transport, cmtApp)
 	if err != nil {
 		return fmt.Errorf("error creating listener: %v", err)
@@ -350,6 +362,7 @@ func startInProcess(
 	gRPCOnly := svrCtx.Viper.GetBool(srvflags.GRPCOnly)
 
 	g, ctx := getCtx(svrCtx, true)
+	syncTracker := rpctypes.NewStateSyncTracker()
 
 	if gRPCOnly {
 		svrCtx.Logger.Info("starting node in query only mode; Tendermint is disabled")
@@ -357,7 +370,7 @@ func startInProcess(
 		svrCfg.JSONRPC.EnableIndexer = false
 	} else {
 		svrCtx.Logger.Info("starting node with ABCI Tendermint in-process")
-		tmNode, cleanupFn, err := startCmtNode(ctx, cmtCfg, app, svrCtx)
+		tmNode, cleanupFn, err := startCmtNode(ctx, cmtCfg, app, svrCtx, syncTracker)
 		if err != nil {
 			return err
 		}
@@ -380,19 +393,17 @@ func startInProcess(
 	// Enable metrics if JSONRPC is enabled and --metrics is passed
 	// Flag not added in config to avoid user enabling in config without passing in CLI
 	if svrCfg.JSONRPC.Enable && svrCtx.Viper.GetBool(srvflags.JSONRPCEnableMetrics) {
-		ethmetricsexp.Setup(svrCfg.JSONRPC.MetricsAddress)
+		startMetricsServer(svrCtx.Logger, svrCfg.JSONRPC.MetricsAddress)
 	}
 
 	var idxer ethermint.EVMTxIndexer
 	if svrCfg.JSONRPC.EnableIndexer {
-		idxDB, err := OpenIndexerDB(home, server.GetAppDBBackend(svrCtx.Viper))
+		idxLogger := svrCtx.Logger.With("indexer", "evm")
+		idxer, err = openEVMIndexer(svrCtx, svrCfg.JSONRPC, idxLogger, clientCtx)
 		if err != nil {
-			svrCtx.Logger.Error("failed to open evm indexer DB", "error", err.Error())
+			svrCtx.Logger.Error("failed to open evm indexer", "error", err.Error())
 			return err
 		}
-
-		idxLogger := svrCtx.Logger.With("indexer", "evm")
-		idxer = indexer.NewKVIndexer(idxDB, idxLogger, clientCtx)
 		indexerService := NewEVMIndexerService(idxer, clientCtx.Client.(rpcclient.Client))
 		indexerService.SetLogger(servercmtlog.CometLoggerWrapper{Logger: idxLogger})
 
@@ -475,7 +486,7 @@ func startInProcess(
 
 		tmEndpoint := "/websocket"
 		tmRPCAddr := svrCtx.Config.RPC.ListenAddress
-		httpSrv, httpSrvDone, err = StartJSONRPC(svrCtx, clientCtx, tmRPCAddr, tmEndpoint, &svrCfg, idxer)
+		httpSrv, httpSrvDone, err = StartJSONRPC(svrCtx, clientCtx, tmRPCAddr, tmEndpoint, &svrCfg, idxer, syncTracker)
 		if err != nil {
 			return err
 		}
@@ -511,13 +522,14 @@ func startCmtNode(
 	cfg *cmtcfg.Config,
 	app types.Application,
 	svrCtx *server.Context,
+	syncTracker *rpctypes.StateSyncTracker,
 ) (tmNode *node.Node, cleanupFn func(), err error) {
 	nodeKey, err := p2p.LoadOrGenNodeKey(cfg.NodeKeyFile())
 	if err != nil {
 		return nil, cleanupFn, err
 	}
 
-	cmtApp := NewCometABCIWrapper(app)
+	cmtApp := NewCometABCIWrapper(app, syncTracker)
 	tmNode, err = node.NewNodeWithContext(
 		ctx,
 		cfg,
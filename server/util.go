@@ -23,14 +23,21 @@ import (
 	"path/filepath"
 	"time"
 
+	ethmetrics "github.com/ethereum/go-ethereum/metrics"
+	ethmetricsexp "github.com/ethereum/go-ethereum/metrics/exp"
+	ethprometheus "github.com/ethereum/go-ethereum/metrics/prometheus"
+	"github.com/evmos/ethermint/indexer"
 	"github.com/evmos/ethermint/server/config"
+	ethermint "github.com/evmos/ethermint/types"
 	"github.com/gorilla/mux"
 	"github.com/improbable-eng/grpc-web/go/grpcweb"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/cobra"
 	"golang.org/x/net/netutil"
 
 	log "cosmossdk.io/log"
 	dbm "github.com/cosmos/cosmos-db"
+	"github.com/cosmos/cosmos-sdk/client"
 	sdkserver "github.com/cosmos/cosmos-sdk/server"
 	"github.com/cosmos/cosmos-sdk/server/types"
 	"github.com/cosmos/cosmos-sdk/version"
@@ -149,12 +156,61 @@ func openDB(_ types.AppOptions, rootDir string, backendType dbm.BackendType) (db
 	return dbm.NewDB("application", backendType, dataDir)
 }
 
+// startMetricsServer starts the EVM metrics server, it serves the go-ethereum metrics and
+// the JSON-RPC requests metrics of the prometheus default registry.
+func startMetricsServer(logger log.Logger, address string) {
+	m := http.NewServeMux()
+	m.Handle("/debug/metrics", ethmetricsexp.ExpHandler(ethmetrics.DefaultRegistry))
+	m.Handle("/debug/metrics/prometheus", ethprometheus.Handler(ethmetrics.DefaultRegistry))
+	m.Handle("/metrics", promhttp.Handler())
+
+	logger.Info("Starting EVM metrics server", "address", address)
+	go func() {
+		srv := &http.Server{Addr: address, Handler: m, ReadHeaderTimeout: ServerStartTime}
+		if err := srv.ListenAndServe(); err != nil {
+			logger.Error("failed to run EVM metrics server", "error", err.Error())
+		}
+	}()
+}
+
 // OpenIndexerDB opens the custom eth indexer db, using the same db backend as the main app
 func OpenIndexerDB(rootDir string, backendType dbm.BackendType) (dbm.DB, error) {
 	dataDir := filepath.Join(rootDir, "data")
 	return dbm.NewDB("evmindexer", backendType, dataDir)
 }
 
+// evmIndexer is the interface of the custom eth indexer backends.
+type evmIndexer interface {
+	ethermint.EVMTxIndexer
+	ethermint.EVMLogIndexer
+	// FirstIndexedBlock returns -1 if indexer db is empty
+	FirstIndexedBlock() (int64, error)
+}
+
+// openEVMIndexer opens the custom eth indexer with the backend of the json-rpc config.
+func openEVMIndexer(
+	svrCtx *sdkserver.Context,
+	cfg config.JSONRPCConfig,
+	logger log.Logger,
+	clientCtx client.Context,
+) (evmIndexer, error) {
+	home := svrCtx.Config.RootDir
+	switch cfg.IndexerBackend {
+	case config.IndexerBackendSQL:
+		dsn := cfg.IndexerSQLDSN
+		if dsn == "" && cfg.IndexerSQLDriver == indexer.SQLDriverSQLite {
+			dsn = filepath.Join(home, "data", "evmindexer.sqlite")
+		}
+		return indexer.NewSQLIndexer(cfg.IndexerSQLDriver, dsn, logger, clientCtx)
+	default:
+		idxDB, err := OpenIndexerDB(home, sdkserver.GetAppDBBackend(svrCtx.Viper))
+		if err != nil {
+			return nil, err
+		}
+		return indexer.NewKVIndexer(idxDB, logger, clientCtx), nil
+	}
+}
+
 func openTraceWriter(traceWriterFile string) (w io.WriteCloser, err error) {
 	if traceWriterFile == "" {
 		return
@@ -16,9 +16,12 @@
 package types
 
 import (
+	"fmt"
+
 	abci "github.com/cometbft/cometbft/abci/types"
 	tmtypes "github.com/cometbft/cometbft/types"
 	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
 )
 
 // EVMTxIndexer defines the interface of custom eth tx indexer.
@@ -32,3 +35,25 @@ type EVMTxIndexer interface {
 	// GetByBlockAndIndex returns nil if tx not found.
 	GetByBlockAndIndex(int64, int32) (*TxResult, error)
 }
+
+// EVMLogIndexer defines the interface of the eth tx indexers which also index the
+// logs of the blocks, so that logs range queries don't need to go through the
+// block results.
+type EVMLogIndexer interface {
+	// LogsIndexedRange returns the first and last blocks with indexed logs, -1 if none.
+	LogsIndexedRange() (int64, int64, error)
+	// GetLogs returns the logs of the blocks [from, to] matching the addresses and
+	// topics, it fails with a LogsLimitError if there are more than limit matching logs.
+	GetLogs(from, to int64, addresses []common.Address, topics [][]common.Hash, limit int) ([]*ethtypes.Log, error)
+	// BloomStatus returns the section size and the number of sections of the bloom bits index.
+	BloomStatus() (uint64, uint64)
+}
+
+// LogsLimitError is returned by the logs queries matching more logs than their limit.
+type LogsLimitError struct {
+	Limit int
+}
+
+func (e LogsLimitError) Error() string {
+	return fmt.Sprintf("query returned more than %d results", e.Limit)
+}
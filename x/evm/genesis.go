@@ -18,7 +18,9 @@ package evm
 import (
 	"bytes"
 	"fmt"
+	"math/big"
 
+	sdkmath "cosmossdk.io/math"
 	abci "github.com/cometbft/cometbft/abci/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/ethereum/go-ethereum/common"
@@ -82,6 +84,14 @@ func InitGenesis(
 		}
 	}
 
+	// the reserve backing the fractional balances is part of the bank genesis
+	for _, balance := range data.FractionalBalances {
+		k.SetFractionalBalance(ctx, common.HexToAddress(balance.Address), balance.Amount.BigInt())
+	}
+	if err := k.ValidateFractionalReserve(ctx); err != nil {
+		panic(err)
+	}
+
 	return []abci.ValidatorUpdate{}
 }
 
@@ -109,8 +119,18 @@ func ExportGenesis(ctx sdk.Context, k *keeper.Keeper, ak types.AccountKeeper) *t
 		return false
 	})
 
+	var fractionalBalances []types.FractionalBalance
+	k.IterateFractionalBalances(ctx, func(addr common.Address, amount *big.Int) bool {
+		fractionalBalances = append(fractionalBalances, types.FractionalBalance{
+			Address: addr.String(),
+			Amount:  sdkmath.NewIntFromBigInt(amount),
+		})
+		return false
+	})
+
 	return &types.GenesisState{
-		Accounts: ethGenAccounts,
-		Params:   k.GetParams(ctx),
+		Accounts:           ethGenAccounts,
+		Params:             k.GetParams(ctx),
+		FractionalBalances: fractionalBalances,
 	}
 }
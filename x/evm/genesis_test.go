@@ -3,6 +3,8 @@ package evm_test
 import (
 	"math/big"
 
+	sdkmath "cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/ethereum/go-ethereum/common"
 
 	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
@@ -165,3 +167,33 @@ func (suite *EvmTestSuite) TestInitGenesis() {
 		})
 	}
 }
+
+func (suite *EvmTestSuite) TestFractionalBalancesGenesis() {
+	address := common.BytesToAddress([]byte("fractional"))
+	params := types.DefaultParams()
+	params.ConversionFactor = 1000000
+
+	genState := &types.GenesisState{
+		Params: params,
+		FractionalBalances: []types.FractionalBalance{
+			{Address: address.String(), Amount: sdkmath.NewInt(123)},
+		},
+	}
+	suite.Require().NoError(genState.Validate())
+
+	// the fractional balances must be backed by the reserve
+	cacheCtx, _ := suite.ctx.CacheContext()
+	suite.Require().PanicsWithError(
+		"the reserve 0aphoton doesn't back the fractional balances, expected 1aphoton",
+		func() { evm.InitGenesis(cacheCtx, suite.app.EvmKeeper, suite.app.AccountKeeper, *genState) },
+	)
+
+	reserve := sdk.NewCoins(sdk.NewInt64Coin(params.EvmDenom, 1))
+	suite.Require().NoError(suite.app.BankKeeper.MintCoins(suite.ctx, types.ModuleName, reserve))
+	evm.InitGenesis(suite.ctx, suite.app.EvmKeeper, suite.app.AccountKeeper, *genState)
+	suite.Require().Equal(big.NewInt(123), suite.app.EvmKeeper.GetFractionalBalance(suite.ctx, address))
+	suite.Require().Equal(big.NewInt(123), suite.app.EvmKeeper.GetTotalFractionalBalance(suite.ctx))
+
+	exported := evm.ExportGenesis(suite.ctx, suite.app.EvmKeeper, suite.app.AccountKeeper)
+	suite.Require().Equal(genState.FractionalBalances, exported.FractionalBalances)
+}
@@ -17,6 +17,7 @@ package keeper
 
 import (
 	"math/big"
+	"slices"
 
 	errorsmod "cosmossdk.io/errors"
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -57,8 +58,18 @@ func (k *Keeper) TxConfig(ctx sdk.Context, txHash common.Hash) statedb.TxConfig
 	)
 }
 
+// shanghaiEIPs and cancunEIPs are the instructions of the Shanghai and Cancun forks,
+// they're not part of the geth jump tables and are enabled as extra EIPs from the fork
+// blocks: PUSH0 (EIP-3855), TSTORE and TLOAD (EIP-1153), MCOPY (EIP-5656) and the
+// SELFDESTRUCT of EIP-6780.
+var (
+	shanghaiEIPs = []int{3855}
+	cancunEIPs   = []int{1153, 5656, 6780}
+)
+
 // VMConfig creates an EVM configuration from the debug setting and the extra EIPs enabled on the
-// module parameters. The config generated uses the default JumpTable from the EVM.
+// module parameters. The config generated uses the default JumpTable from the EVM, the
+// instructions of Shanghai and Cancun are enabled as extra EIPs from their fork blocks.
 func (k Keeper) VMConfig(ctx sdk.Context, _ core.Message, cfg *statedb.EVMConfig, tracer vm.EVMLogger) vm.Config {
 	noBaseFee := true
 	if types.IsLondon(cfg.ChainConfig, ctx.BlockHeight()) {
@@ -70,10 +81,24 @@ func (k Keeper) VMConfig(ctx sdk.Context, _ core.Message, cfg *statedb.EVMConfig
 		debug = true
 	}
 
+	extraEIPs := cfg.Params.EIPs()
+	var forkEIPs []int
+	if types.IsShanghai(cfg.ChainConfig, ctx.BlockHeight()) {
+		forkEIPs = append(forkEIPs, shanghaiEIPs...)
+	}
+	if types.IsCancun(cfg.ChainConfig, ctx.BlockHeight()) {
+		forkEIPs = append(forkEIPs, cancunEIPs...)
+	}
+	for _, eip := range forkEIPs {
+		if !slices.Contains(extraEIPs, eip) {
+			extraEIPs = append(extraEIPs, eip)
+		}
+	}
+
 	return vm.Config{
 		Debug:     debug,
 		Tracer:    tracer,
 		NoBaseFee: noBaseFee,
-		ExtraEips: cfg.Params.EIPs(),
+		ExtraEips: extraEIPs,
 	}
 }
@@ -0,0 +1,189 @@
+// Copyright 2021 Evmos Foundation
+// This file is part of Evmos' Ethermint library.
+//
+// The Ethermint library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Ethermint library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Ethermint library. If not, see https://github.com/evmos/ethermint/blob/main/LICENSE
+package keeper
+
+import (
+	"fmt"
+	"math/big"
+
+	errorsmod "cosmossdk.io/errors"
+	sdkmath "cosmossdk.io/math"
+	"cosmossdk.io/store/prefix"
+	storetypes "cosmossdk.io/store/types"
+	"github.com/cosmos/cosmos-sdk/runtime"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	errortypes "github.com/cosmos/cosmos-sdk/types/errors"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/evmos/ethermint/x/evm/types"
+)
+
+// ----------------------------------------------------------------------------
+// Fractional balances
+// ----------------------------------------------------------------------------
+//
+// When the conversion factor is above one, an EVM balance in wei is stored as
+// the bank balance of the EVM denom, in units of conversion factor wei, and the
+// fractional balance holding the remainder. The EVM module account holds the
+// reserve backing the fractional balances, which is the total of the fractional
+// balances rounded up to a unit.
+
+// GetFractionalBalance returns the sub-unit remainder of the EVM balance of an
+// address in wei.
+func (k Keeper) GetFractionalBalance(ctx sdk.Context, addr common.Address) *big.Int {
+	store := prefix.NewStore(runtime.KVStoreAdapter(k.storeService.OpenKVStore(ctx)), types.KeyPrefixFractionalBalance)
+	return new(big.Int).SetBytes(store.Get(addr.Bytes()))
+}
+
+// SetFractionalBalance sets the sub-unit remainder of the EVM balance of an address
+// in wei, and updates the total of the fractional balances. The reserve is not
+// updated.
+func (k Keeper) SetFractionalBalance(ctx sdk.Context, addr common.Address, amount *big.Int) {
+	kvStore := runtime.KVStoreAdapter(k.storeService.OpenKVStore(ctx))
+	store := prefix.NewStore(kvStore, types.KeyPrefixFractionalBalance)
+
+	total := k.GetTotalFractionalBalance(ctx)
+	total.Sub(total, new(big.Int).SetBytes(store.Get(addr.Bytes())))
+	total.Add(total, amount)
+
+	if amount.Sign() == 0 {
+		store.Delete(addr.Bytes())
+	} else {
+		store.Set(addr.Bytes(), amount.Bytes())
+	}
+	if total.Sign() == 0 {
+		kvStore.Delete(types.KeyTotalFractionalBalance)
+	} else {
+		kvStore.Set(types.KeyTotalFractionalBalance, total.Bytes())
+	}
+}
+
+// GetTotalFractionalBalance returns the total of the fractional balances in wei.
+func (k Keeper) GetTotalFractionalBalance(ctx sdk.Context) *big.Int {
+	store := runtime.KVStoreAdapter(k.storeService.OpenKVStore(ctx))
+	return new(big.Int).SetBytes(store.Get(types.KeyTotalFractionalBalance))
+}
+
+// IterateFractionalBalances iterates the fractional balances, callback return true
+// to break early.
+func (k Keeper) IterateFractionalBalances(ctx sdk.Context, cb func(addr common.Address, amount *big.Int) (stop bool)) {
+	store := runtime.KVStoreAdapter(k.storeService.OpenKVStore(ctx))
+	iterator := storetypes.KVStorePrefixIterator(store, types.KeyPrefixFractionalBalance)
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		addr := common.BytesToAddress(iterator.Key()[len(types.KeyPrefixFractionalBalance):])
+		if cb(addr, new(big.Int).SetBytes(iterator.Value())) {
+			return
+		}
+	}
+}
+
+// ValidateFractionalReserve returns an error if the balance of the EVM module account
+// is not the reserve backing the total of the fractional balances.
+func (k Keeper) ValidateFractionalReserve(ctx sdk.Context) error {
+	params := k.GetParams(ctx)
+	expected := weiToUnits(k.GetTotalFractionalBalance(ctx), params.EVMConversionFactor(), true)
+	reserve := k.bankKeeper.GetBalance(ctx, authtypes.NewModuleAddress(types.ModuleName), params.EvmDenom)
+	if reserve.Amount.BigInt().Cmp(expected) != 0 {
+		return fmt.Errorf("the reserve %s doesn't back the fractional balances, expected %s%s", reserve, expected, params.EvmDenom)
+	}
+	return nil
+}
+
+// setExtendedBalance sets the EVM balance of an address as the bank balance in units
+// and the fractional balance.
+func (k *Keeper) setExtendedBalance(ctx sdk.Context, addr common.Address, amount *big.Int, params types.Params) error {
+	units, fractional := new(big.Int).QuoRem(amount, params.EVMConversionFactor(), new(big.Int))
+	if err := k.setBankBalance(ctx, sdk.AccAddress(addr.Bytes()), units, params.EvmDenom); err != nil {
+		return err
+	}
+	return k.setBackedFractionalBalance(ctx, addr, fractional, params)
+}
+
+// setBackedFractionalBalance sets the fractional balance of an address, then mints or
+// burns the reserve to keep it backing the total of the fractional balances.
+func (k *Keeper) setBackedFractionalBalance(ctx sdk.Context, addr common.Address, fractional *big.Int, params types.Params) error {
+	factor := params.EVMConversionFactor()
+	reserveBefore := weiToUnits(k.GetTotalFractionalBalance(ctx), factor, true)
+	k.SetFractionalBalance(ctx, addr, fractional)
+	reserveAfter := weiToUnits(k.GetTotalFractionalBalance(ctx), factor, true)
+
+	delta := new(big.Int).Sub(reserveAfter, reserveBefore)
+	switch delta.Sign() {
+	case 1:
+		coins := sdk.NewCoins(sdk.NewCoin(params.EvmDenom, sdkmath.NewIntFromBigInt(delta)))
+		return k.bankKeeper.MintCoins(ctx, types.ModuleName, coins)
+	case -1:
+		coins := sdk.NewCoins(sdk.NewCoin(params.EvmDenom, sdkmath.NewIntFromBigInt(new(big.Int).Neg(delta))))
+		return k.bankKeeper.BurnCoins(ctx, types.ModuleName, coins)
+	default:
+		return nil
+	}
+}
+
+// transferExtendedBalance moves an amount in wei from the EVM balance of an address
+// to another one.
+func (k *Keeper) transferExtendedBalance(ctx sdk.Context, from, to common.Address, amount *big.Int, params types.Params) error {
+	fromBalance := k.GetBalance(ctx, from)
+	if fromBalance.Cmp(amount) < 0 {
+		return errorsmod.Wrapf(errortypes.ErrInsufficientFunds, "%s wei is smaller than %s wei", fromBalance, amount)
+	}
+	if err := k.setExtendedBalance(ctx, from, fromBalance.Sub(fromBalance, amount), params); err != nil {
+		return err
+	}
+	toBalance := k.GetBalance(ctx, to)
+	return k.setExtendedBalance(ctx, to, toBalance.Add(toBalance, amount), params)
+}
+
+// sendExtendedBalanceToModule moves an amount in wei from the EVM balance of an address
+// to the one of a module account. The module accounts can't receive funds from the
+// accounts, the units they gain are minted to the EVM module account and sent from it.
+func (k *Keeper) sendExtendedBalanceToModule(ctx sdk.Context, from common.Address, module string, amount *big.Int, params types.Params) error {
+	fromBalance := k.GetBalance(ctx, from)
+	if fromBalance.Cmp(amount) < 0 {
+		return errorsmod.Wrapf(errortypes.ErrInsufficientFunds, "%s wei is smaller than %s wei", fromBalance, amount)
+	}
+	if err := k.setExtendedBalance(ctx, from, fromBalance.Sub(fromBalance, amount), params); err != nil {
+		return err
+	}
+
+	moduleAddr := authtypes.NewModuleAddress(module)
+	balance := k.GetBalance(ctx, common.BytesToAddress(moduleAddr))
+	units, fractional := new(big.Int).QuoRem(balance.Add(balance, amount), params.EVMConversionFactor(), new(big.Int))
+	units.Sub(units, k.bankKeeper.GetBalance(ctx, moduleAddr, params.EvmDenom).Amount.BigInt())
+	if units.Sign() > 0 {
+		coins := sdk.NewCoins(sdk.NewCoin(params.EvmDenom, sdkmath.NewIntFromBigInt(units)))
+		if err := k.bankKeeper.MintCoins(ctx, types.ModuleName, coins); err != nil {
+			return err
+		}
+		if err := k.bankKeeper.SendCoinsFromModuleToModule(ctx, types.ModuleName, module, coins); err != nil {
+			return err
+		}
+	}
+	return k.setBackedFractionalBalance(ctx, common.BytesToAddress(moduleAddr), fractional, params)
+}
+
+// weiToUnits converts an amount in wei to units of the EVM denom, rounding up or
+// down the remainder.
+func weiToUnits(amount, factor *big.Int, roundUp bool) *big.Int {
+	units, remainder := new(big.Int).QuoRem(amount, factor, new(big.Int))
+	if roundUp && remainder.Sign() > 0 {
+		units.Add(units, big.NewInt(1))
+	}
+	return units
+}
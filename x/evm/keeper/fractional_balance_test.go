@@ -0,0 +1,152 @@
+package keeper_test
+
+import (
+	"math/big"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/evmos/ethermint/tests"
+	"github.com/evmos/ethermint/testutil"
+	"github.com/evmos/ethermint/x/evm/types"
+)
+
+// conversionFactor is the factor of a 6 decimals evm denom.
+var conversionFactor = big.NewInt(1000000000000)
+
+func (suite *KeeperTestSuite) setConversionFactor() {
+	params := suite.app.EvmKeeper.GetParams(suite.ctx)
+	params.ConversionFactor = conversionFactor.Uint64()
+	suite.Require().NoError(suite.app.EvmKeeper.SetParams(suite.ctx, params))
+}
+
+func (suite *KeeperTestSuite) bankBalance(addr sdk.AccAddress) int64 {
+	return suite.app.BankKeeper.GetBalance(suite.ctx, addr, types.DefaultEVMDenom).Amount.Int64()
+}
+
+func (suite *KeeperTestSuite) reserveBalance() int64 {
+	return suite.bankBalance(authtypes.NewModuleAddress(types.ModuleName))
+}
+
+func (suite *KeeperTestSuite) TestExtendedBalance() {
+	suite.setConversionFactor()
+	addr1, addr2 := tests.GenerateAddress(), tests.GenerateAddress()
+
+	testCases := []struct {
+		name          string
+		addr          common.Address
+		amount        string
+		expUnits      int64
+		expFractional string
+		expReserve    int64
+	}{
+		{"units and fraction", addr1, "2500000000007", 2, "500000000007", 1},
+		{"fraction of another account", addr2, "600000000000", 0, "600000000000", 2},
+		{"total fraction exceeds a unit", addr1, "2500000000000", 2, "500000000000", 2},
+		{"whole units", addr1, "3000000000000", 3, "0", 1},
+		{"zero", addr2, "0", 0, "0", 0},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			amount, _ := new(big.Int).SetString(tc.amount, 10)
+			expFractional, _ := new(big.Int).SetString(tc.expFractional, 10)
+
+			suite.Require().NoError(suite.app.EvmKeeper.SetBalance(suite.ctx, tc.addr, amount))
+			suite.Require().Equal(amount.String(), suite.app.EvmKeeper.GetBalance(suite.ctx, tc.addr).String())
+			suite.Require().Equal(tc.expUnits, suite.bankBalance(tc.addr.Bytes()))
+			suite.Require().Equal(expFractional.String(), suite.app.EvmKeeper.GetFractionalBalance(suite.ctx, tc.addr).String())
+			suite.Require().Equal(tc.expReserve, suite.reserveBalance())
+		})
+	}
+
+	// the zero fractional balance is deleted
+	suite.app.EvmKeeper.IterateFractionalBalances(suite.ctx, func(addr common.Address, _ *big.Int) bool {
+		suite.Require().Fail("unexpected fractional balance", addr.Hex())
+		return false
+	})
+	suite.Require().Zero(suite.app.EvmKeeper.GetTotalFractionalBalance(suite.ctx).Sign())
+}
+
+func (suite *KeeperTestSuite) TestExtendedBalanceTransfer() {
+	suite.setConversionFactor()
+	recipient := tests.GenerateAddress()
+
+	vmdb := suite.StateDB()
+	vmdb.AddBalance(suite.address, big.NewInt(1000000000000))
+	suite.Require().NoError(vmdb.Commit())
+
+	vmdb = suite.StateDB()
+	vmdb.SubBalance(suite.address, big.NewInt(1))
+	vmdb.AddBalance(recipient, big.NewInt(1))
+	suite.Require().NoError(vmdb.Commit())
+
+	suite.Require().Equal(big.NewInt(999999999999), suite.app.EvmKeeper.GetBalance(suite.ctx, suite.address))
+	suite.Require().Equal(big.NewInt(1), suite.app.EvmKeeper.GetBalance(suite.ctx, recipient))
+	// the transferred wei are backed by the reserve, the supply is unchanged
+	suite.Require().Equal(int64(0), suite.bankBalance(suite.address.Bytes()))
+	suite.Require().Equal(int64(1), suite.reserveBalance())
+	suite.Require().Equal(int64(1), suite.app.BankKeeper.GetSupply(suite.ctx, types.DefaultEVMDenom).Amount.Int64())
+}
+
+func (suite *KeeperTestSuite) TestRefundGasConversionFactor() {
+	suite.setConversionFactor()
+	err := testutil.FundAccount(suite.app.BankKeeper, suite.ctx, suite.address.Bytes(), sdk.NewCoins(sdk.NewInt64Coin(types.DefaultEVMDenom, 10)))
+	suite.Require().NoError(err)
+	feeCollector := authtypes.NewModuleAddress(authtypes.FeeCollectorName)
+	collected := suite.bankBalance(feeCollector)
+
+	// 200000 gas at 10^7 wei are 2 units of fees, 50000 gas used cost 0.5 unit
+	msg := ethtypes.NewMessage(suite.address, nil, 0, big.NewInt(0), 200000, big.NewInt(10000000), nil, nil, nil, nil, true)
+	fees := sdk.NewCoins(sdk.NewInt64Coin(types.DefaultEVMDenom, 2000000000000))
+	suite.Require().NoError(suite.app.EvmKeeper.DeductTxCostsFromUserBalance(suite.ctx, fees, suite.address))
+	suite.Require().NoError(suite.app.EvmKeeper.RefundGas(suite.ctx, msg, 150000, types.DefaultEVMDenom))
+
+	// the sender pays the exact cost, the fee collector keeps it as fractional balance
+	suite.Require().Equal("9500000000000", suite.app.EvmKeeper.GetBalance(suite.ctx, suite.address).String())
+	suite.Require().Equal(int64(9), suite.bankBalance(suite.address.Bytes()))
+	suite.Require().Equal(collected, suite.bankBalance(feeCollector))
+	suite.Require().Equal("500000000000", suite.app.EvmKeeper.GetFractionalBalance(suite.ctx, common.BytesToAddress(feeCollector)).String())
+	suite.Require().Equal(int64(1), suite.reserveBalance())
+	suite.Require().NoError(suite.app.EvmKeeper.ValidateFractionalReserve(suite.ctx))
+
+	// the fees rounded up are refunded without leftover gas
+	fees = sdk.NewCoins(sdk.NewInt64Coin(types.DefaultEVMDenom, 1500000000000))
+	msg = ethtypes.NewMessage(suite.address, nil, 0, big.NewInt(0), 150000, big.NewInt(10000000), nil, nil, nil, nil, true)
+	suite.Require().NoError(suite.app.EvmKeeper.DeductTxCostsFromUserBalance(suite.ctx, fees, suite.address))
+	suite.Require().NoError(suite.app.EvmKeeper.RefundGas(suite.ctx, msg, 0, types.DefaultEVMDenom))
+	suite.Require().Equal("8000000000000", suite.app.EvmKeeper.GetBalance(suite.ctx, suite.address).String())
+	suite.Require().Equal(collected+2, suite.bankBalance(feeCollector))
+	suite.Require().Zero(suite.app.EvmKeeper.GetTotalFractionalBalance(suite.ctx).Sign())
+	suite.Require().Zero(suite.reserveBalance())
+}
+
+func (suite *KeeperTestSuite) TestDeductTxCostsConversionFactor() {
+	suite.setConversionFactor()
+	err := testutil.FundAccount(suite.app.BankKeeper, suite.ctx, suite.address.Bytes(), sdk.NewCoins(sdk.NewInt64Coin(types.DefaultEVMDenom, 10)))
+	suite.Require().NoError(err)
+	feeCollector := authtypes.NewModuleAddress(authtypes.FeeCollectorName)
+	collected := suite.bankBalance(feeCollector)
+
+	// 1.5 units of fees are deducted in wei, the fee collector keeps half a unit as fractional balance
+	fees := sdk.NewCoins(sdk.NewInt64Coin(types.DefaultEVMDenom, 1500000000000))
+	suite.Require().NoError(suite.app.EvmKeeper.DeductTxCostsFromUserBalance(suite.ctx, fees, suite.address))
+	suite.Require().Equal("8500000000000", suite.app.EvmKeeper.GetBalance(suite.ctx, suite.address).String())
+	suite.Require().Equal(int64(8), suite.bankBalance(suite.address.Bytes()))
+	suite.Require().Equal(collected+1, suite.bankBalance(feeCollector))
+	suite.Require().Equal("500000000000", suite.app.EvmKeeper.GetFractionalBalance(suite.ctx, common.BytesToAddress(feeCollector)).String())
+	suite.Require().NoError(suite.app.EvmKeeper.ValidateFractionalReserve(suite.ctx))
+
+	// the fractional balance of the sender is spent
+	fees = sdk.NewCoins(sdk.NewInt64Coin(types.DefaultEVMDenom, 8500000000000))
+	suite.Require().NoError(suite.app.EvmKeeper.DeductTxCostsFromUserBalance(suite.ctx, fees, suite.address))
+	suite.Require().Zero(suite.app.EvmKeeper.GetBalance(suite.ctx, suite.address).Sign())
+	suite.Require().Equal(collected+10, suite.bankBalance(feeCollector))
+	suite.Require().Zero(suite.app.EvmKeeper.GetTotalFractionalBalance(suite.ctx).Sign())
+	suite.Require().NoError(suite.app.EvmKeeper.ValidateFractionalReserve(suite.ctx))
+
+	fees = sdk.NewCoins(sdk.NewInt64Coin(types.DefaultEVMDenom, 1))
+	suite.Require().Error(suite.app.EvmKeeper.DeductTxCostsFromUserBalance(suite.ctx, fees, suite.address))
+}
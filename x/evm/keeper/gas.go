@@ -18,6 +18,7 @@ package keeper
 import (
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/params"
 
@@ -46,6 +47,9 @@ func (k *Keeper) GetEthIntrinsicGas(ctx sdk.Context, msg core.Message, cfg *para
 func (k *Keeper) RefundGas(ctx sdk.Context, msg core.Message, leftoverGas uint64, denom string) error {
 	// Return EVM tokens for remaining gas, exchanged at the original rate.
 	remaining := new(big.Int).Mul(new(big.Int).SetUint64(leftoverGas), msg.GasPrice())
+	if params := k.GetParams(ctx); params.EVMConversionFactor().Cmp(big.NewInt(1)) > 0 && remaining.Sign() >= 0 {
+		return k.refundExtendedGas(ctx, msg, leftoverGas, params)
+	}
 
 	switch remaining.Sign() {
 	case -1:
@@ -69,6 +73,23 @@ func (k *Keeper) RefundGas(ctx sdk.Context, msg core.Message, leftoverGas uint64
 	return nil
 }
 
+// refundExtendedGas refunds the cost of the leftover gas in wei to the sender. The fee
+// deducted in the AnteHandler is in wei too, so the sender pays the exact cost and the
+// fee collector keeps the sub-unit remainder as fractional balance.
+func (k *Keeper) refundExtendedGas(ctx sdk.Context, msg core.Message, leftoverGas uint64, params types.Params) error {
+	refund := new(big.Int).Mul(new(big.Int).SetUint64(leftoverGas), msg.GasPrice())
+	if refund.Sign() == 0 {
+		return nil
+	}
+
+	feeCollector := common.BytesToAddress(authtypes.NewModuleAddress(authtypes.FeeCollectorName))
+	if err := k.transferExtendedBalance(ctx, feeCollector, msg.From(), refund, params); err != nil {
+		err = errorsmod.Wrapf(err, "fee collector account failed to refund fees")
+		return errorsmod.Wrapf(err, "failed to refund %d leftover gas (%s wei)", leftoverGas, refund)
+	}
+	return nil
+}
+
 // ResetGasMeterAndConsumeGas reset first the gas meter consumed value to zero and set it back to the new value
 // 'gasUsed'
 func (k *Keeper) ResetGasMeterAndConsumeGas(ctx sdk.Context, gasUsed uint64) {
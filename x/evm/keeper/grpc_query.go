@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
 	"time"
 
@@ -37,10 +38,12 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
 	ethparams "github.com/ethereum/go-ethereum/params"
 
 	ethermint "github.com/evmos/ethermint/types"
 	"github.com/evmos/ethermint/x/evm/statedb"
+	evmtracers "github.com/evmos/ethermint/x/evm/tracers"
 	"github.com/evmos/ethermint/x/evm/types"
 )
 
@@ -190,6 +193,26 @@ func (k Keeper) Storage(c context.Context, req *types.QueryStorageRequest) (*typ
 	}, nil
 }
 
+// StorageRoot implements the Query/StorageRoot gRPC method
+func (k Keeper) StorageRoot(c context.Context, req *types.QueryStorageRootRequest) (*types.QueryStorageRootResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "empty request")
+	}
+
+	if err := ethermint.ValidateAddress(req.Address); err != nil {
+		return nil, status.Error(
+			codes.InvalidArgument,
+			types.ErrZeroAddress.Error(),
+		)
+	}
+
+	ctx := sdk.UnwrapSDKContext(c)
+
+	return &types.QueryStorageRootResponse{
+		StorageRoot: k.GetStorageHash(ctx, common.HexToAddress(req.Address)).Hex(),
+	}, nil
+}
+
 // Code implements the Query/Code gRPC method
 func (k Keeper) Code(c context.Context, req *types.QueryCodeRequest) (*types.QueryCodeResponse, error) {
 	if req == nil {
@@ -249,9 +272,13 @@ func (k Keeper) EthCall(c context.Context, req *types.EthCallRequest) (*types.Ms
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
+	cfg.Overrides, err = parseStateOverrides(req.Overrides)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 
 	// ApplyMessageWithConfig expect correct nonce set in msg
-	nonce := k.GetNonce(ctx, args.GetFrom())
+	nonce := cfg.Overrides.Nonce(args.GetFrom(), k.GetNonce(ctx, args.GetFrom()))
 	args.Nonce = (*hexutil.Uint64)(&nonce)
 
 	msg, err := args.ToMessage(req.GasCap, cfg.BaseFee)
@@ -270,6 +297,281 @@ func (k Keeper) EthCall(c context.Context, req *types.EthCallRequest) (*types.Ms
 	return res, nil
 }
 
+// CreateAccessList implements eth_createAccessList rpc api. The call is executed with
+// the access list tracer until the generated access list doesn't change anymore.
+func (k Keeper) CreateAccessList(c context.Context, req *types.EthCallRequest) (*types.CreateAccessListResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "empty request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(c)
+
+	var args types.TransactionArgs
+	err := json.Unmarshal(req.Args, &args)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	chainID, err := getChainID(ctx, req.ChainId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	cfg, err := k.EVMConfig(ctx, GetProposerAddress(ctx, req.ProposerAddress), chainID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	cfg.Overrides, err = parseStateOverrides(req.Overrides)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// ApplyMessageWithConfig expect correct nonce set in msg
+	nonce := cfg.Overrides.Nonce(args.GetFrom(), k.GetNonce(ctx, args.GetFrom()))
+	args.Nonce = (*hexutil.Uint64)(&nonce)
+
+	// the sender, the recipient and the precompiles are always warm,
+	// they don't need to be part of the access list
+	to := crypto.CreateAddress(args.GetFrom(), nonce)
+	if args.To != nil {
+		to = *args.To
+	}
+	rules := cfg.ChainConfig.Rules(big.NewInt(ctx.BlockHeight()), cfg.ChainConfig.MergeNetsplitBlock != nil)
+	precompiles := k.activePrecompileAddresses(rules, cfg.Params)
+
+	var accessList ethtypes.AccessList
+	if args.AccessList != nil {
+		accessList = *args.AccessList
+	}
+	prevTracer := logger.NewAccessListTracer(accessList, args.GetFrom(), to, precompiles)
+
+	txConfig := statedb.NewEmptyTxConfig(common.BytesToHash(ctx.HeaderHash()))
+	for {
+		// expand the access list of the previous execution
+		accessList = prevTracer.AccessList()
+		args.AccessList = &accessList
+
+		msg, err := args.ToMessage(req.GasCap, cfg.BaseFee)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		tracer := logger.NewAccessListTracer(accessList, args.GetFrom(), to, precompiles)
+		// pass false to not commit StateDB
+		res, err := k.ApplyMessageWithConfig(ctx, msg, tracer, false, cfg, txConfig)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		if !tracer.Equal(prevTracer) {
+			prevTracer = tracer
+			continue
+		}
+
+		gasUsed := res.GasUsed
+		if !res.Failed() {
+			// the gas used is bounded by the minimum gas multiplier of the gas limit,
+			// estimate the gas limit actually needed with the access list instead.
+			estimateReq := *req
+			if estimateReq.Args, err = json.Marshal(&args); err != nil {
+				return nil, status.Error(codes.Internal, err.Error())
+			}
+			estimate, err := k.EstimateGas(c, &estimateReq)
+			if err != nil {
+				return nil, err
+			}
+			gasUsed = estimate.Gas
+		}
+
+		return &types.CreateAccessListResponse{
+			AccessList: types.NewAccessList(&accessList),
+			GasUsed:    gasUsed,
+			VmError:    res.VmError,
+		}, nil
+	}
+}
+
+// EthSimulate implements eth_simulateV1 rpc api. The blocks are simulated on top of
+// each other, every block executes its calls sequentially on its own StateDB, which is
+// committed to the query context before the next block.
+func (k Keeper) EthSimulate(c context.Context, req *types.EthSimulateRequest) (*types.EthSimulateResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "empty request")
+	}
+
+	// the simulated blocks are committed on a branch of the query state
+	ctx, _ := sdk.UnwrapSDKContext(c).CacheContext()
+
+	var opts types.SimOpts
+	if err := json.Unmarshal(req.Opts, &opts); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if len(opts.BlockStateCalls) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "empty input")
+	}
+	if len(opts.BlockStateCalls) > types.MaxSimulateBlocks {
+		return nil, status.Errorf(codes.InvalidArgument, "too many blocks, max is %d", types.MaxSimulateBlocks)
+	}
+
+	chainID, err := getChainID(ctx, req.ChainId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	cfg, err := k.EVMConfig(ctx, GetProposerAddress(ctx, req.ProposerAddress), chainID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	parent := &ethtypes.Header{
+		Number: big.NewInt(ctx.BlockHeight()),
+		Time:   uint64(ctx.BlockTime().Unix()), //#nosec G115
+	}
+	parentHash := common.BytesToHash(ctx.HeaderHash())
+	// the gas cap is shared by all the calls
+	gasCap := req.GasCap
+	if gasCap == 0 {
+		gasCap = uint64(math.MaxUint64 / 2)
+	}
+
+	results := make([]types.SimBlockResult, 0, len(opts.BlockStateCalls))
+	for i := range opts.BlockStateCalls {
+		block := &opts.BlockStateCalls[i]
+		header := &ethtypes.Header{
+			ParentHash: parentHash,
+			Number:     new(big.Int).Add(parent.Number, common.Big1),
+			Time:       parent.Time + types.SimulateTimestampIncrement,
+			Coinbase:   cfg.CoinBase,
+			BaseFee:    cfg.BaseFee,
+			GasLimit:   ethermint.BlockGasLimit(ctx),
+		}
+		if overrides := block.BlockOverrides; overrides != nil {
+			if overrides.Number != nil {
+				if overrides.Number.ToInt().Cmp(parent.Number) <= 0 {
+					return nil, status.Errorf(codes.InvalidArgument, "block numbers must be in order: %s <= %s", overrides.Number.ToInt(), parent.Number)
+				}
+				header.Number = overrides.Number.ToInt()
+			}
+			if overrides.Time != nil {
+				if uint64(*overrides.Time) <= parent.Time {
+					return nil, status.Errorf(codes.InvalidArgument, "block timestamps must be in order: %d <= %d", uint64(*overrides.Time), parent.Time)
+				}
+				header.Time = uint64(*overrides.Time)
+			}
+			if overrides.FeeRecipient != nil {
+				header.Coinbase = *overrides.FeeRecipient
+			}
+			if overrides.BaseFeePerGas != nil {
+				header.BaseFee = overrides.BaseFeePerGas.ToInt()
+			}
+		}
+
+		result, err := k.simulateBlock(ctx, cfg, chainID, block, header, &gasCap)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *result)
+		parent = header
+		parentHash = result.Hash
+	}
+
+	bz, err := json.Marshal(results)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &types.EthSimulateResponse{Result: bz}, nil
+}
+
+// simulateBlock executes the calls of a simulated block on a shared StateDB and
+// commits it. The gas used by the calls is deducted from the remaining gas cap.
+func (k Keeper) simulateBlock(
+	ctx sdk.Context,
+	cfg *statedb.EVMConfig,
+	chainID *big.Int,
+	block *types.SimBlock,
+	header *ethtypes.Header,
+	gasCap *uint64,
+) (*types.SimBlockResult, error) {
+	if !header.Number.IsInt64() {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid block number %s", header.Number)
+	}
+	ctx = ctx.WithBlockHeight(header.Number.Int64())
+	ctx = ctx.WithBlockTime(time.Unix(int64(header.Time), 0).UTC()) //#nosec G115
+
+	blockCfg := *cfg
+	blockCfg.CoinBase = header.Coinbase
+	blockCfg.BaseFee = header.BaseFee
+
+	overrides, err := parseStateOverrides(block.StateOverrides)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	k.storageToStateDiff(ctx, overrides)
+
+	stateDB := statedb.New(ctx, &k, statedb.NewEmptyTxConfig(common.Hash{}))
+	if err := overrides.Apply(stateDB); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var logIndex uint
+	calls := make([]types.SimCallResult, 0, len(block.Calls))
+	for i := range block.Calls {
+		args := block.Calls[i]
+		if args.Nonce == nil {
+			nonce := stateDB.GetNonce(args.GetFrom())
+			args.Nonce = (*hexutil.Uint64)(&nonce)
+		}
+		if args.ChainID == nil {
+			args.ChainID = (*hexutil.Big)(chainID)
+		}
+		if *gasCap == 0 {
+			return nil, status.Error(codes.InvalidArgument, "gas cap exhausted")
+		}
+
+		msg, err := args.ToMessage(*gasCap, header.BaseFee)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		gas := msg.Gas()
+		args.Gas = (*hexutil.Uint64)(&gas)
+
+		txConfig := statedb.NewTxConfig(common.Hash{}, args.ToTransaction().AsTransaction().Hash(), uint(i), logIndex)
+		stateDB.SetTxConfig(txConfig)
+		res, err := k.applyMessageWithStateDB(ctx, msg, nil, false, &blockCfg, txConfig, stateDB)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		// the simulated calls are transactions, the nonce is increased no matter the result
+		stateDB.SetNonce(msg.From(), msg.Nonce()+1)
+
+		*gasCap -= res.GasUsed
+		header.GasUsed += res.GasUsed
+		logIndex += uint(len(res.Logs))
+		calls = append(calls, types.NewSimCallResult(res))
+	}
+
+	if err := stateDB.Commit(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	hash := header.Hash()
+	for _, call := range calls {
+		for _, log := range call.Logs {
+			log.BlockHash = hash
+			log.BlockNumber = header.Number.Uint64()
+		}
+	}
+
+	return &types.SimBlockResult{
+		Number:        hexutil.Uint64(header.Number.Uint64()),
+		Hash:          hash,
+		ParentHash:    header.ParentHash,
+		Timestamp:     hexutil.Uint64(header.Time),
+		GasLimit:      hexutil.Uint64(header.GasLimit),
+		GasUsed:       hexutil.Uint64(header.GasUsed),
+		FeeRecipient:  header.Coinbase,
+		BaseFeePerGas: (*hexutil.Big)(header.BaseFee),
+		Calls:         calls,
+	}, nil
+}
+
 // EstimateGas implements eth_estimateGas rpc api.
 func (k Keeper) EstimateGas(c context.Context, req *types.EthCallRequest) (*types.EstimateGasResponse, error) {
 	if req == nil {
@@ -312,20 +614,51 @@ func (k Keeper) EstimateGas(c context.Context, req *types.EthCallRequest) (*type
 		}
 	}
 
-	// TODO: Recap the highest gas limit with account's available balance.
+	cfg, err := k.EVMConfig(ctx, GetProposerAddress(ctx, req.ProposerAddress), chainID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to load evm config")
+	}
+	cfg.Overrides, err = parseStateOverrides(req.Overrides)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Recap the highest gas limit with account's available balance.
+	var feeCap *big.Int
+	switch {
+	case args.GasPrice != nil && (args.MaxFeePerGas != nil || args.MaxPriorityFeePerGas != nil):
+		return nil, status.Error(codes.InvalidArgument, "both gasPrice and (maxFeePerGas or maxPriorityFeePerGas) specified")
+	case args.GasPrice != nil:
+		feeCap = args.GasPrice.ToInt()
+	case args.MaxFeePerGas != nil:
+		feeCap = args.MaxFeePerGas.ToInt()
+	default:
+		feeCap = common.Big0
+	}
+	if feeCap.BitLen() != 0 {
+		available := new(big.Int).Set(cfg.Overrides.Balance(args.GetFrom(), k.GetBalance(ctx, args.GetFrom())))
+		if args.Value != nil {
+			if args.Value.ToInt().Cmp(available) >= 0 {
+				return nil, core.ErrInsufficientFundsForTransfer
+			}
+			available.Sub(available, args.Value.ToInt())
+		}
+		allowance := new(big.Int).Div(available, feeCap)
+
+		// If the allowance is larger than maximum uint64, skip checking
+		if allowance.IsUint64() && hi > allowance.Uint64() {
+			hi = allowance.Uint64()
+		}
+	}
 
 	// Recap the highest gas allowance with specified gascap.
 	if req.GasCap != 0 && hi > req.GasCap {
 		hi = req.GasCap
 	}
 	gasCap = hi
-	cfg, err := k.EVMConfig(ctx, GetProposerAddress(ctx, req.ProposerAddress), chainID)
-	if err != nil {
-		return nil, status.Error(codes.Internal, "failed to load evm config")
-	}
 
 	// ApplyMessageWithConfig expect correct nonce set in msg
-	nonce := k.GetNonce(ctx, args.GetFrom())
+	nonce := cfg.Overrides.Nonce(args.GetFrom(), k.GetNonce(ctx, args.GetFrom()))
 	args.Nonce = (*hexutil.Uint64)(&nonce)
 
 	txConfig := statedb.NewEmptyTxConfig(common.BytesToHash(ctx.HeaderHash()))
@@ -471,6 +804,96 @@ func (k Keeper) TraceTx(c context.Context, req *types.QueryTraceTxRequest) (*typ
 	}, nil
 }
 
+// TraceCall implements the `debug_traceCall` rpc api. It executes the call on the state
+// of the requested block, with the state and block overrides applied, and returns the
+// result of the tracer of the trace config.
+func (k Keeper) TraceCall(c context.Context, req *types.QueryTraceCallRequest) (*types.QueryTraceCallResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "empty request")
+	}
+
+	if req.TraceConfig != nil && req.TraceConfig.Limit < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "output limit cannot be negative, got %d", req.TraceConfig.Limit)
+	}
+
+	var args types.TransactionArgs
+	if err := json.Unmarshal(req.Args, &args); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	var blockOverrides types.BlockOverrides
+	if len(req.BlockOverrides) > 0 {
+		if err := json.Unmarshal(req.BlockOverrides, &blockOverrides); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	ctx := sdk.UnwrapSDKContext(c)
+	if req.BlockNumber > 0 {
+		ctx = ctx.WithBlockHeight(req.BlockNumber)
+		ctx = ctx.WithBlockTime(req.BlockTime)
+		ctx = ctx.WithHeaderHash(common.Hex2Bytes(req.BlockHash))
+	}
+	chainID, err := getChainID(ctx, req.ChainId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	cfg, err := k.EVMConfig(ctx, GetProposerAddress(ctx, req.ProposerAddress), chainID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to load evm config: %s", err.Error())
+	}
+	cfg.Overrides, err = parseStateOverrides(req.Overrides)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if blockOverrides.Number != nil {
+		if !blockOverrides.Number.ToInt().IsInt64() {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid block number %s", blockOverrides.Number.ToInt())
+		}
+		ctx = ctx.WithBlockHeight(blockOverrides.Number.ToInt().Int64())
+	}
+	if blockOverrides.Time != nil {
+		ctx = ctx.WithBlockTime(time.Unix(int64(*blockOverrides.Time), 0).UTC()) //#nosec G115
+	}
+	if blockOverrides.FeeRecipient != nil {
+		cfg.CoinBase = *blockOverrides.FeeRecipient
+	}
+	if blockOverrides.BaseFeePerGas != nil {
+		cfg.BaseFee = blockOverrides.BaseFeePerGas.ToInt()
+	}
+
+	// ApplyMessageWithConfig expect correct nonce set in msg
+	nonce := cfg.Overrides.Nonce(args.GetFrom(), k.GetNonce(ctx, args.GetFrom()))
+	args.Nonce = (*hexutil.Uint64)(&nonce)
+
+	msg, err := args.ToMessage(req.GasCap, cfg.BaseFee)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var tracerConfig json.RawMessage
+	if req.TraceConfig != nil && req.TraceConfig.TracerJsonConfig != "" {
+		// ignore error. default to no traceConfig
+		_ = json.Unmarshal([]byte(req.TraceConfig.TracerJsonConfig), &tracerConfig)
+	}
+
+	txConfig := statedb.NewEmptyTxConfig(common.BytesToHash(ctx.HeaderHash()))
+	result, _, err := k.traceMsg(ctx, cfg, txConfig, msg, req.TraceConfig, false, tracerConfig)
+	if err != nil {
+		// error will be returned with detail status from traceMsg
+		return nil, err
+	}
+
+	resultData, err := json.Marshal(result)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &types.QueryTraceCallResponse{
+		Data: resultData,
+	}, nil
+}
+
 // TraceBlock configures a new tracer according to the provided configuration, and
 // executes the given message in the provided environment for all the transactions in the queried block.
 // The return value will be tracer dependent.
@@ -533,6 +956,83 @@ func (k Keeper) TraceBlock(c context.Context, req *types.QueryTraceBlockRequest)
 	}, nil
 }
 
+// IntermediateRoots replays the transactions of a block along with the nonce and fee
+// updates of the AnteHandler, and returns a deterministic state commitment after each
+// transaction. As there's no merkle patricia trie, the commitment of a transaction is chained with the hash of the accounts and
+// storage slots it modified, so two nodes diverge from the first different transition.
+func (k Keeper) IntermediateRoots(c context.Context, req *types.QueryIntermediateRootsRequest) (*types.QueryIntermediateRootsResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "empty request")
+	}
+
+	// get the context of block beginning
+	contextHeight := req.BlockNumber
+	if contextHeight < 1 {
+		// 0 is a special value in `ContextWithHeight`
+		contextHeight = 1
+	}
+
+	ctx := sdk.UnwrapSDKContext(c)
+	ctx = ctx.WithBlockHeight(contextHeight)
+	ctx = ctx.WithBlockTime(req.BlockTime)
+	ctx = ctx.WithHeaderHash(common.Hex2Bytes(req.BlockHash))
+	chainID, err := getChainID(ctx, req.ChainId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	cfg, err := k.EVMConfig(ctx, GetProposerAddress(ctx, req.ProposerAddress), chainID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to load evm config")
+	}
+	signer := ethtypes.MakeSigner(cfg.ChainConfig, big.NewInt(ctx.BlockHeight()))
+
+	var root common.Hash
+	roots := make([][]byte, 0, len(req.Txs))
+	txConfig := statedb.NewEmptyTxConfig(common.BytesToHash(ctx.HeaderHash()))
+	for i, tx := range req.Txs {
+		ethTx := tx.AsTransaction()
+		txConfig.TxHash = ethTx.Hash()
+		txConfig.TxIndex = uint(i)
+
+		msg, err := ethTx.AsMessage(signer, cfg.BaseFee)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		stateDB := statedb.New(ctx, &k, txConfig)
+
+		// the AnteHandler increments the sender nonce and deducts the fees of the gas limit,
+		// the leftover gas is refunded after the execution. The fee collector is a module
+		// account outside of the EVM state, so only the sender side is replayed.
+		fees := new(big.Int).Mul(new(big.Int).SetUint64(msg.Gas()), msg.GasPrice())
+		if balance := stateDB.GetBalance(msg.From()); balance.Cmp(fees) < 0 {
+			return nil, status.Errorf(codes.Internal, "tx %s: insufficient funds, sender balance < tx fees (%s < %s)", ethTx.Hash().Hex(), balance, fees)
+		}
+		stateDB.SubBalance(msg.From(), fees)
+		stateDB.SetNonce(msg.From(), msg.Nonce()+1)
+
+		res, err := k.applyMessageWithStateDB(ctx, msg, types.NewNoOpTracer(), false, cfg, txConfig, stateDB)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "tx %s: %s", ethTx.Hash().Hex(), err.Error())
+		}
+		txConfig.LogIndex += uint(len(res.Logs))
+
+		refund := new(big.Int).Mul(new(big.Int).SetUint64(msg.Gas()-res.GasUsed), msg.GasPrice())
+		stateDB.AddBalance(msg.From(), refund)
+		if err := stateDB.Commit(); err != nil {
+			return nil, status.Errorf(codes.Internal, "tx %s: %s", ethTx.Hash().Hex(), err.Error())
+		}
+
+		root = crypto.Keccak256Hash(root.Bytes(), stateDB.DirtiesHash().Bytes())
+		roots = append(roots, root.Bytes())
+	}
+
+	return &types.QueryIntermediateRootsResponse{
+		Roots: roots,
+	}, nil
+}
+
 // traceTx do trace on one transaction, it returns a tuple: (traceResult, nextLogIndex, error).
 func (k *Keeper) traceTx(
 	ctx sdk.Context,
@@ -543,6 +1043,24 @@ func (k *Keeper) traceTx(
 	traceConfig *types.TraceConfig,
 	commitMessage bool,
 	tracerJSONConfig json.RawMessage,
+) (*interface{}, uint, error) {
+	msg, err := tx.AsMessage(signer, cfg.BaseFee)
+	if err != nil {
+		return nil, 0, status.Error(codes.Internal, err.Error())
+	}
+	return k.traceMsg(ctx, cfg, txConfig, msg, traceConfig, commitMessage, tracerJSONConfig)
+}
+
+// traceMsg executes the message with the tracer of the trace config, and returns
+// the trace result along with the log index of the next message.
+func (k *Keeper) traceMsg(
+	ctx sdk.Context,
+	cfg *statedb.EVMConfig,
+	txConfig statedb.TxConfig,
+	msg core.Message,
+	traceConfig *types.TraceConfig,
+	commitMessage bool,
+	tracerJSONConfig json.RawMessage,
 ) (*interface{}, uint, error) {
 	// Assemble the structured logger or the JavaScript tracer
 	var (
@@ -551,10 +1069,6 @@ func (k *Keeper) traceTx(
 		err       error
 		timeout   = defaultTraceTimeout
 	)
-	msg, err := tx.AsMessage(signer, cfg.BaseFee)
-	if err != nil {
-		return nil, 0, status.Error(codes.Internal, err.Error())
-	}
 
 	if traceConfig == nil {
 		traceConfig = &types.TraceConfig{}
@@ -586,6 +1100,10 @@ func (k *Keeper) traceTx(
 		if tracer, err = tracers.New(traceConfig.Tracer, tCtx, tracerJSONConfig); err != nil {
 			return nil, 0, status.Error(codes.Internal, err.Error())
 		}
+		if t, ok := tracer.(evmtracers.PrecompilesTracer); ok {
+			rules := cfg.ChainConfig.Rules(big.NewInt(ctx.BlockHeight()), cfg.ChainConfig.MergeNetsplitBlock != nil)
+			t.SetPrecompiles(k.activePrecompileAddresses(rules, cfg.Params))
+		}
 	}
 
 	// Define a meaningful timeout of a single transaction trace
@@ -644,3 +1162,39 @@ func getChainID(ctx sdk.Context, chainID int64) (*big.Int, error) {
 	}
 	return big.NewInt(chainID), nil
 }
+
+// parseStateOverrides decodes the json encoded state overrides, it returns nil if not provided
+func parseStateOverrides(bz []byte) (*statedb.StateOverride, error) {
+	if len(bz) == 0 {
+		return nil, nil
+	}
+	var overrides statedb.StateOverride
+	if err := json.Unmarshal(bz, &overrides); err != nil {
+		return nil, err
+	}
+	return &overrides, nil
+}
+
+// storageToStateDiff converts the full storage replacements of the state overrides
+// into diffs against the persisted storage, so they can be committed like the other
+// state changes of a simulated block.
+func (k Keeper) storageToStateDiff(ctx sdk.Context, overrides *statedb.StateOverride) {
+	if overrides == nil {
+		return
+	}
+	for addr, account := range *overrides {
+		if account.State == nil || account.StateDiff != nil {
+			continue
+		}
+		diff := make(map[common.Hash]common.Hash)
+		k.ForEachStorage(ctx, addr, func(key, _ common.Hash) bool {
+			diff[key] = common.Hash{}
+			return true
+		})
+		for key, value := range *account.State {
+			diff[key] = value
+		}
+		account.State, account.StateDiff = nil, &diff
+		(*overrides)[addr] = account
+	}
+}
@@ -4,17 +4,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"strings"
 
 	sdkmath "cosmossdk.io/math"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	ethlogger "github.com/ethereum/go-ethereum/eth/tracers/logger"
 	ethparams "github.com/ethereum/go-ethereum/params"
 	"github.com/evmos/ethermint/tests"
+	"github.com/evmos/ethermint/x/evm/precompiles"
 	"github.com/evmos/ethermint/x/evm/statedb"
+	"github.com/evmos/ethermint/x/evm/tracers"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 
@@ -285,6 +289,92 @@ func (suite *KeeperTestSuite) TestQueryStorage() {
 	}
 }
 
+func (suite *KeeperTestSuite) TestQueryStorageRoot() {
+	var (
+		req     *types.QueryStorageRootRequest
+		expRoot common.Hash
+	)
+
+	testCases := []struct {
+		msg      string
+		malleate func(vm.StateDB)
+		expPass  bool
+	}{
+		{
+			"invalid address",
+			func(vm.StateDB) {
+				req = &types.QueryStorageRootRequest{
+					Address: invalidAddress,
+				}
+			},
+			false,
+		},
+		{
+			"empty storage",
+			func(vm.StateDB) {
+				expRoot = common.Hash{}
+				req = &types.QueryStorageRootRequest{
+					Address: suite.address.String(),
+				}
+			},
+			true,
+		},
+		{
+			"success",
+			func(vmdb vm.StateDB) {
+				storage := map[common.Hash]common.Hash{
+					common.BytesToHash([]byte("key")):  common.BytesToHash([]byte("value")),
+					common.BytesToHash([]byte("key2")): common.BytesToHash([]byte("value2")),
+				}
+				for key, value := range storage {
+					vmdb.SetState(suite.address, key, value)
+				}
+				expRoot = types.StorageHash(storage)
+				req = &types.QueryStorageRootRequest{
+					Address: suite.address.String(),
+				}
+			},
+			true,
+		},
+		{
+			"large storage",
+			func(vmdb vm.StateDB) {
+				storage := make(map[common.Hash]common.Hash)
+				for i := int64(1); i <= 20000; i++ {
+					storage[common.BigToHash(big.NewInt(i))] = common.BigToHash(big.NewInt(i))
+					vmdb.SetState(suite.address, common.BigToHash(big.NewInt(i)), common.BigToHash(big.NewInt(i)))
+				}
+				expRoot = types.StorageHash(storage)
+				req = &types.QueryStorageRootRequest{
+					Address: suite.address.String(),
+				}
+			},
+			true,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(fmt.Sprintf("Case %s", tc.msg), func() {
+			suite.SetupTest() // reset
+
+			vmdb := suite.StateDB()
+			tc.malleate(vmdb)
+			suite.Require().NoError(vmdb.Commit())
+
+			res, err := suite.queryClient.StorageRoot(suite.ctx, req)
+
+			if tc.expPass {
+				suite.Require().NoError(err)
+				suite.Require().NotNil(res)
+
+				suite.Require().Equal(expRoot.Hex(), res.StorageRoot)
+			} else {
+				suite.Require().Error(err)
+			}
+		})
+	}
+}
+
 func (suite *KeeperTestSuite) TestQueryCode() {
 	var (
 		req     *types.QueryCodeRequest
@@ -488,9 +578,17 @@ func (suite *KeeperTestSuite) TestEstimateGas() {
 	hexBigInt := hexutil.Big(*big.NewInt(1))
 
 	var (
-		args   interface{}
-		gasCap uint64
+		args      interface{}
+		gasCap    uint64
+		overrides []byte
 	)
+	balanceOverride := func(balance int64) []byte {
+		bz, err := json.Marshal(map[common.Address]map[string]*hexutil.Big{
+			{}: {"balance": (*hexutil.Big)(big.NewInt(balance))},
+		})
+		suite.Require().NoError(err)
+		return bz
+	}
 	testCases := []struct {
 		msg             string
 		malleate        func()
@@ -717,6 +815,46 @@ func (suite *KeeperTestSuite) TestEstimateGas() {
 			0,
 			false,
 		},
+		// the gas is capped by the balance of the sender, the default From address has no fund
+		{
+			"gas capped by the sender balance",
+			func() {
+				args = types.TransactionArgs{To: &common.Address{}, GasPrice: &hexBigInt}
+			},
+			false,
+			0,
+			false,
+		},
+		{
+			"overridden sender balance funds the gas",
+			func() {
+				args = types.TransactionArgs{To: &common.Address{}, GasPrice: &hexBigInt}
+				overrides = balanceOverride(int64(ethparams.TxGas))
+			},
+			true,
+			ethparams.TxGas,
+			false,
+		},
+		{
+			"overridden sender balance lower than the gas",
+			func() {
+				args = types.TransactionArgs{To: &common.Address{}, MaxFeePerGas: &hexBigInt}
+				overrides = balanceOverride(int64(ethparams.TxGas) - 1)
+			},
+			false,
+			0,
+			false,
+		},
+		{
+			"overridden sender balance lower than the value",
+			func() {
+				args = types.TransactionArgs{To: &common.Address{}, GasPrice: &hexBigInt, Value: (*hexutil.Big)(big.NewInt(100))}
+				overrides = balanceOverride(100)
+			},
+			false,
+			0,
+			false,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -724,6 +862,7 @@ func (suite *KeeperTestSuite) TestEstimateGas() {
 			suite.enableFeemarket = tc.enableFeemarket
 			suite.SetupTest()
 			gasCap = 25_000_000
+			overrides = nil
 			tc.malleate()
 
 			args, err := json.Marshal(&args)
@@ -732,6 +871,7 @@ func (suite *KeeperTestSuite) TestEstimateGas() {
 				Args:            args,
 				GasCap:          gasCap,
 				ProposerAddress: suite.ctx.BlockHeader().ProposerAddress,
+				Overrides:       overrides,
 			}
 
 			rsp, err := suite.queryClient.EstimateGas(suite.ctx, &req)
@@ -973,6 +1113,248 @@ func (suite *KeeperTestSuite) TestTraceTx() {
 	suite.enableFeemarket = false // reset flag
 }
 
+func (suite *KeeperTestSuite) TestTraceTxParityTracers() {
+	suite.SetupTest()
+	supply, amount := sdkmath.NewIntWithDecimal(1000, 18).BigInt(), sdkmath.NewIntWithDecimal(1, 18).BigInt()
+	contractAddr := suite.DeployTestContract(suite.T(), suite.address, supply)
+	suite.Commit()
+	recipient := common.HexToAddress("0x378c50D9264C63F3F92B806d4ee56E9D86FfB3Ec")
+	txMsg := suite.TransferERC20Token(suite.T(), contractAddr, suite.address, recipient, amount)
+	suite.Commit()
+
+	// flat call traces
+	res, err := suite.queryClient.TraceTx(suite.ctx, &types.QueryTraceTxRequest{
+		Msg:         txMsg,
+		TraceConfig: &types.TraceConfig{Tracer: tracers.FlatCallTracer},
+	})
+	suite.Require().NoError(err)
+
+	var traces []tracers.FlatCallTrace
+	suite.Require().NoError(json.Unmarshal(res.Data, &traces))
+	suite.Require().Len(traces, 1)
+	suite.Require().Equal("call", traces[0].Type)
+	suite.Require().Equal("call", traces[0].Action.CallType)
+	suite.Require().Equal(suite.address, *traces[0].Action.From)
+	suite.Require().Equal(contractAddr, *traces[0].Action.To)
+	suite.Require().Empty(traces[0].Error)
+	suite.Require().NotNil(traces[0].Result)
+	suite.Require().Positive(uint64(traces[0].Result.GasUsed))
+	suite.Require().Equal(common.BigToHash(big.NewInt(1)).Bytes(), []byte(*traces[0].Result.Output))
+	suite.Require().Empty(traces[0].TraceAddress)
+	suite.Require().Zero(traces[0].Subtraces)
+	suite.Require().Equal(txMsg.AsTransaction().Hash(), *traces[0].TransactionHash)
+
+	// state diff
+	res, err = suite.queryClient.TraceTx(suite.ctx, &types.QueryTraceTxRequest{
+		Msg:         txMsg,
+		TraceConfig: &types.TraceConfig{Tracer: tracers.StateDiffTracer},
+	})
+	suite.Require().NoError(err)
+
+	var diff map[common.Address]struct {
+		Balance json.RawMessage                 `json:"balance"`
+		Nonce   json.RawMessage                 `json:"nonce"`
+		Storage map[common.Hash]json.RawMessage `json:"storage"`
+	}
+	suite.Require().NoError(json.Unmarshal(res.Data, &diff))
+	suite.Require().Len(diff, 2)
+	diffData := res.Data
+
+	nonce := suite.app.EvmKeeper.GetNonce(suite.ctx, suite.address)
+	suite.Require().JSONEq(`"="`, string(diff[suite.address].Balance))
+	suite.Require().JSONEq(
+		fmt.Sprintf(`{"*":{"from":"%s","to":"%s"}}`, hexutil.Uint64(nonce), hexutil.Uint64(nonce+1)),
+		string(diff[suite.address].Nonce),
+	)
+	suite.Require().Empty(diff[suite.address].Storage)
+
+	// the balances of the sender and of the recipient are updated, the transfer is
+	// replayed on top of the committed one
+	suite.Require().JSONEq(`"="`, string(diff[contractAddr].Nonce))
+	type change struct{ From, To common.Hash }
+	var changes []change
+	for _, raw := range diff[contractAddr].Storage {
+		var storage struct {
+			Change change `json:"*"`
+		}
+		suite.Require().NoError(json.Unmarshal(raw, &storage))
+		changes = append(changes, storage.Change)
+	}
+	suite.Require().ElementsMatch([]change{
+		{common.BigToHash(new(big.Int).Sub(supply, amount)), common.BigToHash(new(big.Int).Sub(supply, new(big.Int).Lsh(amount, 1)))},
+		{common.BigToHash(amount), common.BigToHash(new(big.Int).Lsh(amount, 1))},
+	}, changes)
+
+	// both tracers on a single replay return the same results
+	res, err = suite.queryClient.TraceTx(suite.ctx, &types.QueryTraceTxRequest{
+		Msg: txMsg,
+		TraceConfig: &types.TraceConfig{
+			Tracer:           tracers.MuxTracer,
+			TracerJsonConfig: fmt.Sprintf(`{"%s":{},"%s":{}}`, tracers.FlatCallTracer, tracers.StateDiffTracer),
+		},
+	})
+	suite.Require().NoError(err)
+
+	var muxed map[string]json.RawMessage
+	suite.Require().NoError(json.Unmarshal(res.Data, &muxed))
+	suite.Require().Len(muxed, 2)
+	tracesBz, err := json.Marshal(traces)
+	suite.Require().NoError(err)
+	suite.Require().JSONEq(string(tracesBz), string(muxed[tracers.FlatCallTracer]))
+	suite.Require().JSONEq(string(diffData), string(muxed[tracers.StateDiffTracer]))
+}
+
+func (suite *KeeperTestSuite) TestTraceCallFlatCallPrecompiles() {
+	suite.SetupTest()
+	suite.activatePrecompiles(precompiles.BankAddress)
+
+	// PUSH1 0 PUSH1 0 PUSH1 0 PUSH1 0 PUSH2 0x0804 GAS STATICCALL STOP
+	code := hexutil.Bytes(common.FromHex("0x60006000600060006108045afa00"))
+	contract := tests.GenerateAddress()
+	overrides, err := json.Marshal(&statedb.StateOverride{
+		contract: statedb.OverrideAccount{Code: &code},
+	})
+	suite.Require().NoError(err)
+	args, err := json.Marshal(&types.TransactionArgs{From: &suite.address, To: &contract})
+	suite.Require().NoError(err)
+
+	testCases := []struct {
+		name      string
+		cfg       string
+		expTraces int
+	}{
+		{"the calls to the custom precompiles are excluded", "", 1},
+		{"the calls to the custom precompiles are included", `{"includePrecompiles":true}`, 2},
+	}
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			res, err := suite.queryClient.TraceCall(suite.ctx, &types.QueryTraceCallRequest{
+				Args:        args,
+				Overrides:   overrides,
+				TraceConfig: &types.TraceConfig{Tracer: tracers.FlatCallTracer, TracerJsonConfig: tc.cfg},
+			})
+			suite.Require().NoError(err)
+			var traces []tracers.FlatCallTrace
+			suite.Require().NoError(json.Unmarshal(res.Data, &traces))
+			suite.Require().Len(traces, tc.expTraces)
+		})
+	}
+}
+
+func (suite *KeeperTestSuite) TestTraceCall() {
+	suite.SetupTest()
+
+	// NUMBER PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+	code := hexutil.Bytes(common.FromHex("0x4360005260206000f3"))
+	// PUSH1 0 PUSH1 0 REVERT
+	revertCode := hexutil.Bytes(common.FromHex("0x60006000fd"))
+	contract := tests.GenerateAddress()
+	overrides, err := json.Marshal(&statedb.StateOverride{
+		contract: statedb.OverrideAccount{Code: &code},
+	})
+	suite.Require().NoError(err)
+	revertOverrides, err := json.Marshal(&statedb.StateOverride{
+		contract: statedb.OverrideAccount{Code: &revertCode},
+	})
+	suite.Require().NoError(err)
+	args, err := json.Marshal(&types.TransactionArgs{From: &suite.address, To: &contract})
+	suite.Require().NoError(err)
+
+	number := big.NewInt(suite.ctx.BlockHeight() + 100)
+	blockOverrides, err := json.Marshal(&types.BlockOverrides{Number: (*hexutil.Big)(number)})
+	suite.Require().NoError(err)
+
+	testCases := []struct {
+		msg           string
+		req           *types.QueryTraceCallRequest
+		expPass       bool
+		traceResponse string
+	}{
+		{
+			"default trace without overrides",
+			&types.QueryTraceCallRequest{Args: args},
+			true,
+			`"failed":false,"returnValue":"","structLogs":[]}`,
+		},
+		{
+			"default trace with state overrides",
+			&types.QueryTraceCallRequest{Args: args, Overrides: overrides},
+			true,
+			fmt.Sprintf(`"returnValue":"%x"`, common.BigToHash(big.NewInt(suite.ctx.BlockHeight())).Bytes()),
+		},
+		{
+			"default trace with state and block overrides",
+			&types.QueryTraceCallRequest{Args: args, Overrides: overrides, BlockOverrides: blockOverrides},
+			true,
+			fmt.Sprintf(`"returnValue":"%x"`, common.BigToHash(number).Bytes()),
+		},
+		{
+			"call tracer",
+			&types.QueryTraceCallRequest{Args: args, Overrides: overrides, TraceConfig: &types.TraceConfig{Tracer: "callTracer"}},
+			true,
+			fmt.Sprintf(`"to":"%s"`, strings.ToLower(contract.Hex())),
+		},
+		{
+			"javascript tracer",
+			&types.QueryTraceCallRequest{
+				Args:      args,
+				Overrides: overrides,
+				TraceConfig: &types.TraceConfig{
+					Tracer: "{data: [], fault: function(log) {}, step: function(log) { if(log.op.toString() == \"NUMBER\") this.data.push(log.op.toString()); }, result: function() { return this.data; }}",
+				},
+			},
+			true,
+			`["NUMBER"]`,
+		},
+		{
+			"call tracer with reverted call",
+			&types.QueryTraceCallRequest{Args: args, Overrides: revertOverrides, TraceConfig: &types.TraceConfig{Tracer: "callTracer"}},
+			true,
+			`"error":"execution reverted"`,
+		},
+		{
+			"invalid args",
+			&types.QueryTraceCallRequest{Args: []byte("invalid")},
+			false,
+			"",
+		},
+		{
+			"invalid state overrides",
+			&types.QueryTraceCallRequest{Args: args, Overrides: []byte("invalid")},
+			false,
+			"",
+		},
+		{
+			"invalid block overrides",
+			&types.QueryTraceCallRequest{Args: args, BlockOverrides: []byte("invalid")},
+			false,
+			"",
+		},
+		{
+			"invalid trace config - Negative Limit",
+			&types.QueryTraceCallRequest{Args: args, TraceConfig: &types.TraceConfig{Limit: -1}},
+			false,
+			"",
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(fmt.Sprintf("Case %s", tc.msg), func() {
+			tc.req.GasCap = config.DefaultGasCap
+			res, err := suite.queryClient.TraceCall(suite.ctx, tc.req)
+			if tc.expPass {
+				suite.Require().NoError(err)
+				suite.Require().Contains(string(res.Data), tc.traceResponse)
+			} else {
+				suite.Require().Error(err)
+			}
+		})
+	}
+
+	// overrides are never committed
+	suite.Require().Empty(suite.app.EvmKeeper.GetCode(suite.ctx, common.BytesToHash(crypto.Keccak256(code))))
+}
+
 func (suite *KeeperTestSuite) TestTraceBlock() {
 	var (
 		txs         []*types.MsgEthereumTx
@@ -1326,6 +1708,248 @@ func (suite *KeeperTestSuite) TestEthCall() {
 	}
 }
 
+func (suite *KeeperTestSuite) TestEthCallStateOverride() {
+	suite.SetupTest()
+
+	// PUSH1 0 SLOAD PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+	code := hexutil.Bytes(common.FromHex("0x60005460005260206000f3"))
+	contract := tests.GenerateAddress()
+	slot := common.Hash{}
+	value := common.BigToHash(big.NewInt(42))
+
+	args, err := json.Marshal(&types.TransactionArgs{
+		From: &suite.address,
+		To:   &contract,
+	})
+	suite.Require().NoError(err)
+
+	overrides, err := json.Marshal(&statedb.StateOverride{
+		contract: statedb.OverrideAccount{
+			Code:  &code,
+			State: &map[common.Hash]common.Hash{slot: value},
+		},
+	})
+	suite.Require().NoError(err)
+
+	// without overrides the account has no code
+	res, err := suite.queryClient.EthCall(suite.ctx, &types.EthCallRequest{Args: args, GasCap: config.DefaultGasCap})
+	suite.Require().NoError(err)
+	suite.Require().Empty(res.Ret)
+
+	res, err = suite.queryClient.EthCall(suite.ctx, &types.EthCallRequest{Args: args, GasCap: config.DefaultGasCap, Overrides: overrides})
+	suite.Require().NoError(err)
+	suite.Require().Empty(res.VmError)
+	suite.Require().Equal(value.Bytes(), res.Ret)
+
+	gas, err := suite.queryClient.EstimateGas(suite.ctx, &types.EthCallRequest{Args: args, GasCap: config.DefaultGasCap, Overrides: overrides})
+	suite.Require().NoError(err)
+	suite.Require().Greater(gas.Gas, ethparams.TxGas)
+
+	// overrides are never committed
+	suite.Require().Empty(suite.app.EvmKeeper.GetCode(suite.ctx, common.BytesToHash(crypto.Keccak256(code))))
+
+	_, err = suite.queryClient.EthCall(suite.ctx, &types.EthCallRequest{Args: args, GasCap: config.DefaultGasCap, Overrides: []byte("invalid")})
+	suite.Require().Error(err)
+}
+
+func (suite *KeeperTestSuite) TestCreateAccessList() {
+	suite.SetupTest()
+
+	// PUSH1 0 SLOAD PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+	code := hexutil.Bytes(common.FromHex("0x60005460005260206000f3"))
+	contract := tests.GenerateAddress()
+	overrides, err := json.Marshal(&statedb.StateOverride{
+		contract: statedb.OverrideAccount{Code: &code},
+	})
+	suite.Require().NoError(err)
+
+	args, err := json.Marshal(&types.TransactionArgs{From: &suite.address, To: &contract})
+	suite.Require().NoError(err)
+
+	res, err := suite.queryClient.CreateAccessList(suite.ctx, &types.EthCallRequest{Args: args, GasCap: config.DefaultGasCap, Overrides: overrides})
+	suite.Require().NoError(err)
+	suite.Require().Empty(res.VmError)
+
+	expAccessList := ethtypes.AccessList{{Address: contract, StorageKeys: []common.Hash{{}}}}
+	suite.Require().Equal(&expAccessList, res.AccessList.ToEthAccessList())
+	// execution: 4 PUSH1, a warm SLOAD and a MSTORE expanding the memory by one word
+	suite.Require().Equal(ethparams.TxGas+ethparams.TxAccessListAddressGas+ethparams.TxAccessListStorageKeyGas+118, res.GasUsed)
+
+	// without code, nothing is accessed
+	args, err = json.Marshal(&types.TransactionArgs{From: &suite.address, To: &contract})
+	suite.Require().NoError(err)
+	res, err = suite.queryClient.CreateAccessList(suite.ctx, &types.EthCallRequest{Args: args, GasCap: config.DefaultGasCap})
+	suite.Require().NoError(err)
+	suite.Require().Empty(res.AccessList)
+	suite.Require().Equal(ethparams.TxGas, res.GasUsed)
+}
+
+func (suite *KeeperTestSuite) TestCreateAccessListPrecompiles() {
+	suite.SetupTest()
+	suite.activatePrecompiles(precompiles.BankAddress)
+
+	// PUSH1 0 PUSH1 0 PUSH1 0 PUSH1 0 PUSH2 0x0804 GAS STATICCALL STOP
+	code := hexutil.Bytes(common.FromHex("0x60006000600060006108045afa00"))
+	contract := tests.GenerateAddress()
+	overrides, err := json.Marshal(&statedb.StateOverride{
+		contract: statedb.OverrideAccount{Code: &code},
+	})
+	suite.Require().NoError(err)
+	args, err := json.Marshal(&types.TransactionArgs{From: &suite.address, To: &contract})
+	suite.Require().NoError(err)
+
+	// the enabled custom precompiles are warm like the geth ones
+	res, err := suite.queryClient.CreateAccessList(suite.ctx, &types.EthCallRequest{Args: args, GasCap: config.DefaultGasCap, Overrides: overrides})
+	suite.Require().NoError(err)
+	suite.Require().Empty(res.VmError)
+	suite.Require().Empty(res.AccessList)
+}
+
+func (suite *KeeperTestSuite) TestEthSimulate() {
+	suite.SetupTest()
+
+	// increments slot 0, logs and returns the new value:
+	// PUSH1 0 SLOAD PUSH1 1 ADD DUP1 PUSH1 0 SSTORE PUSH1 0 MSTORE
+	// PUSH1 32 PUSH1 0 LOG0 PUSH1 32 PUSH1 0 RETURN
+	code := hexutil.Bytes(common.FromHex("0x6000546001018060005560005260206000a060206000f3"))
+	contract := tests.GenerateAddress()
+	coinbase := tests.GenerateAddress()
+	overrides, err := json.Marshal(&statedb.StateOverride{
+		contract: statedb.OverrideAccount{Code: &code},
+	})
+	suite.Require().NoError(err)
+
+	call := types.TransactionArgs{From: &suite.address, To: &contract}
+	number := (*hexutil.Big)(big.NewInt(suite.ctx.BlockHeight() + 10))
+	opts := types.SimOpts{
+		BlockStateCalls: []types.SimBlock{
+			{
+				StateOverrides: overrides,
+				Calls:          []types.TransactionArgs{call, call},
+			},
+			{
+				BlockOverrides: &types.BlockOverrides{
+					Number:       number,
+					FeeRecipient: &coinbase,
+				},
+				Calls: []types.TransactionArgs{call},
+			},
+		},
+	}
+	bz, err := json.Marshal(&opts)
+	suite.Require().NoError(err)
+
+	res, err := suite.queryClient.EthSimulate(suite.ctx, &types.EthSimulateRequest{Opts: bz, GasCap: config.DefaultGasCap})
+	suite.Require().NoError(err)
+
+	var blocks []types.SimBlockResult
+	suite.Require().NoError(json.Unmarshal(res.Result, &blocks))
+	suite.Require().Len(blocks, 2)
+
+	suite.Require().Equal(uint64(suite.ctx.BlockHeight()+1), uint64(blocks[0].Number))
+	suite.Require().Equal(number.ToInt().Uint64(), uint64(blocks[1].Number))
+	suite.Require().Equal(blocks[0].Hash, blocks[1].ParentHash)
+	suite.Require().Equal(coinbase, blocks[1].FeeRecipient)
+	suite.Require().Greater(blocks[1].Timestamp, blocks[0].Timestamp)
+
+	// the state carries over between the calls and the blocks
+	suite.Require().Len(blocks[0].Calls, 2)
+	suite.Require().Len(blocks[1].Calls, 1)
+	calls := append(blocks[0].Calls, blocks[1].Calls...)
+	for i, result := range calls {
+		value := common.BigToHash(big.NewInt(int64(i + 1))).Bytes()
+		suite.Require().Nil(result.Error)
+		suite.Require().Equal(uint64(1), uint64(result.Status))
+		suite.Require().Equal(hexutil.Bytes(value), result.ReturnData)
+		suite.Require().Greater(uint64(result.GasUsed), ethparams.TxGas)
+		suite.Require().Len(result.Logs, 1)
+		suite.Require().Equal(value, result.Logs[0].Data)
+	}
+	suite.Require().Equal(uint(1), blocks[0].Calls[1].Logs[0].Index)
+	suite.Require().Equal(uint(1), blocks[0].Calls[1].Logs[0].TxIndex)
+	suite.Require().Equal(blocks[1].Hash, blocks[1].Calls[0].Logs[0].BlockHash)
+	suite.Require().Equal(blocks[0].GasUsed, blocks[0].Calls[0].GasUsed+blocks[0].Calls[1].GasUsed)
+
+	// the simulation is never committed
+	suite.Require().Empty(suite.app.EvmKeeper.GetCode(suite.ctx, common.BytesToHash(crypto.Keccak256(code))))
+	suite.Require().Equal(common.Hash{}, suite.app.EvmKeeper.GetState(suite.ctx, contract, common.Hash{}))
+
+	// block numbers must increase
+	opts.BlockStateCalls[0].BlockOverrides = &types.BlockOverrides{Number: number}
+	bz, err = json.Marshal(&opts)
+	suite.Require().NoError(err)
+	_, err = suite.queryClient.EthSimulate(suite.ctx, &types.EthSimulateRequest{Opts: bz, GasCap: config.DefaultGasCap})
+	suite.Require().Error(err)
+
+	_, err = suite.queryClient.EthSimulate(suite.ctx, &types.EthSimulateRequest{Opts: []byte("{}"), GasCap: config.DefaultGasCap})
+	suite.Require().Error(err)
+}
+
+func (suite *KeeperTestSuite) TestIntermediateRoots() {
+	suite.SetupTest()
+
+	contractAddr := suite.DeployTestContract(suite.T(), suite.address, sdkmath.NewIntWithDecimal(1000, 18).BigInt())
+	suite.Commit()
+	recipient := common.HexToAddress("0x378c50D9264C63F3F92B806d4ee56E9D86FfB3Ec")
+	firstTx := suite.TransferERC20Token(suite.T(), contractAddr, suite.address, recipient, sdkmath.NewIntWithDecimal(1, 18).BigInt())
+	secondTx := suite.TransferERC20Token(suite.T(), contractAddr, suite.address, recipient, sdkmath.NewIntWithDecimal(1, 18).BigInt())
+
+	req := &types.QueryIntermediateRootsRequest{
+		Txs:             []*types.MsgEthereumTx{firstTx, secondTx},
+		BlockNumber:     suite.ctx.BlockHeight(),
+		ProposerAddress: suite.ctx.BlockHeader().ProposerAddress,
+	}
+
+	// replay the block twice on top of the same state
+	ctx, _ := suite.ctx.CacheContext()
+	res, err := suite.app.EvmKeeper.IntermediateRoots(ctx, req)
+	suite.Require().NoError(err)
+	suite.Require().Len(res.Roots, 2)
+	suite.Require().NotEqual(res.Roots[0], res.Roots[1])
+
+	ctx, _ = suite.ctx.CacheContext()
+	again, err := suite.app.EvmKeeper.IntermediateRoots(ctx, req)
+	suite.Require().NoError(err)
+	suite.Require().Equal(res.Roots, again.Roots)
+
+	ctx, _ = suite.ctx.CacheContext()
+	res, err = suite.app.EvmKeeper.IntermediateRoots(ctx, &types.QueryIntermediateRootsRequest{})
+	suite.Require().NoError(err)
+	suite.Require().Empty(res.Roots)
+}
+
+func (suite *KeeperTestSuite) TestIntermediateRootsFees() {
+	suite.enableFeemarket = true
+	defer func() { suite.enableFeemarket = false }()
+	suite.SetupTest()
+
+	contractAddr := suite.DeployTestContract(suite.T(), suite.address, sdkmath.NewIntWithDecimal(1000, 18).BigInt())
+	suite.Commit()
+	recipient := common.HexToAddress("0x378c50D9264C63F3F92B806d4ee56E9D86FfB3Ec")
+	tx := suite.TransferERC20Token(suite.T(), contractAddr, suite.address, recipient, sdkmath.NewIntWithDecimal(1, 18).BigInt())
+	ethTx := tx.AsTransaction()
+
+	balance := sdkmath.NewIntWithDecimal(1, 18).BigInt()
+	suite.Require().NoError(suite.app.EvmKeeper.SetBalance(suite.ctx, suite.address, balance))
+	nonce := suite.app.EvmKeeper.GetNonce(suite.ctx, suite.address)
+	suite.Require().Equal(nonce, ethTx.Nonce())
+
+	ctx, _ := suite.ctx.CacheContext()
+	res, err := suite.app.EvmKeeper.IntermediateRoots(ctx, &types.QueryIntermediateRootsRequest{
+		Txs:             []*types.MsgEthereumTx{tx},
+		BlockNumber:     suite.ctx.BlockHeight(),
+		ProposerAddress: suite.ctx.BlockHeader().ProposerAddress,
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(res.Roots, 1)
+
+	// the sender nonce is incremented and the sender pays the fees of the used gas
+	suite.Require().Equal(nonce+1, suite.app.EvmKeeper.GetNonce(ctx, suite.address))
+	fees := new(big.Int).Sub(balance, suite.app.EvmKeeper.GetBalance(ctx, suite.address))
+	suite.Require().Positive(fees.Sign())
+	suite.Require().True(fees.Cmp(new(big.Int).Mul(new(big.Int).SetUint64(ethTx.Gas()), ethTx.GasFeeCap())) <= 0)
+}
+
 func (suite *KeeperTestSuite) TestEmptyRequest() {
 	k := suite.app.EvmKeeper
 
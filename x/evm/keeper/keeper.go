@@ -331,7 +331,12 @@ func (k *Keeper) GetBalance(ctx sdk.Context, addr common.Address) *big.Int {
 		return big.NewInt(-1)
 	}
 	coin := k.bankKeeper.GetBalance(ctx, cosmosAddr, evmDenom)
-	return coin.Amount.BigInt()
+	factor := evmParams.EVMConversionFactor()
+	if factor.Cmp(big.NewInt(1)) == 0 {
+		return coin.Amount.BigInt()
+	}
+	balance := new(big.Int).Mul(coin.Amount.BigInt(), factor)
+	return balance.Add(balance, k.GetFractionalBalance(ctx, addr))
 }
 
 // GetBaseFee returns current base fee, return values:
@@ -161,7 +161,19 @@ func (k *Keeper) UpdateParams(goCtx context.Context, req *types.MsgUpdateParams)
 		return nil, err
 	}
 
+	for addr := range req.Params.ActivePrecompileAddresses() {
+		if _, ok := k.customPrecompiles[addr]; !ok {
+			return nil, errorsmod.Wrapf(types.ErrUnknownPrecompile, "cannot activate %s", addr)
+		}
+	}
+
 	ctx := sdk.UnwrapSDKContext(goCtx)
+	// the stored balances are denominated in units of the conversion factor
+	current := k.GetParams(ctx).EVMConversionFactor()
+	if current.Cmp(req.Params.EVMConversionFactor()) != 0 {
+		return nil, errorsmod.Wrapf(types.ErrConversionFactorChange, "current %s, got %s", current, req.Params.EVMConversionFactor())
+	}
+
 	if err := k.SetParams(ctx, req.Params); err != nil {
 		return nil, err
 	}
@@ -8,6 +8,7 @@ import (
 
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/evmos/ethermint/x/evm/precompiles"
 	"github.com/evmos/ethermint/x/evm/statedb"
 	"github.com/evmos/ethermint/x/evm/types"
 )
@@ -101,6 +102,42 @@ func (suite *KeeperTestSuite) TestUpdateParams() {
 			},
 			expectErr: false,
 		},
+		{
+			name: "pass - activate registered precompile",
+			request: &types.MsgUpdateParams{
+				Authority: authtypes.NewModuleAddress(govtypes.ModuleName).String(),
+				Params: func() types.Params {
+					params := types.DefaultParams()
+					params.ActivePrecompiles = []string{precompiles.BankAddress.Hex()}
+					return params
+				}(),
+			},
+			expectErr: false,
+		},
+		{
+			name: "fail - activate unknown precompile",
+			request: &types.MsgUpdateParams{
+				Authority: authtypes.NewModuleAddress(govtypes.ModuleName).String(),
+				Params: func() types.Params {
+					params := types.DefaultParams()
+					params.ActivePrecompiles = []string{"0x0000000000000000000000000000000000000900"}
+					return params
+				}(),
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail - change conversion factor",
+			request: &types.MsgUpdateParams{
+				Authority: authtypes.NewModuleAddress(govtypes.ModuleName).String(),
+				Params: func() types.Params {
+					params := types.DefaultParams()
+					params.ConversionFactor = 1000000000000
+					return params
+				}(),
+			},
+			expectErr: true,
+		},
 	}
 
 	for _, tc := range testCases {
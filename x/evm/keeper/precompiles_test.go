@@ -0,0 +1,402 @@
+package keeper_test
+
+import (
+	"math/big"
+	"os"
+
+	sdkmath "cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/evmos/ethermint/tests"
+	"github.com/evmos/ethermint/testutil"
+	"github.com/evmos/ethermint/x/evm/precompiles"
+	"github.com/evmos/ethermint/x/evm/statedb"
+	"github.com/evmos/ethermint/x/evm/types"
+)
+
+func (suite *KeeperTestSuite) loadPrecompileABI(name string) abi.ABI {
+	f, err := os.Open("../precompiles/" + name + ".abi.json")
+	suite.Require().NoError(err)
+	defer f.Close()
+	contractABI, err := abi.JSON(f)
+	suite.Require().NoError(err)
+	return contractABI
+}
+
+func (suite *KeeperTestSuite) evmConfig() *statedb.EVMConfig {
+	cfg, err := suite.app.EvmKeeper.EVMConfig(suite.ctx, suite.ctx.BlockHeader().ProposerAddress, big.NewInt(9000))
+	suite.Require().NoError(err)
+	return cfg
+}
+
+func (suite *KeeperTestSuite) callPrecompile(to common.Address, input []byte, commit bool) *types.MsgEthereumTxResponse {
+	cfg := suite.evmConfig()
+	nonce := suite.app.EvmKeeper.GetNonce(suite.ctx, suite.address)
+	msg := ethtypes.NewMessage(suite.address, &to, nonce, big.NewInt(0), 1000000, big.NewInt(0), big.NewInt(0), big.NewInt(0), input, nil, true)
+	txConfig := suite.app.EvmKeeper.TxConfig(suite.ctx, common.Hash{})
+	res, err := suite.app.EvmKeeper.ApplyMessageWithConfig(suite.ctx, msg, nil, commit, cfg, txConfig)
+	suite.Require().NoError(err)
+	return res
+}
+
+func (suite *KeeperTestSuite) activatePrecompiles(addrs ...common.Address) {
+	params := suite.app.EvmKeeper.GetParams(suite.ctx)
+	params.ActivePrecompiles = nil
+	for _, addr := range addrs {
+		params.ActivePrecompiles = append(params.ActivePrecompiles, addr.Hex())
+	}
+	suite.Require().NoError(suite.app.EvmKeeper.SetParams(suite.ctx, params))
+}
+
+func (suite *KeeperTestSuite) bondedValidator() stakingtypes.Validator {
+	validators, err := suite.app.StakingKeeper.GetBondedValidatorsByPower(suite.ctx)
+	suite.Require().NoError(err)
+	suite.Require().NotEmpty(validators)
+	return validators[0]
+}
+
+func (suite *KeeperTestSuite) TestBankPrecompile() {
+	bankABI := suite.loadPrecompileABI("bank")
+	recipient := tests.GenerateAddress()
+
+	testCases := []struct {
+		msg              string
+		amount           int64
+		expFailed        bool
+		expBalance       int64
+		expSenderBalance int64
+	}{
+		{"send within balance", 400, false, 400, 600},
+		{"send exceeding balance", 1200, true, 0, 1000},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.msg, func() {
+			suite.SetupTest()
+			suite.activatePrecompiles(precompiles.BankAddress)
+			err := testutil.FundAccount(suite.app.BankKeeper, suite.ctx, suite.address.Bytes(), sdk.NewCoins(sdk.NewInt64Coin("stake", 1000)))
+			suite.Require().NoError(err)
+
+			input, err := bankABI.Pack("send", recipient, "stake", big.NewInt(tc.amount))
+			suite.Require().NoError(err)
+			res := suite.callPrecompile(precompiles.BankAddress, input, true)
+			suite.Require().Equal(tc.expFailed, res.Failed())
+			if tc.expFailed {
+				suite.Require().Equal(vm.ErrExecutionReverted.Error(), res.VmError)
+			}
+
+			suite.Require().Equal(tc.expBalance, suite.app.BankKeeper.GetBalance(suite.ctx, recipient.Bytes(), "stake").Amount.Int64())
+			suite.Require().Equal(tc.expSenderBalance, suite.app.BankKeeper.GetBalance(suite.ctx, suite.address.Bytes(), "stake").Amount.Int64())
+
+			input, err = bankABI.Pack("balanceOf", recipient, "stake")
+			suite.Require().NoError(err)
+			res = suite.callPrecompile(precompiles.BankAddress, input, false)
+			suite.Require().False(res.Failed(), res.VmError)
+			out, err := bankABI.Unpack("balanceOf", res.Ret)
+			suite.Require().NoError(err)
+			suite.Require().Equal(tc.expBalance, out[0].(*big.Int).Int64())
+		})
+	}
+}
+
+func (suite *KeeperTestSuite) TestBankPrecompileEVMDenom() {
+	bankABI := suite.loadPrecompileABI("bank")
+	recipient := tests.GenerateAddress()
+	suite.activatePrecompiles(precompiles.BankAddress)
+
+	vmdb := suite.StateDB()
+	vmdb.AddBalance(suite.address, big.NewInt(1000))
+	suite.Require().NoError(vmdb.Commit())
+
+	// the EVM balance of both accounts reflects the native send
+	input, err := bankABI.Pack("send", recipient, types.DefaultEVMDenom, big.NewInt(300))
+	suite.Require().NoError(err)
+	res := suite.callPrecompile(precompiles.BankAddress, input, true)
+	suite.Require().False(res.Failed(), res.VmError)
+	suite.Require().Equal(big.NewInt(700), suite.app.EvmKeeper.GetBalance(suite.ctx, suite.address))
+	suite.Require().Equal(big.NewInt(300), suite.app.EvmKeeper.GetBalance(suite.ctx, recipient))
+}
+
+func (suite *KeeperTestSuite) TestStakingPrecompile() {
+	stakingABI := suite.loadPrecompileABI("staking")
+	err := testutil.FundAccount(suite.app.BankKeeper, suite.ctx, suite.address.Bytes(), sdk.NewCoins(sdk.NewInt64Coin("stake", 1000)))
+	suite.Require().NoError(err)
+	validator := suite.bondedValidator()
+	suite.activatePrecompiles(precompiles.StakingAddress)
+
+	input, err := stakingABI.Pack("delegate", validator.OperatorAddress, big.NewInt(600))
+	suite.Require().NoError(err)
+	res := suite.callPrecompile(precompiles.StakingAddress, input, true)
+	suite.Require().False(res.Failed(), res.VmError)
+
+	valAddr, err := sdk.ValAddressFromBech32(validator.OperatorAddress)
+	suite.Require().NoError(err)
+	delegation, err := suite.app.StakingKeeper.GetDelegation(suite.ctx, suite.address.Bytes(), valAddr)
+	suite.Require().NoError(err)
+	shares, err := validator.SharesFromTokens(sdkmath.NewInt(600))
+	suite.Require().NoError(err)
+	suite.Require().Equal(shares, delegation.Shares)
+	suite.Require().Equal(int64(400), suite.app.BankKeeper.GetBalance(suite.ctx, suite.address.Bytes(), "stake").Amount.Int64())
+
+	input, err = stakingABI.Pack("delegation", suite.address, validator.OperatorAddress)
+	suite.Require().NoError(err)
+	res = suite.callPrecompile(precompiles.StakingAddress, input, false)
+	suite.Require().False(res.Failed(), res.VmError)
+	out, err := stakingABI.Unpack("delegation", res.Ret)
+	suite.Require().NoError(err)
+	suite.Require().Equal(big.NewInt(600), out[0])
+
+	// delegating to an unknown validator reverts without side effects
+	input, err = stakingABI.Pack("delegate", sdk.ValAddress(tests.GenerateAddress().Bytes()).String(), big.NewInt(100))
+	suite.Require().NoError(err)
+	res = suite.callPrecompile(precompiles.StakingAddress, input, true)
+	suite.Require().True(res.Failed())
+	suite.Require().Equal(int64(400), suite.app.BankKeeper.GetBalance(suite.ctx, suite.address.Bytes(), "stake").Amount.Int64())
+}
+
+func (suite *KeeperTestSuite) TestInactivePrecompile() {
+	bankABI := suite.loadPrecompileABI("bank")
+	recipient := tests.GenerateAddress()
+	err := testutil.FundAccount(suite.app.BankKeeper, suite.ctx, suite.address.Bytes(), sdk.NewCoins(sdk.NewInt64Coin("stake", 1000)))
+	suite.Require().NoError(err)
+	input, err := bankABI.Pack("send", recipient, "stake", big.NewInt(400))
+	suite.Require().NoError(err)
+
+	// the bank precompile is only active once enabled by the params
+	suite.activatePrecompiles(precompiles.StakingAddress)
+	msg := ethtypes.NewMessage(suite.address, &precompiles.BankAddress, 0, big.NewInt(0), 1000000, big.NewInt(0), big.NewInt(0), big.NewInt(0), input, nil, true)
+	evm := suite.app.EvmKeeper.NewEVM(suite.ctx, msg, suite.evmConfig(), nil, suite.StateDB())
+	_, found := evm.Precompile(precompiles.BankAddress)
+	suite.Require().False(found)
+	suite.Require().NotContains(evm.ActivePrecompiles(params.Rules{}), precompiles.BankAddress)
+	suite.Require().Contains(evm.ActivePrecompiles(params.Rules{}), precompiles.StakingAddress)
+
+	// calling an inactive precompile is a call to an empty account
+	res := suite.callPrecompile(precompiles.BankAddress, input, true)
+	suite.Require().False(res.Failed(), res.VmError)
+	suite.Require().Empty(res.Ret)
+	suite.Require().True(suite.app.BankKeeper.GetBalance(suite.ctx, recipient.Bytes(), "stake").IsZero())
+}
+
+func (suite *KeeperTestSuite) TestExecuteNativeActionRevert() {
+	recipient := tests.GenerateAddress()
+	vmdb := suite.StateDB()
+	vmdb.AddBalance(suite.address, big.NewInt(1000))
+	suite.Require().NoError(vmdb.Commit())
+
+	vmdb = suite.StateDB()
+	send := func(amount int64) {
+		gasUsed, err := vmdb.ExecuteNativeAction(100000, func(ctx sdk.Context) error {
+			coins := sdk.NewCoins(sdk.NewInt64Coin(types.DefaultEVMDenom, amount))
+			return suite.app.BankKeeper.SendCoins(ctx, suite.address.Bytes(), recipient.Bytes(), coins)
+		})
+		suite.Require().NoError(err)
+		suite.Require().NotZero(gasUsed)
+	}
+
+	send(100)
+	snapshot := vmdb.Snapshot()
+	send(200)
+	suite.Require().Equal(big.NewInt(700), vmdb.GetBalance(suite.address))
+	suite.Require().Equal(big.NewInt(300), vmdb.GetBalance(recipient))
+
+	vmdb.RevertToSnapshot(snapshot)
+	suite.Require().Equal(big.NewInt(900), vmdb.GetBalance(suite.address))
+	suite.Require().Equal(big.NewInt(100), vmdb.GetBalance(recipient))
+
+	// nothing is written before commit
+	suite.Require().Equal(big.NewInt(1000), suite.app.EvmKeeper.GetBalance(suite.ctx, suite.address))
+	suite.Require().NoError(vmdb.Commit())
+	suite.Require().Equal(big.NewInt(900), suite.app.EvmKeeper.GetBalance(suite.ctx, suite.address))
+	suite.Require().Equal(big.NewInt(100), suite.app.EvmKeeper.GetBalance(suite.ctx, recipient))
+}
+
+func (suite *KeeperTestSuite) TestExecuteNativeActionOutOfGas() {
+	recipient := tests.GenerateAddress()
+	vmdb := suite.StateDB()
+	vmdb.AddBalance(suite.address, big.NewInt(1000))
+	suite.Require().NoError(vmdb.Commit())
+
+	vmdb = suite.StateDB()
+	gasUsed, err := vmdb.ExecuteNativeAction(100, func(ctx sdk.Context) error {
+		coins := sdk.NewCoins(sdk.NewInt64Coin(types.DefaultEVMDenom, 100))
+		return suite.app.BankKeeper.SendCoins(ctx, suite.address.Bytes(), recipient.Bytes(), coins)
+	})
+	suite.Require().ErrorIs(err, vm.ErrOutOfGas)
+	suite.Require().Equal(uint64(100), gasUsed)
+	suite.Require().Equal(big.NewInt(1000), vmdb.GetBalance(suite.address))
+	suite.Require().NoError(vmdb.Commit())
+	suite.Require().Equal(big.NewInt(0), suite.app.EvmKeeper.GetBalance(suite.ctx, recipient))
+}
+
+func (suite *KeeperTestSuite) TestPrecompileGas() {
+	bankABI := suite.loadPrecompileABI("bank")
+	recipient := tests.GenerateAddress()
+	suite.activatePrecompiles(precompiles.BankAddress)
+	err := testutil.FundAccount(suite.app.BankKeeper, suite.ctx, suite.address.Bytes(), sdk.NewCoins(sdk.NewInt64Coin("stake", 1000)))
+	suite.Require().NoError(err)
+	input, err := bankABI.Pack("send", recipient, "stake", big.NewInt(100))
+	suite.Require().NoError(err)
+
+	// the Cosmos gas of the send is charged on top of the flat gas
+	intrinsicGas, err := core.IntrinsicGas(input, nil, false, true, true)
+	suite.Require().NoError(err)
+	res := suite.callPrecompile(precompiles.BankAddress, input, false)
+	suite.Require().False(res.Failed(), res.VmError)
+	suite.Require().Greater(res.GasUsed, intrinsicGas+30000)
+
+	// running out of gas in the Cosmos action fails the call without side effects
+	cfg := suite.evmConfig()
+	msg := ethtypes.NewMessage(suite.address, &precompiles.BankAddress, 0, big.NewInt(0), intrinsicGas+30100, big.NewInt(0), big.NewInt(0), big.NewInt(0), input, nil, true)
+	txConfig := suite.app.EvmKeeper.TxConfig(suite.ctx, common.Hash{})
+	res, err = suite.app.EvmKeeper.ApplyMessageWithConfig(suite.ctx, msg, nil, true, cfg, txConfig)
+	suite.Require().NoError(err)
+	suite.Require().Equal(vm.ErrOutOfGas.Error(), res.VmError)
+	suite.Require().True(suite.app.BankKeeper.GetBalance(suite.ctx, recipient.Bytes(), "stake").IsZero())
+}
+
+// proxyCode returns the code of a contract forwarding its call data to the target
+// with the call opcode. The contract returns the success flag of the call, or
+// reverts after the call if revert is set.
+func proxyCode(op vm.OpCode, target common.Address, revert bool) []byte {
+	code := []byte{
+		byte(vm.CALLDATASIZE), byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.CALLDATACOPY),
+		byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.CALLDATASIZE), byte(vm.PUSH1), 0x00,
+	}
+	if op == vm.CALL {
+		code = append(code, byte(vm.PUSH1), 0x00)
+	}
+	code = append(code, byte(vm.PUSH20))
+	code = append(code, target.Bytes()...)
+	code = append(code, byte(vm.GAS), byte(op))
+	if revert {
+		return append(code, byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.REVERT))
+	}
+	return append(code,
+		byte(vm.PUSH1), 0x00, byte(vm.MSTORE), byte(vm.PUSH1), 0x20, byte(vm.PUSH1), 0x00, byte(vm.RETURN),
+	)
+}
+
+func (suite *KeeperTestSuite) TestNestedPrecompileCall() {
+	bankABI := suite.loadPrecompileABI("bank")
+	stakingABI := suite.loadPrecompileABI("staking")
+	distrABI := suite.loadPrecompileABI("distribution")
+	recipient := tests.GenerateAddress()
+	contract := tests.GenerateAddress()
+	validator := suite.bondedValidator()
+	valAddr, err := sdk.ValAddressFromBech32(validator.OperatorAddress)
+	suite.Require().NoError(err)
+	suite.activatePrecompiles(precompiles.BankAddress, precompiles.StakingAddress, precompiles.DistributionAddress)
+
+	vmdb := suite.StateDB()
+	vmdb.AddBalance(contract, big.NewInt(1000))
+	suite.Require().NoError(vmdb.Commit())
+	err = testutil.FundAccount(suite.app.BankKeeper, suite.ctx, contract.Bytes(), sdk.NewCoins(sdk.NewInt64Coin("stake", 1000)))
+	suite.Require().NoError(err)
+
+	// call runs the input through the proxy contract and returns its result
+	call := func(op vm.OpCode, target common.Address, revert bool, input []byte) *types.MsgEthereumTxResponse {
+		vmdb := suite.StateDB()
+		vmdb.SetCode(contract, proxyCode(op, target, revert))
+		suite.Require().NoError(vmdb.Commit())
+		return suite.callPrecompile(contract, input, true)
+	}
+	success := common.LeftPadBytes([]byte{1}, 32)
+	failure := common.LeftPadBytes(nil, 32)
+	delegation := func() sdkmath.LegacyDec {
+		delegation, err := suite.app.StakingKeeper.GetDelegation(suite.ctx, contract.Bytes(), valAddr)
+		if err != nil {
+			return sdkmath.LegacyZeroDec()
+		}
+		return delegation.Shares
+	}
+
+	suite.Run("bank", func() {
+		input, err := bankABI.Pack("send", recipient, types.DefaultEVMDenom, big.NewInt(100))
+		suite.Require().NoError(err)
+
+		// the revert of the caller undoes the send
+		res := call(vm.CALL, precompiles.BankAddress, true, input)
+		suite.Require().True(res.Failed())
+		suite.Require().Equal(big.NewInt(1000), suite.app.EvmKeeper.GetBalance(suite.ctx, contract))
+		suite.Require().Equal(big.NewInt(0), suite.app.EvmKeeper.GetBalance(suite.ctx, recipient))
+
+		res = call(vm.CALL, precompiles.BankAddress, false, input)
+		suite.Require().False(res.Failed(), res.VmError)
+		suite.Require().Equal(success, res.Ret)
+		suite.Require().Equal(big.NewInt(900), suite.app.EvmKeeper.GetBalance(suite.ctx, contract))
+		suite.Require().Equal(big.NewInt(100), suite.app.EvmKeeper.GetBalance(suite.ctx, recipient))
+	})
+
+	suite.Run("staking", func() {
+		input, err := stakingABI.Pack("delegate", validator.OperatorAddress, big.NewInt(600))
+		suite.Require().NoError(err)
+
+		res := call(vm.CALL, precompiles.StakingAddress, true, input)
+		suite.Require().True(res.Failed())
+		suite.Require().True(delegation().IsZero())
+		suite.Require().Equal(int64(1000), suite.app.BankKeeper.GetBalance(suite.ctx, contract.Bytes(), "stake").Amount.Int64())
+
+		// the contract is the delegator
+		res = call(vm.CALL, precompiles.StakingAddress, false, input)
+		suite.Require().False(res.Failed(), res.VmError)
+		suite.Require().Equal(success, res.Ret)
+		shares, err := validator.SharesFromTokens(sdkmath.NewInt(600))
+		suite.Require().NoError(err)
+		suite.Require().Equal(shares, delegation())
+		suite.Require().Equal(int64(400), suite.app.BankKeeper.GetBalance(suite.ctx, contract.Bytes(), "stake").Amount.Int64())
+	})
+
+	suite.Run("distribution", func() {
+		// the withdrawal starts a new reward period for the delegation
+		startingPeriod := func() uint64 {
+			info, err := suite.app.DistrKeeper.GetDelegatorStartingInfo(suite.ctx, valAddr, contract.Bytes())
+			suite.Require().NoError(err)
+			return info.PreviousPeriod
+		}
+		period := startingPeriod()
+		input, err := distrABI.Pack("withdrawDelegatorRewards", validator.OperatorAddress)
+		suite.Require().NoError(err)
+
+		res := call(vm.CALL, precompiles.DistributionAddress, true, input)
+		suite.Require().True(res.Failed())
+		suite.Require().Equal(period, startingPeriod())
+
+		res = call(vm.CALL, precompiles.DistributionAddress, false, input)
+		suite.Require().False(res.Failed(), res.VmError)
+		suite.Require().Equal(success, res.Ret)
+		suite.Require().Greater(startingPeriod(), period)
+	})
+
+	suite.Run("static and delegated calls", func() {
+		send, err := bankABI.Pack("send", recipient, types.DefaultEVMDenom, big.NewInt(100))
+		suite.Require().NoError(err)
+		balanceOf, err := bankABI.Pack("balanceOf", recipient, types.DefaultEVMDenom)
+		suite.Require().NoError(err)
+
+		testCases := []struct {
+			msg    string
+			op     vm.OpCode
+			input  []byte
+			expRet []byte
+		}{
+			{"static call to a view method", vm.STATICCALL, balanceOf, success},
+			{"static call to a state changing method", vm.STATICCALL, send, failure},
+			{"delegate call", vm.DELEGATECALL, send, failure},
+			{"delegate call to a view method", vm.DELEGATECALL, balanceOf, failure},
+		}
+		for _, tc := range testCases {
+			res := call(tc.op, precompiles.BankAddress, false, tc.input)
+			suite.Require().False(res.Failed(), res.VmError)
+			suite.Require().Equal(tc.expRet, res.Ret, tc.msg)
+			suite.Require().Equal(big.NewInt(900), suite.app.EvmKeeper.GetBalance(suite.ctx, contract), tc.msg)
+			suite.Require().Equal(big.NewInt(100), suite.app.EvmKeeper.GetBalance(suite.ctx, recipient), tc.msg)
+		}
+	})
+}
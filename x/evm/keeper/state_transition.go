@@ -16,7 +16,10 @@
 package keeper
 
 import (
+	"bytes"
 	"math/big"
+	"slices"
+	"sort"
 
 	tmtypes "github.com/cometbft/cometbft/types"
 
@@ -71,7 +74,34 @@ func (k *Keeper) NewEVM(
 		tracer = k.Tracer(ctx, msg, cfg.ChainConfig)
 	}
 	vmConfig := k.VMConfig(ctx, msg, cfg, tracer)
-	return k.evmConstructor(blockCtx, txCtx, stateDB, cfg.ChainConfig, vmConfig, k.customPrecompiles)
+	return k.evmConstructor(blockCtx, txCtx, stateDB, cfg.ChainConfig, vmConfig, k.activePrecompiles(cfg.Params))
+}
+
+// activePrecompiles returns the custom precompiled contracts that are enabled by the
+// ActivePrecompiles module parameter.
+func (k *Keeper) activePrecompiles(params types.Params) evm.PrecompiledContracts {
+	active := params.ActivePrecompileAddresses()
+	precompiles := make(evm.PrecompiledContracts, len(active))
+	for addr, p := range k.customPrecompiles {
+		if _, ok := active[addr]; ok {
+			precompiles[addr] = p
+		}
+	}
+	return precompiles
+}
+
+// activePrecompileAddresses returns the addresses of the precompiled contracts of the
+// rules, followed by the ones of the custom precompiled contracts enabled by the params.
+func (k *Keeper) activePrecompileAddresses(rules params.Rules, evmParams types.Params) []common.Address {
+	custom := k.activePrecompiles(evmParams)
+	addrs := make([]common.Address, 0, len(custom))
+	for addr := range custom {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i].Bytes(), addrs[j].Bytes()) < 0
+	})
+	return append(slices.Clone(vm.ActivePrecompiles(rules)), addrs...)
 }
 
 // GetHashFn implements vm.GetHashFunc for Ethermint. It handles 3 cases:
@@ -318,6 +348,23 @@ func (k *Keeper) ApplyMessageWithConfig(ctx sdk.Context,
 	commit bool,
 	cfg *statedb.EVMConfig,
 	txConfig statedb.TxConfig,
+) (*types.MsgEthereumTxResponse, error) {
+	stateDB := statedb.New(ctx, k, txConfig)
+	if err := cfg.Overrides.Apply(stateDB); err != nil {
+		return nil, errorsmod.Wrap(err, "failed to apply state overrides")
+	}
+	return k.applyMessageWithStateDB(ctx, msg, tracer, commit, cfg, txConfig, stateDB)
+}
+
+// applyMessageWithStateDB is the same as ApplyMessageWithConfig, but executes the
+// message on top of the provided StateDB, so the caller can inspect it afterwards.
+func (k *Keeper) applyMessageWithStateDB(ctx sdk.Context,
+	msg core.Message,
+	tracer vm.EVMLogger,
+	commit bool,
+	cfg *statedb.EVMConfig,
+	txConfig statedb.TxConfig,
+	stateDB *statedb.StateDB,
 ) (*types.MsgEthereumTxResponse, error) {
 	var (
 		ret   []byte // return bytes from evm execution
@@ -331,7 +378,6 @@ func (k *Keeper) ApplyMessageWithConfig(ctx sdk.Context,
 		return nil, errorsmod.Wrap(types.ErrCallDisabled, "failed to call contract")
 	}
 
-	stateDB := statedb.New(ctx, k, txConfig)
 	evm := k.NewEVM(ctx, msg, cfg, tracer, stateDB)
 
 	leftoverGas := msg.Gas()
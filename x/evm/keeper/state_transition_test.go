@@ -663,6 +663,135 @@ func (suite *KeeperTestSuite) TestApplyMessageWithConfig() {
 	}
 }
 
+func (suite *KeeperTestSuite) TestApplyMessagePUSH0() {
+	// PUSH0 PUSH0 MSTORE PUSH1 0x20 PUSH0 RETURN
+	code := common.FromHex("0x5f5f5260205ff3")
+	contract := tests.GenerateAddress()
+
+	testCases := []struct {
+		name     string
+		shanghai bool
+		expFail  bool
+	}{
+		{"before shanghai, invalid opcode", false, true},
+		{"after shanghai", true, false},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.SetupTest()
+			vmdb := suite.StateDB()
+			vmdb.SetCode(contract, code)
+			suite.Require().NoError(vmdb.Commit())
+
+			proposerAddress := suite.ctx.BlockHeader().ProposerAddress
+			config, err := suite.app.EvmKeeper.EVMConfig(suite.ctx, proposerAddress, big.NewInt(9000))
+			suite.Require().NoError(err)
+			if !tc.shanghai {
+				config.ChainConfig.ShanghaiBlock = nil
+				config.ChainConfig.CancunBlock = nil
+			}
+
+			msg := ethtypes.NewMessage(suite.address, &contract, 0, big.NewInt(0), 100000, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, true)
+			txConfig := suite.app.EvmKeeper.TxConfig(suite.ctx, common.Hash{})
+			res, err := suite.app.EvmKeeper.ApplyMessageWithConfig(suite.ctx, msg, nil, false, config, txConfig)
+			suite.Require().NoError(err)
+			suite.Require().Equal(tc.expFail, res.Failed())
+			if !tc.expFail {
+				suite.Require().Equal(make([]byte, 32), res.Ret)
+			}
+		})
+	}
+}
+
+func (suite *KeeperTestSuite) TestApplyMessageCancunOpcodes() {
+	// PUSH1 0x2a PUSH1 0x00 TSTORE PUSH1 0x00 TLOAD PUSH1 0x20 MSTORE
+	// PUSH1 0x20 PUSH1 0x20 PUSH1 0x00 MCOPY PUSH1 0x20 PUSH1 0x00 RETURN
+	code := common.FromHex("0x602a60005d60005c6020526020602060005e60206000f3")
+	contract := tests.GenerateAddress()
+
+	testCases := []struct {
+		name    string
+		cancun  bool
+		expFail bool
+	}{
+		{"before cancun, invalid opcode", false, true},
+		{"after cancun", true, false},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.SetupTest()
+			vmdb := suite.StateDB()
+			vmdb.SetCode(contract, code)
+			suite.Require().NoError(vmdb.Commit())
+
+			proposerAddress := suite.ctx.BlockHeader().ProposerAddress
+			config, err := suite.app.EvmKeeper.EVMConfig(suite.ctx, proposerAddress, big.NewInt(9000))
+			suite.Require().NoError(err)
+			if !tc.cancun {
+				config.ChainConfig.CancunBlock = nil
+			}
+
+			msg := ethtypes.NewMessage(suite.address, &contract, 0, big.NewInt(0), 100000, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, true)
+			txConfig := suite.app.EvmKeeper.TxConfig(suite.ctx, common.Hash{})
+			res, err := suite.app.EvmKeeper.ApplyMessageWithConfig(suite.ctx, msg, nil, true, config, txConfig)
+			suite.Require().NoError(err)
+			suite.Require().Equal(tc.expFail, res.Failed(), res.VmError)
+			if !tc.expFail {
+				// the value stored in the transient storage is copied in memory
+				suite.Require().Equal(common.LeftPadBytes([]byte{0x2a}, 32), res.Ret)
+			}
+			// the transient storage isn't persisted
+			suite.Require().Equal(common.Hash{}, suite.app.EvmKeeper.GetState(suite.ctx, contract, common.Hash{}))
+		})
+	}
+}
+
+func (suite *KeeperTestSuite) TestApplyMessageSelfdestructEIP6780() {
+	beneficiary := tests.GenerateAddress()
+	contract := tests.GenerateAddress()
+
+	testCases := []struct {
+		name        string
+		code        []byte
+		expBalance  int64
+		expTransfer int64
+	}{
+		// ADDRESS SELFDESTRUCT
+		{"the contract is the beneficiary", common.FromHex("0x30ff"), 100, 0},
+		// PUSH20 beneficiary SELFDESTRUCT
+		{"another beneficiary", append(append([]byte{0x73}, beneficiary.Bytes()...), 0xff), 0, 100},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.SetupTest()
+			vmdb := suite.StateDB()
+			vmdb.SetCode(contract, tc.code)
+			vmdb.AddBalance(contract, big.NewInt(100))
+			suite.Require().NoError(vmdb.Commit())
+
+			proposerAddress := suite.ctx.BlockHeader().ProposerAddress
+			config, err := suite.app.EvmKeeper.EVMConfig(suite.ctx, proposerAddress, big.NewInt(9000))
+			suite.Require().NoError(err)
+
+			msg := ethtypes.NewMessage(suite.address, &contract, 0, big.NewInt(0), 100000, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, true)
+			txConfig := suite.app.EvmKeeper.TxConfig(suite.ctx, common.Hash{})
+			res, err := suite.app.EvmKeeper.ApplyMessageWithConfig(suite.ctx, msg, nil, true, config, txConfig)
+			suite.Require().NoError(err)
+			suite.Require().False(res.Failed())
+
+			// the contract isn't deleted, its balance is only moved to the beneficiary
+			acc := suite.app.EvmKeeper.GetAccount(suite.ctx, contract)
+			suite.Require().NotNil(acc)
+			suite.Require().Equal(tc.code, suite.app.EvmKeeper.GetCode(suite.ctx, common.BytesToHash(acc.CodeHash)))
+			suite.Require().Equal(tc.expBalance, suite.app.EvmKeeper.GetBalance(suite.ctx, contract).Int64())
+			suite.Require().Equal(tc.expTransfer, suite.app.EvmKeeper.GetBalance(suite.ctx, beneficiary).Int64())
+		})
+	}
+}
+
 func (suite *KeeperTestSuite) createContractGethMsg(nonce uint64, signer ethtypes.Signer, cfg *params.ChainConfig, gasPrice *big.Int) (core.Message, error) {
 	ethMsg, err := suite.createContractMsgTx(nonce, signer, cfg, gasPrice)
 	if err != nil {
@@ -87,18 +87,42 @@ func (k *Keeper) ForEachStorage(ctx sdk.Context, addr common.Address, cb func(ke
 	}
 }
 
+// GetStorageHash returns the storage hash of an account persisted with its storage, see
+// `types.StorageHash`.
+func (k *Keeper) GetStorageHash(ctx sdk.Context, addr common.Address) common.Hash {
+	store := runtime.KVStoreAdapter(k.storeService.OpenKVStore(ctx))
+	return common.BytesToHash(store.Get(types.StorageHashKey(addr)))
+}
+
+// setStorageHash sets the storage hash of an account, the empty storage has no hash.
+func (k *Keeper) setStorageHash(ctx sdk.Context, addr common.Address, hash common.Hash) {
+	store := runtime.KVStoreAdapter(k.storeService.OpenKVStore(ctx))
+	if hash == (common.Hash{}) {
+		store.Delete(types.StorageHashKey(addr))
+		return
+	}
+	store.Set(types.StorageHashKey(addr), hash.Bytes())
+}
+
 // SetBalance update account's balance, compare with current balance first, then decide to mint or burn.
+// The sub-unit remainder is kept as fractional balance when the conversion factor is above one.
 func (k *Keeper) SetBalance(ctx sdk.Context, addr common.Address, amount *big.Int) error {
-	cosmosAddr := sdk.AccAddress(addr.Bytes())
-
 	params := k.GetParams(ctx)
-	coin := k.bankKeeper.GetBalance(ctx, cosmosAddr, params.EvmDenom)
+	if params.EVMConversionFactor().Cmp(big.NewInt(1)) > 0 {
+		return k.setExtendedBalance(ctx, addr, amount, params)
+	}
+	return k.setBankBalance(ctx, sdk.AccAddress(addr.Bytes()), amount, params.EvmDenom)
+}
+
+// setBankBalance sets the bank balance of the denom, minting or burning the difference.
+func (k *Keeper) setBankBalance(ctx sdk.Context, cosmosAddr sdk.AccAddress, amount *big.Int, denom string) error {
+	coin := k.bankKeeper.GetBalance(ctx, cosmosAddr, denom)
 	balance := coin.Amount.BigInt()
 	delta := new(big.Int).Sub(amount, balance)
 	switch delta.Sign() {
 	case 1:
 		// mint
-		coins := sdk.NewCoins(sdk.NewCoin(params.EvmDenom, sdkmath.NewIntFromBigInt(delta)))
+		coins := sdk.NewCoins(sdk.NewCoin(denom, sdkmath.NewIntFromBigInt(delta)))
 		if err := k.bankKeeper.MintCoins(ctx, types.ModuleName, coins); err != nil {
 			return err
 		}
@@ -107,7 +131,7 @@ func (k *Keeper) SetBalance(ctx sdk.Context, addr common.Address, amount *big.In
 		}
 	case -1:
 		// burn
-		coins := sdk.NewCoins(sdk.NewCoin(params.EvmDenom, sdkmath.NewIntFromBigInt(new(big.Int).Neg(delta))))
+		coins := sdk.NewCoins(sdk.NewCoin(denom, sdkmath.NewIntFromBigInt(new(big.Int).Neg(delta))))
 		if err := k.bankKeeper.SendCoinsFromAccountToModule(ctx, cosmosAddr, types.ModuleName, coins); err != nil {
 			return err
 		}
@@ -161,6 +185,8 @@ func (k *Keeper) SetAccount(ctx sdk.Context, addr common.Address, account stated
 func (k *Keeper) SetState(ctx sdk.Context, addr common.Address, key common.Hash, value []byte) {
 	store := runtime.KVStoreAdapter(k.storeService.OpenKVStore(ctx))
 	prefixStore := prefix.NewStore(store, types.AddressStoragePrefix(addr))
+	prevValue := common.BytesToHash(prefixStore.Get(key.Bytes()))
+	k.setStorageHash(ctx, addr, types.UpdateStorageHash(k.GetStorageHash(ctx, addr), key, prevValue, common.BytesToHash(value)))
 	action := "updated"
 	if len(value) == 0 {
 		prefixStore.Delete(key.Bytes())
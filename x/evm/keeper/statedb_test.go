@@ -450,6 +450,37 @@ func (suite *KeeperTestSuite) TestCommittedState() {
 	suite.Require().Equal(value2, tmp)
 }
 
+func (suite *KeeperTestSuite) TestStorageHash() {
+	addr := tests.GenerateAddress()
+	storage := make(map[common.Hash]common.Hash)
+	setState := func(key, value common.Hash) {
+		vmdb := suite.StateDB()
+		vmdb.SetState(addr, key, value)
+		suite.Require().NoError(vmdb.Commit())
+		storage[key] = value
+	}
+
+	// the storage hash follows the storage writes
+	for i := int64(1); i <= 5; i++ {
+		setState(common.BigToHash(big.NewInt(i)), common.BigToHash(big.NewInt(i*10)))
+	}
+	suite.Require().Equal(types.StorageHash(storage), suite.app.EvmKeeper.GetStorageHash(suite.ctx, addr))
+	setState(common.BigToHash(big.NewInt(1)), common.BigToHash(big.NewInt(100)))
+	setState(common.BigToHash(big.NewInt(2)), common.Hash{})
+	suite.Require().Equal(types.StorageHash(storage), suite.app.EvmKeeper.GetStorageHash(suite.ctx, addr))
+
+	// the hash of the deleted account is removed with its storage
+	vmdb := suite.StateDB()
+	vmdb.SetCode(addr, []byte("code"))
+	suite.Require().NoError(vmdb.Commit())
+	vmdb = suite.StateDB()
+	vmdb.Suicide(addr)
+	suite.Require().NoError(vmdb.Commit())
+	suite.Require().Equal(common.Hash{}, suite.app.EvmKeeper.GetStorageHash(suite.ctx, addr))
+	store := suite.ctx.KVStore(suite.app.GetKey(types.StoreKey))
+	suite.Require().False(store.Has(types.StorageHashKey(addr)))
+}
+
 func (suite *KeeperTestSuite) TestSuicide() {
 	code := []byte("code")
 	db := suite.StateDB()
@@ -27,6 +27,7 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	errortypes "github.com/cosmos/cosmos-sdk/types/errors"
 	authante "github.com/cosmos/cosmos-sdk/x/auth/ante"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
 
 	"github.com/evmos/ethermint/x/evm/types"
 )
@@ -61,6 +62,8 @@ func GetProposerAddress(ctx sdk.Context, proposerAddress sdk.ConsAddress) sdk.Co
 
 // DeductTxCostsFromUserBalance deducts the fees from the user balance. Returns an
 // error if the specified sender address does not exist or the account balance is not sufficient.
+// The fees of the EVM denom are in wei, they are moved from the EVM balance of the user to the
+// one of the fee collector, so the fractional balance of the user is spent too.
 func (k *Keeper) DeductTxCostsFromUserBalance(
 	ctx sdk.Context,
 	fees sdk.Coins,
@@ -72,6 +75,16 @@ func (k *Keeper) DeductTxCostsFromUserBalance(
 		return errorsmod.Wrapf(err, "account not found for sender %s", from)
 	}
 
+	params := k.GetParams(ctx)
+	if params.EVMConversionFactor().Cmp(big.NewInt(1)) > 0 {
+		if found, fee := fees.Find(params.EvmDenom); found {
+			if err := k.sendExtendedBalanceToModule(ctx, from, authtypes.FeeCollectorName, fee.Amount.BigInt(), params); err != nil {
+				return errorsmod.Wrapf(err, "failed to deduct full gas cost %s from the user %s balance", fees, from)
+			}
+			fees = fees.Sub(fee)
+		}
+	}
+
 	// deduct the full gas cost from the user balance
 	if err := authante.DeductFees(k.bankKeeper, ctx, signerAcc, fees); err != nil {
 		return errorsmod.Wrapf(err, "failed to deduct full gas cost %s from the user %s balance", fees, from)
@@ -0,0 +1,62 @@
+// Copyright 2021 Evmos Foundation
+// This file is part of Evmos' Ethermint library.
+//
+// The Ethermint library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Ethermint library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Ethermint library. If not, see https://github.com/evmos/ethermint/blob/main/LICENSE
+package v6
+
+import (
+	corestore "cosmossdk.io/core/store"
+	storetypes "cosmossdk.io/store/types"
+	"github.com/cosmos/cosmos-sdk/runtime"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/evmos/ethermint/x/evm/types"
+)
+
+// MigrateStore migrates the x/evm module state from the consensus version 5 to
+// version 6. Specifically, it persists the storage hash of the accounts with a
+// storage, which is then updated by the storage writes.
+func MigrateStore(ctx sdk.Context, storeService corestore.KVStoreService) error {
+	store := runtime.KVStoreAdapter(storeService.OpenKVStore(ctx))
+
+	// the slots are iterated by account, the hashes are written once the iteration is done
+	var (
+		addrs  []common.Address
+		hashes = make(map[common.Address]common.Hash)
+	)
+	iterator := storetypes.KVStorePrefixIterator(store, types.KeyPrefixStorage)
+	for ; iterator.Valid(); iterator.Next() {
+		key := iterator.Key()[len(types.KeyPrefixStorage):]
+		if len(key) != common.AddressLength+common.HashLength {
+			continue
+		}
+		addr := common.BytesToAddress(key[:common.AddressLength])
+		if _, ok := hashes[addr]; !ok {
+			addrs = append(addrs, addr)
+		}
+		slot := common.BytesToHash(key[common.AddressLength:])
+		hashes[addr] = types.UpdateStorageHash(hashes[addr], slot, common.Hash{}, common.BytesToHash(iterator.Value()))
+	}
+	if err := iterator.Close(); err != nil {
+		return err
+	}
+
+	for _, addr := range addrs {
+		if hash := hashes[addr]; hash != (common.Hash{}) {
+			store.Set(types.StorageHashKey(addr), hash.Bytes())
+		}
+	}
+	return nil
+}
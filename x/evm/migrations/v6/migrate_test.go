@@ -0,0 +1,52 @@
+package v6_test
+
+import (
+	"testing"
+
+	storetypes "cosmossdk.io/store/types"
+	"github.com/cosmos/cosmos-sdk/runtime"
+	"github.com/cosmos/cosmos-sdk/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	v6 "github.com/evmos/ethermint/x/evm/migrations/v6"
+	"github.com/evmos/ethermint/x/evm/types"
+)
+
+func TestMigrate(t *testing.T) {
+	storeKey := storetypes.NewKVStoreKey(types.ModuleName)
+	tKey := storetypes.NewTransientStoreKey("transient_test")
+	ctx := testutil.DefaultContext(storeKey, tKey)
+	storeService := runtime.NewKVStoreService(storeKey)
+	kvStore := ctx.KVStore(storeKey)
+
+	storages := map[common.Address]map[common.Hash]common.Hash{
+		common.HexToAddress("0x01"): {
+			common.HexToHash("0x01"): common.HexToHash("0x0a"),
+			common.HexToHash("0x02"): common.HexToHash("0x0b"),
+		},
+		common.HexToAddress("0x02"): {
+			common.HexToHash("0x01"): common.HexToHash("0x0c"),
+		},
+		// the empty slots have no hash
+		common.HexToAddress("0x03"): {
+			common.HexToHash("0x01"): {},
+		},
+	}
+	for addr, storage := range storages {
+		for key, value := range storage {
+			kvStore.Set(types.StateKey(addr, key.Bytes()), value.Bytes())
+		}
+	}
+
+	require.NoError(t, v6.MigrateStore(ctx, storeService))
+
+	for addr, storage := range storages {
+		hash := types.StorageHash(storage)
+		if hash == (common.Hash{}) {
+			require.False(t, kvStore.Has(types.StorageHashKey(addr)))
+			continue
+		}
+		require.Equal(t, hash.Bytes(), kvStore.Get(types.StorageHashKey(addr)))
+	}
+}
@@ -0,0 +1,12 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+pragma solidity >=0.8.0;
+
+/// @dev The bank precompiled contract, at 0x0000000000000000000000000000000000000804.
+/// It is only callable as the recipient of a transaction, the calls from contracts revert.
+interface IBank {
+    /// @dev sends native coins of the caller to an address.
+    function send(address to, string calldata denom, uint256 amount) external returns (bool);
+
+    /// @dev returns the native coin balance of an address.
+    function balanceOf(address account, string calldata denom) external view returns (uint256);
+}
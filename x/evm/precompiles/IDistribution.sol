@@ -0,0 +1,17 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+pragma solidity >=0.8.0;
+
+struct Coin {
+    string denom;
+    uint256 amount;
+}
+
+/// @dev The distribution precompiled contract, at 0x0000000000000000000000000000000000000801.
+/// It is only callable as the recipient of a transaction, the calls from contracts revert.
+interface IDistribution {
+    /// @dev withdraws the rewards of the caller delegation to a validator, returns the withdrawn coins.
+    function withdrawDelegatorRewards(string calldata validatorAddress) external returns (Coin[] memory amount);
+
+    /// @dev returns the rewards of a delegation, truncated to integer amounts.
+    function delegationRewards(address delegator, string calldata validatorAddress) external view returns (Coin[] memory rewards);
+}
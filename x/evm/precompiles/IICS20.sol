@@ -0,0 +1,40 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+pragma solidity >=0.8.0;
+
+struct DenomTrace {
+    string path;
+    string baseDenom;
+}
+
+/// @dev The ICS-20 transfer precompiled contract, at 0x0000000000000000000000000000000000000802.
+/// It is only callable as the recipient of a transaction, the calls from contracts revert.
+/// The IICS20Callback of the senders are thus not reachable until the precompiles support
+/// nested calls.
+interface IICS20 {
+    /// @dev sends native coins of the caller to a receiver on the counterparty chain of a
+    /// transfer channel, the timeout is a unix timestamp in nanoseconds. Returns the packet
+    /// sequence.
+    function transfer(
+        string calldata sourceChannel,
+        string calldata denom,
+        uint256 amount,
+        string calldata receiver,
+        uint64 timeoutTimestamp
+    ) external returns (uint64 sequence);
+
+    /// @dev returns the denom trace of an IBC voucher, by hash or "ibc/{hash}" denom.
+    function denomTrace(string calldata hash) external view returns (DenomTrace memory trace);
+}
+
+
+/// @dev The callback of the contracts sending ICS-20 transfers, called by the precompile
+/// address with the result of each packet and a gas limit of 200000. The changes of a
+/// reverted callback are discarded, the transfer result is not affected.
+interface IICS20Callback {
+    /// @dev called when the packet is acknowledged, success is false for an error
+    /// acknowledgement, in which case the coins were refunded.
+    function onICS20Acknowledgement(string calldata sourceChannel, uint64 sequence, bool success) external;
+
+    /// @dev called when the packet timed out and the coins were refunded.
+    function onICS20Timeout(string calldata sourceChannel, uint64 sequence) external;
+}
@@ -0,0 +1,23 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+pragma solidity >=0.8.0;
+
+/// @dev The staking precompiled contract, at 0x0000000000000000000000000000000000000800.
+/// It is only callable as the recipient of a transaction, the calls from contracts revert.
+/// The amounts are in the bond denom.
+interface IStaking {
+    /// @dev delegates coins of the caller to a validator.
+    function delegate(string calldata validatorAddress, uint256 amount) external returns (bool);
+
+    /// @dev undelegates coins of the caller from a validator, returns the unix time of the completion.
+    function undelegate(string calldata validatorAddress, uint256 amount) external returns (int64 completionTime);
+
+    /// @dev redelegates coins of the caller between validators, returns the unix time of the completion.
+    function redelegate(
+        string calldata validatorSrcAddress,
+        string calldata validatorDstAddress,
+        uint256 amount
+    ) external returns (int64 completionTime);
+
+    /// @dev returns the amount delegated by a delegator to a validator.
+    function delegation(address delegator, string calldata validatorAddress) external view returns (uint256);
+}
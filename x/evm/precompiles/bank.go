@@ -0,0 +1,73 @@
+// Copyright 2021 Evmos Foundation
+// This file is part of Evmos' Ethermint library.
+//
+// The Ethermint library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Ethermint library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Ethermint library. If not, see https://github.com/evmos/ethermint/blob/main/LICENSE
+package precompiles
+
+import (
+	// embed the precompiled contract ABI
+	_ "embed"
+	"math/big"
+
+	sdkmath "cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	bankkeeper "github.com/cosmos/cosmos-sdk/x/bank/keeper"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/ethereum/go-ethereum/common"
+
+	evm "github.com/evmos/ethermint/x/evm/vm"
+)
+
+const (
+	bankSendGas      = 30000
+	bankBalanceOfGas = 3000
+)
+
+//go:embed bank.abi.json
+var bankABI []byte
+
+// NewBankPrecompile returns the bank precompiled contract, it sends the native coins
+// of the caller and queries the balances, see IBank.sol.
+func NewBankPrecompile(bankKeeper bankkeeper.Keeper) evm.StatefulPrecompiledContract {
+	msgServer := bankkeeper.NewMsgServerImpl(bankKeeper)
+	return mustNewPrecompile(bankABI, map[string]method{
+		"send": {
+			gas: bankSendGas,
+			run: func(ctx sdk.Context, caller common.Address, args []interface{}) ([]interface{}, error) {
+				to := args[0].(common.Address)
+				coin := sdk.Coin{Denom: args[1].(string), Amount: sdkmath.NewIntFromBigInt(args[2].(*big.Int))}
+				if err := coin.Validate(); err != nil {
+					return nil, err
+				}
+				msg := banktypes.NewMsgSend(sdk.AccAddress(caller.Bytes()), sdk.AccAddress(to.Bytes()), sdk.NewCoins(coin))
+				if _, err := msgServer.Send(ctx, msg); err != nil {
+					return nil, err
+				}
+				return []interface{}{true}, nil
+			},
+		},
+		"balanceOf": {
+			gas: bankBalanceOfGas,
+			run: func(ctx sdk.Context, _ common.Address, args []interface{}) ([]interface{}, error) {
+				account := args[0].(common.Address)
+				denom := args[1].(string)
+				if err := sdk.ValidateDenom(denom); err != nil {
+					return nil, err
+				}
+				balance := bankKeeper.GetBalance(ctx, sdk.AccAddress(account.Bytes()), denom)
+				return []interface{}{balance.Amount.BigInt()}, nil
+			},
+		},
+	})
+}
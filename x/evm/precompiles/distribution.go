@@ -0,0 +1,70 @@
+// Copyright 2021 Evmos Foundation
+// This file is part of Evmos' Ethermint library.
+//
+// The Ethermint library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Ethermint library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Ethermint library. If not, see https://github.com/evmos/ethermint/blob/main/LICENSE
+package precompiles
+
+import (
+	// embed the precompiled contract ABI
+	_ "embed"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	distrkeeper "github.com/cosmos/cosmos-sdk/x/distribution/keeper"
+	distrtypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	"github.com/ethereum/go-ethereum/common"
+
+	evm "github.com/evmos/ethermint/x/evm/vm"
+)
+
+const (
+	distrWithdrawDelegatorRewardsGas = 50000
+	distrDelegationRewardsGas        = 5000
+)
+
+//go:embed distribution.abi.json
+var distributionABI []byte
+
+// NewDistributionPrecompile returns the distribution precompiled contract, it
+// withdraws and queries the delegation rewards, see IDistribution.sol.
+func NewDistributionPrecompile(distrKeeper distrkeeper.Keeper) evm.StatefulPrecompiledContract {
+	msgServer := distrkeeper.NewMsgServerImpl(distrKeeper)
+	querier := distrkeeper.NewQuerier(distrKeeper)
+	return mustNewPrecompile(distributionABI, map[string]method{
+		"withdrawDelegatorRewards": {
+			gas: distrWithdrawDelegatorRewardsGas,
+			run: func(ctx sdk.Context, caller common.Address, args []interface{}) ([]interface{}, error) {
+				msg := distrtypes.NewMsgWithdrawDelegatorReward(sdk.AccAddress(caller.Bytes()).String(), args[0].(string))
+				res, err := msgServer.WithdrawDelegatorReward(ctx, msg)
+				if err != nil {
+					return nil, err
+				}
+				return []interface{}{newCoins(res.Amount)}, nil
+			},
+		},
+		"delegationRewards": {
+			gas: distrDelegationRewardsGas,
+			run: func(ctx sdk.Context, _ common.Address, args []interface{}) ([]interface{}, error) {
+				res, err := querier.DelegationRewards(ctx, &distrtypes.QueryDelegationRewardsRequest{
+					DelegatorAddress: sdk.AccAddress(args[0].(common.Address).Bytes()).String(),
+					ValidatorAddress: args[1].(string),
+				})
+				if err != nil {
+					return nil, err
+				}
+				rewards, _ := res.Rewards.TruncateDecimal()
+				return []interface{}{newCoins(rewards)}, nil
+			},
+		},
+	})
+}
@@ -0,0 +1,90 @@
+// Copyright 2021 Evmos Foundation
+// This file is part of Evmos' Ethermint library.
+//
+// The Ethermint library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Ethermint library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Ethermint library. If not, see https://github.com/evmos/ethermint/blob/main/LICENSE
+package precompiles
+
+import (
+	// embed the precompiled contract ABI
+	_ "embed"
+	"math/big"
+
+	"cosmossdk.io/core/store"
+	sdkmath "cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ibctransferkeeper "github.com/cosmos/ibc-go/v8/modules/apps/transfer/keeper"
+	ibctransfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	"github.com/ethereum/go-ethereum/common"
+
+	evmtypes "github.com/evmos/ethermint/x/evm/types"
+	evm "github.com/evmos/ethermint/x/evm/vm"
+)
+
+const (
+	ics20TransferGas   = 100000
+	ics20DenomTraceGas = 3000
+)
+
+//go:embed ics20.abi.json
+var ics20ABI []byte
+
+// denomTrace is the ABI tuple of an IBC denom trace.
+type denomTrace struct {
+	Path      string
+	BaseDenom string
+}
+
+// NewICS20Precompile returns the ICS-20 transfer precompiled contract, it sends the
+// native coins of the caller over IBC and queries the denom traces, see IICS20.sol.
+// The sent packets are recorded in the EVM store, the ICS20Middleware only delivers the
+// results of the recorded packets to its hooks.
+func NewICS20Precompile(transferKeeper ibctransferkeeper.Keeper, storeService store.KVStoreService) evm.StatefulPrecompiledContract {
+	return mustNewPrecompile(ics20ABI, map[string]method{
+		"transfer": {
+			gas: ics20TransferGas,
+			run: func(ctx sdk.Context, caller common.Address, args []interface{}) ([]interface{}, error) {
+				token := sdk.Coin{Denom: args[1].(string), Amount: sdkmath.NewIntFromBigInt(args[2].(*big.Int))}
+				msg := ibctransfertypes.NewMsgTransfer(
+					ibctransfertypes.PortID, args[0].(string), token,
+					sdk.AccAddress(caller.Bytes()).String(), args[3].(string),
+					clienttypes.ZeroHeight(), args[4].(uint64), "",
+				)
+				if err := msg.ValidateBasic(); err != nil {
+					return nil, err
+				}
+				res, err := transferKeeper.Transfer(ctx, msg)
+				if err != nil {
+					return nil, err
+				}
+				key := evmtypes.ICS20PacketKey(msg.SourceChannel, res.Sequence)
+				if err := storeService.OpenKVStore(ctx).Set(key, []byte{1}); err != nil {
+					return nil, err
+				}
+				return []interface{}{res.Sequence}, nil
+			},
+		},
+		"denomTrace": {
+			gas: ics20DenomTraceGas,
+			run: func(ctx sdk.Context, _ common.Address, args []interface{}) ([]interface{}, error) {
+				res, err := transferKeeper.DenomTrace(ctx, &ibctransfertypes.QueryDenomTraceRequest{Hash: args[0].(string)})
+				if err != nil {
+					return nil, err
+				}
+				trace := denomTrace{Path: res.DenomTrace.Path, BaseDenom: res.DenomTrace.BaseDenom}
+				return []interface{}{trace}, nil
+			},
+		},
+	})
+}
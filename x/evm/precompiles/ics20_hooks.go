@@ -0,0 +1,254 @@
+// Copyright 2021 Evmos Foundation
+// This file is part of Evmos' Ethermint library.
+//
+// The Ethermint library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Ethermint library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Ethermint library. If not, see https://github.com/evmos/ethermint/blob/main/LICENSE
+package precompiles
+
+import (
+	"bytes"
+	// embed the callback ABI
+	_ "embed"
+	"math/big"
+
+	"cosmossdk.io/core/store"
+	errorsmod "cosmossdk.io/errors"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ibctransfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	porttypes "github.com/cosmos/ibc-go/v8/modules/core/05-port/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+
+	"github.com/evmos/ethermint/x/evm/statedb"
+	evmtypes "github.com/evmos/ethermint/x/evm/types"
+)
+
+// ics20CallbackGas is the gas limit of the calls to the IICS20Callback contracts.
+const ics20CallbackGas = 200000
+
+//go:embed ics20_callback.abi.json
+var ics20CallbackABIJSON []byte
+
+// ics20CallbackABI is the ABI of IICS20Callback.
+var ics20CallbackABI = func() abi.ABI {
+	callbackABI, err := abi.JSON(bytes.NewReader(ics20CallbackABIJSON))
+	if err != nil {
+		panic(err)
+	}
+	return callbackABI
+}()
+
+// ICS20Hooks are called back with the results of the ICS-20 transfers sent by EVM
+// accounts, once the transfer module processed the packet.
+type ICS20Hooks interface {
+	// OnAcknowledgementPacket is called when a transfer packet is acknowledged, if it
+	// returns an error the changes of the hook are discarded.
+	OnAcknowledgementPacket(ctx sdk.Context, sender common.Address, packet channeltypes.Packet, success bool) error
+	// OnTimeoutPacket is called when a transfer packet timed out and the coins are
+	// refunded, if it returns an error the changes of the hook are discarded.
+	OnTimeoutPacket(ctx sdk.Context, sender common.Address, packet channeltypes.Packet) error
+}
+
+var _ ICS20Hooks = MultiICS20Hooks{}
+
+// MultiICS20Hooks combine multiple ICS-20 hooks, all hook functions are run in array sequence
+type MultiICS20Hooks []ICS20Hooks
+
+// NewMultiICS20Hooks combine multiple ICS-20 hooks
+func NewMultiICS20Hooks(hooks ...ICS20Hooks) MultiICS20Hooks {
+	return hooks
+}
+
+// OnAcknowledgementPacket delegate the call to underlying hooks
+func (mh MultiICS20Hooks) OnAcknowledgementPacket(ctx sdk.Context, sender common.Address, packet channeltypes.Packet, success bool) error {
+	for i := range mh {
+		if err := mh[i].OnAcknowledgementPacket(ctx, sender, packet, success); err != nil {
+			return errorsmod.Wrapf(err, "ICS-20 hook %T failed", mh[i])
+		}
+	}
+	return nil
+}
+
+// OnTimeoutPacket delegate the call to underlying hooks
+func (mh MultiICS20Hooks) OnTimeoutPacket(ctx sdk.Context, sender common.Address, packet channeltypes.Packet) error {
+	for i := range mh {
+		if err := mh[i].OnTimeoutPacket(ctx, sender, packet); err != nil {
+			return errorsmod.Wrapf(err, "ICS-20 hook %T failed", mh[i])
+		}
+	}
+	return nil
+}
+
+// EVMKeeper defines the EVM keeper methods used by the EVMCallbackHooks.
+type EVMKeeper interface {
+	GetAccountWithoutBalance(ctx sdk.Context, addr common.Address) *statedb.Account
+	ApplyMessage(ctx sdk.Context, msg core.Message, tracer vm.EVMLogger, commit bool) (*evmtypes.MsgEthereumTxResponse, error)
+}
+
+var _ ICS20Hooks = EVMCallbackHooks{}
+
+// EVMCallbackHooks call back the contracts which sent the transfer packets with their
+// results, see IICS20Callback. The calls are sent by the precompile address with a gas
+// limit of ics20CallbackGas, the senders without code are not called.
+type EVMCallbackHooks struct {
+	evmKeeper EVMKeeper
+}
+
+// NewEVMCallbackHooks creates the hooks calling back the sending contracts.
+func NewEVMCallbackHooks(evmKeeper EVMKeeper) EVMCallbackHooks {
+	return EVMCallbackHooks{evmKeeper: evmKeeper}
+}
+
+// OnAcknowledgementPacket calls onICS20Acknowledgement on the sending contract.
+func (h EVMCallbackHooks) OnAcknowledgementPacket(ctx sdk.Context, sender common.Address, packet channeltypes.Packet, success bool) error {
+	return h.callback(ctx, sender, "onICS20Acknowledgement", packet.GetSourceChannel(), packet.GetSequence(), success)
+}
+
+// OnTimeoutPacket calls onICS20Timeout on the sending contract.
+func (h EVMCallbackHooks) OnTimeoutPacket(ctx sdk.Context, sender common.Address, packet channeltypes.Packet) error {
+	return h.callback(ctx, sender, "onICS20Timeout", packet.GetSourceChannel(), packet.GetSequence())
+}
+
+// callback calls the method of IICS20Callback on the sender if it's a contract, the
+// call fails if the contract reverts. The gas used by the call is charged to the
+// context, it's paid by the relayer of the packet.
+func (h EVMCallbackHooks) callback(ctx sdk.Context, sender common.Address, method string, args ...interface{}) error {
+	account := h.evmKeeper.GetAccountWithoutBalance(ctx, sender)
+	if account == nil || !account.IsContract() {
+		return nil
+	}
+	input, err := ics20CallbackABI.Pack(method, args...)
+	if err != nil {
+		return err
+	}
+	msg := ethtypes.NewMessage(
+		ICS20Address, &sender, 0, big.NewInt(0), ics20CallbackGas,
+		big.NewInt(0), big.NewInt(0), big.NewInt(0), input, nil, true,
+	)
+	res, err := h.evmKeeper.ApplyMessage(ctx, msg, nil, true)
+	if err != nil {
+		return err
+	}
+	ctx.GasMeter().ConsumeGas(res.GasUsed, "ICS-20 callback")
+	if res.Failed() {
+		return errorsmod.Wrapf(evmtypes.ErrVMExecution, "%s of %s failed: %s", method, sender, res.VmError)
+	}
+	return nil
+}
+
+var _ porttypes.IBCModule = ICS20Middleware{}
+
+// ICS20Middleware wraps the transfer IBC module to run the ICS-20 hooks with the
+// results of the transfer packets sent through the precompile, the other packets
+// are passed through. The hooks run after the transfer module and their failures
+// are logged, they never fail the acknowledgement or the timeout.
+type ICS20Middleware struct {
+	porttypes.IBCModule
+
+	storeService store.KVStoreService
+	hooks        ICS20Hooks
+}
+
+// NewICS20Middleware creates the middleware running the hooks on top of the transfer
+// IBC module, the store service is the one of the EVM module where the precompile
+// records its packets.
+func NewICS20Middleware(app porttypes.IBCModule, storeService store.KVStoreService, hooks ICS20Hooks) ICS20Middleware {
+	return ICS20Middleware{IBCModule: app, storeService: storeService, hooks: hooks}
+}
+
+// OnAcknowledgementPacket implements the IBCModule interface
+func (im ICS20Middleware) OnAcknowledgementPacket(
+	ctx sdk.Context,
+	packet channeltypes.Packet,
+	acknowledgement []byte,
+	relayer sdk.AccAddress,
+) error {
+	if err := im.IBCModule.OnAcknowledgementPacket(ctx, packet, acknowledgement, relayer); err != nil {
+		return err
+	}
+
+	sender, ok := im.precompilePacketSender(ctx, packet)
+	if !ok {
+		return nil
+	}
+	var ack channeltypes.Acknowledgement
+	if err := ibctransfertypes.ModuleCdc.UnmarshalJSON(acknowledgement, &ack); err != nil {
+		return nil
+	}
+	im.runHooks(ctx, func(ctx sdk.Context) error {
+		return im.hooks.OnAcknowledgementPacket(ctx, sender, packet, ack.Success())
+	})
+	return nil
+}
+
+// OnTimeoutPacket implements the IBCModule interface
+func (im ICS20Middleware) OnTimeoutPacket(
+	ctx sdk.Context,
+	packet channeltypes.Packet,
+	relayer sdk.AccAddress,
+) error {
+	if err := im.IBCModule.OnTimeoutPacket(ctx, packet, relayer); err != nil {
+		return err
+	}
+
+	sender, ok := im.precompilePacketSender(ctx, packet)
+	if !ok {
+		return nil
+	}
+	im.runHooks(ctx, func(ctx sdk.Context) error {
+		return im.hooks.OnTimeoutPacket(ctx, sender, packet)
+	})
+	return nil
+}
+
+// runHooks runs the hooks in a cache context which is only written on success.
+func (im ICS20Middleware) runHooks(ctx sdk.Context, run func(ctx sdk.Context) error) {
+	cacheCtx, write := ctx.CacheContext()
+	if err := run(cacheCtx); err != nil {
+		ctx.Logger().Error("ICS-20 hooks failed", "error", err.Error())
+		return
+	}
+	write()
+}
+
+// precompilePacketSender returns the EVM address of the sender of a transfer packet
+// sent through the precompile and forgets the packet, the packets sent by the
+// transfer messages are not recorded.
+func (im ICS20Middleware) precompilePacketSender(ctx sdk.Context, packet channeltypes.Packet) (common.Address, bool) {
+	kvStore := im.storeService.OpenKVStore(ctx)
+	key := evmtypes.ICS20PacketKey(packet.GetSourceChannel(), packet.GetSequence())
+	if has, err := kvStore.Has(key); err != nil || !has {
+		return common.Address{}, false
+	}
+	if err := kvStore.Delete(key); err != nil {
+		return common.Address{}, false
+	}
+	return packetSender(packet)
+}
+
+// packetSender returns the EVM address of the transfer packet sender.
+func packetSender(packet channeltypes.Packet) (common.Address, bool) {
+	var data ibctransfertypes.FungibleTokenPacketData
+	if err := ibctransfertypes.ModuleCdc.UnmarshalJSON(packet.GetData(), &data); err != nil {
+		return common.Address{}, false
+	}
+	sender, err := sdk.AccAddressFromBech32(data.Sender)
+	if err != nil {
+		return common.Address{}, false
+	}
+	return common.BytesToAddress(sender), true
+}
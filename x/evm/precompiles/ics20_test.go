@@ -0,0 +1,497 @@
+package precompiles_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"cosmossdk.io/log"
+	sdkmath "cosmossdk.io/math"
+	storetypes "cosmossdk.io/store/types"
+	dbm "github.com/cosmos/cosmos-db"
+	"github.com/cosmos/cosmos-sdk/runtime"
+	"github.com/cosmos/cosmos-sdk/testutil"
+	simtestutil "github.com/cosmos/cosmos-sdk/testutil/sims"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ibctransfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	porttypes "github.com/cosmos/ibc-go/v8/modules/core/05-port/types"
+	host "github.com/cosmos/ibc-go/v8/modules/core/24-host"
+	ibctesting "github.com/cosmos/ibc-go/v8/testing"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/evmos/ethermint/app"
+	"github.com/evmos/ethermint/x/evm/precompiles"
+	"github.com/evmos/ethermint/x/evm/statedb"
+	evmtypes "github.com/evmos/ethermint/x/evm/types"
+	feemarkettypes "github.com/evmos/ethermint/x/feemarket/types"
+)
+
+// setupTestingApp returns an EthermintApp for the ibc-go testing chains with the
+// ICS-20 precompile enabled. The base fee is disabled, the testing chains send
+// their transactions without fees.
+func setupTestingApp() (ibctesting.TestingApp, map[string]json.RawMessage) {
+	ethermintApp := app.NewEthermintApp(
+		log.NewNopLogger(), dbm.NewMemDB(), nil, true, map[int64]bool{},
+		app.DefaultNodeHome, 5, simtestutil.NewAppOptionsWithFlagHome(app.DefaultNodeHome),
+	)
+	genesis := app.NewDefaultGenesisState()
+
+	feemarketGenesis := feemarkettypes.DefaultGenesisState()
+	feemarketGenesis.Params.NoBaseFee = true
+	genesis[feemarkettypes.ModuleName] = ethermintApp.AppCodec().MustMarshalJSON(feemarketGenesis)
+
+	evmGenesis := evmtypes.DefaultGenesisState()
+	evmGenesis.Params.ActivePrecompiles = []string{precompiles.ICS20Address.Hex()}
+	genesis[evmtypes.ModuleName] = ethermintApp.AppCodec().MustMarshalJSON(evmGenesis)
+
+	return ethermintApp, genesis
+}
+
+type ICS20TestSuite struct {
+	suite.Suite
+
+	coordinator *ibctesting.Coordinator
+	chainA      *ibctesting.TestChain
+	chainB      *ibctesting.TestChain
+	path        *ibctesting.Path
+	ics20ABI    abi.ABI
+}
+
+func TestICS20TestSuite(t *testing.T) {
+	suite.Run(t, new(ICS20TestSuite))
+}
+
+func (suite *ICS20TestSuite) SetupTest() {
+	ibctesting.DefaultTestingAppInit = setupTestingApp
+	// the EVM chain ids are formatted as {identifier}_{EIP155}-{epoch}
+	ibctesting.ChainIDPrefix = "ethermint_900"
+
+	suite.coordinator = ibctesting.NewCoordinator(suite.T(), 2)
+	suite.chainA = suite.coordinator.GetChain(ibctesting.GetChainID(1))
+	suite.chainB = suite.coordinator.GetChain(ibctesting.GetChainID(2))
+	// the EVM coinbase is the block proposer
+	for _, chain := range []*ibctesting.TestChain{suite.chainA, suite.chainB} {
+		chain.CurrentHeader.ProposerAddress = chain.Vals.Proposer.Address
+	}
+	suite.path = ibctesting.NewTransferPath(suite.chainA, suite.chainB)
+	suite.coordinator.Setup(suite.path)
+
+	suite.ics20ABI = suite.loadABI("ics20.abi.json")
+}
+
+func (suite *ICS20TestSuite) loadABI(file string) abi.ABI {
+	f, err := os.Open(file)
+	suite.Require().NoError(err)
+	defer f.Close()
+	contractABI, err := abi.JSON(f)
+	suite.Require().NoError(err)
+	return contractABI
+}
+
+// callICS20 calls the ICS-20 precompile from the sender account of the chain.
+func (suite *ICS20TestSuite) callICS20(chain *ibctesting.TestChain, commit bool, method string, args ...interface{}) []interface{} {
+	input, err := suite.ics20ABI.Pack(method, args...)
+	suite.Require().NoError(err)
+
+	evmKeeper := chain.App.(*app.EthermintApp).EvmKeeper
+	sender := common.BytesToAddress(chain.SenderAccount.GetAddress())
+	msg := ethtypes.NewMessage(
+		sender, &precompiles.ICS20Address, 0, big.NewInt(0), 1000000,
+		big.NewInt(0), big.NewInt(0), big.NewInt(0), input, nil, true,
+	)
+	res, err := evmKeeper.ApplyMessage(chain.GetContext(), msg, nil, commit)
+	suite.Require().NoError(err)
+	suite.Require().False(res.Failed(), res.VmError)
+
+	out, err := suite.ics20ABI.Unpack(method, res.Ret)
+	suite.Require().NoError(err)
+	return out
+}
+
+// transfer sends the bond denom from the chain A sender to the chain B sender
+// through the precompile and returns the sent packet.
+func (suite *ICS20TestSuite) transfer(amount int64, timeout uint64) channeltypes.Packet {
+	receiver := suite.chainB.SenderAccount.GetAddress().String()
+	out := suite.callICS20(
+		suite.chainA, true, "transfer",
+		suite.path.EndpointA.ChannelID, sdk.DefaultBondDenom, big.NewInt(amount), receiver, timeout,
+	)
+	sequence := out[0].(uint64)
+	suite.coordinator.CommitBlock(suite.chainA)
+
+	data := ibctransfertypes.NewFungibleTokenPacketData(
+		sdk.DefaultBondDenom, sdkmath.NewInt(amount).String(),
+		suite.chainA.SenderAccount.GetAddress().String(), receiver, "",
+	)
+	return channeltypes.NewPacket(
+		data.GetBytes(), sequence,
+		suite.path.EndpointA.ChannelConfig.PortID, suite.path.EndpointA.ChannelID,
+		suite.path.EndpointB.ChannelConfig.PortID, suite.path.EndpointB.ChannelID,
+		clienttypes.ZeroHeight(), timeout,
+	)
+}
+
+// packetRecorded returns whether the packet is recorded as sent through the precompile.
+func (suite *ICS20TestSuite) packetRecorded(packet channeltypes.Packet) bool {
+	key := suite.chainA.App.(*app.EthermintApp).GetKey(evmtypes.StoreKey)
+	return suite.chainA.GetContext().KVStore(key).Has(evmtypes.ICS20PacketKey(packet.SourceChannel, packet.Sequence))
+}
+
+func (suite *ICS20TestSuite) balance(chain *ibctesting.TestChain, denom string) sdkmath.Int {
+	bankKeeper := chain.App.(*app.EthermintApp).BankKeeper
+	return bankKeeper.GetBalance(chain.GetContext(), chain.SenderAccount.GetAddress(), denom).Amount
+}
+
+func (suite *ICS20TestSuite) TestTransfer() {
+	balanceA := suite.balance(suite.chainA, sdk.DefaultBondDenom)
+	timeout := uint64(suite.chainB.GetContext().BlockTime().Add(time.Hour).UnixNano())
+
+	packet := suite.transfer(1000, timeout)
+	suite.Require().Equal(balanceA.SubRaw(1000), suite.balance(suite.chainA, sdk.DefaultBondDenom))
+	suite.Require().True(suite.packetRecorded(packet))
+	suite.Require().NoError(suite.path.RelayPacket(packet))
+	suite.Require().False(suite.packetRecorded(packet))
+
+	trace := ibctransfertypes.ParseDenomTrace(
+		ibctransfertypes.GetPrefixedDenom(packet.DestinationPort, packet.DestinationChannel, sdk.DefaultBondDenom),
+	)
+	suite.Require().Equal(sdkmath.NewInt(1000), suite.balance(suite.chainB, trace.IBCDenom()))
+
+	for _, hash := range []string{trace.Hash().String(), trace.IBCDenom()} {
+		out := suite.callICS20(suite.chainB, false, "denomTrace", hash)
+		res := out[0].(struct {
+			Path      string `json:"path"`
+			BaseDenom string `json:"baseDenom"`
+		})
+		suite.Require().Equal(trace.Path, res.Path)
+		suite.Require().Equal(sdk.DefaultBondDenom, res.BaseDenom)
+	}
+}
+
+func (suite *ICS20TestSuite) TestTransferTimeout() {
+	balanceA := suite.balance(suite.chainA, sdk.DefaultBondDenom)
+	timeout := uint64(suite.chainB.GetContext().BlockTime().Add(time.Minute).UnixNano())
+
+	packet := suite.transfer(1000, timeout)
+	suite.Require().Equal(balanceA.SubRaw(1000), suite.balance(suite.chainA, sdk.DefaultBondDenom))
+
+	suite.coordinator.IncrementTimeBy(time.Hour)
+	suite.coordinator.CommitBlock(suite.chainB)
+	suite.Require().NoError(suite.path.EndpointA.UpdateClient())
+	suite.Require().NoError(suite.path.EndpointA.TimeoutPacket(packet))
+
+	// the escrowed coins are refunded
+	suite.Require().Equal(balanceA, suite.balance(suite.chainA, sdk.DefaultBondDenom))
+	suite.Require().False(suite.packetRecorded(packet))
+}
+
+func (suite *ICS20TestSuite) TestNestedTransfer() {
+	ethermintApp := suite.chainA.App.(*app.EthermintApp)
+	ctx := suite.chainA.GetContext()
+	contract := common.BytesToAddress([]byte("proxy"))
+	coins := sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 1000))
+	suite.Require().NoError(ethermintApp.BankKeeper.SendCoins(ctx, suite.chainA.SenderAccount.GetAddress(), contract.Bytes(), coins))
+
+	// the contract forwards its call data to the precompile, then returns the success
+	// flag of the call or reverts
+	proxy := func(revert bool) []byte {
+		// CALLDATASIZE PUSH1 0 PUSH1 0 CALLDATACOPY PUSH1 0 PUSH1 0 CALLDATASIZE PUSH1 0 PUSH1 0
+		code := []byte{0x36, 0x60, 0x00, 0x60, 0x00, 0x37, 0x60, 0x00, 0x60, 0x00, 0x36, 0x60, 0x00, 0x60, 0x00}
+		// PUSH20 precompile GAS CALL
+		code = append(append(append(code, 0x73), precompiles.ICS20Address.Bytes()...), 0x5a, 0xf1)
+		if revert {
+			// PUSH1 0 DUP1 REVERT
+			return append(code, 0x60, 0x00, 0x80, 0xfd)
+		}
+		// PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+		return append(code, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xf3)
+	}
+	transfer := func(revert bool) *evmtypes.MsgEthereumTxResponse {
+		ctx := suite.chainA.GetContext()
+		vmdb := statedb.New(ctx, ethermintApp.EvmKeeper, statedb.NewEmptyTxConfig(common.BytesToHash(ctx.HeaderHash())))
+		vmdb.SetCode(contract, proxy(revert))
+		suite.Require().NoError(vmdb.Commit())
+
+		timeout := uint64(suite.chainB.GetContext().BlockTime().Add(time.Hour).UnixNano())
+		input, err := suite.ics20ABI.Pack(
+			"transfer", suite.path.EndpointA.ChannelID, sdk.DefaultBondDenom, big.NewInt(400),
+			suite.chainB.SenderAccount.GetAddress().String(), timeout,
+		)
+		suite.Require().NoError(err)
+		sender := common.BytesToAddress(suite.chainA.SenderAccount.GetAddress())
+		msg := ethtypes.NewMessage(
+			sender, &contract, 0, big.NewInt(0), 1000000,
+			big.NewInt(0), big.NewInt(0), big.NewInt(0), input, nil, true,
+		)
+		res, err := ethermintApp.EvmKeeper.ApplyMessage(ctx, msg, nil, true)
+		suite.Require().NoError(err)
+		return res
+	}
+	contractBalance := func() sdkmath.Int {
+		return ethermintApp.BankKeeper.GetBalance(suite.chainA.GetContext(), contract.Bytes(), sdk.DefaultBondDenom).Amount
+	}
+	nextSequence := func() uint64 {
+		sequence, ok := ethermintApp.IBCKeeper.ChannelKeeper.GetNextSequenceSend(
+			suite.chainA.GetContext(), suite.path.EndpointA.ChannelConfig.PortID, suite.path.EndpointA.ChannelID,
+		)
+		suite.Require().True(ok)
+		return sequence
+	}
+
+	// the revert of the caller undoes the transfer
+	sequence := nextSequence()
+	res := transfer(true)
+	suite.Require().True(res.Failed())
+	suite.Require().Equal(sdkmath.NewInt(1000), contractBalance())
+	suite.Require().Equal(sequence, nextSequence())
+
+	// the contract is the sender of the packet
+	res = transfer(false)
+	suite.Require().False(res.Failed(), res.VmError)
+	suite.Require().Equal(common.LeftPadBytes([]byte{1}, 32), res.Ret)
+	suite.Require().Equal(sdkmath.NewInt(600), contractBalance())
+	suite.Require().Equal(sequence+1, nextSequence())
+	key := ethermintApp.GetKey(evmtypes.StoreKey)
+	suite.Require().True(suite.chainA.GetContext().KVStore(key).Has(evmtypes.ICS20PacketKey(suite.path.EndpointA.ChannelID, sequence)))
+}
+
+func (suite *ICS20TestSuite) TestTransferCallback() {
+	ethermintApp := suite.chainA.App.(*app.EthermintApp)
+	callbackABI := suite.loadABI("ics20_callback.abi.json")
+	contract := common.BytesToAddress([]byte("callback"))
+	coins := sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 1000))
+	err := ethermintApp.BankKeeper.SendCoins(suite.chainA.GetContext(), suite.chainA.SenderAccount.GetAddress(), contract.Bytes(), coins)
+	suite.Require().NoError(err)
+
+	// the contract stores the hash of the call data of the callbacks, the other calls
+	// are forwarded to the precompile:
+	// CALLER PUSH20 precompile EQ PUSH1 callback JUMPI
+	code := append(append([]byte{0x33, 0x73}, precompiles.ICS20Address.Bytes()...), 0x14, 0x60, 0x00, 0x57)
+	// CALLDATASIZE PUSH1 0 PUSH1 0 CALLDATACOPY PUSH1 0 PUSH1 0 CALLDATASIZE PUSH1 0 PUSH1 0
+	// PUSH20 precompile GAS CALL PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+	code = append(code, 0x36, 0x60, 0x00, 0x60, 0x00, 0x37, 0x60, 0x00, 0x60, 0x00, 0x36, 0x60, 0x00, 0x60, 0x00, 0x73)
+	code = append(append(code, precompiles.ICS20Address.Bytes()...), 0x5a, 0xf1, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xf3)
+	// callback: JUMPDEST CALLDATASIZE PUSH1 0 PUSH1 0 CALLDATACOPY CALLDATASIZE PUSH1 0 SHA3
+	// PUSH1 0 SSTORE STOP
+	code[24] = byte(len(code))
+	code = append(code, 0x5b, 0x36, 0x60, 0x00, 0x60, 0x00, 0x37, 0x36, 0x60, 0x00, 0x20, 0x60, 0x00, 0x55, 0x00)
+	ctx := suite.chainA.GetContext()
+	vmdb := statedb.New(ctx, ethermintApp.EvmKeeper, statedb.NewEmptyTxConfig(common.BytesToHash(ctx.HeaderHash())))
+	vmdb.SetCode(contract, code)
+	suite.Require().NoError(vmdb.Commit())
+
+	// transfer sends the coins from the contract and returns the sent packet
+	transfer := func(timeout uint64) channeltypes.Packet {
+		receiver := suite.chainB.SenderAccount.GetAddress().String()
+		input, err := suite.ics20ABI.Pack(
+			"transfer", suite.path.EndpointA.ChannelID, sdk.DefaultBondDenom, big.NewInt(100), receiver, timeout,
+		)
+		suite.Require().NoError(err)
+		sequence, ok := ethermintApp.IBCKeeper.ChannelKeeper.GetNextSequenceSend(
+			suite.chainA.GetContext(), suite.path.EndpointA.ChannelConfig.PortID, suite.path.EndpointA.ChannelID,
+		)
+		suite.Require().True(ok)
+		sender := common.BytesToAddress(suite.chainA.SenderAccount.GetAddress())
+		msg := ethtypes.NewMessage(
+			sender, &contract, 0, big.NewInt(0), 1000000,
+			big.NewInt(0), big.NewInt(0), big.NewInt(0), input, nil, true,
+		)
+		res, err := ethermintApp.EvmKeeper.ApplyMessage(suite.chainA.GetContext(), msg, nil, true)
+		suite.Require().NoError(err)
+		suite.Require().False(res.Failed(), res.VmError)
+		suite.coordinator.CommitBlock(suite.chainA)
+
+		data := ibctransfertypes.NewFungibleTokenPacketData(
+			sdk.DefaultBondDenom, "100", sdk.AccAddress(contract.Bytes()).String(), receiver, "",
+		)
+		return channeltypes.NewPacket(
+			data.GetBytes(), sequence,
+			suite.path.EndpointA.ChannelConfig.PortID, suite.path.EndpointA.ChannelID,
+			suite.path.EndpointB.ChannelConfig.PortID, suite.path.EndpointB.ChannelID,
+			clienttypes.ZeroHeight(), timeout,
+		)
+	}
+	storedCallback := func() common.Hash {
+		return ethermintApp.EvmKeeper.GetState(suite.chainA.GetContext(), contract, common.Hash{})
+	}
+
+	// the testing chains deliver their transactions without block proposer, so the
+	// acknowledgement and the timeout are handled in a context of chain A with the
+	// proposer, the EVM coinbase of the callbacks
+	signer := suite.chainA.SenderAccount.GetAddress().String()
+
+	// the acknowledgement is delivered to the contract
+	packet := transfer(uint64(suite.chainB.GetContext().BlockTime().Add(time.Hour).UnixNano()))
+	suite.Require().NoError(suite.path.EndpointB.UpdateClient())
+	suite.Require().NoError(suite.path.EndpointB.RecvPacket(packet))
+	ack := channeltypes.NewResultAcknowledgement([]byte{byte(1)}).Acknowledgement()
+	proof, proofHeight := suite.chainB.QueryProof(host.PacketAcknowledgementKey(packet.DestinationPort, packet.DestinationChannel, packet.Sequence))
+	_, err = ethermintApp.IBCKeeper.Acknowledgement(
+		suite.chainA.GetContext(), channeltypes.NewMsgAcknowledgement(packet, ack, proof, proofHeight, signer),
+	)
+	suite.Require().NoError(err)
+	ackInput, err := callbackABI.Pack("onICS20Acknowledgement", packet.SourceChannel, packet.Sequence, true)
+	suite.Require().NoError(err)
+	suite.Require().Equal(crypto.Keccak256Hash(ackInput), storedCallback())
+
+	// so is the timeout
+	packet = transfer(uint64(suite.chainB.GetContext().BlockTime().Add(time.Minute).UnixNano()))
+	suite.coordinator.IncrementTimeBy(time.Hour)
+	suite.coordinator.CommitBlock(suite.chainB)
+	suite.Require().NoError(suite.path.EndpointA.UpdateClient())
+	proof, proofHeight = suite.chainB.QueryProof(host.PacketReceiptKey(packet.DestinationPort, packet.DestinationChannel, packet.Sequence))
+	nextSeqRecv, found := suite.chainB.App.GetIBCKeeper().ChannelKeeper.GetNextSequenceRecv(
+		suite.chainB.GetContext(), packet.DestinationPort, packet.DestinationChannel,
+	)
+	suite.Require().True(found)
+	_, err = ethermintApp.IBCKeeper.Timeout(
+		suite.chainA.GetContext(), channeltypes.NewMsgTimeout(packet, nextSeqRecv, proof, proofHeight, signer),
+	)
+	suite.Require().NoError(err)
+	timeoutInput, err := callbackABI.Pack("onICS20Timeout", packet.SourceChannel, packet.Sequence)
+	suite.Require().NoError(err)
+	suite.Require().Equal(crypto.Keccak256Hash(timeoutInput), storedCallback())
+	// the timed out coins are refunded
+	suite.Require().Equal(sdkmath.NewInt(900), ethermintApp.BankKeeper.GetBalance(suite.chainA.GetContext(), contract.Bytes(), sdk.DefaultBondDenom).Amount)
+}
+
+// mockTransferModule acknowledges and times out the packets without state changes.
+type mockTransferModule struct {
+	porttypes.IBCModule
+	err error
+}
+
+func (m mockTransferModule) OnAcknowledgementPacket(sdk.Context, channeltypes.Packet, []byte, sdk.AccAddress) error {
+	return m.err
+}
+
+func (m mockTransferModule) OnTimeoutPacket(sdk.Context, channeltypes.Packet, sdk.AccAddress) error {
+	return m.err
+}
+
+// recordingHooks records the hook calls in the store and fails on demand.
+type recordingHooks struct {
+	key    storetypes.StoreKey
+	err    error
+	called []string
+}
+
+func (h *recordingHooks) OnAcknowledgementPacket(ctx sdk.Context, sender common.Address, _ channeltypes.Packet, success bool) error {
+	h.called = append(h.called, "ack")
+	ctx.KVStore(h.key).Set(sender.Bytes(), []byte{1})
+	if !success {
+		return errors.New("unexpected error acknowledgement")
+	}
+	return h.err
+}
+
+func (h *recordingHooks) OnTimeoutPacket(ctx sdk.Context, sender common.Address, _ channeltypes.Packet) error {
+	h.called = append(h.called, "timeout")
+	ctx.KVStore(h.key).Set(sender.Bytes(), []byte{1})
+	return h.err
+}
+
+func TestICS20Middleware(t *testing.T) {
+	sender := common.BytesToAddress([]byte("sender"))
+	data := ibctransfertypes.NewFungibleTokenPacketData(
+		sdk.DefaultBondDenom, "1000", sdk.AccAddress(sender.Bytes()).String(), "receiver", "",
+	)
+	packet := channeltypes.NewPacket(data.GetBytes(), 1, "transfer", "channel-0", "transfer", "channel-1", clienttypes.ZeroHeight(), 1)
+	ack := channeltypes.NewResultAcknowledgement([]byte{1}).Acknowledgement()
+
+	testCases := []struct {
+		name      string
+		moduleErr error
+		hooksErr  error
+		packet    channeltypes.Packet
+		recorded  bool
+		expCalled []string
+		expStored bool
+	}{
+		{"hooks run", nil, nil, packet, true, []string{"ack", "timeout"}, true},
+		{"hooks failure is discarded", nil, errors.New("hook failure"), packet, true, []string{"ack", "timeout"}, false},
+		{"transfer failure skips the hooks", errors.New("transfer failure"), nil, packet, true, nil, false},
+		{"packet not sent through the precompile skips the hooks", nil, nil, packet, false, nil, false},
+		{
+			"invalid sender skips the hooks", nil, nil,
+			channeltypes.NewPacket([]byte("{}"), 1, "transfer", "channel-0", "transfer", "channel-1", clienttypes.ZeroHeight(), 1),
+			true, nil, false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			key := storetypes.NewKVStoreKey("hooks")
+			ctx := testutil.DefaultContext(key, storetypes.NewTransientStoreKey("transient_hooks"))
+			hooks := &recordingHooks{key: key, err: tc.hooksErr}
+			middleware := precompiles.NewICS20Middleware(
+				mockTransferModule{err: tc.moduleErr}, runtime.NewKVStoreService(key), precompiles.NewMultiICS20Hooks(hooks),
+			)
+			packetKey := evmtypes.ICS20PacketKey(tc.packet.SourceChannel, tc.packet.Sequence)
+			record := func() {
+				if tc.recorded {
+					ctx.KVStore(key).Set(packetKey, []byte{1})
+				}
+			}
+
+			record()
+			require.Equal(t, tc.moduleErr, middleware.OnAcknowledgementPacket(ctx, tc.packet, ack, nil))
+			// the record is kept when the transfer module fails
+			require.Equal(t, tc.recorded && tc.moduleErr != nil, ctx.KVStore(key).Has(packetKey))
+			record()
+			require.Equal(t, tc.moduleErr, middleware.OnTimeoutPacket(ctx, tc.packet, nil))
+			require.Equal(t, tc.expCalled, hooks.called)
+			require.Equal(t, tc.expStored, ctx.KVStore(key).Has(sender.Bytes()))
+		})
+	}
+}
+
+func (suite *ICS20TestSuite) TestEVMCallbackHooks() {
+	evmKeeper := suite.chainA.App.(*app.EthermintApp).EvmKeeper
+	packet := channeltypes.NewPacket(nil, 7, "transfer", "channel-0", "transfer", "channel-1", clienttypes.ZeroHeight(), 1)
+	hooks := precompiles.NewEVMCallbackHooks(evmKeeper)
+
+	// CALLER PUSH1 0 SSTORE CALLDATASIZE PUSH1 1 SSTORE STOP
+	recorder := common.BytesToAddress([]byte("recorder"))
+	// PUSH1 0 DUP1 REVERT
+	reverter := common.BytesToAddress([]byte("reverter"))
+	ctx := suite.chainA.GetContext()
+	vmdb := statedb.New(ctx, evmKeeper, statedb.NewEmptyTxConfig(common.BytesToHash(ctx.HeaderHash())))
+	vmdb.SetCode(recorder, []byte{0x33, 0x60, 0x00, 0x55, 0x36, 0x60, 0x01, 0x55, 0x00})
+	vmdb.SetCode(reverter, []byte{0x60, 0x00, 0x80, 0xfd})
+	suite.Require().NoError(vmdb.Commit())
+
+	storedInput := func() common.Hash {
+		return evmKeeper.GetState(ctx, recorder, common.BigToHash(big.NewInt(1)))
+	}
+	callbackABI := suite.loadABI("ics20_callback.abi.json")
+	ackInput, err := callbackABI.Pack("onICS20Acknowledgement", "channel-0", uint64(7), true)
+	suite.Require().NoError(err)
+	timeoutInput, err := callbackABI.Pack("onICS20Timeout", "channel-0", uint64(7))
+	suite.Require().NoError(err)
+
+	// the gas of the callback is charged to the context
+	gasMeter := storetypes.NewInfiniteGasMeter()
+	suite.Require().NoError(hooks.OnAcknowledgementPacket(ctx.WithGasMeter(gasMeter), recorder, packet, true))
+	suite.Require().Greater(gasMeter.GasConsumed(), params.TxGas+2*params.SstoreSetGasEIP2200)
+	suite.Require().Equal(common.BytesToHash(precompiles.ICS20Address.Bytes()), evmKeeper.GetState(ctx, recorder, common.Hash{}))
+	suite.Require().Equal(common.BigToHash(big.NewInt(int64(len(ackInput)))), storedInput())
+	suite.Require().NoError(hooks.OnTimeoutPacket(ctx, recorder, packet))
+	suite.Require().Equal(common.BigToHash(big.NewInt(int64(len(timeoutInput)))), storedInput())
+
+	// the accounts without code are not called
+	suite.Require().NoError(hooks.OnTimeoutPacket(ctx, common.BytesToAddress([]byte("eoa")), packet))
+	suite.Require().Error(hooks.OnAcknowledgementPacket(ctx, reverter, packet, false))
+	suite.Require().Error(hooks.OnTimeoutPacket(ctx, reverter, packet))
+}
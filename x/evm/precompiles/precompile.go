@@ -0,0 +1,196 @@
+// Copyright 2021 Evmos Foundation
+// This file is part of Evmos' Ethermint library.
+//
+// The Ethermint library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Ethermint library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Ethermint library. If not, see https://github.com/evmos/ethermint/blob/main/LICENSE
+package precompiles
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"cosmossdk.io/core/store"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	bankkeeper "github.com/cosmos/cosmos-sdk/x/bank/keeper"
+	distrkeeper "github.com/cosmos/cosmos-sdk/x/distribution/keeper"
+	stakingkeeper "github.com/cosmos/cosmos-sdk/x/staking/keeper"
+	ibctransferkeeper "github.com/cosmos/ibc-go/v8/modules/apps/transfer/keeper"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/evmos/ethermint/x/evm/statedb"
+	evm "github.com/evmos/ethermint/x/evm/vm"
+)
+
+var (
+	// StakingAddress is the address of the staking precompiled contract.
+	StakingAddress = common.HexToAddress("0x0000000000000000000000000000000000000800")
+	// DistributionAddress is the address of the distribution precompiled contract.
+	DistributionAddress = common.HexToAddress("0x0000000000000000000000000000000000000801")
+	// ICS20Address is the address of the ICS-20 transfer precompiled contract.
+	ICS20Address = common.HexToAddress("0x0000000000000000000000000000000000000802")
+	// BankAddress is the address of the bank precompiled contract.
+	BankAddress = common.HexToAddress("0x0000000000000000000000000000000000000804")
+)
+
+// revertSelector is the selector of the Error(string) revert reason.
+var revertSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+
+// NewPrecompiles returns the stateful precompiled contracts of the bank, staking,
+// distribution and ICS-20 transfer modules by address. The store service is the one of
+// the EVM module, the ICS-20 precompile records its packets in it.
+func NewPrecompiles(
+	bankKeeper bankkeeper.Keeper,
+	stakingKeeper *stakingkeeper.Keeper,
+	distrKeeper distrkeeper.Keeper,
+	transferKeeper ibctransferkeeper.Keeper,
+	storeService store.KVStoreService,
+) evm.PrecompiledContracts {
+	return evm.PrecompiledContracts{
+		BankAddress:         NewBankPrecompile(bankKeeper),
+		StakingAddress:      NewStakingPrecompile(stakingKeeper),
+		DistributionAddress: NewDistributionPrecompile(distrKeeper),
+		ICS20Address:        NewICS20Precompile(transferKeeper, storeService),
+	}
+}
+
+// method is a method of a precompiled contract, it's run on the Cosmos state with
+// the unpacked arguments and returns the outputs to pack. The gas is the flat cost
+// of the method, the Cosmos gas consumed by the run is added to it.
+type method struct {
+	gas uint64
+	run func(ctx sdk.Context, caller common.Address, args []interface{}) ([]interface{}, error)
+}
+
+// precompile is a stateful precompiled contract exposing the messages and queries of
+// a Cosmos module as ABI methods. The methods are not payable, the Cosmos errors
+// revert the call with their message as reason.
+type precompile struct {
+	abi     abi.ABI
+	methods map[string]method
+}
+
+var _ evm.StatefulPrecompiledContract = (*precompile)(nil)
+
+// mustNewPrecompile creates a precompile from its ABI and methods by name.
+func mustNewPrecompile(abiJSON []byte, methods map[string]method) *precompile {
+	contractABI, err := abi.JSON(bytes.NewReader(abiJSON))
+	if err != nil {
+		panic(err)
+	}
+	for name := range methods {
+		if _, ok := contractABI.Methods[name]; !ok {
+			panic(fmt.Sprintf("method %s not in the ABI", name))
+		}
+	}
+	return &precompile{abi: contractABI, methods: methods}
+}
+
+// lookup returns the ABI method and the method of the call input.
+func (p *precompile) lookup(input []byte) (*abi.Method, method, error) {
+	if len(input) < 4 {
+		return nil, method{}, errors.New("invalid input length")
+	}
+	abiMethod, err := p.abi.MethodById(input[:4])
+	if err != nil {
+		return nil, method{}, err
+	}
+	m, ok := p.methods[abiMethod.Name]
+	if !ok {
+		return nil, method{}, fmt.Errorf("method %s not implemented", abiMethod.Name)
+	}
+	return abiMethod, m, nil
+}
+
+// RequiredGas returns the gas of the called method, 0 for an unknown method which
+// fails to run.
+func (p *precompile) RequiredGas(input []byte) uint64 {
+	_, m, err := p.lookup(input)
+	if err != nil {
+		return 0
+	}
+	return m.gas
+}
+
+// Run implements vm.PrecompiledContract, the precompile is only run statefully.
+func (p *precompile) Run(_ []byte) ([]byte, error) {
+	return nil, errors.New("stateful precompiled contract called statelessly")
+}
+
+// RunStateful runs the called method as a native action of the StateDB. The Cosmos
+// gas consumed by the method is charged on top of its required gas. Only the view
+// methods can be run by a read-only call.
+func (p *precompile) RunStateful(evm evm.EVM, caller common.Address, input []byte, gas uint64, value *big.Int, readOnly bool) ([]byte, uint64, error) {
+	abiMethod, m, err := p.lookup(input)
+	if err != nil {
+		return nil, 0, err
+	}
+	if readOnly && !abiMethod.IsConstant() {
+		return nil, 0, vm.ErrWriteProtection
+	}
+	if value != nil && value.Sign() != 0 {
+		return nil, 0, errors.New("precompiled contract method is not payable")
+	}
+	args, err := abiMethod.Inputs.Unpack(input[4:])
+	if err != nil {
+		return nil, 0, err
+	}
+	stateDB, ok := evm.StateDB().(statedb.ExtStateDB)
+	if !ok {
+		return nil, 0, fmt.Errorf("invalid StateDB type %T", evm.StateDB())
+	}
+
+	var outputs []interface{}
+	gasUsed, err := stateDB.ExecuteNativeAction(gas, func(ctx sdk.Context) error {
+		var err error
+		outputs, err = m.run(ctx, caller, args)
+		return err
+	})
+	switch {
+	case errors.Is(err, vm.ErrOutOfGas):
+		return nil, 0, err
+	case err != nil:
+		return revertReason(err), gas - gasUsed, vm.ErrExecutionReverted
+	}
+	ret, err := abiMethod.Outputs.Pack(outputs...)
+	return ret, gas - gasUsed, err
+}
+
+// revertReason returns the Error(string) revert reason of an error.
+func revertReason(err error) []byte {
+	stringType, _ := abi.NewType("string", "", nil)
+	reason, packErr := abi.Arguments{{Type: stringType}}.Pack(err.Error())
+	if packErr != nil {
+		return nil
+	}
+	return append(append([]byte{}, revertSelector...), reason...)
+}
+
+// coin is the ABI tuple of a Cosmos coin.
+type coin struct {
+	Denom  string
+	Amount *big.Int
+}
+
+// newCoins converts the Cosmos coins to their ABI tuples.
+func newCoins(coins sdk.Coins) []coin {
+	abiCoins := make([]coin, len(coins))
+	for i, c := range coins {
+		abiCoins[i] = coin{Denom: c.Denom, Amount: c.Amount.BigInt()}
+	}
+	return abiCoins
+}
@@ -0,0 +1,127 @@
+// Copyright 2021 Evmos Foundation
+// This file is part of Evmos' Ethermint library.
+//
+// The Ethermint library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Ethermint library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Ethermint library. If not, see https://github.com/evmos/ethermint/blob/main/LICENSE
+package precompiles
+
+import (
+	// embed the precompiled contract ABI
+	_ "embed"
+	"errors"
+	"math/big"
+
+	sdkmath "cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	stakingkeeper "github.com/cosmos/cosmos-sdk/x/staking/keeper"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/ethereum/go-ethereum/common"
+
+	evm "github.com/evmos/ethermint/x/evm/vm"
+)
+
+const (
+	stakingDelegateGas   = 60000
+	stakingUndelegateGas = 60000
+	stakingRedelegateGas = 80000
+	stakingDelegationGas = 5000
+)
+
+//go:embed staking.abi.json
+var stakingABI []byte
+
+// NewStakingPrecompile returns the staking precompiled contract, it delegates,
+// undelegates and redelegates the bond denom coins of the caller, see IStaking.sol.
+func NewStakingPrecompile(stakingKeeper *stakingkeeper.Keeper) evm.StatefulPrecompiledContract {
+	msgServer := stakingkeeper.NewMsgServerImpl(stakingKeeper)
+
+	// bondCoin returns the coin of the bond denom of an amount
+	bondCoin := func(ctx sdk.Context, amount *big.Int) (sdk.Coin, error) {
+		bondDenom, err := stakingKeeper.BondDenom(ctx)
+		if err != nil {
+			return sdk.Coin{}, err
+		}
+		return sdk.Coin{Denom: bondDenom, Amount: sdkmath.NewIntFromBigInt(amount)}, nil
+	}
+
+	return mustNewPrecompile(stakingABI, map[string]method{
+		"delegate": {
+			gas: stakingDelegateGas,
+			run: func(ctx sdk.Context, caller common.Address, args []interface{}) ([]interface{}, error) {
+				amount, err := bondCoin(ctx, args[1].(*big.Int))
+				if err != nil {
+					return nil, err
+				}
+				msg := stakingtypes.NewMsgDelegate(sdk.AccAddress(caller.Bytes()).String(), args[0].(string), amount)
+				if _, err := msgServer.Delegate(ctx, msg); err != nil {
+					return nil, err
+				}
+				return []interface{}{true}, nil
+			},
+		},
+		"undelegate": {
+			gas: stakingUndelegateGas,
+			run: func(ctx sdk.Context, caller common.Address, args []interface{}) ([]interface{}, error) {
+				amount, err := bondCoin(ctx, args[1].(*big.Int))
+				if err != nil {
+					return nil, err
+				}
+				msg := stakingtypes.NewMsgUndelegate(sdk.AccAddress(caller.Bytes()).String(), args[0].(string), amount)
+				res, err := msgServer.Undelegate(ctx, msg)
+				if err != nil {
+					return nil, err
+				}
+				return []interface{}{res.CompletionTime.Unix()}, nil
+			},
+		},
+		"redelegate": {
+			gas: stakingRedelegateGas,
+			run: func(ctx sdk.Context, caller common.Address, args []interface{}) ([]interface{}, error) {
+				amount, err := bondCoin(ctx, args[2].(*big.Int))
+				if err != nil {
+					return nil, err
+				}
+				msg := stakingtypes.NewMsgBeginRedelegate(
+					sdk.AccAddress(caller.Bytes()).String(), args[0].(string), args[1].(string), amount,
+				)
+				res, err := msgServer.BeginRedelegate(ctx, msg)
+				if err != nil {
+					return nil, err
+				}
+				return []interface{}{res.CompletionTime.Unix()}, nil
+			},
+		},
+		"delegation": {
+			gas: stakingDelegationGas,
+			run: func(ctx sdk.Context, _ common.Address, args []interface{}) ([]interface{}, error) {
+				delegator := sdk.AccAddress(args[0].(common.Address).Bytes())
+				valAddr, err := sdk.ValAddressFromBech32(args[1].(string))
+				if err != nil {
+					return nil, err
+				}
+				delegation, err := stakingKeeper.GetDelegation(ctx, delegator, valAddr)
+				if errors.Is(err, stakingtypes.ErrNoDelegation) {
+					return []interface{}{new(big.Int)}, nil
+				} else if err != nil {
+					return nil, err
+				}
+				validator, err := stakingKeeper.GetValidator(ctx, valAddr)
+				if err != nil {
+					return nil, err
+				}
+				amount := validator.TokensFromShares(delegation.Shares).TruncateInt()
+				return []interface{}{amount.BigInt()}, nil
+			},
+		},
+	})
+}
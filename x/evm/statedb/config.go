@@ -59,4 +59,6 @@ type EVMConfig struct {
 	ChainConfig *params.ChainConfig
 	CoinBase    common.Address
 	BaseFee     *big.Int
+	// Overrides are applied to the StateDB before execution, only used by the simulated calls.
+	Overrides *StateOverride
 }
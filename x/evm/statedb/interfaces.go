@@ -25,10 +25,12 @@ import (
 // codebase to support additional state transition functionalities. In particular
 // it supports appending a new entry to the state journal through
 // AppendJournalEntry so that the state can be reverted after running
-// stateful precompiled contracts.
+// stateful precompiled contracts, which run their Cosmos actions with
+// ExecuteNativeAction.
 type ExtStateDB interface {
 	vm.StateDB
 	AppendJournalEntry(JournalEntry)
+	ExecuteNativeAction(gasLimit uint64, action func(ctx sdk.Context) error) (gasUsed uint64, err error)
 }
 
 // Keeper provide underlying storage of StateDB
@@ -131,6 +131,17 @@ type (
 	}
 	addLogChange struct{}
 
+	// Changes to the Cosmos state by the native actions
+	nativeChange struct {
+		layers int
+	}
+
+	// Changes to the transient storage
+	transientStorageChange struct {
+		account       *common.Address
+		key, prevalue common.Hash
+	}
+
 	// Changes to the access list
 	accessListAddAccountChange struct {
 		address *common.Address
@@ -241,3 +252,25 @@ func (ch accessListAddSlotChange) Revert(s *StateDB) {
 func (ch accessListAddSlotChange) Dirtied() *common.Address {
 	return nil
 }
+
+func (ch transientStorageChange) Revert(s *StateDB) {
+	s.transientStorage.Set(*ch.account, ch.key, ch.prevalue)
+}
+
+func (ch transientStorageChange) Dirtied() *common.Address {
+	return nil
+}
+
+func (ch nativeChange) Revert(s *StateDB) {
+	s.nativeLayers = s.nativeLayers[:ch.layers]
+	// the clean accounts may have been loaded from the discarded caches
+	for addr := range s.stateObjects {
+		if _, dirty := s.journal.dirties[addr]; !dirty {
+			delete(s.stateObjects, addr)
+		}
+	}
+}
+
+func (ch nativeChange) Dirtied() *common.Address {
+	return nil
+}
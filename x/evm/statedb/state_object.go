@@ -80,6 +80,12 @@ type stateObject struct {
 	// flags
 	dirtyCode bool
 	suicided  bool
+	// created is set when the account is created in the current transaction
+	created bool
+
+	// fakeStorage is set when the storage is replaced by a state override,
+	// the keeper is not consulted anymore for the missing slots.
+	fakeStorage bool
 }
 
 // newObject creates a state object.
@@ -217,6 +223,9 @@ func (s *stateObject) GetCommittedState(key common.Hash) common.Hash {
 	if value, cached := s.originStorage[key]; cached {
 		return value
 	}
+	if s.fakeStorage {
+		return common.Hash{}
+	}
 	// If no live objects are available, load it from keeper
 	value := s.db.keeper.GetState(s.db.ctx, s.Address(), key)
 	s.originStorage[key] = value
@@ -250,3 +259,16 @@ func (s *stateObject) SetState(key common.Hash, value common.Hash) {
 func (s *stateObject) setState(key, value common.Hash) {
 	s.dirtyStorage[key] = value
 }
+
+// SetStorage replaces the entire state storage with the given one.
+//
+// It's only used by the state override feature of the simulated calls, the
+// change is not journaled and the object must not be committed afterwards.
+func (s *stateObject) SetStorage(storage map[common.Hash]common.Hash) {
+	s.originStorage = make(Storage, len(storage))
+	for key, value := range storage {
+		s.originStorage[key] = value
+	}
+	s.dirtyStorage = make(Storage)
+	s.fakeStorage = true
+}
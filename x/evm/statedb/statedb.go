@@ -16,11 +16,13 @@
 package statedb
 
 import (
+	"bytes"
 	"fmt"
 	"math/big"
 	"sort"
 
 	errorsmod "cosmossdk.io/errors"
+	storetypes "cosmossdk.io/store/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
@@ -36,7 +38,16 @@ type revision struct {
 	journalIndex int
 }
 
-var _ vm.StateDB = &StateDB{}
+var (
+	_ vm.StateDB = &StateDB{}
+	_ ExtStateDB = &StateDB{}
+)
+
+// nativeLayer is a cache of the Cosmos state written by a native action.
+type nativeLayer struct {
+	ctx   sdk.Context
+	write func()
+}
 
 // StateDB structs within the ethereum protocol are used to store anything
 // within the merkle trie. StateDBs take care of caching and storing
@@ -65,6 +76,12 @@ type StateDB struct {
 
 	// Per-transaction access list
 	accessList *accessList
+
+	// Per-transaction EIP-1153 transient storage
+	transientStorage transientStorage
+
+	// The caches of the native actions, each one on top of the previous one
+	nativeLayers []nativeLayer
 }
 
 // New creates a new state from a given trie.
@@ -76,6 +93,8 @@ func New(ctx sdk.Context, keeper Keeper, txConfig TxConfig) *StateDB {
 		journal:      newJournal(),
 		accessList:   newAccessList(),
 
+		transientStorage: newTransientStorage(),
+
 		txConfig: txConfig,
 	}
 }
@@ -85,6 +104,103 @@ func (s *StateDB) Keeper() Keeper {
 	return s.keeper
 }
 
+// nativeCtx returns the context of the Cosmos state with the changes of the native
+// actions.
+func (s *StateDB) nativeCtx() sdk.Context {
+	if len(s.nativeLayers) == 0 {
+		return s.ctx
+	}
+	return s.nativeLayers[len(s.nativeLayers)-1].ctx
+}
+
+// AppendJournalEntry appends an entry to the journal, it's reverted with the
+// snapshots of the state.
+func (s *StateDB) AppendJournalEntry(entry JournalEntry) {
+	s.journal.append(entry)
+}
+
+// ExecuteNativeAction runs a Cosmos action of a stateful precompiled contract on
+// a cache of the Cosmos state. The dirty accounts are written to the cache before
+// the action, and the balances of the live accounts are reloaded from it after,
+// so the EVM and the action agree on the balances. The cache is discarded if the
+// call is reverted, it's written with the StateDB commit otherwise.
+//
+// The action runs with a gas meter limited to gasLimit, it returns the Cosmos gas
+// consumed by the action, and vm.ErrOutOfGas if the action runs out of gas.
+func (s *StateDB) ExecuteNativeAction(gasLimit uint64, action func(ctx sdk.Context) error) (gasUsed uint64, err error) {
+	ctx, write := s.nativeCtx().CacheContext()
+	ctx = ctx.WithGasMeter(storetypes.NewInfiniteGasMeter())
+
+	for _, addr := range s.journal.sortedDirties() {
+		obj := s.stateObjects[addr]
+		if obj == nil || obj.suicided {
+			continue
+		}
+		if err := s.keeper.SetAccount(ctx, addr, obj.account); err != nil {
+			return 0, errorsmod.Wrap(err, "failed to set account")
+		}
+	}
+
+	gasMeter := storetypes.NewGasMeter(gasLimit)
+	if err := runNativeAction(ctx.WithGasMeter(gasMeter), action); err != nil {
+		return gasMeter.GasConsumedToLimit(), err
+	}
+
+	s.journal.append(nativeChange{layers: len(s.nativeLayers)})
+	s.nativeLayers = append(s.nativeLayers, nativeLayer{ctx: ctx, write: write})
+
+	addrs := make([]common.Address, 0, len(s.stateObjects))
+	for addr, obj := range s.stateObjects {
+		if !obj.suicided {
+			addrs = append(addrs, addr)
+		}
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i].Bytes(), addrs[j].Bytes()) < 0
+	})
+	for _, addr := range addrs {
+		account := s.keeper.GetAccount(ctx, addr)
+		if account == nil {
+			continue
+		}
+		obj := s.stateObjects[addr]
+		if obj.Balance().Cmp(account.Balance) != 0 {
+			obj.SetBalance(account.Balance)
+		}
+	}
+	return gasMeter.GasConsumed(), nil
+}
+
+// runNativeAction runs the action, the out of gas panic of its gas meter is returned
+// as vm.ErrOutOfGas.
+func runNativeAction(ctx sdk.Context, action func(ctx sdk.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(storetypes.ErrorOutOfGas); !ok {
+				panic(r)
+			}
+			err = vm.ErrOutOfGas
+		}
+	}()
+	return action(ctx)
+}
+
+// SetTxConfig prepares the StateDB to execute another transaction on top of the
+// uncommitted changes of the previous ones: it sets the new tx config, and resets
+// the per-transaction logs, refund counter, access list, transient storage and
+// created accounts.
+func (s *StateDB) SetTxConfig(txConfig TxConfig) {
+	s.txConfig = txConfig
+	s.logs = nil
+	s.refund = 0
+	s.accessList = newAccessList()
+	s.transientStorage = newTransientStorage()
+	s.validRevisions = s.validRevisions[:0]
+	for _, obj := range s.stateObjects {
+		obj.created = false
+	}
+}
+
 // AddLog adds a log, called by evm.
 func (s *StateDB) AddLog(log *ethtypes.Log) {
 	s.journal.append(addLogChange{})
@@ -194,6 +310,11 @@ func (s *StateDB) GetCommittedState(addr common.Address, hash common.Hash) commo
 	return common.Hash{}
 }
 
+// GetTransientState returns the EIP-1153 transient storage of the key of an account.
+func (s *StateDB) GetTransientState(addr common.Address, key common.Hash) common.Hash {
+	return s.transientStorage.Get(addr, key)
+}
+
 // GetRefund returns the current value of the refund counter.
 func (s *StateDB) GetRefund() uint64 {
 	return s.refund
@@ -222,7 +343,7 @@ func (s *StateDB) getStateObject(addr common.Address) *stateObject {
 		return obj
 	}
 	// If no live objects are available, load it from keeper
-	account := s.keeper.GetAccount(s.ctx, addr)
+	account := s.keeper.GetAccount(s.nativeCtx(), addr)
 	if account == nil {
 		return nil
 	}
@@ -247,6 +368,7 @@ func (s *StateDB) createObject(addr common.Address) (newobj, prev *stateObject)
 	prev = s.getStateObject(addr)
 
 	newobj = newObject(s, addr, Account{})
+	newobj.created = true
 	if prev == nil {
 		s.journal.append(createObjectChange{account: &addr})
 	} else {
@@ -282,6 +404,25 @@ func (s *StateDB) ForEachStorage(addr common.Address, cb func(key, value common.
 	if so == nil {
 		return nil
 	}
+	if so.fakeStorage {
+		storage := make(Storage, len(so.originStorage)+len(so.dirtyStorage))
+		for key, value := range so.originStorage {
+			storage[key] = value
+		}
+		for key, value := range so.dirtyStorage {
+			storage[key] = value
+		}
+		for _, key := range storage.SortedKeys() {
+			value := storage[key]
+			if value == (common.Hash{}) {
+				continue
+			}
+			if !cb(key, value) {
+				break
+			}
+		}
+		return nil
+	}
 	s.keeper.ForEachStorage(s.ctx, addr, func(key, value common.Hash) bool {
 		if value, dirty := so.dirtyStorage[key]; dirty {
 			return cb(key, value)
@@ -318,6 +459,14 @@ func (s *StateDB) SubBalance(addr common.Address, amount *big.Int) {
 	}
 }
 
+// SetBalance sets the balance of account.
+func (s *StateDB) SetBalance(addr common.Address, amount *big.Int) {
+	stateObject := s.getOrNewStateObject(addr)
+	if stateObject != nil {
+		stateObject.SetBalance(new(big.Int).Set(amount))
+	}
+}
+
 // SetNonce sets the nonce of account.
 func (s *StateDB) SetNonce(addr common.Address, nonce uint64) {
 	stateObject := s.getOrNewStateObject(addr)
@@ -342,6 +491,30 @@ func (s *StateDB) SetState(addr common.Address, key, value common.Hash) {
 	}
 }
 
+// SetStorage replaces the entire storage of the account with the given one.
+// It's used by the state overrides of the simulated calls, the StateDB must
+// not be committed afterwards.
+func (s *StateDB) SetStorage(addr common.Address, storage map[common.Hash]common.Hash) {
+	stateObject := s.getOrNewStateObject(addr)
+	if stateObject != nil {
+		stateObject.SetStorage(storage)
+	}
+}
+
+// SetTransientState sets the EIP-1153 transient storage of the key of an account.
+func (s *StateDB) SetTransientState(addr common.Address, key, value common.Hash) {
+	prev := s.GetTransientState(addr, key)
+	if prev == value {
+		return
+	}
+	s.journal.append(transientStorageChange{
+		account:  &addr,
+		key:      key,
+		prevalue: prev,
+	})
+	s.transientStorage.Set(addr, key, value)
+}
+
 // Suicide marks the given account as suicided.
 // This clears the account balance.
 //
@@ -363,6 +536,19 @@ func (s *StateDB) Suicide(addr common.Address) bool {
 	return true
 }
 
+// Selfdestruct6780 marks the given account as suicided if it was created in the
+// current transaction, the other accounts are kept (EIP-6780). The SELFDESTRUCT
+// opcode has already moved the balance of the account to the beneficiary.
+func (s *StateDB) Selfdestruct6780(addr common.Address) {
+	stateObject := s.getStateObject(addr)
+	if stateObject == nil {
+		return
+	}
+	if stateObject.created {
+		s.Suicide(addr)
+	}
+}
+
 // PrepareAccessList handles the preparatory steps for executing a state transition with
 // regards to both EIP-2929 and EIP-2930:
 //
@@ -451,6 +637,12 @@ func (s *StateDB) RevertToSnapshot(revid int) {
 // Commit writes the dirty states to keeper
 // the StateDB object should be discarded after committed.
 func (s *StateDB) Commit() error {
+	// each cache is written to the previous one
+	for i := len(s.nativeLayers) - 1; i >= 0; i-- {
+		s.nativeLayers[i].write()
+	}
+	s.nativeLayers = nil
+
 	for _, addr := range s.journal.sortedDirties() {
 		obj := s.stateObjects[addr]
 		if obj.suicided {
@@ -476,3 +668,36 @@ func (s *StateDB) Commit() error {
 	}
 	return nil
 }
+
+// DirtiesHash returns a deterministic commitment of the accounts and storage slots
+// modified in the StateDB. Ethermint doesn't maintain a merkle patricia trie, the
+// hash replaces the intermediate state root to compare state transitions between nodes.
+func (s *StateDB) DirtiesHash() common.Hash {
+	hasher := crypto.NewKeccakState()
+	for _, addr := range s.journal.sortedDirties() {
+		obj := s.stateObjects[addr]
+		if obj == nil {
+			continue
+		}
+		hasher.Write(addr.Bytes())
+		if obj.suicided {
+			hasher.Write([]byte{1})
+			continue
+		}
+		hasher.Write([]byte{0})
+		hasher.Write(sdk.Uint64ToBigEndian(obj.account.Nonce))
+		hasher.Write(common.BigToHash(obj.account.Balance).Bytes())
+		hasher.Write(obj.account.CodeHash)
+		for _, key := range obj.dirtyStorage.SortedKeys() {
+			value := obj.dirtyStorage[key]
+			if value == obj.originStorage[key] {
+				continue
+			}
+			hasher.Write(key.Bytes())
+			hasher.Write(value.Bytes())
+		}
+	}
+	var hash common.Hash
+	_, _ = hasher.Read(hash[:])
+	return hash
+}
@@ -6,6 +6,7 @@ import (
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -503,6 +504,84 @@ func (suite *StateDBTestSuite) TestLog() {
 	suite.Require().Equal(expecedLog, db.Logs()[1])
 }
 
+func (suite *StateDBTestSuite) TestSetTxConfig() {
+	db := statedb.New(sdk.Context{}, NewMockKeeper(), emptyTxConfig)
+	db.SetState(address, common.Hash{}, common.BytesToHash([]byte("value")))
+	db.AddLog(&ethtypes.Log{Address: address})
+	db.AddRefund(10)
+	db.AddAddressToAccessList(address)
+	db.SetTransientState(address, common.Hash{}, common.BytesToHash([]byte("transient")))
+
+	txHash := common.BytesToHash([]byte("tx"))
+	db.SetTxConfig(statedb.NewTxConfig(blockHash, txHash, 1, 1))
+	suite.Require().Empty(db.Logs())
+	suite.Require().Zero(db.GetRefund())
+	suite.Require().False(db.AddressInAccessList(address))
+	suite.Require().Equal(common.Hash{}, db.GetTransientState(address, common.Hash{}))
+	// the state changes of the previous transaction are kept
+	suite.Require().Equal(common.BytesToHash([]byte("value")), db.GetState(address, common.Hash{}))
+
+	db.AddLog(&ethtypes.Log{Address: address})
+	suite.Require().Equal(txHash, db.Logs()[0].TxHash)
+	suite.Require().Equal(uint(1), db.Logs()[0].TxIndex)
+	suite.Require().Equal(uint(1), db.Logs()[0].Index)
+}
+
+func (suite *StateDBTestSuite) TestTransientStorage() {
+	key := common.BigToHash(big.NewInt(1))
+	value1 := common.BigToHash(big.NewInt(2))
+	value2 := common.BigToHash(big.NewInt(3))
+
+	keeper := NewMockKeeper()
+	db := statedb.New(sdk.Context{}, keeper, emptyTxConfig)
+	db.SetTransientState(address, key, value1)
+	suite.Require().Equal(value1, db.GetTransientState(address, key))
+	suite.Require().Equal(common.Hash{}, db.GetTransientState(address2, key))
+
+	rev := db.Snapshot()
+	db.SetTransientState(address, key, value2)
+	suite.Require().Equal(value2, db.GetTransientState(address, key))
+	db.RevertToSnapshot(rev)
+	suite.Require().Equal(value1, db.GetTransientState(address, key))
+
+	// the transient storage is not persisted
+	suite.Require().NoError(db.Commit())
+	suite.Require().Empty(keeper.accounts)
+	db = statedb.New(sdk.Context{}, keeper, emptyTxConfig)
+	suite.Require().Equal(common.Hash{}, db.GetTransientState(address, key))
+}
+
+func (suite *StateDBTestSuite) TestSelfdestruct6780() {
+	keeper := NewMockKeeper()
+	db := statedb.New(sdk.Context{}, keeper, emptyTxConfig)
+	db.CreateAccount(address)
+	db.SetCode(address, []byte("hello world"))
+	db.AddBalance(address, big.NewInt(100))
+	suite.Require().NoError(db.Commit())
+
+	// the existing account is kept with its balance
+	db = statedb.New(sdk.Context{}, keeper, emptyTxConfig)
+	db.Selfdestruct6780(address)
+	suite.Require().False(db.HasSuicided(address))
+	suite.Require().Equal(big.NewInt(100), db.GetBalance(address))
+	suite.Require().NoError(db.Commit())
+	db = statedb.New(sdk.Context{}, keeper, emptyTxConfig)
+	suite.Require().True(db.Exist(address))
+	suite.Require().Equal([]byte("hello world"), db.GetCode(address))
+
+	// the account created in the transaction is deleted
+	db.CreateAccount(address2)
+	db.SetCode(address2, []byte("hello world"))
+	db.Selfdestruct6780(address2)
+	suite.Require().True(db.HasSuicided(address2))
+
+	// but not in the next transaction
+	db.CreateAccount(address3)
+	db.SetTxConfig(emptyTxConfig)
+	db.Selfdestruct6780(address3)
+	suite.Require().False(db.HasSuicided(address3))
+}
+
 func (suite *StateDBTestSuite) TestRefund() {
 	testCases := []struct {
 		name      string
@@ -565,6 +644,63 @@ func (suite *StateDBTestSuite) TestIterateStorage() {
 	suite.Require().Equal(1, len(storage))
 }
 
+func (suite *StateDBTestSuite) TestStateOverride() {
+	key1 := common.BigToHash(big.NewInt(1))
+	value1 := common.BigToHash(big.NewInt(2))
+	key2 := common.BigToHash(big.NewInt(3))
+	value2 := common.BigToHash(big.NewInt(4))
+	code := hexutil.Bytes("hello world")
+	nonce := hexutil.Uint64(3)
+	balance := (*hexutil.Big)(big.NewInt(100))
+
+	keeper := NewMockKeeper()
+	db := statedb.New(sdk.Context{}, keeper, emptyTxConfig)
+	db.SetState(address, key1, value1)
+	db.SetState(address2, key1, value1)
+	suite.Require().NoError(db.Commit())
+
+	overrides := statedb.StateOverride{
+		address: statedb.OverrideAccount{
+			Nonce:   &nonce,
+			Code:    &code,
+			Balance: &balance,
+			State:   &map[common.Hash]common.Hash{key2: value2},
+		},
+		address2: statedb.OverrideAccount{
+			StateDiff: &map[common.Hash]common.Hash{key2: value2},
+		},
+	}
+	db = statedb.New(sdk.Context{}, keeper, emptyTxConfig)
+	suite.Require().NoError(overrides.Apply(db))
+
+	suite.Require().Equal(uint64(nonce), db.GetNonce(address))
+	suite.Require().Equal([]byte(code), db.GetCode(address))
+	suite.Require().Equal(big.NewInt(100), db.GetBalance(address))
+
+	// state replaces the whole storage
+	suite.Require().Equal(common.Hash{}, db.GetState(address, key1))
+	suite.Require().Equal(value2, db.GetState(address, key2))
+	suite.Require().Equal(statedb.Storage{key2: value2}, CollectContractStorage(db))
+
+	// state diff is applied on top of the existing storage
+	suite.Require().Equal(value1, db.GetState(address2, key1))
+	suite.Require().Equal(value2, db.GetState(address2, key2))
+
+	// state and stateDiff are exclusive
+	overrides = statedb.StateOverride{
+		address: statedb.OverrideAccount{
+			State:     &map[common.Hash]common.Hash{},
+			StateDiff: &map[common.Hash]common.Hash{},
+		},
+	}
+	suite.Require().Error(overrides.Apply(statedb.New(sdk.Context{}, keeper, emptyTxConfig)))
+
+	// nil overrides is a noop
+	var nilOverrides *statedb.StateOverride
+	suite.Require().NoError(nilOverrides.Apply(db))
+	suite.Require().Equal(uint64(5), nilOverrides.Nonce(address, 5))
+}
+
 func CollectContractStorage(db vm.StateDB) statedb.Storage {
 	storage := make(statedb.Storage)
 	db.ForEachStorage(address, func(k, v common.Hash) bool {
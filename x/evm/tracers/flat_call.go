@@ -0,0 +1,340 @@
+// Copyright 2021 Evmos Foundation
+// This file is part of Evmos' Ethermint library.
+//
+// The Ethermint library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Ethermint library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Ethermint library. If not, see https://github.com/evmos/ethermint/blob/main/LICENSE
+package tracers
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+)
+
+// FlatCallTrace is a call trace in the OpenEthereum format. The block and transaction
+// fields are omitted when the trace is not bound to a block.
+type FlatCallTrace struct {
+	Action              FlatCallAction  `json:"action"`
+	BlockHash           *common.Hash    `json:"blockHash,omitempty"`
+	BlockNumber         *uint64         `json:"blockNumber,omitempty"`
+	Error               string          `json:"error,omitempty"`
+	Result              *FlatCallResult `json:"result"`
+	Subtraces           int             `json:"subtraces"`
+	TraceAddress        []int           `json:"traceAddress"`
+	TransactionHash     *common.Hash    `json:"transactionHash,omitempty"`
+	TransactionPosition *uint64         `json:"transactionPosition,omitempty"`
+	Type                string          `json:"type"`
+}
+
+// FlatCallAction is the action of a call trace: the fields of calls and creations
+// are set depending on the call type, suicides only set the address, the refund
+// address and the balance.
+type FlatCallAction struct {
+	CallType      string          `json:"callType,omitempty"`
+	From          *common.Address `json:"from,omitempty"`
+	To            *common.Address `json:"to,omitempty"`
+	Gas           *hexutil.Uint64 `json:"gas,omitempty"`
+	Input         *hexutil.Bytes  `json:"input,omitempty"`
+	Init          *hexutil.Bytes  `json:"init,omitempty"`
+	Value         *hexutil.Big    `json:"value,omitempty"`
+	Address       *common.Address `json:"address,omitempty"`
+	RefundAddress *common.Address `json:"refundAddress,omitempty"`
+	Balance       *hexutil.Big    `json:"balance,omitempty"`
+}
+
+// FlatCallResult is the result of a successful call or creation.
+type FlatCallResult struct {
+	Address *common.Address `json:"address,omitempty"`
+	Code    *hexutil.Bytes  `json:"code,omitempty"`
+	GasUsed hexutil.Uint64  `json:"gasUsed"`
+	Output  *hexutil.Bytes  `json:"output,omitempty"`
+}
+
+// callFrame is a call of the call tree built during the execution.
+type callFrame struct {
+	typ     vm.OpCode
+	from    common.Address
+	to      common.Address
+	input   []byte
+	output  []byte
+	gas     uint64
+	gasUsed uint64
+	value   *big.Int
+	err     error
+	calls   []*callFrame
+}
+
+type flatCallTracerConfig struct {
+	// IncludePrecompiles includes the calls to the precompiled contracts
+	IncludePrecompiles bool `json:"includePrecompiles"`
+}
+
+// flatCallTracer builds the call tree of a transaction, and returns it as a flat
+// list of call traces addressed by their position in the tree.
+type flatCallTracer struct {
+	ctx         *tracers.Context
+	config      flatCallTracerConfig
+	blockNumber uint64
+	precompiles map[common.Address]struct{}
+	// customPrecompiles are the addresses of the custom precompiled contracts
+	customPrecompiles []common.Address
+	callstack         []*callFrame
+	// skipped counts the nested frames excluded from the trace
+	skipped   int
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+func newFlatCallTracer(ctx *tracers.Context, cfg json.RawMessage) (tracers.Tracer, error) {
+	var config flatCallTracerConfig
+	if cfg != nil {
+		if err := json.Unmarshal(cfg, &config); err != nil {
+			return nil, err
+		}
+	}
+	return &flatCallTracer{ctx: ctx, config: config}, nil
+}
+
+// SetPrecompiles implements the PrecompilesTracer interface to exclude the calls to
+// the custom precompiled contracts.
+func (t *flatCallTracer) SetPrecompiles(addrs []common.Address) {
+	t.customPrecompiles = addrs
+}
+
+// CaptureStart implements the EVMLogger interface to initialize the tracing operation.
+func (t *flatCallTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.blockNumber = env.Context.BlockNumber.Uint64()
+	t.precompiles = make(map[common.Address]struct{})
+	rules := env.ChainConfig().Rules(env.Context.BlockNumber, env.Context.Random != nil)
+	for _, addr := range append(slices.Clone(vm.ActivePrecompiles(rules)), t.customPrecompiles...) {
+		t.precompiles[addr] = struct{}{}
+	}
+
+	typ := vm.CALL
+	if create {
+		typ = vm.CREATE
+	}
+	t.callstack = []*callFrame{{
+		typ:   typ,
+		from:  from,
+		to:    to,
+		input: common.CopyBytes(input),
+		gas:   gas,
+		value: value,
+	}}
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *flatCallTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) {
+	t.callstack[0].output = common.CopyBytes(output)
+	t.callstack[0].gasUsed = gasUsed
+	t.callstack[0].err = err
+}
+
+// CaptureState implements the EVMLogger interface to trace a single step of VM execution.
+func (t *flatCallTracer) CaptureState(_ uint64, _ vm.OpCode, _, _ uint64, _ *vm.ScopeContext, _ []byte, _ int, _ error) {
+}
+
+// CaptureFault implements the EVMLogger interface to trace an execution fault.
+func (t *flatCallTracer) CaptureFault(_ uint64, _ vm.OpCode, _, _ uint64, _ *vm.ScopeContext, _ int, _ error) {
+}
+
+// CaptureEnter is called when EVM enters a new scope (via call, create or selfdestruct).
+func (t *flatCallTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	if t.skipped > 0 || atomic.LoadUint32(&t.interrupt) > 0 {
+		t.skipped++
+		return
+	}
+	if _, ok := t.precompiles[to]; ok && !t.config.IncludePrecompiles && typ != vm.SELFDESTRUCT {
+		t.skipped++
+		return
+	}
+
+	// delegate calls run with the value of the parent call
+	if typ == vm.DELEGATECALL {
+		value = t.callstack[len(t.callstack)-1].value
+	}
+	t.callstack = append(t.callstack, &callFrame{
+		typ:   typ,
+		from:  from,
+		to:    to,
+		input: common.CopyBytes(input),
+		gas:   gas,
+		value: value,
+	})
+}
+
+// CaptureExit is called when EVM exits a scope, even if the scope didn't
+// execute any code.
+func (t *flatCallTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	if t.skipped > 0 {
+		t.skipped--
+		return
+	}
+	size := len(t.callstack)
+	if size <= 1 {
+		return
+	}
+
+	call := t.callstack[size-1]
+	t.callstack = t.callstack[:size-1]
+	call.output = common.CopyBytes(output)
+	call.gasUsed = gasUsed
+	call.err = err
+
+	parent := t.callstack[size-2]
+	parent.calls = append(parent.calls, call)
+}
+
+// CaptureTxStart implements the EVMLogger interface.
+func (t *flatCallTracer) CaptureTxStart(_ uint64) {}
+
+// CaptureTxEnd implements the EVMLogger interface.
+func (t *flatCallTracer) CaptureTxEnd(_ uint64) {}
+
+// GetResult returns the json-encoded flat list of call traces, and any
+// error arising from the encoding or forceful termination (via `Stop`).
+func (t *flatCallTracer) GetResult() (json.RawMessage, error) {
+	if len(t.callstack) != 1 {
+		return nil, errors.New("incorrect number of top-level calls")
+	}
+
+	traces := t.flatten(t.callstack[0], []int{}, nil)
+	res, err := json.Marshal(traces)
+	if err != nil {
+		return nil, err
+	}
+	return res, t.reason
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *flatCallTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
+
+// flatten appends the traces of the call and of its sub calls in depth first order.
+func (t *flatCallTracer) flatten(call *callFrame, traceAddress []int, traces []FlatCallTrace) []FlatCallTrace {
+	trace := t.newTrace(call)
+	trace.Subtraces = len(call.calls)
+	trace.TraceAddress = traceAddress
+	traces = append(traces, trace)
+
+	for i, sub := range call.calls {
+		subAddress := make([]int, len(traceAddress), len(traceAddress)+1)
+		copy(subAddress, traceAddress)
+		traces = t.flatten(sub, append(subAddress, i), traces)
+	}
+	return traces
+}
+
+// newTrace converts a call frame to a trace, without the position in the call tree.
+func (t *flatCallTracer) newTrace(call *callFrame) FlatCallTrace {
+	from, to := call.from, call.to
+	value := new(big.Int)
+	if call.value != nil {
+		value = call.value
+	}
+
+	trace := FlatCallTrace{
+		BlockNumber: &t.blockNumber,
+	}
+	if t.ctx != nil {
+		blockHash, txHash, txIndex := t.ctx.BlockHash, t.ctx.TxHash, uint64(t.ctx.TxIndex) // #nosec G115
+		trace.BlockHash = &blockHash
+		trace.TransactionHash = &txHash
+		trace.TransactionPosition = &txIndex
+	}
+
+	gas, gasUsed := hexutil.Uint64(call.gas), hexutil.Uint64(call.gasUsed)
+	input, output := hexutil.Bytes(call.input), hexutil.Bytes(call.output)
+	switch call.typ {
+	case vm.SELFDESTRUCT:
+		trace.Type = "suicide"
+		trace.Action = FlatCallAction{
+			Address:       &from,
+			RefundAddress: &to,
+			Balance:       (*hexutil.Big)(value),
+		}
+		return trace
+	case vm.CREATE, vm.CREATE2:
+		trace.Type = "create"
+		trace.Action = FlatCallAction{
+			From:  &from,
+			Gas:   &gas,
+			Init:  &input,
+			Value: (*hexutil.Big)(value),
+		}
+		trace.Result = &FlatCallResult{
+			Address: &to,
+			Code:    &output,
+			GasUsed: gasUsed,
+		}
+	default:
+		trace.Type = "call"
+		trace.Action = FlatCallAction{
+			CallType: strings.ToLower(call.typ.String()),
+			From:     &from,
+			To:       &to,
+			Gas:      &gas,
+			Input:    &input,
+			Value:    (*hexutil.Big)(value),
+		}
+		trace.Result = &FlatCallResult{
+			GasUsed: gasUsed,
+			Output:  &output,
+		}
+	}
+
+	if call.err != nil {
+		trace.Error = parityError(call.err)
+		trace.Result = nil
+	}
+	return trace
+}
+
+// parityError returns the OpenEthereum message of the common execution errors.
+func parityError(err error) string {
+	var (
+		invalidOpCode  *vm.ErrInvalidOpCode
+		stackUnderflow *vm.ErrStackUnderflow
+		stackOverflow  *vm.ErrStackOverflow
+	)
+	switch {
+	case errors.Is(err, vm.ErrExecutionReverted):
+		return "Reverted"
+	case errors.Is(err, vm.ErrOutOfGas), errors.Is(err, vm.ErrCodeStoreOutOfGas):
+		return "Out of gas"
+	case errors.Is(err, vm.ErrInvalidJump):
+		return "Bad jump destination"
+	case errors.Is(err, vm.ErrWriteProtection):
+		return "Mutable Call In Static Context"
+	case errors.As(err, &invalidOpCode):
+		return "Bad instruction"
+	case errors.As(err, &stackUnderflow):
+		return "Stack underflow"
+	case errors.As(err, &stackOverflow):
+		return "Out of stack"
+	default:
+		return err.Error()
+	}
+}
@@ -0,0 +1,131 @@
+package tracers
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlatCallTracer(t *testing.T) {
+	var (
+		sender    = common.HexToAddress("0x01a0")
+		contract  = common.HexToAddress("0x01b0")
+		callee    = common.HexToAddress("0x01c0")
+		created   = common.HexToAddress("0x01d0")
+		refund    = common.HexToAddress("0x01e0")
+		ecrecover = common.BytesToAddress([]byte{1})
+	)
+
+	tracerCtx := &tracers.Context{BlockHash: common.HexToHash("0x0a"), TxHash: common.HexToHash("0x0b"), TxIndex: 2}
+	tracer, err := tracers.New(FlatCallTracer, tracerCtx, nil)
+	require.NoError(t, err)
+
+	env := vm.NewEVM(vm.BlockContext{BlockNumber: big.NewInt(10)}, vm.TxContext{}, nil, params.TestChainConfig, vm.Config{})
+	tracer.CaptureStart(env, sender, contract, false, []byte{0x01}, 100000, big.NewInt(5))
+	tracer.CaptureEnter(vm.CALL, contract, callee, []byte{0x02}, 50000, big.NewInt(1))
+	tracer.CaptureEnter(vm.STATICCALL, callee, contract, nil, 20000, nil)
+	tracer.CaptureExit([]byte{0x03}, 100, nil)
+	tracer.CaptureExit(nil, 1000, nil)
+	tracer.CaptureEnter(vm.CREATE, contract, created, []byte{0x04}, 30000, big.NewInt(0))
+	tracer.CaptureExit(nil, 30000, vm.ErrExecutionReverted)
+	// precompile calls are excluded
+	tracer.CaptureEnter(vm.DELEGATECALL, contract, ecrecover, nil, 3000, nil)
+	tracer.CaptureExit(nil, 3000, nil)
+	tracer.CaptureEnter(vm.SELFDESTRUCT, contract, refund, []byte{}, 0, big.NewInt(4))
+	tracer.CaptureExit([]byte{}, 0, nil)
+	tracer.CaptureEnd([]byte{0x05}, 40000, 0, nil)
+
+	res, err := tracer.GetResult()
+	require.NoError(t, err)
+
+	var traces []FlatCallTrace
+	require.NoError(t, json.Unmarshal(res, &traces))
+	require.Len(t, traces, 5)
+
+	for _, trace := range traces {
+		require.Equal(t, uint64(10), *trace.BlockNumber)
+		require.Equal(t, tracerCtx.BlockHash, *trace.BlockHash)
+		require.Equal(t, tracerCtx.TxHash, *trace.TransactionHash)
+		require.Equal(t, uint64(2), *trace.TransactionPosition)
+	}
+
+	expected := []struct {
+		typ          string
+		callType     string
+		traceAddress []int
+		subtraces    int
+		err          string
+	}{
+		{"call", "call", []int{}, 3, ""},
+		{"call", "call", []int{0}, 1, ""},
+		{"call", "staticcall", []int{0, 0}, 0, ""},
+		{"create", "", []int{1}, 0, "Reverted"},
+		{"suicide", "", []int{2}, 0, ""},
+	}
+	for i, exp := range expected {
+		require.Equal(t, exp.typ, traces[i].Type, i)
+		require.Equal(t, exp.callType, traces[i].Action.CallType, i)
+		require.Equal(t, exp.traceAddress, traces[i].TraceAddress, i)
+		require.Equal(t, exp.subtraces, traces[i].Subtraces, i)
+		require.Equal(t, exp.err, traces[i].Error, i)
+	}
+
+	require.Equal(t, uint64(40000), uint64(traces[0].Result.GasUsed))
+	require.Equal(t, []byte{0x05}, []byte(*traces[0].Result.Output))
+	require.Equal(t, []byte{0x03}, []byte(*traces[2].Result.Output))
+	require.Equal(t, contract, *traces[3].Action.From)
+	require.Nil(t, traces[3].Result)
+	require.Equal(t, []byte{0x04}, []byte(*traces[3].Action.Init))
+	require.Equal(t, contract, *traces[4].Action.Address)
+	require.Equal(t, refund, *traces[4].Action.RefundAddress)
+	require.Equal(t, big.NewInt(4), traces[4].Action.Balance.ToInt())
+	require.Nil(t, traces[4].Result)
+}
+
+func TestFlatCallTracerCustomPrecompiles(t *testing.T) {
+	var (
+		sender    = common.HexToAddress("0x01a0")
+		contract  = common.HexToAddress("0x01b0")
+		bank      = common.HexToAddress("0x0804")
+		ecrecover = common.BytesToAddress([]byte{1})
+	)
+
+	testCases := []struct {
+		name      string
+		cfg       json.RawMessage
+		expTraces int
+	}{
+		{"custom precompile calls are excluded", nil, 1},
+		{"custom precompile calls are included", json.RawMessage(`{"includePrecompiles":true}`), 3},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tracer, err := tracers.New(FlatCallTracer, &tracers.Context{}, tc.cfg)
+			require.NoError(t, err)
+			tracer.(PrecompilesTracer).SetPrecompiles([]common.Address{bank})
+
+			env := vm.NewEVM(vm.BlockContext{BlockNumber: big.NewInt(10)}, vm.TxContext{}, nil, params.TestChainConfig, vm.Config{})
+			tracer.CaptureStart(env, sender, contract, false, nil, 100000, big.NewInt(0))
+			tracer.CaptureEnter(vm.CALL, contract, bank, nil, 50000, big.NewInt(0))
+			tracer.CaptureExit(nil, 30000, nil)
+			tracer.CaptureEnter(vm.STATICCALL, contract, ecrecover, nil, 3000, nil)
+			tracer.CaptureExit(nil, 3000, nil)
+			tracer.CaptureEnd(nil, 40000, 0, nil)
+
+			res, err := tracer.GetResult()
+			require.NoError(t, err)
+			var traces []FlatCallTrace
+			require.NoError(t, json.Unmarshal(res, &traces))
+			require.Len(t, traces, tc.expTraces)
+		})
+	}
+
+	// the geth precompiles are shared, they aren't modified
+	require.NotContains(t, vm.ActivePrecompiles(params.TestChainConfig.Rules(big.NewInt(10), false)), bank)
+}
@@ -0,0 +1,278 @@
+// Copyright 2021 Evmos Foundation
+// This file is part of Evmos' Ethermint library.
+//
+// The Ethermint library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Ethermint library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Ethermint library. If not, see https://github.com/evmos/ethermint/blob/main/LICENSE
+package tracers
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+)
+
+// StateDiff is the OpenEthereum state diff of a transaction, by account.
+type StateDiff map[common.Address]*AccountDiff
+
+// AccountDiff is the state diff of an account.
+type AccountDiff struct {
+	Balance DiffValue                 `json:"balance"`
+	Code    DiffValue                 `json:"code"`
+	Nonce   DiffValue                 `json:"nonce"`
+	Storage map[common.Hash]DiffValue `json:"storage"`
+}
+
+// DiffValue is the change of a value: "=" if unchanged, {"+": to} if born,
+// {"-": from} if died, and {"*": {"from": from, "to": to}} if changed.
+type DiffValue struct {
+	kind     string
+	from, to interface{}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d DiffValue) MarshalJSON() ([]byte, error) {
+	switch d.kind {
+	case "+":
+		return json.Marshal(map[string]interface{}{"+": d.to})
+	case "-":
+		return json.Marshal(map[string]interface{}{"-": d.from})
+	case "*":
+		return json.Marshal(map[string]interface{}{"*": map[string]interface{}{"from": d.from, "to": d.to}})
+	default:
+		return json.Marshal("=")
+	}
+}
+
+// accountState is the state of an account before the transaction.
+type accountState struct {
+	exist   bool
+	balance *big.Int
+	nonce   uint64
+	code    []byte
+	storage map[common.Hash]common.Hash
+}
+
+// stateDiffTracer records the state of the accounts touched by a transaction
+// before their first access, and diffs it against the state after the execution.
+//
+// The fees and the nonce increment of a call are handled by the ante handler,
+// outside of the EVM state: the diff doesn't include the fees paid by the sender,
+// and the sender nonce is incremented for calls as it is for contract creations.
+type stateDiffTracer struct {
+	env     *vm.EVM
+	pre     map[common.Address]*accountState
+	sender  common.Address
+	create  bool
+	created common.Address
+
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+func newStateDiffTracer(_ *tracers.Context, _ json.RawMessage) (tracers.Tracer, error) {
+	return &stateDiffTracer{pre: make(map[common.Address]*accountState)}, nil
+}
+
+// CaptureStart implements the EVMLogger interface to initialize the tracing operation.
+func (t *stateDiffTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, _ []byte, _ uint64, value *big.Int) {
+	t.env = env
+	t.sender = from
+	t.create = create
+	t.created = to
+
+	t.lookupAccount(from)
+	t.lookupAccount(to)
+
+	// the value is already transferred when the execution starts
+	if value != nil && from != to {
+		t.pre[from].balance = new(big.Int).Add(t.pre[from].balance, value)
+		t.pre[to].balance = new(big.Int).Sub(t.pre[to].balance, value)
+	}
+	if create {
+		// the contract is already created, and the nonce of the sender incremented
+		t.pre[from].nonce--
+		t.pre[to].exist = false
+		t.pre[to].nonce = 0
+		t.pre[to].code = nil
+	}
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *stateDiffTracer) CaptureEnd(_ []byte, _ uint64, _ time.Duration, _ error) {}
+
+// CaptureState implements the EVMLogger interface to trace a single step of VM execution.
+func (t *stateDiffTracer) CaptureState(_ uint64, op vm.OpCode, _, _ uint64, scope *vm.ScopeContext, _ []byte, _ int, err error) {
+	if err != nil || atomic.LoadUint32(&t.interrupt) > 0 {
+		return
+	}
+	stackData := scope.Stack.Data()
+	stackLen := len(stackData)
+	switch {
+	case stackLen >= 1 && (op == vm.SLOAD || op == vm.SSTORE):
+		slot := common.Hash(stackData[stackLen-1].Bytes32())
+		t.lookupStorage(scope.Contract.Address(), slot)
+	case stackLen >= 1 && (op == vm.EXTCODECOPY || op == vm.EXTCODEHASH || op == vm.EXTCODESIZE || op == vm.BALANCE || op == vm.SELFDESTRUCT):
+		addr := common.Address(stackData[stackLen-1].Bytes20())
+		t.lookupAccount(addr)
+	case stackLen >= 5 && (op == vm.DELEGATECALL || op == vm.CALL || op == vm.STATICCALL || op == vm.CALLCODE):
+		addr := common.Address(stackData[stackLen-2].Bytes20())
+		t.lookupAccount(addr)
+	case op == vm.CREATE:
+		addr := scope.Contract.Address()
+		nonce := t.env.StateDB.GetNonce(addr)
+		t.lookupAccount(crypto.CreateAddress(addr, nonce))
+	case stackLen >= 4 && op == vm.CREATE2:
+		offset := stackData[stackLen-2]
+		size := stackData[stackLen-3]
+		init := scope.Memory.GetCopy(int64(offset.Uint64()), int64(size.Uint64())) // #nosec G115
+		inithash := crypto.Keccak256(init)
+		salt := stackData[stackLen-4]
+		t.lookupAccount(crypto.CreateAddress2(scope.Contract.Address(), salt.Bytes32(), inithash))
+	}
+}
+
+// CaptureFault implements the EVMLogger interface to trace an execution fault.
+func (t *stateDiffTracer) CaptureFault(_ uint64, _ vm.OpCode, _, _ uint64, _ *vm.ScopeContext, _ int, _ error) {
+}
+
+// CaptureEnter is called when EVM enters a new scope (via call, create or selfdestruct).
+func (t *stateDiffTracer) CaptureEnter(_ vm.OpCode, _ common.Address, _ common.Address, _ []byte, _ uint64, _ *big.Int) {
+}
+
+// CaptureExit is called when EVM exits a scope, even if the scope didn't
+// execute any code.
+func (t *stateDiffTracer) CaptureExit(_ []byte, _ uint64, _ error) {}
+
+// CaptureTxStart implements the EVMLogger interface.
+func (t *stateDiffTracer) CaptureTxStart(_ uint64) {}
+
+// CaptureTxEnd implements the EVMLogger interface.
+func (t *stateDiffTracer) CaptureTxEnd(_ uint64) {}
+
+// GetResult returns the json-encoded state diff, and any error arising from the
+// encoding or forceful termination (via `Stop`).
+func (t *stateDiffTracer) GetResult() (json.RawMessage, error) {
+	diff := StateDiff{}
+	for addr, pre := range t.pre {
+		if accDiff := t.accountDiff(addr, pre); accDiff != nil {
+			diff[addr] = accDiff
+		}
+	}
+
+	res, err := json.Marshal(diff)
+	if err != nil {
+		return nil, err
+	}
+	return res, t.reason
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *stateDiffTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
+
+// accountDiff diffs the state of the account before the transaction with its
+// current state. It returns nil if the account is unchanged.
+func (t *stateDiffTracer) accountDiff(addr common.Address, pre *accountState) *AccountDiff {
+	stateDB := t.env.StateDB
+	exist := stateDB.Exist(addr) && !stateDB.HasSuicided(addr)
+	if !pre.exist && !exist {
+		return nil
+	}
+
+	nonce := stateDB.GetNonce(addr)
+	if addr == t.sender && !t.create {
+		nonce++
+	}
+	balance, code := stateDB.GetBalance(addr), stateDB.GetCode(addr)
+
+	accDiff := &AccountDiff{Storage: make(map[common.Hash]DiffValue)}
+	switch {
+	case !pre.exist:
+		accDiff.Balance = DiffValue{kind: "+", to: (*hexutil.Big)(balance)}
+		accDiff.Nonce = DiffValue{kind: "+", to: hexutil.Uint64(nonce)}
+		accDiff.Code = DiffValue{kind: "+", to: hexutil.Bytes(code)}
+		for key := range pre.storage {
+			if value := stateDB.GetState(addr, key); value != (common.Hash{}) {
+				accDiff.Storage[key] = DiffValue{kind: "+", to: value}
+			}
+		}
+		return accDiff
+	case !exist:
+		accDiff.Balance = DiffValue{kind: "-", from: (*hexutil.Big)(pre.balance)}
+		accDiff.Nonce = DiffValue{kind: "-", from: hexutil.Uint64(pre.nonce)}
+		accDiff.Code = DiffValue{kind: "-", from: hexutil.Bytes(pre.code)}
+		for key, value := range pre.storage {
+			if value != (common.Hash{}) {
+				accDiff.Storage[key] = DiffValue{kind: "-", from: value}
+			}
+		}
+		return accDiff
+	}
+
+	changed := false
+	if pre.balance.Cmp(balance) != 0 {
+		accDiff.Balance = DiffValue{kind: "*", from: (*hexutil.Big)(pre.balance), to: (*hexutil.Big)(balance)}
+		changed = true
+	}
+	if pre.nonce != nonce {
+		accDiff.Nonce = DiffValue{kind: "*", from: hexutil.Uint64(pre.nonce), to: hexutil.Uint64(nonce)}
+		changed = true
+	}
+	if !bytes.Equal(pre.code, code) {
+		accDiff.Code = DiffValue{kind: "*", from: hexutil.Bytes(pre.code), to: hexutil.Bytes(code)}
+		changed = true
+	}
+	for key, value := range pre.storage {
+		if current := stateDB.GetState(addr, key); current != value {
+			accDiff.Storage[key] = DiffValue{kind: "*", from: value, to: current}
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return accDiff
+}
+
+// lookupAccount records the state of the account before its first access.
+func (t *stateDiffTracer) lookupAccount(addr common.Address) {
+	if _, ok := t.pre[addr]; ok {
+		return
+	}
+	t.pre[addr] = &accountState{
+		exist:   t.env.StateDB.Exist(addr),
+		balance: new(big.Int).Set(t.env.StateDB.GetBalance(addr)),
+		nonce:   t.env.StateDB.GetNonce(addr),
+		code:    t.env.StateDB.GetCode(addr),
+		storage: make(map[common.Hash]common.Hash),
+	}
+}
+
+// lookupStorage records the value of the storage slot before its first access.
+func (t *stateDiffTracer) lookupStorage(addr common.Address, key common.Hash) {
+	t.lookupAccount(addr)
+	if _, ok := t.pre[addr].storage[key]; ok {
+		return
+	}
+	t.pre[addr].storage[key] = t.env.StateDB.GetState(addr, key)
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 Evmos Foundation
+// This file is part of Evmos' Ethermint library.
+//
+// The Ethermint library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Ethermint library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Ethermint library. If not, see https://github.com/evmos/ethermint/blob/main/LICENSE
+
+// Package tracers implements the native tracers of Ethermint, used to serve the
+// OpenEthereum (parity) style `trace` json rpc namespace. Importing the package
+// registers the tracers, so they can be selected by name in the trace config of
+// the `TraceTx` and `TraceBlock` queries.
+package tracers
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+)
+
+const (
+	// FlatCallTracer is the name of the tracer returning the flat list of the
+	// call traces of a transaction.
+	FlatCallTracer = "flatCallTracer"
+	// StateDiffTracer is the name of the tracer returning the state changes
+	// of a transaction.
+	StateDiffTracer = "stateDiffTracer"
+	// MuxTracer is the name of the tracer running the tracers of its config on a
+	// single execution.
+	MuxTracer = "muxTracer"
+)
+
+// PrecompilesTracer is implemented by the tracers which need the addresses of the
+// custom precompiled contracts, the EVM passed to the tracers only knows the geth
+// ones.
+type PrecompilesTracer interface {
+	SetPrecompiles(addrs []common.Address)
+}
+
+type ctorFn = func(*tracers.Context, json.RawMessage) (tracers.Tracer, error)
+
+var ctors = map[string]ctorFn{
+	FlatCallTracer:  newFlatCallTracer,
+	StateDiffTracer: newStateDiffTracer,
+	MuxTracer:       newMuxTracer,
+}
+
+func init() {
+	tracers.RegisterLookup(false, lookup)
+}
+
+// lookup returns the tracer registered under the given name.
+func lookup(name string, ctx *tracers.Context, cfg json.RawMessage) (tracers.Tracer, error) {
+	if ctor, ok := ctors[name]; ok {
+		return ctor(ctx, cfg)
+	}
+	return nil, errors.New("no tracer found")
+}
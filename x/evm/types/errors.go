@@ -49,6 +49,8 @@ const (
 	codeErrGasOverflow
 	codeErrInvalidAccount
 	codeErrInvalidGasLimit
+	codeErrUnknownPrecompile
+	codeErrConversionFactorChange
 )
 
 var ErrPostTxProcessing = errors.New("failed to execute post processing")
@@ -116,6 +118,12 @@ var (
 
 	// ErrInvalidGasLimit returns an error if gas limit value is invalid
 	ErrInvalidGasLimit = errorsmod.Register(ModuleName, codeErrInvalidGasLimit, "invalid gas limit")
+
+	// ErrUnknownPrecompile returns an error if an activated precompile is not registered in the keeper
+	ErrUnknownPrecompile = errorsmod.Register(ModuleName, codeErrUnknownPrecompile, "unknown precompiled contract")
+
+	// ErrConversionFactorChange returns an error if the conversion factor of the evm denom is changed after genesis
+	ErrConversionFactorChange = errorsmod.Register(ModuleName, codeErrConversionFactorChange, "conversion factor cannot be changed")
 )
 
 // NewExecErrorWithReason unpacks the revert return bytes and returns a wrapped error
@@ -42,6 +42,13 @@ type Params struct {
 	// allow_unprotected_txs defines if replay-protected (i.e non EIP155
 	// signed) transactions can be executed on the state machine.
 	AllowUnprotectedTxs bool `protobuf:"varint,6,opt,name=allow_unprotected_txs,json=allowUnprotectedTxs,proto3" json:"allow_unprotected_txs,omitempty"`
+	// active_precompiles defines the hex addresses of the stateful precompiled
+	// contracts that are enabled in the EVM.
+	ActivePrecompiles []string `protobuf:"bytes,7,rep,name=active_precompiles,json=activePrecompiles,proto3" json:"active_precompiles,omitempty" yaml:"active_precompiles"`
+	// conversion_factor defines the number of EVM wei per unit of evm_denom, a
+	// power of ten. Above one, the sub-unit remainders of the EVM balances are
+	// kept as fractional balances, zero and one define a 1:1 conversion.
+	ConversionFactor uint64 `protobuf:"varint,8,opt,name=conversion_factor,json=conversionFactor,proto3" json:"conversion_factor,omitempty" yaml:"conversion_factor"`
 }
 
 func (m *Params) Reset()         { *m = Params{} }
@@ -119,6 +126,20 @@ func (m *Params) GetAllowUnprotectedTxs() bool {
 	return false
 }
 
+func (m *Params) GetActivePrecompiles() []string {
+	if m != nil {
+		return m.ActivePrecompiles
+	}
+	return nil
+}
+
+func (m *Params) GetConversionFactor() uint64 {
+	if m != nil {
+		return m.ConversionFactor
+	}
+	return 0
+}
+
 // ChainConfig defines the Ethereum ChainConfig parameters using *sdk.Int values
 // instead of *big.Int.
 type ChainConfig struct {
@@ -691,111 +712,116 @@ func init() {
 func init() { proto.RegisterFile("ethermint/evm/v1/evm.proto", fileDescriptor_d21ecc92c8c8583e) }
 
 var fileDescriptor_d21ecc92c8c8583e = []byte{
-	// 1659 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x97, 0x4f, 0x6f, 0x23, 0xb7,
-	0x15, 0xc0, 0x6d, 0x4b, 0xb6, 0x47, 0x94, 0x2c, 0x8d, 0x69, 0xd9, 0xd1, 0x7a, 0x51, 0x8f, 0x3b,
-	0x87, 0xc2, 0x0d, 0x12, 0x3b, 0x76, 0x60, 0x74, 0xb1, 0x41, 0x83, 0x58, 0xbb, 0xde, 0xd6, 0xee,
-	0x26, 0x35, 0xb8, 0x0e, 0x0a, 0xf4, 0x32, 0xa0, 0x66, 0x98, 0xd1, 0xc4, 0x33, 0x43, 0x81, 0xe4,
-	0x68, 0xa5, 0x7e, 0x82, 0xa2, 0xbd, 0xf4, 0x23, 0xe4, 0x58, 0xa0, 0x97, 0x1c, 0xfa, 0x21, 0x82,
-	0x9e, 0x82, 0x9e, 0x8a, 0x1e, 0x06, 0x85, 0xf7, 0x10, 0xc0, 0xbd, 0xf9, 0x13, 0x14, 0xfc, 0xa3,
-	0x7f, 0x23, 0x57, 0xf1, 0xc5, 0x9e, 0xc7, 0xf7, 0xe7, 0xc7, 0xf7, 0xf8, 0x28, 0x92, 0x60, 0x97,
-	0x88, 0x2e, 0x61, 0x49, 0x94, 0x8a, 0x23, 0xd2, 0x4f, 0x8e, 0xfa, 0xc7, 0xf2, 0xdf, 0x61, 0x8f,
-	0x51, 0x41, 0xa1, 0x3d, 0xd6, 0x1d, 0xca, 0xc1, 0xfe, 0xf1, 0x6e, 0x33, 0xa4, 0x21, 0x55, 0xca,
-	0x23, 0xf9, 0xa5, 0xed, 0x76, 0x37, 0x71, 0x12, 0xa5, 0xf4, 0x48, 0xfd, 0x35, 0x43, 0x4f, 0x7c,
-	0xca, 0x13, 0xca, 0x3d, 0x6d, 0xab, 0x05, 0xad, 0x72, 0xff, 0x56, 0x02, 0x6b, 0x57, 0x98, 0xe1,
-	0x84, 0xc3, 0x63, 0x50, 0x21, 0xfd, 0xc4, 0x0b, 0x48, 0x4a, 0x93, 0xd6, 0xf2, 0xfe, 0xf2, 0x41,
-	0xa5, 0xdd, 0xbc, 0xcf, 0x1d, 0x7b, 0x88, 0x93, 0xf8, 0xb9, 0x3b, 0x56, 0xb9, 0xc8, 0x22, 0xfd,
-	0xe4, 0xa5, 0xfc, 0x84, 0xbf, 0x04, 0x1b, 0x24, 0xc5, 0x9d, 0x98, 0x78, 0x3e, 0x23, 0x58, 0x90,
-	0xd6, 0xca, 0xfe, 0xf2, 0x81, 0xd5, 0x6e, 0xdd, 0xe7, 0x4e, 0xd3, 0xb8, 0x4d, 0xab, 0x5d, 0x54,
-	0xd3, 0xf2, 0x0b, 0x25, 0xc2, 0x5f, 0x80, 0xea, 0x48, 0x8f, 0xe3, 0xb8, 0x55, 0x52, 0xce, 0x3b,
-	0xf7, 0xb9, 0x03, 0x67, 0x9d, 0x71, 0x1c, 0xbb, 0x08, 0x18, 0x57, 0x1c, 0xc7, 0xf0, 0x0c, 0x00,
-	0x32, 0x10, 0x0c, 0x7b, 0x24, 0xea, 0xf1, 0x56, 0x79, 0xbf, 0x74, 0x50, 0x6a, 0xbb, 0xb7, 0xb9,
-	0x53, 0x39, 0x97, 0xa3, 0xe7, 0x17, 0x57, 0xfc, 0x3e, 0x77, 0x36, 0x4d, 0x90, 0xb1, 0xa1, 0x8b,
-	0x2a, 0x4a, 0x38, 0x8f, 0x7a, 0x1c, 0x76, 0x40, 0xcd, 0xef, 0xe2, 0x28, 0xf5, 0x7c, 0x9a, 0x7e,
-	0x15, 0x85, 0xad, 0xd5, 0xfd, 0xe5, 0x83, 0xea, 0xc9, 0x4f, 0x0e, 0x8b, 0x55, 0x3e, 0x7c, 0x21,
-	0xad, 0x5e, 0x28, 0xa3, 0xf6, 0xfe, 0x77, 0xb9, 0xb3, 0x74, 0x9f, 0x3b, 0x5b, 0x3a, 0xf4, 0x74,
-	0x00, 0xf7, 0xaf, 0x3f, 0x7c, 0xfb, 0xfe, 0x32, 0xaa, 0xfa, 0x13, 0x73, 0x78, 0x02, 0xb6, 0x71,
-	0x1c, 0xd3, 0xb7, 0x5e, 0x96, 0xca, 0x6a, 0x13, 0x5f, 0x90, 0xc0, 0x13, 0x03, 0xde, 0x5a, 0x93,
-	0x99, 0xa2, 0x2d, 0xa5, 0xfc, 0x72, 0xa2, 0xbb, 0x1e, 0xf0, 0xe7, 0x4f, 0xff, 0xf4, 0xc3, 0xb7,
-	0xef, 0xef, 0x4c, 0xfa, 0x60, 0xa0, 0x3a, 0x41, 0x2f, 0x91, 0xfb, 0x5f, 0x1b, 0x54, 0xa7, 0xe6,
-	0x03, 0xbf, 0x06, 0x8d, 0x2e, 0x4d, 0x08, 0x17, 0x04, 0x07, 0x5e, 0x27, 0xa6, 0xfe, 0x8d, 0x59,
-	0xb8, 0xb3, 0x7f, 0xe7, 0xce, 0xb6, 0x5e, 0x68, 0x1e, 0xdc, 0x1c, 0x46, 0xf4, 0x28, 0xc1, 0xa2,
-	0x7b, 0x78, 0x91, 0x8a, 0xfb, 0xdc, 0xd9, 0xd1, 0xb3, 0x2f, 0x78, 0xba, 0xff, 0xfc, 0xfb, 0x87,
-	0xc0, 0xf4, 0xc6, 0x45, 0x2a, 0x50, 0x7d, 0xac, 0x6f, 0x4b, 0x35, 0xec, 0x83, 0x7a, 0x80, 0xa9,
-	0xf7, 0x15, 0x65, 0x37, 0x06, 0xb5, 0xa2, 0x50, 0x57, 0xff, 0x17, 0x75, 0x9b, 0x3b, 0xb5, 0x97,
-	0x67, 0xbf, 0x7d, 0x45, 0xd9, 0x8d, 0x0a, 0x71, 0x9f, 0x3b, 0xdb, 0x1a, 0x3d, 0x1b, 0xa8, 0x48,
-	0xae, 0x05, 0x98, 0x8e, 0x9d, 0xe0, 0xef, 0x80, 0x3d, 0x36, 0xe7, 0x59, 0xaf, 0x47, 0x99, 0x30,
-	0x9d, 0xf2, 0xe1, 0x6d, 0xee, 0xd4, 0x0d, 0xe0, 0x8d, 0xd6, 0xdc, 0xe7, 0xce, 0x7b, 0x05, 0x84,
-	0xf1, 0x71, 0x51, 0xdd, 0x84, 0x35, 0xa6, 0xb0, 0x07, 0x6a, 0x24, 0xea, 0x1d, 0x9f, 0x7e, 0x64,
-	0xd2, 0x29, 0xab, 0x74, 0x3e, 0x5f, 0x94, 0x4e, 0xf5, 0xfc, 0xe2, 0xea, 0xf8, 0xf4, 0xa3, 0x51,
-	0x36, 0xa6, 0x0d, 0xa6, 0xa3, 0x14, 0x73, 0xa9, 0x6a, 0xa5, 0x4e, 0xe5, 0x02, 0x18, 0xd1, 0xeb,
-	0x62, 0xde, 0x55, 0x2d, 0x57, 0x69, 0x1f, 0xdc, 0xe6, 0x0e, 0xd0, 0x71, 0x7f, 0x8d, 0x79, 0x77,
-	0xb2, 0x3e, 0x9d, 0xe1, 0x1f, 0x70, 0x2a, 0xa2, 0x2c, 0x31, 0x91, 0x11, 0xd0, 0xce, 0xd2, 0x6a,
-	0x3c, 0xf9, 0x53, 0x33, 0xf9, 0xb5, 0xc7, 0x4e, 0xfe, 0xf4, 0xa1, 0xc9, 0x9f, 0x2e, 0x9a, 0xbc,
-	0xf6, 0x18, 0x13, 0x9f, 0x19, 0xe2, 0xfa, 0x63, 0x89, 0xcf, 0x1e, 0x22, 0x3e, 0x5b, 0x44, 0xd4,
-	0x1e, 0xb2, 0xbb, 0x0b, 0x35, 0x68, 0x59, 0x8f, 0xee, 0xee, 0x62, 0xf5, 0x8a, 0xdd, 0x3d, 0xd6,
-	0x6b, 0xd6, 0x10, 0x34, 0x7d, 0x9a, 0x72, 0x21, 0xc7, 0x52, 0xda, 0x8b, 0x89, 0x01, 0x56, 0x14,
-	0xf0, 0xd5, 0x22, 0xe0, 0x53, 0xf3, 0x63, 0xf0, 0x80, 0x7b, 0x91, 0xba, 0x35, 0x6b, 0xa4, 0xd1,
-	0x09, 0xb0, 0x7b, 0x44, 0x10, 0xc6, 0x3b, 0x19, 0x0b, 0x0d, 0x16, 0x28, 0x6c, 0x7b, 0x11, 0xd6,
-	0xf4, 0x79, 0xd1, 0xb5, 0x88, 0x6c, 0x4c, 0x0c, 0x34, 0x2e, 0x04, 0xf5, 0x48, 0xce, 0xa1, 0x93,
-	0xc5, 0x06, 0x56, 0x55, 0xb0, 0xcf, 0x16, 0xc1, 0xcc, 0xbe, 0x9d, 0x75, 0x2c, 0xa2, 0x36, 0x46,
-	0x6a, 0x0d, 0x62, 0x00, 0x26, 0x59, 0xc4, 0xbc, 0x30, 0xc6, 0x7e, 0x44, 0x98, 0x81, 0xd5, 0x14,
-	0xec, 0xe5, 0x22, 0xd8, 0x13, 0x0d, 0x9b, 0x77, 0x2e, 0x02, 0x6d, 0x69, 0xf2, 0x2b, 0x6d, 0xa1,
-	0x99, 0x18, 0xd4, 0x3a, 0x84, 0xc5, 0x51, 0x6a, 0x68, 0x1b, 0x8a, 0xf6, 0xe9, 0x22, 0x9a, 0xe9,
-	0xca, 0x69, 0xb7, 0xb9, 0xae, 0xd4, 0xca, 0x31, 0x22, 0xa6, 0x69, 0x40, 0x47, 0x88, 0xcd, 0x47,
-	0x23, 0xa6, 0xdd, 0xe6, 0x10, 0x5a, 0xa9, 0x11, 0x19, 0xd8, 0xc2, 0x8c, 0xd1, 0xb7, 0x85, 0xd2,
-	0x41, 0x45, 0x3a, 0x5f, 0x44, 0xda, 0xd5, 0xa4, 0x07, 0xbc, 0x8b, 0xc0, 0x4d, 0x65, 0x33, 0x53,
-	0x3c, 0x06, 0x60, 0xc8, 0xf0, 0xb0, 0x40, 0x6d, 0x3e, 0x7a, 0xc1, 0xe6, 0x9d, 0xe7, 0x16, 0x4c,
-	0x9a, 0xcc, 0x30, 0x07, 0xa0, 0x99, 0x10, 0x16, 0x12, 0x2f, 0x25, 0x82, 0xf7, 0xe2, 0x48, 0x18,
-	0xea, 0xf6, 0xa3, 0xf7, 0xdd, 0x43, 0xee, 0x45, 0x2e, 0x54, 0x46, 0x5f, 0x18, 0x9b, 0xf1, 0x3e,
-	0xe0, 0x5d, 0x9c, 0x86, 0x5d, 0x1c, 0x19, 0xe6, 0xce, 0xa3, 0xf7, 0xc1, 0xac, 0xe3, 0xdc, 0x3e,
-	0x18, 0xa9, 0xc7, 0x0d, 0xe3, 0xe3, 0xd4, 0xcf, 0x46, 0x0d, 0xf3, 0xde, 0xa3, 0x1b, 0x66, 0xda,
-	0x6d, 0xae, 0x61, 0xb4, 0x52, 0x21, 0x2e, 0xcb, 0x56, 0xdd, 0x6e, 0x5c, 0x96, 0xad, 0x86, 0x6d,
-	0x5f, 0x96, 0x2d, 0xdb, 0xde, 0xbc, 0x2c, 0x5b, 0x5b, 0x76, 0x13, 0x6d, 0x0c, 0x69, 0x4c, 0xbd,
-	0xfe, 0xc7, 0x3a, 0x04, 0xaa, 0x92, 0xb7, 0x98, 0x9b, 0x1f, 0x44, 0x54, 0xf7, 0xb1, 0xc0, 0xf1,
-	0x90, 0x9b, 0x92, 0x21, 0x5b, 0x17, 0x72, 0xea, 0x58, 0x3e, 0x02, 0xab, 0x6f, 0x84, 0xbc, 0xa7,
-	0xd9, 0xa0, 0x74, 0x43, 0x86, 0xfa, 0x6a, 0x81, 0xe4, 0x27, 0x6c, 0x82, 0xd5, 0x3e, 0x8e, 0x33,
-	0x7d, 0xe1, 0xab, 0x20, 0x2d, 0xb8, 0x57, 0xa0, 0x71, 0xcd, 0x70, 0xca, 0xb1, 0x2f, 0x22, 0x9a,
-	0xbe, 0xa6, 0x21, 0x87, 0x10, 0x94, 0xd5, 0x59, 0xa7, 0x7d, 0xd5, 0x37, 0xfc, 0x39, 0x28, 0xc7,
-	0x34, 0xe4, 0xad, 0x95, 0xfd, 0xd2, 0x41, 0xf5, 0x64, 0x7b, 0xfe, 0xca, 0xf5, 0x9a, 0x86, 0x48,
-	0x99, 0xb8, 0xff, 0x58, 0x01, 0xa5, 0xd7, 0x34, 0x84, 0x2d, 0xb0, 0x8e, 0x83, 0x80, 0x11, 0xce,
-	0x4d, 0xa4, 0x91, 0x08, 0x77, 0xc0, 0x9a, 0xa0, 0xbd, 0xc8, 0xd7, 0xe1, 0x2a, 0xc8, 0x48, 0x12,
-	0x1c, 0x60, 0x81, 0xd5, 0x55, 0xa1, 0x86, 0xd4, 0x37, 0x3c, 0x01, 0x35, 0x95, 0x99, 0x97, 0x66,
-	0x49, 0x87, 0x30, 0x75, 0xe2, 0x97, 0xdb, 0x8d, 0xbb, 0xdc, 0xa9, 0xaa, 0xf1, 0x2f, 0xd4, 0x30,
-	0x9a, 0x16, 0xe0, 0x07, 0x60, 0x5d, 0x0c, 0xa6, 0xcf, 0xeb, 0xad, 0xbb, 0xdc, 0x69, 0x88, 0x49,
-	0x9a, 0xf2, 0x38, 0x46, 0x6b, 0x62, 0xa0, 0x8e, 0xe5, 0x23, 0x60, 0x89, 0x81, 0x17, 0xa5, 0x01,
-	0x19, 0xa8, 0x23, 0xb9, 0xdc, 0x6e, 0xde, 0xe5, 0x8e, 0x3d, 0x65, 0x7e, 0x21, 0x75, 0x68, 0x5d,
-	0x0c, 0xd4, 0x07, 0xfc, 0x00, 0x00, 0x3d, 0x25, 0x45, 0xd0, 0x67, 0xea, 0xc6, 0x5d, 0xee, 0x54,
-	0xd4, 0xa8, 0x8a, 0x3d, 0xf9, 0x84, 0x2e, 0x58, 0xd5, 0xb1, 0x2d, 0x15, 0xbb, 0x76, 0x97, 0x3b,
-	0x56, 0x4c, 0x43, 0x1d, 0x53, 0xab, 0x64, 0xa9, 0x18, 0x49, 0x68, 0x9f, 0x04, 0xea, 0xf0, 0xb2,
-	0xd0, 0x48, 0x74, 0xff, 0xbc, 0x02, 0xac, 0xeb, 0x01, 0x22, 0x3c, 0x8b, 0x05, 0x7c, 0x05, 0x6c,
-	0x9f, 0xa6, 0x82, 0x61, 0x5f, 0x78, 0x33, 0xa5, 0x6d, 0x3f, 0x9d, 0x1c, 0x2e, 0x45, 0x0b, 0x17,
-	0x35, 0x46, 0x43, 0x67, 0xa6, 0xfe, 0x4d, 0xb0, 0xda, 0x89, 0x29, 0x4d, 0x54, 0x27, 0xd4, 0x90,
-	0x16, 0x20, 0x52, 0x55, 0x53, 0xab, 0x5c, 0x52, 0x17, 0xeb, 0x9f, 0xce, 0xaf, 0x72, 0xa1, 0x55,
-	0xda, 0x3b, 0xe6, 0x72, 0x5d, 0xd7, 0x6c, 0xe3, 0xef, 0xca, 0xda, 0xaa, 0x56, 0xb2, 0x41, 0x89,
-	0x11, 0xa1, 0x16, 0xad, 0x86, 0xe4, 0x27, 0xdc, 0x05, 0x16, 0x23, 0x7d, 0xc2, 0x04, 0x09, 0xd4,
-	0xe2, 0x58, 0x68, 0x2c, 0xc3, 0x27, 0xc0, 0x0a, 0x31, 0xf7, 0x32, 0x4e, 0x02, 0xbd, 0x12, 0x68,
-	0x3d, 0xc4, 0xfc, 0x4b, 0x4e, 0x82, 0xe7, 0xe5, 0x3f, 0x7e, 0xe3, 0x2c, 0xb9, 0x18, 0x54, 0xcf,
-	0x7c, 0x9f, 0x70, 0x7e, 0x9d, 0xf5, 0x62, 0xb2, 0xa0, 0xc3, 0x4e, 0x40, 0x8d, 0x0b, 0xca, 0x70,
-	0x48, 0xbc, 0x1b, 0x32, 0x34, 0x7d, 0xa6, 0xbb, 0xc6, 0x8c, 0xff, 0x86, 0x0c, 0x39, 0x9a, 0x16,
-	0x0c, 0xe2, 0x9b, 0x32, 0xa8, 0x5e, 0x33, 0xec, 0x13, 0x73, 0x5d, 0x97, 0xbd, 0x2a, 0x45, 0x66,
-	0x10, 0x46, 0x92, 0x6c, 0x11, 0x25, 0x84, 0x66, 0xc2, 0xec, 0xa7, 0x91, 0x28, 0x3d, 0x18, 0x21,
-	0x03, 0xe2, 0xab, 0x32, 0x96, 0x91, 0x91, 0xe0, 0x29, 0xd8, 0x08, 0x22, 0xae, 0x5e, 0x47, 0x5c,
-	0x60, 0xff, 0x46, 0xa7, 0xdf, 0xb6, 0xef, 0x72, 0xa7, 0x66, 0x14, 0x6f, 0xe4, 0x38, 0x9a, 0x91,
-	0xe0, 0x27, 0xa0, 0x31, 0x71, 0x53, 0xb3, 0xd5, 0x4f, 0x91, 0x36, 0xbc, 0xcb, 0x9d, 0xfa, 0xd8,
-	0x54, 0x69, 0x50, 0x41, 0x96, 0x2b, 0x1d, 0x90, 0x4e, 0x16, 0xaa, 0xe6, 0xb3, 0x90, 0x16, 0xe4,
-	0x68, 0x1c, 0x25, 0x91, 0x50, 0xcd, 0xb6, 0x8a, 0xb4, 0x00, 0x3f, 0x01, 0x15, 0xda, 0x27, 0x8c,
-	0x45, 0x01, 0xe1, 0xea, 0x32, 0xf3, 0x63, 0x4f, 0x2b, 0x34, 0xb1, 0x97, 0xc9, 0x99, 0x97, 0x5f,
-	0x42, 0x12, 0xca, 0x86, 0xea, 0x82, 0x62, 0x92, 0xd3, 0x8a, 0xcf, 0xd5, 0x38, 0x9a, 0x91, 0x60,
-	0x1b, 0x40, 0xe3, 0xc6, 0x88, 0xc8, 0x58, 0xea, 0xa9, 0xfd, 0x5f, 0x53, 0xbe, 0x6a, 0x17, 0x6a,
-	0x2d, 0x52, 0xca, 0x97, 0x58, 0x60, 0x34, 0x37, 0x02, 0x3f, 0x05, 0x50, 0xaf, 0x89, 0xf7, 0x35,
-	0xa7, 0xe3, 0xb7, 0xa1, 0xbe, 0x45, 0x28, 0xbe, 0xd6, 0x9a, 0x39, 0xdb, 0x5a, 0xba, 0xe4, 0xd4,
-	0x64, 0x71, 0x59, 0xb6, 0xca, 0xf6, 0xea, 0x65, 0xd9, 0x5a, 0xb7, 0xad, 0x71, 0xfd, 0x4c, 0x16,
-	0x68, 0x6b, 0x24, 0x4f, 0x4d, 0xaf, 0xfd, 0xd9, 0x77, 0xb7, 0x7b, 0xcb, 0xdf, 0xdf, 0xee, 0x2d,
-	0xff, 0xe7, 0x76, 0x6f, 0xf9, 0x2f, 0xef, 0xf6, 0x96, 0xbe, 0x7f, 0xb7, 0xb7, 0xf4, 0xaf, 0x77,
-	0x7b, 0x4b, 0xbf, 0xff, 0x59, 0x18, 0x89, 0x6e, 0xd6, 0x39, 0xf4, 0x69, 0x22, 0x9f, 0x80, 0x94,
-	0x1f, 0x15, 0x1f, 0x85, 0x62, 0xd8, 0x23, 0xbc, 0xb3, 0xa6, 0x1e, 0xf2, 0x1f, 0xff, 0x2f, 0x00,
-	0x00, 0xff, 0xff, 0x9d, 0x1a, 0x02, 0x8b, 0x3c, 0x10, 0x00, 0x00,
+	// 1735 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x98, 0xcf, 0x6e, 0x23, 0xb7,
+	0x19, 0xc0, 0x2d, 0x6b, 0x6c, 0x8f, 0x28, 0x59, 0x1a, 0xd3, 0xb2, 0xa3, 0xf5, 0x36, 0x1e, 0x77,
+	0x0e, 0x85, 0x1b, 0x24, 0x76, 0xec, 0xc0, 0xe8, 0x62, 0x83, 0x06, 0xb1, 0x76, 0xbd, 0xad, 0xdd,
+	0x4d, 0x6a, 0x70, 0x1d, 0x14, 0xe8, 0x65, 0x40, 0xcd, 0x70, 0x47, 0x13, 0xcf, 0x0c, 0x05, 0x92,
+	0xd2, 0x4a, 0x7d, 0x82, 0xa2, 0xbd, 0xf4, 0x11, 0x72, 0xec, 0x31, 0x87, 0x3e, 0x42, 0x0f, 0x41,
+	0x4f, 0x41, 0x4f, 0x45, 0x0f, 0x83, 0xc2, 0x7b, 0x08, 0xe0, 0xde, 0xf4, 0x04, 0xc5, 0x90, 0xd4,
+	0x48, 0x1a, 0x6d, 0x15, 0x5f, 0x76, 0xf9, 0xfd, 0xe3, 0x8f, 0xdf, 0xc7, 0x8f, 0x22, 0xc7, 0x60,
+	0x8f, 0x88, 0x2e, 0x61, 0x71, 0x98, 0x88, 0x63, 0x32, 0x88, 0x8f, 0x07, 0x27, 0xd9, 0x7f, 0x47,
+	0x3d, 0x46, 0x05, 0x85, 0x56, 0x6e, 0x3b, 0xca, 0x94, 0x83, 0x93, 0xbd, 0x66, 0x40, 0x03, 0x2a,
+	0x8d, 0xc7, 0xd9, 0x48, 0xf9, 0xed, 0x6d, 0xe1, 0x38, 0x4c, 0xe8, 0xb1, 0xfc, 0x57, 0xab, 0x1e,
+	0x79, 0x94, 0xc7, 0x94, 0xbb, 0xca, 0x57, 0x09, 0xca, 0xe4, 0xfc, 0xdd, 0x00, 0xeb, 0xd7, 0x98,
+	0xe1, 0x98, 0xc3, 0x13, 0x50, 0x21, 0x83, 0xd8, 0xf5, 0x49, 0x42, 0xe3, 0x56, 0xe9, 0xa0, 0x74,
+	0x58, 0x69, 0x37, 0xc7, 0xa9, 0x6d, 0x8d, 0x70, 0x1c, 0x3d, 0x75, 0x72, 0x93, 0x83, 0x4c, 0x32,
+	0x88, 0x9f, 0x67, 0x43, 0xf8, 0x4b, 0xb0, 0x49, 0x12, 0xdc, 0x89, 0x88, 0xeb, 0x31, 0x82, 0x05,
+	0x69, 0xad, 0x1e, 0x94, 0x0e, 0xcd, 0x76, 0x6b, 0x9c, 0xda, 0x4d, 0x1d, 0x36, 0x6b, 0x76, 0x50,
+	0x4d, 0xc9, 0xcf, 0xa4, 0x08, 0x7f, 0x01, 0xaa, 0x13, 0x3b, 0x8e, 0xa2, 0x56, 0x59, 0x06, 0xef,
+	0x8e, 0x53, 0x1b, 0xce, 0x07, 0xe3, 0x28, 0x72, 0x10, 0xd0, 0xa1, 0x38, 0x8a, 0xe0, 0x39, 0x00,
+	0x64, 0x28, 0x18, 0x76, 0x49, 0xd8, 0xe3, 0x2d, 0xe3, 0xa0, 0x7c, 0x58, 0x6e, 0x3b, 0x77, 0xa9,
+	0x5d, 0xb9, 0xc8, 0xb4, 0x17, 0x97, 0xd7, 0x7c, 0x9c, 0xda, 0x5b, 0x7a, 0x92, 0xdc, 0xd1, 0x41,
+	0x15, 0x29, 0x5c, 0x84, 0x3d, 0x0e, 0x3b, 0xa0, 0xe6, 0x75, 0x71, 0x98, 0xb8, 0x1e, 0x4d, 0x5e,
+	0x87, 0x41, 0x6b, 0xed, 0xa0, 0x74, 0x58, 0x3d, 0x7d, 0xff, 0xa8, 0x58, 0xe5, 0xa3, 0x67, 0x99,
+	0xd7, 0x33, 0xe9, 0xd4, 0x3e, 0xf8, 0x2e, 0xb5, 0x57, 0xc6, 0xa9, 0xbd, 0xad, 0xa6, 0x9e, 0x9d,
+	0xc0, 0xf9, 0xeb, 0x0f, 0xdf, 0x7e, 0x50, 0x42, 0x55, 0x6f, 0xea, 0x0e, 0x4f, 0xc1, 0x0e, 0x8e,
+	0x22, 0xfa, 0xc6, 0xed, 0x27, 0x59, 0xb5, 0x89, 0x27, 0x88, 0xef, 0x8a, 0x21, 0x6f, 0xad, 0x67,
+	0x99, 0xa2, 0x6d, 0x69, 0xfc, 0x6a, 0x6a, 0xbb, 0x19, 0x72, 0xf8, 0x12, 0x40, 0xec, 0x89, 0x70,
+	0x40, 0xdc, 0x1e, 0x23, 0x1e, 0x8d, 0x7b, 0x61, 0x44, 0x78, 0x6b, 0xe3, 0xa0, 0x7c, 0x58, 0x69,
+	0xbf, 0x3f, 0x4e, 0xed, 0x47, 0x0a, 0xbd, 0xe8, 0xe3, 0xa0, 0x2d, 0xa5, 0xbc, 0x9e, 0xea, 0xe0,
+	0x25, 0xd8, 0xf2, 0x68, 0x32, 0x20, 0x8c, 0x87, 0x34, 0x71, 0x5f, 0x63, 0x4f, 0x50, 0xd6, 0x32,
+	0x0f, 0x4a, 0x87, 0x46, 0xfb, 0x27, 0xe3, 0xd4, 0x6e, 0xe9, 0x3c, 0x8a, 0x2e, 0x0e, 0xb2, 0xa6,
+	0xba, 0x17, 0x52, 0xf5, 0xf4, 0xf1, 0x9f, 0x7e, 0xf8, 0xf6, 0x83, 0xdd, 0x69, 0x83, 0x0e, 0x65,
+	0x8b, 0xaa, 0xde, 0x71, 0xfe, 0x6b, 0x81, 0xea, 0x4c, 0xa1, 0xe0, 0xd7, 0xa0, 0xd1, 0xa5, 0x31,
+	0xe1, 0x82, 0x60, 0xdf, 0xed, 0x44, 0xd4, 0xbb, 0xd5, 0x1d, 0x75, 0xfe, 0xef, 0xd4, 0xde, 0x51,
+	0x1d, 0xc8, 0xfd, 0xdb, 0xa3, 0x90, 0x1e, 0xc7, 0x58, 0x74, 0x8f, 0x2e, 0x13, 0x31, 0x4e, 0xed,
+	0x5d, 0xb5, 0x9c, 0x42, 0xa4, 0xf3, 0xcf, 0xbf, 0x7d, 0x04, 0x74, 0xd3, 0x5e, 0x26, 0x02, 0xd5,
+	0x73, 0x7b, 0x3b, 0x33, 0xc3, 0x01, 0xa8, 0xfb, 0x98, 0xba, 0xaf, 0x29, 0xbb, 0xd5, 0xa8, 0x55,
+	0x89, 0xba, 0xfe, 0xbf, 0xa8, 0xbb, 0xd4, 0xae, 0x3d, 0x3f, 0xff, 0xed, 0x0b, 0xca, 0x6e, 0xe5,
+	0x14, 0xe3, 0xd4, 0xde, 0x51, 0xe8, 0xf9, 0x89, 0x8a, 0xe4, 0x9a, 0x8f, 0x69, 0x1e, 0x04, 0x7f,
+	0x07, 0xac, 0xdc, 0x9d, 0xf7, 0x7b, 0x3d, 0xca, 0x84, 0x6e, 0xe1, 0x8f, 0xee, 0x52, 0xbb, 0xae,
+	0x01, 0xaf, 0x94, 0x65, 0x9c, 0xda, 0xef, 0x15, 0x10, 0x3a, 0xc6, 0x41, 0x75, 0x3d, 0xad, 0x76,
+	0x85, 0x3d, 0x50, 0x23, 0x61, 0xef, 0xe4, 0xec, 0x63, 0x9d, 0x8e, 0x21, 0xd3, 0xf9, 0x62, 0x59,
+	0x3a, 0xd5, 0x8b, 0xcb, 0xeb, 0x93, 0xb3, 0x8f, 0x27, 0xd9, 0xe8, 0xfe, 0x9c, 0x9d, 0xa5, 0x98,
+	0x4b, 0x55, 0x19, 0x55, 0x2a, 0x97, 0x40, 0x8b, 0x6e, 0x17, 0xf3, 0xae, 0x3c, 0x0b, 0x95, 0xf6,
+	0xe1, 0x5d, 0x6a, 0x03, 0x35, 0xef, 0xaf, 0x31, 0xef, 0x4e, 0xf7, 0xa7, 0x33, 0xfa, 0x03, 0x4e,
+	0x44, 0xd8, 0x8f, 0xf5, 0xcc, 0x08, 0xa8, 0xe0, 0xcc, 0x2b, 0x5f, 0xfc, 0x99, 0x5e, 0xfc, 0xfa,
+	0x43, 0x17, 0x7f, 0xf6, 0xae, 0xc5, 0x9f, 0x2d, 0x5b, 0xbc, 0x8a, 0xc8, 0x89, 0x4f, 0x34, 0x71,
+	0xe3, 0xa1, 0xc4, 0x27, 0xef, 0x22, 0x3e, 0x59, 0x46, 0x54, 0x11, 0x59, 0x77, 0x17, 0x6a, 0x20,
+	0xcf, 0xd4, 0xc3, 0xba, 0xbb, 0x58, 0xbd, 0x62, 0x77, 0xe7, 0x76, 0xc5, 0x1a, 0x81, 0xa6, 0x47,
+	0x13, 0x2e, 0x32, 0x5d, 0x42, 0x7b, 0x11, 0xd1, 0xc0, 0x8a, 0x04, 0xbe, 0x58, 0x06, 0x7c, 0x9c,
+	0x9f, 0xee, 0x85, 0xf0, 0x22, 0x75, 0x7b, 0xde, 0x49, 0xa1, 0x63, 0x60, 0xf5, 0x88, 0x20, 0x8c,
+	0x77, 0xfa, 0x2c, 0xd0, 0x58, 0x20, 0xb1, 0xed, 0x65, 0x58, 0xdd, 0xe7, 0xc5, 0xd0, 0x22, 0xb2,
+	0x31, 0x75, 0x50, 0xb8, 0x00, 0xd4, 0xc3, 0x6c, 0x0d, 0x9d, 0x7e, 0xa4, 0x61, 0x55, 0x09, 0xfb,
+	0x7c, 0x19, 0x4c, 0x9f, 0xdb, 0xf9, 0xc0, 0x22, 0x6a, 0x73, 0x62, 0x56, 0x20, 0x06, 0x60, 0xdc,
+	0x0f, 0x99, 0x1b, 0x44, 0xd8, 0x0b, 0x09, 0xd3, 0xb0, 0x9a, 0x84, 0x3d, 0x5f, 0x06, 0xd3, 0xbf,
+	0xbd, 0x8b, 0xc1, 0x45, 0xa0, 0x95, 0xb9, 0xfc, 0x4a, 0x79, 0x28, 0x26, 0x06, 0xb5, 0x0e, 0x61,
+	0x51, 0x98, 0x68, 0xda, 0xa6, 0xa4, 0x7d, 0xb6, 0x8c, 0xa6, 0xbb, 0x72, 0x36, 0x6c, 0xa1, 0x2b,
+	0x95, 0x31, 0x47, 0x44, 0x34, 0xf1, 0xe9, 0x04, 0xb1, 0xf5, 0x60, 0xc4, 0x6c, 0xd8, 0x02, 0x42,
+	0x19, 0x15, 0xa2, 0x0f, 0xb6, 0x31, 0x63, 0xf4, 0x4d, 0xa1, 0x74, 0x50, 0x92, 0x2e, 0x96, 0x91,
+	0xf6, 0xf4, 0xb5, 0xb5, 0x18, 0x5d, 0x04, 0x6e, 0x49, 0x9f, 0xb9, 0xe2, 0x31, 0x00, 0x03, 0x86,
+	0x47, 0x05, 0x6a, 0xf3, 0xc1, 0x1b, 0xb6, 0x18, 0xbc, 0xb0, 0x61, 0x99, 0xcb, 0x1c, 0x73, 0x08,
+	0x9a, 0x31, 0x61, 0x01, 0x71, 0x13, 0x22, 0x78, 0x2f, 0x0a, 0x85, 0xa6, 0xee, 0x3c, 0xf8, 0xdc,
+	0xbd, 0x2b, 0xbc, 0xc8, 0x85, 0xd2, 0xe9, 0x4b, 0xed, 0x93, 0x9f, 0x03, 0xde, 0xc5, 0x49, 0xd0,
+	0xc5, 0xa1, 0x66, 0xee, 0x3e, 0xf8, 0x1c, 0xcc, 0x07, 0x2e, 0x9c, 0x83, 0x89, 0x39, 0x6f, 0x18,
+	0x0f, 0x27, 0x5e, 0x7f, 0xd2, 0x30, 0xef, 0x3d, 0xb8, 0x61, 0x66, 0xc3, 0x16, 0x1a, 0x46, 0x19,
+	0x25, 0xe2, 0xca, 0x30, 0xeb, 0x56, 0xe3, 0xca, 0x30, 0x1b, 0x96, 0x75, 0x65, 0x98, 0x96, 0xb5,
+	0x75, 0x65, 0x98, 0xdb, 0x56, 0x13, 0x6d, 0x8e, 0x68, 0x44, 0xdd, 0xc1, 0x27, 0x6a, 0x0a, 0x54,
+	0x25, 0x6f, 0x30, 0xd7, 0x3f, 0x88, 0xa8, 0xee, 0x61, 0x81, 0xa3, 0x11, 0xd7, 0x25, 0x43, 0x96,
+	0x2a, 0xe4, 0xcc, 0xb5, 0x7c, 0x0c, 0xd6, 0x5e, 0x89, 0xec, 0x01, 0x69, 0x81, 0xf2, 0x2d, 0x19,
+	0xa9, 0xa7, 0x05, 0xca, 0x86, 0xb0, 0x09, 0xd6, 0x06, 0x38, 0xea, 0xab, 0x97, 0x68, 0x05, 0x29,
+	0xc1, 0xb9, 0x06, 0x8d, 0x1b, 0x86, 0x13, 0x9e, 0x3d, 0x90, 0x68, 0xf2, 0x92, 0x06, 0x1c, 0x42,
+	0x60, 0xc8, 0xbb, 0x4e, 0xc5, 0xca, 0x31, 0xfc, 0x39, 0x30, 0x22, 0x1a, 0xf0, 0xd6, 0xea, 0x41,
+	0xf9, 0xb0, 0x7a, 0xba, 0xb3, 0xf8, 0x16, 0x7c, 0x49, 0x03, 0x24, 0x5d, 0x9c, 0x7f, 0xac, 0x82,
+	0xf2, 0x4b, 0x1a, 0xc0, 0x16, 0xd8, 0xc0, 0xbe, 0xcf, 0x08, 0xe7, 0x7a, 0xa6, 0x89, 0x08, 0x77,
+	0xc1, 0xba, 0xa0, 0xbd, 0xd0, 0x53, 0xd3, 0x55, 0x90, 0x96, 0x32, 0xb0, 0x8f, 0x05, 0x96, 0x4f,
+	0x85, 0x1a, 0x92, 0x63, 0x78, 0x0a, 0x6a, 0x32, 0x33, 0x37, 0xe9, 0xc7, 0x1d, 0xc2, 0xe4, 0x8d,
+	0x6f, 0xb4, 0x1b, 0xf7, 0xa9, 0x5d, 0x95, 0xfa, 0x2f, 0xa5, 0x1a, 0xcd, 0x0a, 0xf0, 0x43, 0xb0,
+	0x21, 0x86, 0xb3, 0xf7, 0xf5, 0xf6, 0x7d, 0x6a, 0x37, 0xc4, 0x34, 0xcd, 0xec, 0x3a, 0x46, 0xeb,
+	0x62, 0x28, 0xaf, 0xe5, 0x63, 0x60, 0x8a, 0xa1, 0x1b, 0x26, 0x3e, 0x19, 0xca, 0x2b, 0xd9, 0x68,
+	0x37, 0xef, 0x53, 0xdb, 0x9a, 0x71, 0xbf, 0xcc, 0x6c, 0x68, 0x43, 0x0c, 0xe5, 0x00, 0x7e, 0x08,
+	0x80, 0x5a, 0x92, 0x24, 0xa8, 0x3b, 0x75, 0xf3, 0x3e, 0xb5, 0x2b, 0x52, 0x2b, 0xe7, 0x9e, 0x0e,
+	0xa1, 0x03, 0xd6, 0xd4, 0xdc, 0xea, 0x6d, 0x59, 0xbb, 0x4f, 0x6d, 0x33, 0xa2, 0x81, 0x9a, 0x53,
+	0x99, 0xb2, 0x52, 0x31, 0x12, 0xd3, 0x01, 0xf1, 0xe5, 0xe5, 0x65, 0xa2, 0x89, 0xe8, 0xfc, 0x79,
+	0x15, 0x98, 0x37, 0x43, 0x44, 0x78, 0x3f, 0x12, 0xf0, 0x05, 0xc8, 0xde, 0x9e, 0x82, 0x61, 0x4f,
+	0xb8, 0x73, 0xa5, 0x6d, 0x3f, 0x9e, 0x5e, 0x2e, 0x45, 0x0f, 0x07, 0x35, 0x26, 0xaa, 0x73, 0x5d,
+	0xff, 0x26, 0x58, 0xeb, 0x44, 0x94, 0xc6, 0xb2, 0x13, 0x6a, 0x48, 0x09, 0x10, 0xc9, 0xaa, 0xc9,
+	0x5d, 0x2e, 0xcb, 0x17, 0xff, 0x4f, 0x17, 0x77, 0xb9, 0xd0, 0x2a, 0xed, 0x5d, 0xfd, 0xea, 0xaf,
+	0x2b, 0xb6, 0x8e, 0x77, 0xb2, 0xda, 0xca, 0x56, 0xb2, 0x40, 0x99, 0x11, 0x21, 0x37, 0xad, 0x86,
+	0xb2, 0x21, 0xdc, 0x03, 0x26, 0x23, 0x03, 0xc2, 0x04, 0xf1, 0xe5, 0xe6, 0x98, 0x28, 0x97, 0xe1,
+	0x23, 0x60, 0x06, 0x98, 0xbb, 0x7d, 0x4e, 0x7c, 0xb5, 0x13, 0x68, 0x23, 0xc0, 0xfc, 0x2b, 0x4e,
+	0xfc, 0xa7, 0xc6, 0x1f, 0xbf, 0xb1, 0x57, 0x1c, 0x0c, 0xaa, 0xe7, 0x9e, 0x47, 0x38, 0xbf, 0xe9,
+	0xf7, 0x22, 0xb2, 0xa4, 0xc3, 0x4e, 0x41, 0x8d, 0x0b, 0xca, 0x70, 0x40, 0xdc, 0x5b, 0x32, 0xd2,
+	0x7d, 0xa6, 0xba, 0x46, 0xeb, 0x7f, 0x43, 0x46, 0x1c, 0xcd, 0x0a, 0x1a, 0xf1, 0x8d, 0x01, 0xaa,
+	0x37, 0x0c, 0x7b, 0x44, 0x3f, 0xd7, 0xb3, 0x5e, 0xcd, 0x44, 0xa6, 0x11, 0x5a, 0xca, 0xd8, 0x22,
+	0x8c, 0x09, 0xed, 0x0b, 0x7d, 0x9e, 0x26, 0x62, 0x16, 0xc1, 0x08, 0x19, 0x12, 0x4f, 0x96, 0xd1,
+	0x40, 0x5a, 0x82, 0x67, 0x60, 0xd3, 0x0f, 0xb9, 0xfc, 0x6c, 0xe3, 0x02, 0x7b, 0xb7, 0x2a, 0xfd,
+	0xb6, 0x75, 0x9f, 0xda, 0x35, 0x6d, 0x78, 0x95, 0xe9, 0xd1, 0x9c, 0x04, 0x3f, 0x05, 0x8d, 0x69,
+	0x98, 0x5c, 0xad, 0xfa, 0x46, 0x6a, 0xc3, 0xfb, 0xd4, 0xae, 0xe7, 0xae, 0xd2, 0x82, 0x0a, 0x72,
+	0xb6, 0xd3, 0x3e, 0xe9, 0xf4, 0x03, 0xd9, 0x7c, 0x26, 0x52, 0x42, 0xa6, 0x8d, 0xc2, 0x38, 0x14,
+	0xb2, 0xd9, 0xd6, 0x90, 0x12, 0xe0, 0xa7, 0xa0, 0x42, 0x07, 0x84, 0xb1, 0xd0, 0x27, 0x5c, 0x3e,
+	0x66, 0x7e, 0xec, 0x9b, 0x0f, 0x4d, 0xfd, 0xb3, 0xe4, 0xf4, 0x27, 0x69, 0x4c, 0x62, 0xca, 0x46,
+	0xf2, 0x81, 0xa2, 0x93, 0x53, 0x86, 0x2f, 0xa4, 0x1e, 0xcd, 0x49, 0xb0, 0x0d, 0xa0, 0x0e, 0x63,
+	0x44, 0xf4, 0x59, 0xe2, 0xca, 0xf3, 0x5f, 0x93, 0xb1, 0xf2, 0x14, 0x2a, 0x2b, 0x92, 0xc6, 0xe7,
+	0x58, 0x60, 0xb4, 0xa0, 0x81, 0x9f, 0x01, 0xa8, 0xf6, 0xc4, 0xfd, 0x9a, 0xd3, 0xfc, 0xa3, 0x55,
+	0xbd, 0x22, 0x24, 0x5f, 0x59, 0xf5, 0x9a, 0x2d, 0x25, 0x5d, 0x71, 0xaa, 0xb3, 0xb8, 0x32, 0x4c,
+	0xc3, 0x5a, 0xbb, 0x32, 0xcc, 0x0d, 0xcb, 0xcc, 0xeb, 0xa7, 0xb3, 0x40, 0xdb, 0x13, 0x79, 0x66,
+	0x79, 0xed, 0xcf, 0xbf, 0xbb, 0xdb, 0x2f, 0x7d, 0x7f, 0xb7, 0x5f, 0xfa, 0xcf, 0xdd, 0x7e, 0xe9,
+	0x2f, 0x6f, 0xf7, 0x57, 0xbe, 0x7f, 0xbb, 0xbf, 0xf2, 0xaf, 0xb7, 0xfb, 0x2b, 0xbf, 0xff, 0x59,
+	0x10, 0x8a, 0x6e, 0xbf, 0x73, 0xe4, 0xd1, 0x38, 0xfb, 0x04, 0xa4, 0xfc, 0xb8, 0xf8, 0x51, 0x28,
+	0x46, 0x3d, 0xc2, 0x3b, 0xeb, 0xf2, 0x2f, 0x0c, 0x9f, 0xfc, 0x2f, 0x00, 0x00, 0xff, 0xff, 0x4e,
+	0x6c, 0x91, 0x3c, 0xd5, 0x10, 0x00, 0x00,
 }
 
 func (m *Params) Marshal() (dAtA []byte, err error) {
@@ -818,6 +844,20 @@ func (m *Params) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.ConversionFactor != 0 {
+		i = encodeVarintEvm(dAtA, i, uint64(m.ConversionFactor))
+		i--
+		dAtA[i] = 0x40
+	}
+	if len(m.ActivePrecompiles) > 0 {
+		for iNdEx := len(m.ActivePrecompiles) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.ActivePrecompiles[iNdEx])
+			copy(dAtA[i:], m.ActivePrecompiles[iNdEx])
+			i = encodeVarintEvm(dAtA, i, uint64(len(m.ActivePrecompiles[iNdEx])))
+			i--
+			dAtA[i] = 0x3a
+		}
+	}
 	if m.AllowUnprotectedTxs {
 		i--
 		if m.AllowUnprotectedTxs {
@@ -1572,6 +1612,15 @@ func (m *Params) Size() (n int) {
 	if m.AllowUnprotectedTxs {
 		n += 2
 	}
+	if len(m.ActivePrecompiles) > 0 {
+		for _, s := range m.ActivePrecompiles {
+			l = len(s)
+			n += 1 + l + sovEvm(uint64(l))
+		}
+	}
+	if m.ConversionFactor != 0 {
+		n += 1 + sovEvm(uint64(m.ConversionFactor))
+	}
 	return n
 }
 
@@ -2068,6 +2117,57 @@ func (m *Params) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.AllowUnprotectedTxs = bool(v != 0)
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ActivePrecompiles", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvm
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthEvm
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthEvm
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ActivePrecompiles = append(m.ActivePrecompiles, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ConversionFactor", wireType)
+			}
+			m.ConversionFactor = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvm
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ConversionFactor |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipEvm(dAtA[iNdEx:])
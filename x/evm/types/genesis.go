@@ -18,6 +18,8 @@ package types
 import (
 	"fmt"
 
+	"github.com/ethereum/go-ethereum/common"
+
 	ethermint "github.com/evmos/ethermint/types"
 )
 
@@ -29,6 +31,17 @@ func (ga GenesisAccount) Validate() error {
 	return ga.Storage.Validate()
 }
 
+// Validate performs a basic validation of a FractionalBalance fields.
+func (fb FractionalBalance) Validate() error {
+	if err := ethermint.ValidateAddress(fb.Address); err != nil {
+		return err
+	}
+	if fb.Amount.IsNil() || !fb.Amount.IsPositive() {
+		return fmt.Errorf("fractional balance must be positive, got %s", fb.Amount)
+	}
+	return nil
+}
+
 // DefaultGenesisState sets default evm genesis state with empty accounts and default params and
 // chain config values.
 func DefaultGenesisState() *GenesisState {
@@ -60,5 +73,25 @@ func (gs GenesisState) Validate() error {
 		seenAccounts[acc.Address] = true
 	}
 
-	return gs.Params.Validate()
+	if err := gs.Params.Validate(); err != nil {
+		return err
+	}
+
+	factor := gs.Params.EVMConversionFactor()
+	seenBalances := make(map[common.Address]bool)
+	for _, balance := range gs.FractionalBalances {
+		if err := balance.Validate(); err != nil {
+			return fmt.Errorf("invalid fractional balance %s: %w", balance.Address, err)
+		}
+		addr := common.HexToAddress(balance.Address)
+		if seenBalances[addr] {
+			return fmt.Errorf("duplicated fractional balance %s", balance.Address)
+		}
+		if balance.Amount.BigInt().Cmp(factor) >= 0 {
+			return fmt.Errorf("fractional balance %s of %s is not below the conversion factor %s", balance.Amount, balance.Address, factor)
+		}
+		seenBalances[addr] = true
+	}
+
+	return nil
 }
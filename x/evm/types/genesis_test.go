@@ -3,6 +3,7 @@ package types
 import (
 	"testing"
 
+	sdkmath "cosmossdk.io/math"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/suite"
 
@@ -83,6 +84,9 @@ func (suite *GenesisTestSuite) TestValidateGenesisAccount() {
 }
 
 func (suite *GenesisTestSuite) TestValidateGenesis() {
+	extendedParams := DefaultParams()
+	extendedParams.ConversionFactor = 1000000
+
 	testCases := []struct {
 		name     string
 		genState *GenesisState
@@ -211,6 +215,47 @@ func (suite *GenesisTestSuite) TestValidateGenesis() {
 			},
 			expPass: false,
 		},
+		{
+			name: "valid fractional balance",
+			genState: &GenesisState{
+				Params: extendedParams,
+				FractionalBalances: []FractionalBalance{
+					{Address: suite.address, Amount: sdkmath.NewInt(999999)},
+				},
+			},
+			expPass: true,
+		},
+		{
+			name: "fractional balance not below the conversion factor",
+			genState: &GenesisState{
+				Params: extendedParams,
+				FractionalBalances: []FractionalBalance{
+					{Address: suite.address, Amount: sdkmath.NewInt(1000000)},
+				},
+			},
+			expPass: false,
+		},
+		{
+			name: "zero fractional balance",
+			genState: &GenesisState{
+				Params: extendedParams,
+				FractionalBalances: []FractionalBalance{
+					{Address: suite.address, Amount: sdkmath.ZeroInt()},
+				},
+			},
+			expPass: false,
+		},
+		{
+			name: "duplicated fractional balance",
+			genState: &GenesisState{
+				Params: extendedParams,
+				FractionalBalances: []FractionalBalance{
+					{Address: suite.address, Amount: sdkmath.NewInt(1)},
+					{Address: suite.address, Amount: sdkmath.NewInt(2)},
+				},
+			},
+			expPass: false,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -16,6 +16,8 @@
 package types
 
 import (
+	"encoding/binary"
+
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -41,6 +43,10 @@ const (
 	prefixCode = iota + 1
 	prefixStorage
 	prefixParams
+	prefixFractionalBalance
+	prefixTotalFractionalBalance
+	prefixICS20Packet
+	prefixStorageHash
 )
 
 // prefix bytes for the EVM transient store
@@ -56,6 +62,13 @@ var (
 	KeyPrefixCode    = []byte{prefixCode}
 	KeyPrefixStorage = []byte{prefixStorage}
 	KeyPrefixParams  = []byte{prefixParams}
+
+	KeyPrefixFractionalBalance = []byte{prefixFractionalBalance}
+	KeyTotalFractionalBalance  = []byte{prefixTotalFractionalBalance}
+
+	KeyPrefixICS20Packet = []byte{prefixICS20Packet}
+
+	KeyPrefixStorageHash = []byte{prefixStorageHash}
 )
 
 // Transient Store key prefixes
@@ -75,3 +88,20 @@ func AddressStoragePrefix(address common.Address) []byte {
 func StateKey(address common.Address, key []byte) []byte {
 	return append(AddressStoragePrefix(address), key...)
 }
+
+// StorageHashKey defines the key under which the storage hash of an account is stored.
+func StorageHashKey(address common.Address) []byte {
+	return append(KeyPrefixStorageHash, address.Bytes()...)
+}
+
+// FractionalBalanceKey defines the key under which the fractional balance of an account is stored.
+func FractionalBalanceKey(address common.Address) []byte {
+	return append(KeyPrefixFractionalBalance, address.Bytes()...)
+}
+
+// ICS20PacketKey defines the key under which a transfer packet sent through the ICS-20
+// precompile is recorded until its acknowledgement or timeout.
+func ICS20PacketKey(sourceChannel string, sequence uint64) []byte {
+	key := append(append(KeyPrefixICS20Packet, sourceChannel...), '/')
+	return binary.BigEndian.AppendUint64(key, sequence)
+}
@@ -399,8 +399,15 @@ func (msg *MsgEthereumTx) UnmarshalBinary(b []byte) error {
 	return msg.FromEthereumTx(tx)
 }
 
-// BuildTx builds the canonical cosmos tx from ethereum msg
+// BuildTx builds the canonical cosmos tx from ethereum msg, for an evm denom converted
+// 1:1 to wei.
 func (msg *MsgEthereumTx) BuildTx(b client.TxBuilder, evmDenom string) (authsigning.Tx, error) {
+	return msg.BuildTxWithParams(b, Params{EvmDenom: evmDenom})
+}
+
+// BuildTxWithParams builds the canonical cosmos tx from ethereum msg, the fee is in
+// units of the evm denom of the params.
+func (msg *MsgEthereumTx) BuildTxWithParams(b client.TxBuilder, params Params) (authsigning.Tx, error) {
 	builder, ok := b.(authtx.ExtensionOptionsTxBuilder)
 	if !ok {
 		return nil, errors.New("unsupported builder")
@@ -416,9 +423,9 @@ func (msg *MsgEthereumTx) BuildTx(b client.TxBuilder, evmDenom string) (authsign
 		return nil, err
 	}
 	fees := make(sdk.Coins, 0)
-	feeAmt := sdkmath.NewIntFromBigInt(txData.Fee())
+	feeAmt := sdkmath.NewIntFromBigInt(params.FeeUnits(txData.Fee()))
 	if feeAmt.Sign() > 0 {
-		fees = append(fees, sdk.NewCoin(evmDenom, feeAmt))
+		fees = append(fees, sdk.NewCoin(params.EvmDenom, feeAmt))
 	}
 
 	builder.SetExtensionOptions(option)
@@ -109,6 +109,16 @@ func (suite *MsgsTestSuite) TestMsgEthereumTx_BuildTx() {
 	}
 }
 
+func (suite *MsgsTestSuite) TestMsgEthereumTx_BuildTxWithParams() {
+	// the fee in wei is rounded up to units of the evm denom
+	msg := types.NewTx(nil, 0, &suite.to, nil, 100000, big.NewInt(15000000), nil, nil, []byte("test"), nil)
+	params := types.Params{EvmDenom: "ustake", ConversionFactor: 1000000000000}
+	tx, err := msg.BuildTxWithParams(suite.clientCtx.TxConfig.NewTxBuilder(), params)
+	suite.Require().NoError(err)
+	suite.Require().Equal(uint64(100000), tx.GetGas())
+	suite.Require().Equal(sdk.NewCoins(sdk.NewCoin("ustake", sdkmath.NewInt(2))), tx.GetFee())
+}
+
 func (suite *MsgsTestSuite) TestMsgEthereumTx_ValidateBasic() {
 	hundredInt := big.NewInt(100)
 	zeroInt := big.NewInt(0)
@@ -21,7 +21,9 @@ import (
 
 	"github.com/ethereum/go-ethereum/params"
 
+	sdkmath "cosmossdk.io/math"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/evmos/ethermint/types"
 )
@@ -35,8 +37,13 @@ var (
 	DefaultEnableCreate = true
 	// DefaultEnableCall enables contract calls (i.e true)
 	DefaultEnableCall = true
+	// DefaultConversionFactor converts the EVM denom 1:1 to wei (i.e 1)
+	DefaultConversionFactor = uint64(1)
 )
 
+// maxConversionFactor is the conversion factor of a denom without decimals.
+const maxConversionFactor = uint64(1e18)
+
 // AvailableExtraEIPs define the list of all EIPs that can be enabled by the
 // EVM interpreter. These EIPs are applied in order and can override the
 // instruction sets from the latest hard fork enabled by the ChainConfig. For
@@ -66,6 +73,7 @@ func DefaultParams() Params {
 		ChainConfig:         DefaultChainConfig(),
 		ExtraEIPs:           nil,
 		AllowUnprotectedTxs: DefaultAllowUnprotectedTxs,
+		ConversionFactor:    DefaultConversionFactor,
 	}
 }
 
@@ -91,6 +99,14 @@ func (p Params) Validate() error {
 		return err
 	}
 
+	if err := validatePrecompiles(p.ActivePrecompiles); err != nil {
+		return err
+	}
+
+	if err := validateConversionFactor(p.ConversionFactor); err != nil {
+		return err
+	}
+
 	return validateChainConfig(p.ChainConfig)
 }
 
@@ -103,6 +119,46 @@ func (p Params) EIPs() []int {
 	return eips
 }
 
+// ActivePrecompileAddresses returns the ActivePrecompiles as a set of addresses
+func (p Params) ActivePrecompileAddresses() map[common.Address]struct{} {
+	addrs := make(map[common.Address]struct{}, len(p.ActivePrecompiles))
+	for _, precompile := range p.ActivePrecompiles {
+		addrs[common.HexToAddress(precompile)] = struct{}{}
+	}
+	return addrs
+}
+
+// EVMConversionFactor returns the number of EVM wei per unit of EvmDenom, one if
+// the ConversionFactor is unset.
+func (p Params) EVMConversionFactor() *big.Int {
+	if p.ConversionFactor == 0 {
+		return big.NewInt(1)
+	}
+	return new(big.Int).SetUint64(p.ConversionFactor)
+}
+
+// FeeUnits converts a fee in wei to units of EvmDenom, as charged to the cosmos txs.
+// The sub-unit remainder is rounded up, so the fee in units covers the fee in wei.
+func (p Params) FeeUnits(fee *big.Int) *big.Int {
+	units, remainder := new(big.Int).QuoRem(fee, p.EVMConversionFactor(), new(big.Int))
+	if remainder.Sign() > 0 {
+		units.Add(units, big.NewInt(1))
+	}
+	return units
+}
+
+// GasPriceToWei converts a gas price in units of EvmDenom, such as the node
+// min-gas-prices, to wei.
+func (p Params) GasPriceToWei(price sdkmath.LegacyDec) sdkmath.LegacyDec {
+	return price.MulInt(sdkmath.NewIntFromBigInt(p.EVMConversionFactor()))
+}
+
+// GasPriceToUnits converts a gas price in wei, such as the base fee, to units of
+// EvmDenom.
+func (p Params) GasPriceToUnits(price sdkmath.LegacyDec) sdkmath.LegacyDec {
+	return price.QuoInt(sdkmath.NewIntFromBigInt(p.EVMConversionFactor()))
+}
+
 func validateEVMDenom(i interface{}) error {
 	denom, ok := i.(string)
 	if !ok {
@@ -135,6 +191,45 @@ func validateEIPs(i interface{}) error {
 	return nil
 }
 
+func validatePrecompiles(i interface{}) error {
+	precompiles, ok := i.([]string)
+	if !ok {
+		return fmt.Errorf("invalid precompile slice type: %T", i)
+	}
+
+	seen := make(map[common.Address]bool, len(precompiles))
+	for _, precompile := range precompiles {
+		if !common.IsHexAddress(precompile) {
+			return fmt.Errorf("invalid precompile address: %s", precompile)
+		}
+		addr := common.HexToAddress(precompile)
+		if seen[addr] {
+			return fmt.Errorf("duplicate precompile address: %s", precompile)
+		}
+		seen[addr] = true
+	}
+
+	return nil
+}
+
+func validateConversionFactor(i interface{}) error {
+	factor, ok := i.(uint64)
+	if !ok {
+		return fmt.Errorf("invalid conversion factor type: %T", i)
+	}
+	if factor > maxConversionFactor {
+		return fmt.Errorf("conversion factor %d is above %d", factor, maxConversionFactor)
+	}
+
+	for f := factor; f > 1; f /= 10 {
+		if f%10 != 0 {
+			return fmt.Errorf("conversion factor %d is not a power of ten", factor)
+		}
+	}
+
+	return nil
+}
+
 func validateChainConfig(i interface{}) error {
 	cfg, ok := i.(ChainConfig)
 	if !ok {
@@ -148,3 +243,13 @@ func validateChainConfig(i interface{}) error {
 func IsLondon(ethConfig *params.ChainConfig, height int64) bool {
 	return ethConfig.IsLondon(big.NewInt(height))
 }
+
+// IsShanghai returns if shanghai hardfork is enabled.
+func IsShanghai(ethConfig *params.ChainConfig, height int64) bool {
+	return ethConfig.IsShanghai(big.NewInt(height))
+}
+
+// IsCancun returns if cancun hardfork is enabled.
+func IsCancun(ethConfig *params.ChainConfig, height int64) bool {
+	return ethConfig.IsCancun(big.NewInt(height))
+}
@@ -1,8 +1,10 @@
 package types
 
 import (
+	"math/big"
 	"testing"
 
+	sdkmath "cosmossdk.io/math"
 	"github.com/ethereum/go-ethereum/params"
 
 	"github.com/stretchr/testify/require"
@@ -41,6 +43,54 @@ func TestParamsValidate(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"valid precompiles",
+			Params{
+				EvmDenom:          "stake",
+				ActivePrecompiles: []string{"0x0000000000000000000000000000000000000800", "0x0000000000000000000000000000000000000804"},
+			},
+			false,
+		},
+		{
+			"invalid precompile address",
+			Params{
+				EvmDenom:          "stake",
+				ActivePrecompiles: []string{"0x0800"},
+			},
+			true,
+		},
+		{
+			"duplicate precompile",
+			Params{
+				EvmDenom:          "stake",
+				ActivePrecompiles: []string{"0x0000000000000000000000000000000000000800", "0x0000000000000000000000000000000000000800"},
+			},
+			true,
+		},
+		{
+			"valid conversion factor",
+			Params{
+				EvmDenom:         "ustake",
+				ConversionFactor: 1000000000000,
+			},
+			false,
+		},
+		{
+			"conversion factor not a power of ten",
+			Params{
+				EvmDenom:         "ustake",
+				ConversionFactor: 1200,
+			},
+			true,
+		},
+		{
+			"conversion factor above 18 decimals",
+			Params{
+				EvmDenom:         "ustake",
+				ConversionFactor: 10000000000000000000,
+			},
+			true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -54,6 +104,19 @@ func TestParamsValidate(t *testing.T) {
 	}
 }
 
+func TestParamsConversion(t *testing.T) {
+	params := Params{EvmDenom: "ustake", ConversionFactor: 1000000000000}
+	require.Equal(t, big.NewInt(2), params.FeeUnits(big.NewInt(1500000000000)))
+	require.Equal(t, big.NewInt(1), params.FeeUnits(big.NewInt(1000000000000)))
+	require.Equal(t, sdkmath.LegacyNewDec(25000000000), params.GasPriceToWei(sdkmath.LegacyNewDecWithPrec(25, 3)))
+	require.Equal(t, sdkmath.LegacyNewDecWithPrec(25, 3), params.GasPriceToUnits(sdkmath.LegacyNewDec(25000000000)))
+
+	// the default conversion is 1:1
+	params = DefaultParams()
+	require.Equal(t, big.NewInt(1500000000000), params.FeeUnits(big.NewInt(1500000000000)))
+	require.Equal(t, sdkmath.LegacyNewDec(10), params.GasPriceToWei(sdkmath.LegacyNewDec(10)))
+}
+
 func TestParamsEIPs(t *testing.T) {
 	extraEips := []int64{2929, 1884, 1344}
 	params := NewParams("ara", false, true, true, DefaultChainConfig(), extraEips)
@@ -69,6 +132,10 @@ func TestParamsValidatePriv(t *testing.T) {
 	require.NoError(t, validateBool(true))
 	require.Error(t, validateEIPs(""))
 	require.NoError(t, validateEIPs([]int64{1884}))
+	require.Error(t, validatePrecompiles(""))
+	require.NoError(t, validatePrecompiles([]string{"0x0000000000000000000000000000000000000801"}))
+	require.Error(t, validateConversionFactor(int64(10)))
+	require.NoError(t, validateConversionFactor(uint64(0)))
 }
 
 func TestValidateChainConfig(t *testing.T) {
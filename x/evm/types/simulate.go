@@ -0,0 +1,120 @@
+// Copyright 2021 Evmos Foundation
+// This file is part of Evmos' Ethermint library.
+//
+// The Ethermint library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Ethermint library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Ethermint library. If not, see https://github.com/evmos/ethermint/blob/main/LICENSE
+package types
+
+import (
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// MaxSimulateBlocks is the maximum number of blocks of an `eth_simulateV1` request.
+	MaxSimulateBlocks = 256
+	// SimulateTimestampIncrement is the default time between two simulated blocks, in seconds.
+	SimulateTimestampIncrement = 1
+
+	// simCallErrorCodeReverted is the json rpc error code of a reverted simulated call.
+	simCallErrorCodeReverted = 3
+	// simCallErrorCodeVMError is the json rpc error code of a simulated call failed with a vm error.
+	simCallErrorCodeVMError = -32015
+)
+
+// SimOpts are the inputs of `eth_simulateV1`.
+type SimOpts struct {
+	BlockStateCalls []SimBlock `json:"blockStateCalls"`
+}
+
+// SimBlock is a batch of calls executed in the same simulated block.
+type SimBlock struct {
+	BlockOverrides *BlockOverrides `json:"blockOverrides,omitempty"`
+	// StateOverrides are applied before the calls of the block, they use the same
+	// format as the `eth_call` state overrides.
+	StateOverrides json.RawMessage   `json:"stateOverrides,omitempty"`
+	Calls          []TransactionArgs `json:"calls"`
+}
+
+// BlockOverrides is the set of header fields to override in a simulated block.
+type BlockOverrides struct {
+	Number        *hexutil.Big    `json:"number,omitempty"`
+	Time          *hexutil.Uint64 `json:"time,omitempty"`
+	FeeRecipient  *common.Address `json:"feeRecipient,omitempty"`
+	BaseFeePerGas *hexutil.Big    `json:"baseFeePerGas,omitempty"`
+}
+
+// SimBlockResult is the result of a simulated block.
+type SimBlockResult struct {
+	Number        hexutil.Uint64  `json:"number"`
+	Hash          common.Hash     `json:"hash"`
+	ParentHash    common.Hash     `json:"parentHash"`
+	Timestamp     hexutil.Uint64  `json:"timestamp"`
+	GasLimit      hexutil.Uint64  `json:"gasLimit"`
+	GasUsed       hexutil.Uint64  `json:"gasUsed"`
+	FeeRecipient  common.Address  `json:"miner"`
+	BaseFeePerGas *hexutil.Big    `json:"baseFeePerGas,omitempty"`
+	Calls         []SimCallResult `json:"calls"`
+}
+
+// SimCallResult is the result of a simulated call.
+type SimCallResult struct {
+	ReturnData hexutil.Bytes   `json:"returnData"`
+	Logs       []*ethtypes.Log `json:"logs"`
+	GasUsed    hexutil.Uint64  `json:"gasUsed"`
+	Status     hexutil.Uint64  `json:"status"`
+	Error      *SimCallError   `json:"error,omitempty"`
+}
+
+// SimCallError is the error of a failed simulated call.
+type SimCallError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data,omitempty"`
+}
+
+// NewSimCallResult builds the result of a simulated call from the response of the
+// message execution.
+func NewSimCallResult(res *MsgEthereumTxResponse) SimCallResult {
+	result := SimCallResult{
+		ReturnData: res.Ret,
+		Logs:       LogsToEthereum(res.Logs),
+		GasUsed:    hexutil.Uint64(res.GasUsed),
+		Status:     hexutil.Uint64(ethtypes.ReceiptStatusSuccessful),
+	}
+	if result.Logs == nil {
+		result.Logs = []*ethtypes.Log{}
+	}
+	if !res.Failed() {
+		return result
+	}
+
+	result.Status = hexutil.Uint64(ethtypes.ReceiptStatusFailed)
+	if revert := res.Revert(); revert != nil {
+		revertErr := NewExecErrorWithReason(revert)
+		result.Error = &SimCallError{
+			Code:    simCallErrorCodeReverted,
+			Message: revertErr.Error(),
+			Data:    hexutil.Encode(revert),
+		}
+	} else {
+		result.Error = &SimCallError{
+			Code:    simCallErrorCodeVMError,
+			Message: res.VmError,
+		}
+	}
+	return result
+}
@@ -21,6 +21,8 @@ import (
 
 	errorsmod "cosmossdk.io/errors"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
 )
 
 // Storage represents the account Storage map as a slice of single key value
@@ -79,3 +81,33 @@ func NewState(key, value common.Hash) State {
 		Value: value.String(),
 	}
 }
+
+// StorageHash returns the storage commitment of an account, the sum modulo 2^256 of the
+// hashes of its non-empty slots. Unlike the ethereum storage root it isn't the root of a
+// trie: it's persisted under the `StorageHashKey` of the account and updated by each slot
+// write with UpdateStorageHash, and the slots are proven by the IAVL proofs of their
+// `StateKey`.
+func StorageHash(storage map[common.Hash]common.Hash) common.Hash {
+	hash := new(uint256.Int)
+	for key, value := range storage {
+		hash.Add(hash, slotHash(key, value))
+	}
+	return hash.Bytes32()
+}
+
+// UpdateStorageHash returns the storage hash of an account once the value of a slot is
+// changed from prevValue to value.
+func UpdateStorageHash(hash, key, prevValue, value common.Hash) common.Hash {
+	updated := new(uint256.Int).SetBytes(hash.Bytes())
+	updated.Sub(updated, slotHash(key, prevValue))
+	updated.Add(updated, slotHash(key, value))
+	return updated.Bytes32()
+}
+
+// slotHash returns the term of a slot in the storage hash, the empty slots are left out.
+func slotHash(key, value common.Hash) *uint256.Int {
+	if value == (common.Hash{}) {
+		return new(uint256.Int)
+	}
+	return new(uint256.Int).SetBytes(crypto.Keccak256(key.Bytes(), value.Bytes()))
+}
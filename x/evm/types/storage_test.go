@@ -1,6 +1,7 @@
 package types
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -82,3 +83,31 @@ func TestStorageString(t *testing.T) {
 	str := "key:\"0x00000000000000000000000000000000000000000000000000000000006b6579\" value:\"0x00000000000000000000000000000000000000000000000000000076616c7565\" \n"
 	require.Equal(t, str, storage.String())
 }
+
+func TestStorageHash(t *testing.T) {
+	require.Equal(t, common.Hash{}, StorageHash(nil))
+	// empty slots are left out
+	require.Equal(t, common.Hash{}, StorageHash(map[common.Hash]common.Hash{{1}: {}}))
+
+	storage := map[common.Hash]common.Hash{
+		common.BigToHash(big.NewInt(0)): common.BigToHash(big.NewInt(42)),
+		common.BigToHash(big.NewInt(1)): common.HexToHash("0xdeadbeef"),
+		common.HexToHash("0xabcd"):      common.BytesToHash([]byte("value")),
+	}
+	hash := StorageHash(storage)
+	require.NotEqual(t, common.Hash{}, hash)
+
+	// the updates of the slots give the hash of the updated storage
+	updated := UpdateStorageHash(hash, common.HexToHash("0xabcd"), storage[common.HexToHash("0xabcd")], common.Hash{})
+	delete(storage, common.HexToHash("0xabcd"))
+	require.Equal(t, StorageHash(storage), updated)
+
+	updated = UpdateStorageHash(updated, common.BigToHash(big.NewInt(0)), storage[common.BigToHash(big.NewInt(0))], common.BigToHash(big.NewInt(7)))
+	storage[common.BigToHash(big.NewInt(0))] = common.BigToHash(big.NewInt(7))
+	require.Equal(t, StorageHash(storage), updated)
+
+	for key, value := range storage {
+		updated = UpdateStorageHash(updated, key, value, common.Hash{})
+	}
+	require.Equal(t, common.Hash{}, updated)
+}
@@ -16,7 +16,10 @@
 package geth
 
 import (
+	"bytes"
 	"math/big"
+	"slices"
+	"sort"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/vm"
@@ -33,22 +36,54 @@ var (
 // EVM is the wrapper for the go-ethereum EVM.
 type EVM struct {
 	*vm.EVM
+
+	stateDB           vm.StateDB
+	customPrecompiles evm.PrecompiledContracts
 }
 
 // NewEVM defines the constructor function for the go-ethereum (geth) EVM. It uses
 // the default precompiled contracts and the EVM concrete implementation from
 // geth.
+//
+// The custom precompiles are registered in the geth EVM, the interpreter runs them
+// for the top-level and the nested calls like the default precompiled contracts.
 func NewEVM(
 	blockCtx vm.BlockContext,
 	txCtx vm.TxContext,
 	stateDB vm.StateDB,
 	chainConfig *params.ChainConfig,
 	config vm.Config,
-	_ evm.PrecompiledContracts, // unused
+	customPrecompiles evm.PrecompiledContracts,
 ) evm.EVM {
-	return &EVM{
-		EVM: vm.NewEVM(blockCtx, txCtx, stateDB, chainConfig, config),
+	e := &EVM{
+		EVM:               vm.NewEVM(blockCtx, txCtx, stateDB, chainConfig, config),
+		stateDB:           stateDB,
+		customPrecompiles: customPrecompiles,
+	}
+	e.EVM.WithPrecompiles(e.gethPrecompiles())
+	return e
+}
+
+// gethPrecompiles returns the custom precompiles for the geth EVM, the stateful ones
+// are run with the wrapper EVM.
+func (e *EVM) gethPrecompiles() map[common.Address]vm.PrecompiledContract {
+	if len(e.customPrecompiles) == 0 {
+		return nil
 	}
+	precompiles := make(map[common.Address]vm.PrecompiledContract, len(e.customPrecompiles))
+	for addr, p := range e.customPrecompiles {
+		if sp, ok := p.(evm.StatefulPrecompiledContract); ok {
+			p = statefulPrecompile{StatefulPrecompiledContract: sp, evm: e}
+		}
+		precompiles[addr] = p
+	}
+	return precompiles
+}
+
+// Reset resets the EVM with a new transaction context and StateDB.
+func (e *EVM) Reset(txCtx vm.TxContext, stateDB vm.StateDB) {
+	e.EVM.Reset(txCtx, stateDB)
+	e.stateDB = stateDB
 }
 
 // Context returns the EVM's Block Context
@@ -61,6 +96,11 @@ func (e EVM) TxContext() vm.TxContext {
 	return e.EVM.TxContext
 }
 
+// StateDB returns the state database of the EVM.
+func (e EVM) StateDB() vm.StateDB {
+	return e.stateDB
+}
+
 // Config returns the configuration options for the EVM.
 func (e EVM) Config() vm.Config {
 	return e.EVM.Config
@@ -70,25 +110,64 @@ func (e EVM) Config() vm.Config {
 // and the current chain configuration. If the contract cannot be found it returns
 // nil.
 func (e EVM) Precompile(addr common.Address) (p vm.PrecompiledContract, found bool) {
+	if p, found = e.customPrecompiles[addr]; found {
+		return p, found
+	}
 	precompiles := GetPrecompiles(e.ChainConfig(), e.EVM.Context.BlockNumber)
 	p, found = precompiles[addr]
 	return p, found
 }
 
 // ActivePrecompiles returns a list of all the active precompiled contract addresses
-// for the current chain configuration.
-func (EVM) ActivePrecompiles(rules params.Rules) []common.Address {
-	return vm.ActivePrecompiles(rules)
+// for the current chain configuration, including the custom ones.
+func (e EVM) ActivePrecompiles(rules params.Rules) []common.Address {
+	custom := make([]common.Address, 0, len(e.customPrecompiles))
+	for addr := range e.customPrecompiles {
+		custom = append(custom, addr)
+	}
+	sort.Slice(custom, func(i, j int) bool {
+		return bytes.Compare(custom[i].Bytes(), custom[j].Bytes()) < 0
+	})
+	// the geth addresses are shared, they're copied
+	return append(slices.Clone(vm.ActivePrecompiles(rules)), custom...)
 }
 
-// RunPrecompiledContract runs a stateless precompiled contract and ignores the address and
-// value arguments. It uses the RunPrecompiledContract function from the geth vm package
-func (EVM) RunPrecompiledContract(
+// RunPrecompiledContract runs a stateful precompiled contract with the caller address,
+// the call value and the read-only flag, after charging its required gas.
+func (e EVM) RunPrecompiledContract(
 	p evm.StatefulPrecompiledContract,
-	_ common.Address, // address arg is unused
+	caller common.Address,
 	input []byte,
 	suppliedGas uint64,
-	_ *big.Int, // 	value arg is unused
+	value *big.Int,
+	readOnly bool,
 ) (ret []byte, remainingGas uint64, err error) {
-	return vm.RunPrecompiledContract(p, input, suppliedGas)
+	gasCost := p.RequiredGas(input)
+	if suppliedGas < gasCost {
+		return nil, 0, vm.ErrOutOfGas
+	}
+	return p.RunStateful(&e, caller, input, suppliedGas-gasCost, value, readOnly)
+}
+
+// statefulPrecompile is a custom stateful precompile registered in the geth EVM, it's
+// run by the interpreter with the wrapper EVM.
+type statefulPrecompile struct {
+	evm.StatefulPrecompiledContract
+
+	evm *EVM
+}
+
+var _ vm.StatefulPrecompiledContract = statefulPrecompile{}
+
+// RunStateful implements vm.StatefulPrecompiledContract, the geth EVM has already
+// charged the required gas of the contract.
+func (p statefulPrecompile) RunStateful(
+	_ *vm.EVM,
+	caller common.Address,
+	input []byte,
+	gas uint64,
+	value *big.Int,
+	readOnly bool,
+) ([]byte, uint64, error) {
+	return p.StatefulPrecompiledContract.RunStateful(p.evm, caller, input, gas, value, readOnly)
 }
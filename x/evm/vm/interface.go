@@ -27,9 +27,13 @@ import (
 // PrecompiledContracts defines a map of address -> precompiled contract
 type PrecompiledContracts map[common.Address]vm.PrecompiledContract
 
+// StatefulPrecompiledContract defines a precompiled contract with access to the EVM
+// state, it's run with the caller address, the call value, whether the call is
+// read-only and the gas left after its required gas. It returns the gas remaining
+// after the run.
 type StatefulPrecompiledContract interface {
 	vm.PrecompiledContract
-	RunStateful(evm EVM, addr common.Address, input []byte, value *big.Int) (ret []byte, err error)
+	RunStateful(evm EVM, caller common.Address, input []byte, gas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error)
 }
 
 // EVM defines the interface for the Ethereum Virtual Machine used by the EVM module.
@@ -37,6 +41,7 @@ type EVM interface {
 	Config() vm.Config
 	Context() vm.BlockContext
 	TxContext() vm.TxContext
+	StateDB() vm.StateDB
 
 	Reset(txCtx vm.TxContext, statedb vm.StateDB)
 	Cancel()
@@ -64,7 +69,8 @@ type EVM interface {
 		addr common.Address,
 		input []byte,
 		suppliedGas uint64,
-		value *big.Int) (
+		value *big.Int,
+		readOnly bool) (
 		ret []byte, remainingGas uint64, err error,
 	)
 }